@@ -62,7 +62,8 @@ func setupFiberApp() *fiber.App {
 	app.Use(compress.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     config.Env.Cors.Origins,
-		AllowHeaders:     "Content-Type,Authorization,Accept,Origin,X-Requested-With",
+		AllowHeaders:     "Content-Type,Authorization,Accept,Origin,X-Requested-With,Idempotency-Key",
+		ExposeHeaders:    "Idempotent-Replayed",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
 		AllowCredentials: true,
 	}))
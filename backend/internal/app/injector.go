@@ -2,6 +2,7 @@ package app
 
 import (
 	"app/internal/config"
+	"app/internal/cron"
 	"app/internal/handler"
 	"app/internal/repository"
 	"app/internal/usecase"
@@ -77,9 +78,15 @@ func InjectHTTPHandlers(ctx context.Context, app *fiber.App) {
 	authHandler := handler.NewAuthHandler(authUsecase)
 	authHandler.RegisterRoutes(app, db)
 
+	// Ingredient setup, recipes deduct ingredients when a sale is paid
+	ingredientRepo := repository.NewIngredientRepository(db)
+	ingredientUsecase := usecase.NewIngredientUsecase(ingredientRepo)
+	ingredientHandler := handler.NewIngredientHandler(ingredientUsecase)
+	ingredientHandler.RegisterRoutes(app, db)
+
 	// Product setup
 	productRepo := repository.NewProductRepository(db)
-	productUsecase := usecase.NewProductUsecase(productRepo, businessRepo, storage)
+	productUsecase := usecase.NewProductUsecase(productRepo, businessRepo, ingredientRepo, db, storage)
 	productHandler := handler.NewProductHandler(productUsecase)
 	productHandler.RegisterRoutes(app, db)
 
@@ -89,15 +96,98 @@ func InjectHTTPHandlers(ctx context.Context, app *fiber.App) {
 	categoryHandler := handler.NewCategoryHandler(categoryUsecase)
 	categoryHandler.RegisterRoutes(app, db)
 
+	// Tax rule setup
+	taxRuleRepo := repository.NewTaxRuleRepository(db)
+	taxRuleUsecase := usecase.NewTaxRuleUsecase(taxRuleRepo, categoryRepo, productRepo, db)
+	taxRuleHandler := handler.NewTaxRuleHandler(taxRuleUsecase)
+	taxRuleHandler.RegisterRoutes(app, db)
+
+	// Promotion setup
+	promotionRepo := repository.NewPromotionRepository(db)
+	promotionUsecase := usecase.NewPromotionUsecase(promotionRepo, categoryRepo, productRepo, db)
+	promotionHandler := handler.NewPromotionHandler(promotionUsecase)
+	promotionHandler.RegisterRoutes(app, db)
+
+	// Modifier setup, chosen modifiers are priced and stocked as part of sales and refunds
+	modifierRepo := repository.NewModifierRepository(db)
+	modifierUsecase := usecase.NewModifierUsecase(modifierRepo, categoryRepo, productRepo, db)
+	modifierHandler := handler.NewModifierHandler(modifierUsecase)
+	modifierHandler.RegisterRoutes(app, db)
+
+	// Cash drawer setup
+	cashSessionRepo := repository.NewCashSessionRepository(db)
+	cashSessionUsecase := usecase.NewCashSessionUsecase(cashSessionRepo, db)
+	cashSessionHandler := handler.NewCashSessionHandler(cashSessionUsecase)
+	cashSessionHandler.RegisterRoutes(app, db)
+
+	// Loyalty setup, points are earned and redeemed as part of sales, refunds and payments
+	customerRepo := repository.NewCustomerRepository(db)
+	loyaltyRepo := repository.NewLoyaltyRepository(db)
+	loyaltyUsecase := usecase.NewLoyaltyUsecase(loyaltyRepo, customerRepo, businessRepo, db)
+
+	// Expire loyalty points past their expiry
+	_ = cron.NewLoyaltyExpiryCron(ctx, loyaltyUsecase)
+
+	// Voucher and gift card setup, vouchers are redeemed and gift cards charged as part of sales and refunds
+	voucherRepo := repository.NewVoucherRepository(db)
+	voucherUsecase := usecase.NewVoucherUsecase(voucherRepo, db)
+	voucherHandler := handler.NewVoucherHandler(voucherUsecase)
+	voucherHandler.RegisterRoutes(app, db)
+
+	giftCardRepo := repository.NewGiftCardRepository(db)
+	giftCardUsecase := usecase.NewGiftCardUsecase(giftCardRepo, db)
+	giftCardHandler := handler.NewGiftCardHandler(giftCardUsecase)
+	giftCardHandler.RegisterRoutes(app, db)
+
 	// Transaction setup
 	transactionRepo := repository.NewTransactionRepository(db)
 	transactionItemRepo := repository.NewTransactionItemRepository(db)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, transactionItemRepo, productRepo, businessRepo, db, storage)
+	invoiceSequenceRepo := repository.NewInvoiceSequenceRepository(db)
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, transactionItemRepo, productRepo, businessRepo, userRepo, invoiceSequenceRepo, taxRuleRepo, promotionRepo, cashSessionRepo, customerRepo, loyaltyUsecase, voucherUsecase, giftCardUsecase, modifierUsecase, ingredientUsecase, db, storage)
 	transactionHandler := handler.NewTransactionHandler(transactionUsecase)
 	transactionHandler.RegisterRoutes(app, db)
 
+	// Customer setup
+	customerUsecase := usecase.NewCustomerUsecase(customerRepo, transactionRepo, loyaltyRepo)
+	customerHandler := handler.NewCustomerHandler(customerUsecase)
+	customerHandler.RegisterRoutes(app, db)
+
+	// Customer credit setup
+	messagingProvider := usecase.NewMessagingProvider()
+	customerCreditUsecase := usecase.NewCustomerCreditUsecase(customerRepo, businessRepo, cashSessionRepo, messagingProvider, db)
+	customerCreditHandler := handler.NewCustomerCreditHandler(customerCreditUsecase)
+	customerCreditHandler.RegisterRoutes(app, db)
+
+	// Receipt setup
+	receiptDeliveryRepo := repository.NewReceiptDeliveryRepository(db)
+	receiptUsecase := usecase.NewReceiptUsecase(transactionRepo, businessRepo, receiptDeliveryRepo, emailUsecase, messagingProvider, storage)
+	receiptHandler := handler.NewReceiptHandler(receiptUsecase)
+	receiptHandler.RegisterRoutes(app, db)
+
+	// Held cart setup
+	heldCartRepo := repository.NewHeldCartRepository(db)
+	heldCartUsecase := usecase.NewHeldCartUsecase(heldCartRepo, productRepo, modifierUsecase, transactionUsecase)
+	heldCartHandler := handler.NewHeldCartHandler(heldCartUsecase)
+	heldCartHandler.RegisterRoutes(app, db)
+
+	// Expire abandoned pending transactions and return their stock
+	_ = cron.NewTransactionExpiryCron(ctx, transactionUsecase)
+
+	// Refund setup
+	refundRepo := repository.NewRefundRepository(db)
+	refundUsecase := usecase.NewRefundUsecase(refundRepo, transactionRepo, productRepo, cashSessionRepo, loyaltyUsecase, voucherUsecase, giftCardUsecase, modifierUsecase, ingredientUsecase, db)
+	refundHandler := handler.NewRefundHandler(refundUsecase)
+	refundHandler.RegisterRoutes(app, db)
+
+	// Payment gateway setup
+	paymentChargeRepo := repository.NewPaymentChargeRepository(db)
+	paymentProvider := usecase.NewPaymentProvider()
+	paymentUsecase := usecase.NewPaymentUsecase(paymentChargeRepo, transactionRepo, loyaltyUsecase, ingredientUsecase, paymentProvider, db)
+	paymentHandler := handler.NewPaymentHandler(paymentUsecase)
+	paymentHandler.RegisterRoutes(app, db)
+
 	// User setup
-	userUsecase := usecase.NewUserUsecase(userRepo, businessRepo, storage)
+	userUsecase := usecase.NewUserUsecase(userRepo, businessRepo, invoiceSequenceRepo, storage, db)
 	userHandler := handler.NewUserHandler(userUsecase)
 	userHandler.RegisterRoutes(app, db)
 
@@ -3,14 +3,26 @@ package config
 import "time"
 
 const (
-	APP_NAME                   = "Qlaris"
-	ACCESS_TOKEN_COOKIE_NAME   = "qlaris.access-token"
-	REFRESH_TOKEN_COOKIE_NAME  = "qlaris.refresh-token"
-	REQUEST_RESET_PASSWORD_TTL = 5 * time.Minute
-	REQUEST_VERIFICATION_TTL   = 5 * time.Minute
-	TRANSACTION_EXPIRY_TIME    = 15 * time.Minute
-	JWT_ACCESS_TTL             = 15 * time.Minute
-	JWT_REFRESH_TTL            = 7 * 24 * time.Hour
+	APP_NAME                      = "Qlaris"
+	ACCESS_TOKEN_COOKIE_NAME      = "qlaris.access-token"
+	REFRESH_TOKEN_COOKIE_NAME     = "qlaris.refresh-token"
+	REQUEST_RESET_PASSWORD_TTL    = 5 * time.Minute
+	REQUEST_VERIFICATION_TTL      = 5 * time.Minute
+	TRANSACTION_EXPIRY_TIME       = 15 * time.Minute
+	TRANSACTION_EXPIRY_BATCH_SIZE = 100
+	LOYALTY_EXPIRY_BATCH_SIZE     = 100
+	CODE_GENERATION_MAX_COUNT     = 1000
+	CODE_RANDOM_LENGTH            = 8
+	PRODUCT_VARIANT_MAX_COUNT     = 100
+	QRIS_IMAGE_SIZE               = 512
+	IDEMPOTENCY_KEY_HEADER        = "Idempotency-Key"
+	IDEMPOTENCY_KEY_TTL           = 24 * time.Hour
+	IDEMPOTENCY_KEY_LOCK_TIMEOUT  = 1 * time.Minute
+	JWT_ACCESS_TTL                = 15 * time.Minute
+	JWT_REFRESH_TTL               = 7 * 24 * time.Hour
+	RECEIPT_LINK_TTL              = 30 * 24 * time.Hour
+	APPROVER_PIN_MAX_ATTEMPTS     = 5
+	APPROVER_PIN_LOCK_DURATION    = 15 * time.Minute
 )
 
 type UserRole string
@@ -38,3 +50,144 @@ const (
 	TRANSACTION_STATUS_EXPIRED   TransactionStatus = "expired"
 	TRANSACTION_STATUS_CANCELLED TransactionStatus = "cancelled"
 )
+
+type PaymentMethod string
+
+const (
+	PAYMENT_METHOD_CASH          PaymentMethod = "cash"
+	PAYMENT_METHOD_QRIS          PaymentMethod = "qris"
+	PAYMENT_METHOD_BANK_TRANSFER PaymentMethod = "bank_transfer"
+	PAYMENT_METHOD_CARD          PaymentMethod = "card"
+	PAYMENT_METHOD_CREDIT        PaymentMethod = "credit"
+	PAYMENT_METHOD_POINTS        PaymentMethod = "points"
+	PAYMENT_METHOD_GIFT_CARD     PaymentMethod = "gift_card"
+)
+
+type PaymentChargeStatus string
+
+const (
+	PAYMENT_CHARGE_STATUS_PENDING PaymentChargeStatus = "pending"
+	PAYMENT_CHARGE_STATUS_PAID    PaymentChargeStatus = "paid"
+	PAYMENT_CHARGE_STATUS_EXPIRED PaymentChargeStatus = "expired"
+	PAYMENT_CHARGE_STATUS_FAILED  PaymentChargeStatus = "failed"
+)
+
+type PaymentProviderName string
+
+const (
+	PAYMENT_PROVIDER_FAKE   PaymentProviderName = "fake"
+	PAYMENT_PROVIDER_XENDIT PaymentProviderName = "xendit"
+)
+
+type MessagingProviderName string
+
+const (
+	MESSAGING_PROVIDER_LOG            MessagingProviderName = "log"
+	MESSAGING_PROVIDER_WHATSAPP_CLOUD MessagingProviderName = "whatsapp_cloud"
+)
+
+type InvoiceResetPeriod string
+
+const (
+	INVOICE_RESET_PERIOD_DAILY   InvoiceResetPeriod = "daily"
+	INVOICE_RESET_PERIOD_MONTHLY InvoiceResetPeriod = "monthly"
+	INVOICE_RESET_PERIOD_YEARLY  InvoiceResetPeriod = "yearly"
+	INVOICE_RESET_PERIOD_NEVER   InvoiceResetPeriod = "never"
+)
+
+type DiscountType string
+
+const (
+	DISCOUNT_TYPE_FLAT       DiscountType = "flat"
+	DISCOUNT_TYPE_PERCENTAGE DiscountType = "percentage"
+)
+
+type TaxKind string
+
+const (
+	TAX_KIND_TAX            TaxKind = "tax"
+	TAX_KIND_SERVICE_CHARGE TaxKind = "service_charge"
+)
+
+type CashRoundingMode string
+
+const (
+	CASH_ROUNDING_MODE_NONE    CashRoundingMode = "none"
+	CASH_ROUNDING_MODE_NEAREST CashRoundingMode = "nearest"
+	CASH_ROUNDING_MODE_UP      CashRoundingMode = "up"
+	CASH_ROUNDING_MODE_DOWN    CashRoundingMode = "down"
+)
+
+type CashSessionStatus string
+
+const (
+	CASH_SESSION_STATUS_OPEN   CashSessionStatus = "open"
+	CASH_SESSION_STATUS_CLOSED CashSessionStatus = "closed"
+)
+
+type CashMovementType string
+
+const (
+	CASH_MOVEMENT_TYPE_IN  CashMovementType = "in"
+	CASH_MOVEMENT_TYPE_OUT CashMovementType = "out"
+)
+
+type ReceiptChannel string
+
+const (
+	RECEIPT_CHANNEL_EMAIL    ReceiptChannel = "email"
+	RECEIPT_CHANNEL_WHATSAPP ReceiptChannel = "whatsapp"
+)
+
+type ReceiptDeliveryStatus string
+
+const (
+	RECEIPT_DELIVERY_STATUS_PENDING ReceiptDeliveryStatus = "pending"
+	RECEIPT_DELIVERY_STATUS_SENT    ReceiptDeliveryStatus = "sent"
+	RECEIPT_DELIVERY_STATUS_FAILED  ReceiptDeliveryStatus = "failed"
+)
+
+type LoyaltyEntryType string
+
+const (
+	LOYALTY_ENTRY_TYPE_EARN    LoyaltyEntryType = "earn"
+	LOYALTY_ENTRY_TYPE_REDEEM  LoyaltyEntryType = "redeem"
+	LOYALTY_ENTRY_TYPE_REVERSE LoyaltyEntryType = "reverse"
+	LOYALTY_ENTRY_TYPE_RESTORE LoyaltyEntryType = "restore"
+	LOYALTY_ENTRY_TYPE_EXPIRE  LoyaltyEntryType = "expire"
+)
+
+type PromotionType string
+
+const (
+	PROMOTION_TYPE_BUY_X_GET_Y  PromotionType = "buy_x_get_y"
+	PROMOTION_TYPE_BUNDLE_PRICE PromotionType = "bundle_price"
+	PROMOTION_TYPE_PERCENTAGE   PromotionType = "percentage"
+	PROMOTION_TYPE_AMOUNT       PromotionType = "amount"
+)
+
+type PromotionStacking string
+
+const (
+	PROMOTION_STACKING_STACKABLE PromotionStacking = "stackable"
+	PROMOTION_STACKING_EXCLUSIVE PromotionStacking = "exclusive"
+)
+
+type GiftCardEntryType string
+
+const (
+	GIFT_CARD_ENTRY_TYPE_ISSUE   GiftCardEntryType = "issue"
+	GIFT_CARD_ENTRY_TYPE_REDEEM  GiftCardEntryType = "redeem"
+	GIFT_CARD_ENTRY_TYPE_RESTORE GiftCardEntryType = "restore"
+)
+
+type StockMovementType string
+
+const (
+	STOCK_MOVEMENT_TYPE_SALE       StockMovementType = "sale"
+	STOCK_MOVEMENT_TYPE_REFUND     StockMovementType = "refund"
+	STOCK_MOVEMENT_TYPE_ADJUSTMENT StockMovementType = "adjustment"
+	STOCK_MOVEMENT_TYPE_RECEIPT    StockMovementType = "receipt"
+	STOCK_MOVEMENT_TYPE_TRANSFER   StockMovementType = "transfer"
+	STOCK_MOVEMENT_TYPE_STOCK_TAKE StockMovementType = "stock_take"
+)
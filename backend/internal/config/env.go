@@ -19,6 +19,8 @@ type Environment struct {
 	GoogleOAuth GoogleOAuth
 	Auth        Auth
 	Cors        Cors
+	Payment     Payment
+	Messaging   Messaging
 }
 
 type App struct {
@@ -94,6 +96,21 @@ type Storage struct {
 	DefaultTTL int    `env:"STORAGE_DEFAULT_TTL"`
 }
 
+type Payment struct {
+	Provider        string `env:"PAYMENT_PROVIDER" envDefault:"fake"` // fake, xendit
+	CallbackToken   string `env:"PAYMENT_CALLBACK_TOKEN"`
+	XenditSecretKey string `env:"XENDIT_SECRET_KEY"`
+	XenditBaseURL   string `env:"XENDIT_BASE_URL" envDefault:"https://api.xendit.co"`
+}
+
+type Messaging struct {
+	Provider              string `env:"MESSAGING_PROVIDER" envDefault:"log"` // log, whatsapp_cloud
+	LogDirectory          string `env:"MESSAGING_LOG_DIRECTORY" envDefault:"tmp/messages"`
+	WhatsappBaseURL       string `env:"WHATSAPP_BASE_URL" envDefault:"https://graph.facebook.com/v21.0"`
+	WhatsappPhoneNumberID string `env:"WHATSAPP_PHONE_NUMBER_ID"`
+	WhatsappAccessToken   string `env:"WHATSAPP_ACCESS_TOKEN"`
+}
+
 type Cors struct {
 	Origins string `env:"CORS_ORIGINS"`
 }
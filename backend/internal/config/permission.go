@@ -34,11 +34,69 @@ const (
 	READ_TRANSACTION_ORG   Permission = "read_transaction:org"
 	UPDATE_TRANSACTION_ORG Permission = "update_transaction:org"
 	PAY_TRANSACTION_ORG    Permission = "pay_transaction:org"
+	CANCEL_TRANSACTION_ORG Permission = "cancel_transaction:org"
+	REFUND_TRANSACTION_ORG Permission = "refund_transaction:org"
 
 	CREATE_TRANSACTION_ANY Permission = "create_transaction:any"
 	READ_TRANSACTION_ANY   Permission = "read_transaction:any"
 	UPDATE_TRANSACTION_ANY Permission = "update_transaction:any"
 	PAY_TRANSACTION_ANY    Permission = "pay_transaction:any"
+	CANCEL_TRANSACTION_ANY Permission = "cancel_transaction:any"
+	REFUND_TRANSACTION_ANY Permission = "refund_transaction:any"
+
+	READ_TAX_ORG   Permission = "read_tax:org"
+	MANAGE_TAX_ORG Permission = "manage_tax:org"
+
+	READ_TAX_ANY   Permission = "read_tax:any"
+	MANAGE_TAX_ANY Permission = "manage_tax:any"
+
+	READ_CASH_SESSION_SELF   Permission = "read_cash_session:self"
+	MANAGE_CASH_SESSION_SELF Permission = "manage_cash_session:self"
+
+	READ_CASH_SESSION_ORG   Permission = "read_cash_session:org"
+	MANAGE_CASH_SESSION_ORG Permission = "manage_cash_session:org"
+
+	READ_CASH_SESSION_ANY   Permission = "read_cash_session:any"
+	MANAGE_CASH_SESSION_ANY Permission = "manage_cash_session:any"
+
+	READ_CUSTOMER_ORG   Permission = "read_customer:org"
+	MANAGE_CUSTOMER_ORG Permission = "manage_customer:org"
+	DELETE_CUSTOMER_ORG Permission = "delete_customer:org"
+
+	READ_CUSTOMER_ANY   Permission = "read_customer:any"
+	MANAGE_CUSTOMER_ANY Permission = "manage_customer:any"
+	DELETE_CUSTOMER_ANY Permission = "delete_customer:any"
+
+	READ_CREDIT_ORG   Permission = "read_credit:org"
+	REPAY_CREDIT_ORG  Permission = "repay_credit:org"
+	MANAGE_CREDIT_ORG Permission = "manage_credit:org"
+
+	READ_CREDIT_ANY   Permission = "read_credit:any"
+	REPAY_CREDIT_ANY  Permission = "repay_credit:any"
+	MANAGE_CREDIT_ANY Permission = "manage_credit:any"
+
+	READ_PROMOTION_ORG   Permission = "read_promotion:org"
+	MANAGE_PROMOTION_ORG Permission = "manage_promotion:org"
+
+	READ_PROMOTION_ANY   Permission = "read_promotion:any"
+	MANAGE_PROMOTION_ANY Permission = "manage_promotion:any"
+
+	READ_VOUCHER_ORG   Permission = "read_voucher:org"
+	MANAGE_VOUCHER_ORG Permission = "manage_voucher:org"
+
+	READ_VOUCHER_ANY   Permission = "read_voucher:any"
+	MANAGE_VOUCHER_ANY Permission = "manage_voucher:any"
+
+	READ_GIFT_CARD_ORG   Permission = "read_gift_card:org"
+	MANAGE_GIFT_CARD_ORG Permission = "manage_gift_card:org"
+
+	READ_GIFT_CARD_ANY   Permission = "read_gift_card:any"
+	MANAGE_GIFT_CARD_ANY Permission = "manage_gift_card:any"
+
+	// Business profile and settings: discount caps, cash rounding, invoice numbering, loyalty and QRIS
+	MANAGE_BUSINESS_ORG Permission = "manage_business:org"
+
+	MANAGE_BUSINESS_ANY Permission = "manage_business:any"
 )
 
 var RolePermissionMap = map[UserRole][]Permission{
@@ -55,6 +113,25 @@ var RolePermissionMap = map[UserRole][]Permission{
 		READ_TRANSACTION_ANY,
 		UPDATE_TRANSACTION_ANY,
 		PAY_TRANSACTION_ANY,
+		CANCEL_TRANSACTION_ANY,
+		REFUND_TRANSACTION_ANY,
+		READ_TAX_ANY,
+		MANAGE_TAX_ANY,
+		READ_CASH_SESSION_ANY,
+		MANAGE_CASH_SESSION_ANY,
+		READ_CUSTOMER_ANY,
+		MANAGE_CUSTOMER_ANY,
+		DELETE_CUSTOMER_ANY,
+		READ_CREDIT_ANY,
+		REPAY_CREDIT_ANY,
+		MANAGE_CREDIT_ANY,
+		READ_PROMOTION_ANY,
+		MANAGE_PROMOTION_ANY,
+		READ_VOUCHER_ANY,
+		MANAGE_VOUCHER_ANY,
+		READ_GIFT_CARD_ANY,
+		MANAGE_GIFT_CARD_ANY,
+		MANAGE_BUSINESS_ANY,
 	},
 	USER_ROLE_OWNER: {
 		CREATE_USER_ORG,
@@ -69,6 +146,25 @@ var RolePermissionMap = map[UserRole][]Permission{
 		READ_TRANSACTION_ORG,
 		UPDATE_TRANSACTION_ORG,
 		PAY_TRANSACTION_ORG,
+		CANCEL_TRANSACTION_ORG,
+		REFUND_TRANSACTION_ORG,
+		READ_TAX_ORG,
+		MANAGE_TAX_ORG,
+		READ_CASH_SESSION_ORG,
+		MANAGE_CASH_SESSION_ORG,
+		READ_CUSTOMER_ORG,
+		MANAGE_CUSTOMER_ORG,
+		DELETE_CUSTOMER_ORG,
+		READ_CREDIT_ORG,
+		REPAY_CREDIT_ORG,
+		MANAGE_CREDIT_ORG,
+		READ_PROMOTION_ORG,
+		MANAGE_PROMOTION_ORG,
+		READ_VOUCHER_ORG,
+		MANAGE_VOUCHER_ORG,
+		READ_GIFT_CARD_ORG,
+		MANAGE_GIFT_CARD_ORG,
+		MANAGE_BUSINESS_ORG,
 	},
 	USER_ROLE_CASHIER: {
 		READ_USER_SELF,
@@ -81,6 +177,18 @@ var RolePermissionMap = map[UserRole][]Permission{
 		READ_TRANSACTION_ORG,
 		UPDATE_TRANSACTION_ORG,
 		PAY_TRANSACTION_ORG,
+		CANCEL_TRANSACTION_ORG,
+		REFUND_TRANSACTION_ORG,
+		READ_TAX_ORG,
+		READ_CASH_SESSION_SELF,
+		MANAGE_CASH_SESSION_SELF,
+		READ_CUSTOMER_ORG,
+		MANAGE_CUSTOMER_ORG,
+		READ_CREDIT_ORG,
+		REPAY_CREDIT_ORG,
+		READ_PROMOTION_ORG,
+		READ_VOUCHER_ORG,
+		READ_GIFT_CARD_ORG,
 	},
 	USER_ROLE_MANAGER: {
 		READ_USER_SELF,
@@ -89,6 +197,18 @@ var RolePermissionMap = map[UserRole][]Permission{
 		CREATE_TRANSACTION_ORG,
 		READ_TRANSACTION_ORG,
 		PAY_TRANSACTION_ORG,
+		CANCEL_TRANSACTION_ORG,
+		REFUND_TRANSACTION_ORG,
+		READ_TAX_ORG,
+		READ_CASH_SESSION_SELF,
+		MANAGE_CASH_SESSION_SELF,
+		READ_CUSTOMER_ORG,
+		MANAGE_CUSTOMER_ORG,
+		READ_CREDIT_ORG,
+		REPAY_CREDIT_ORG,
+		READ_PROMOTION_ORG,
+		READ_VOUCHER_ORG,
+		READ_GIFT_CARD_ORG,
 	},
 }
 
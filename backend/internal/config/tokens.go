@@ -7,4 +7,5 @@ const (
 	TokenTypeRefresh       TokenType = "refresh"
 	TokenTypeResetPassword TokenType = "resetPassword"
 	TokenTypeVerifyEmail   TokenType = "verifyEmail"
+	TokenTypeReceipt       TokenType = "receipt"
 )
@@ -0,0 +1,81 @@
+package contract
+
+type OpenCashSessionReq struct {
+	OpeningFloat float64 `json:"openingFloat" validate:"min=0"`
+	Note         *string `json:"note" validate:"omitempty,max=255"`
+}
+
+type CreateCashMovementReq struct {
+	Type   string  `json:"type" validate:"required,oneof=in out"`
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+	Reason string  `json:"reason" validate:"required,max=255"`
+}
+
+type CloseCashSessionReq struct {
+	CountedCash float64 `json:"countedCash" validate:"min=0"`
+	Note        *string `json:"note" validate:"omitempty,max=255"`
+}
+
+type CashMovementRes struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+	CreatedBy string  `json:"createdBy"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type ZReportPaymentRes struct {
+	Method string  `json:"method"`
+	Count  int64   `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+// ZReportRes is the end of shift summary. Expected cash is the opening float plus cash sales,
+// less change and cash refunds, plus cash in and less cash out
+type ZReportRes struct {
+	SessionID    string              `json:"sessionId"`
+	OpenedBy     string              `json:"openedBy"`
+	OpenerName   string              `json:"openerName"`
+	ClosedBy     *string             `json:"closedBy"`
+	OpenedAt     string              `json:"openedAt"`
+	ClosedAt     *string             `json:"closedAt"`
+	Transactions int64               `json:"transactions"`
+	GrossSales   float64             `json:"grossSales"`
+	Discounts    float64             `json:"discounts"`
+	Taxes        float64             `json:"taxes"`
+	Rounding     float64             `json:"rounding"`
+	NetSales     float64             `json:"netSales"`
+	Payments     []ZReportPaymentRes `json:"payments"`
+	ChangeGiven  float64             `json:"changeGiven"`
+	Refunds      float64             `json:"refunds"`
+	CashRefunds  float64             `json:"cashRefunds"`
+	OpeningFloat float64             `json:"openingFloat"`
+	CashIn       float64             `json:"cashIn"`
+	CashOut      float64             `json:"cashOut"`
+	// Repayments of earlier credit sales, the cash ones are in the drawer
+	CreditRepayments     float64  `json:"creditRepayments"`
+	CashCreditRepayments float64  `json:"cashCreditRepayments"`
+	ExpectedCash         float64  `json:"expectedCash"`
+	CountedCash          *float64 `json:"countedCash"`
+	Variance             *float64 `json:"variance"`
+}
+
+type CashSessionRes struct {
+	ID           string            `json:"id"`
+	BusinessID   string            `json:"businessId"`
+	Status       string            `json:"status"`
+	OpenedBy     string            `json:"openedBy"`
+	OpenerName   string            `json:"openerName"`
+	ClosedBy     *string           `json:"closedBy"`
+	CloserName   *string           `json:"closerName"`
+	OpeningFloat float64           `json:"openingFloat"`
+	ExpectedCash *float64          `json:"expectedCash"`
+	CountedCash  *float64          `json:"countedCash"`
+	Variance     *float64          `json:"variance"`
+	OpeningNote  *string           `json:"openingNote"`
+	ClosingNote  *string           `json:"closingNote"`
+	OpenedAt     string            `json:"openedAt"`
+	ClosedAt     *string           `json:"closedAt"`
+	Movements    []CashMovementRes `json:"movements,omitempty"`
+}
@@ -0,0 +1,49 @@
+package contract
+
+// Request contracts
+
+type CreateCustomerReq struct {
+	Name  string   `json:"name" validate:"required,max=255"`
+	Phone *string  `json:"phone" validate:"omitempty,max=32"`
+	Email *string  `json:"email" validate:"omitempty,email,max=255"`
+	Notes *string  `json:"notes" validate:"omitempty,max=1000"`
+	Tags  []string `json:"tags" validate:"omitempty,max=20,dive,required,max=32"`
+	// Most the customer may owe on credit sales, 0 or omitted means no credit
+	CreditLimit *float64 `json:"creditLimit" validate:"omitempty,min=0"`
+}
+
+// UpdateCustomerReq changes the given fields, an empty phone or email clears it
+type UpdateCustomerReq struct {
+	Name  *string  `json:"name" validate:"omitempty,max=255"`
+	Phone *string  `json:"phone" validate:"omitempty,max=32"`
+	Email *string  `json:"email" validate:"omitempty,max=255"`
+	Notes *string  `json:"notes" validate:"omitempty,max=1000"`
+	Tags  []string `json:"tags" validate:"omitempty,max=20,dive,required,max=32"`
+	// Most the customer may owe on credit sales, 0 means no credit
+	CreditLimit *float64 `json:"creditLimit" validate:"omitempty,min=0"`
+}
+
+// Response contracts
+
+type CustomerStatsRes struct {
+	LifetimeSpend float64 `json:"lifetimeSpend"`
+	VisitCount    int64   `json:"visitCount"`
+	LastVisitAt   *string `json:"lastVisitAt"`
+	// Unpaid part of credit sales
+	OutstandingBalance float64 `json:"outstandingBalance"`
+}
+
+type CustomerRes struct {
+	ID            string            `json:"id"`
+	BusinessID    string            `json:"businessId"`
+	Name          string            `json:"name"`
+	Phone         *string           `json:"phone"`
+	Email         *string           `json:"email"`
+	Notes         *string           `json:"notes"`
+	Tags          []string          `json:"tags"`
+	CreditLimit   float64           `json:"creditLimit"`
+	PointsBalance int               `json:"pointsBalance"`
+	Stats         *CustomerStatsRes `json:"stats,omitempty"`
+	CreatedAt     string            `json:"createdAt"`
+	UpdatedAt     string            `json:"updatedAt"`
+}
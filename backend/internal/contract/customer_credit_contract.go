@@ -0,0 +1,88 @@
+package contract
+
+// Request contracts
+
+// CreateCreditRepaymentReq pays off credit sales of a customer, oldest first
+type CreateCreditRepaymentReq struct {
+	Method    string  `json:"method" validate:"required,oneof=cash qris bank_transfer card"`
+	Amount    float64 `json:"amount" validate:"required,gt=0"`
+	Reference *string `json:"reference" validate:"omitempty,max=255"`
+}
+
+// Response contracts
+
+type CreditTransactionRes struct {
+	TransactionID     string  `json:"transactionId"`
+	InvoiceNumber     string  `json:"invoiceNumber"`
+	PaidAt            string  `json:"paidAt"`
+	TotalAmount       float64 `json:"totalAmount"`
+	CreditAmount      float64 `json:"creditAmount"`
+	OutstandingAmount float64 `json:"outstandingAmount"`
+	AgeDays           int     `json:"ageDays"`
+}
+
+type CustomerCreditRes struct {
+	CustomerID         string                 `json:"customerId"`
+	CustomerName       string                 `json:"customerName"`
+	Phone              *string                `json:"phone"`
+	CreditLimit        float64                `json:"creditLimit"`
+	OutstandingBalance float64                `json:"outstandingBalance"`
+	AvailableCredit    float64                `json:"availableCredit"`
+	Transactions       []CreditTransactionRes `json:"transactions"`
+}
+
+// CreditRepaymentLineRes is the part of a repayment applied to one credit sale
+type CreditRepaymentLineRes struct {
+	PaymentID         string  `json:"paymentId"`
+	TransactionID     string  `json:"transactionId"`
+	InvoiceNumber     string  `json:"invoiceNumber"`
+	Amount            float64 `json:"amount"`
+	OutstandingAmount float64 `json:"outstandingAmount"`
+}
+
+type CreditRepaymentRes struct {
+	Method    string                   `json:"method"`
+	Amount    float64                  `json:"amount"`
+	Reference *string                  `json:"reference"`
+	PaidAt    string                   `json:"paidAt"`
+	Lines     []CreditRepaymentLineRes `json:"lines"`
+	Credit    CustomerCreditRes        `json:"credit"`
+}
+
+// CreditAgingLineRes splits what a customer owes by the age of the credit sales
+type CreditAgingLineRes struct {
+	CustomerID   string  `json:"customerId"`
+	CustomerName string  `json:"customerName"`
+	Phone        *string `json:"phone"`
+	Current      float64 `json:"current"`
+	Days31To60   float64 `json:"days31To60"`
+	Days61To90   float64 `json:"days61To90"`
+	Over90Days   float64 `json:"over90Days"`
+	Total        float64 `json:"total"`
+	OldestAt     string  `json:"oldestAt"`
+}
+
+type CreditAgingTotalsRes struct {
+	Current    float64 `json:"current"`
+	Days31To60 float64 `json:"days31To60"`
+	Days61To90 float64 `json:"days61To90"`
+	Over90Days float64 `json:"over90Days"`
+	Total      float64 `json:"total"`
+}
+
+type CreditAgingRes struct {
+	AsOf   string               `json:"asOf"`
+	Lines  []CreditAgingLineRes `json:"lines"`
+	Totals CreditAgingTotalsRes `json:"totals"`
+}
+
+// CreditReminderRes is the reminder message for a customer, WhatsApp URL opens a chat with it filled in.
+// Provider message ID is set once the reminder was sent.
+type CreditReminderRes struct {
+	CustomerID         string  `json:"customerId"`
+	Phone              *string `json:"phone"`
+	OutstandingBalance float64 `json:"outstandingBalance"`
+	Message            string  `json:"message"`
+	WhatsappURL        *string `json:"whatsappUrl"`
+	ProviderMessageID  *string `json:"providerMessageId,omitempty"`
+}
@@ -12,15 +12,29 @@ type DayStatsRes struct {
 	Sales            float64        `json:"sales"`
 	Transactions     int64          `json:"transactions"`
 	Profit           float64        `json:"profit"`
+	Refunds          float64        `json:"refunds"`
+	Discounts        float64        `json:"discounts"`
+	Taxes            float64        `json:"taxes"`
+	Rounding         float64        `json:"rounding"`
+	CreditSales      float64        `json:"creditSales"`
+	CreditRepayments float64        `json:"creditRepayments"`
+	CashReceived     float64        `json:"cashReceived"`
 	CompareYesterday *ComparisonRes `json:"compareYesterday,omitempty"`
 }
 
 // WeekStatsRes represents weekly statistics
 type WeekStatsRes struct {
-	Sales           float64        `json:"sales"`
-	Transactions    int64          `json:"transactions"`
-	Profit          float64        `json:"profit"`
-	CompareLastWeek *ComparisonRes `json:"compareLastWeek,omitempty"`
+	Sales            float64        `json:"sales"`
+	Transactions     int64          `json:"transactions"`
+	Profit           float64        `json:"profit"`
+	Refunds          float64        `json:"refunds"`
+	Discounts        float64        `json:"discounts"`
+	Taxes            float64        `json:"taxes"`
+	Rounding         float64        `json:"rounding"`
+	CreditSales      float64        `json:"creditSales"`
+	CreditRepayments float64        `json:"creditRepayments"`
+	CashReceived     float64        `json:"cashReceived"`
+	CompareLastWeek  *ComparisonRes `json:"compareLastWeek,omitempty"`
 }
 
 // DashboardSummaryRes is the main dashboard response
@@ -30,3 +44,48 @@ type DashboardSummaryRes struct {
 	LastTransactions []TransactionRes `json:"lastTransactions"`
 	TopProducts      []ProductRes     `json:"topProducts"`
 }
+
+// PeriodReportQuery selects the days of a report, both dates are inclusive
+type PeriodReportQuery struct {
+	StartDate string `query:"startDate" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"endDate" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// TaxReportLineRes is the amount collected by one tax or service charge rule
+type TaxReportLineRes struct {
+	TaxRuleID     *string `json:"taxRuleId"`
+	Name          string  `json:"name"`
+	Kind          string  `json:"kind"`
+	Rate          float64 `json:"rate"`
+	IsInclusive   bool    `json:"isInclusive"`
+	Transactions  int64   `json:"transactions"`
+	TaxableAmount float64 `json:"taxableAmount"`
+	Amount        float64 `json:"amount"`
+}
+
+// TaxReportRes is the tax collected in a period
+type TaxReportRes struct {
+	StartDate          string             `json:"startDate"`
+	EndDate            string             `json:"endDate"`
+	TotalTax           float64            `json:"totalTax"`
+	TotalServiceCharge float64            `json:"totalServiceCharge"`
+	Lines              []TaxReportLineRes `json:"lines"`
+}
+
+// PromotionReportLineRes is the discount given by one promotion
+type PromotionReportLineRes struct {
+	PromotionID  *string `json:"promotionId"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Transactions int64   `json:"transactions"`
+	Quantity     int64   `json:"quantity"`
+	Amount       float64 `json:"amount"`
+}
+
+// PromotionReportRes is the discount given by promotions in a period
+type PromotionReportRes struct {
+	StartDate   string                   `json:"startDate"`
+	EndDate     string                   `json:"endDate"`
+	TotalAmount float64                  `json:"totalAmount"`
+	Lines       []PromotionReportLineRes `json:"lines"`
+}
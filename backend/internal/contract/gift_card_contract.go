@@ -0,0 +1,56 @@
+package contract
+
+// CreateGiftCardReq issues one gift card, the code is generated when not chosen
+type CreateGiftCardReq struct {
+	Code      *string `json:"code" validate:"omitempty,min=3,max=32,alphanum"`
+	Balance   float64 `json:"balance" validate:"required,gt=0"`
+	ExpiresAt *string `json:"expiresAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// GenerateGiftCardsReq issues a batch of gift cards with random codes and the same balance
+type GenerateGiftCardsReq struct {
+	Count     int     `json:"count" validate:"required,gte=1,lte=1000"`
+	Prefix    string  `json:"prefix" validate:"omitempty,max=16,alphanum"`
+	Balance   float64 `json:"balance" validate:"required,gt=0"`
+	ExpiresAt *string `json:"expiresAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// UpdateGiftCardReq changes only the fields sent, an empty expiry removes it
+type UpdateGiftCardReq struct {
+	ExpiresAt *string `json:"expiresAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IsActive  *bool   `json:"isActive"`
+}
+
+// Response contracts
+
+type GiftCardRes struct {
+	ID             string  `json:"id"`
+	BusinessID     string  `json:"businessId"`
+	Code           string  `json:"code"`
+	BatchID        *string `json:"batchId"`
+	InitialBalance float64 `json:"initialBalance"`
+	Balance        float64 `json:"balance"`
+	ExpiresAt      *string `json:"expiresAt"`
+	IsActive       bool    `json:"isActive"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+type GiftCardBatchRes struct {
+	BatchID   string        `json:"batchId"`
+	GiftCards []GiftCardRes `json:"giftCards"`
+}
+
+// GiftCardEntryRes is one line of a gift card's balance ledger, amounts are negative when spent
+type GiftCardEntryRes struct {
+	ID            string  `json:"id"`
+	Type          string  `json:"type"`
+	Amount        float64 `json:"amount"`
+	BalanceAfter  float64 `json:"balanceAfter"`
+	TransactionID *string `json:"transactionId"`
+	InvoiceNumber *string `json:"invoiceNumber"`
+	RefundID      *string `json:"refundId"`
+	CreatedBy     *string `json:"createdBy"`
+	CreatorName   *string `json:"creatorName"`
+	CreatedAt     string  `json:"createdAt"`
+}
@@ -0,0 +1,45 @@
+package contract
+
+type HoldCartReq struct {
+	Name  string               `json:"name" validate:"required,max=64"`
+	Note  *string              `json:"note" validate:"omitempty,max=255"`
+	Items []TransactionItemReq `json:"items" validate:"required,min=1,dive"`
+	// Order level discount, applied after line discounts
+	Discount *DiscountReq `json:"discount" validate:"omitempty"`
+	// Customer from the customer directory, optional
+	CustomerID *string `json:"customerId" validate:"omitempty,uuid"`
+	// Voucher code, checked when the cart is resumed
+	VoucherCode *string `json:"voucherCode" validate:"omitempty,max=32"`
+}
+
+type ResumeHeldCartReq struct {
+	// Required when a discount goes over the cashier's limit. Defaults to the current user when omitted.
+	ApproverID  *string `json:"approverId" validate:"omitempty,uuid"`
+	ApproverPin *string `json:"approverPin" validate:"omitempty,numeric,len=6"`
+}
+
+type HeldCartItemRes struct {
+	ProductID    string                       `json:"productId"`
+	ProductName  string                       `json:"productName"`
+	VariantID    *string                      `json:"variantId"`
+	VariantLabel *string                      `json:"variantLabel"`
+	Modifiers    []TransactionItemModifierRes `json:"modifiers"`
+	Price        float64                      `json:"price"`
+	Quantity     int                          `json:"quantity"`
+	Discount     *DiscountReq                 `json:"discount"`
+}
+
+type HeldCartRes struct {
+	ID           string            `json:"id"`
+	BusinessID   string            `json:"businessId"`
+	Name         string            `json:"name"`
+	Note         *string           `json:"note"`
+	Items        []HeldCartItemRes `json:"items"`
+	Discount     *DiscountReq      `json:"discount"`
+	CustomerID   *string           `json:"customerId"`
+	CustomerName *string           `json:"customerName"`
+	VoucherCode  *string           `json:"voucherCode"`
+	CreatedBy    string            `json:"createdBy"`
+	CreatorName  string            `json:"creatorName"`
+	CreatedAt    string            `json:"createdAt"`
+}
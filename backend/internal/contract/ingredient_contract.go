@@ -0,0 +1,31 @@
+package contract
+
+type CreateIngredientReq struct {
+	Name string `json:"name" validate:"required,max=64"`
+	// Unit the stock and recipes are counted in, e.g. g, ml or pcs
+	Unit string `json:"unit" validate:"required,max=16"`
+	// Cost of one unit
+	Cost     float64 `json:"cost" validate:"gte=0"`
+	StockQty float64 `json:"stockQty" validate:"gte=0"`
+}
+
+type UpdateIngredientReq struct {
+	Name     *string  `json:"name" validate:"omitempty,max=64"`
+	Unit     *string  `json:"unit" validate:"omitempty,max=16"`
+	Cost     *float64 `json:"cost" validate:"omitempty,gte=0"`
+	StockQty *float64 `json:"stockQty"`
+	IsActive *bool    `json:"isActive"`
+}
+
+type IngredientRes struct {
+	ID         string  `json:"id"`
+	BusinessID string  `json:"businessId"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	Cost       float64 `json:"cost"`
+	// Below zero when more was sold than was counted in
+	StockQty  float64 `json:"stockQty"`
+	IsActive  bool    `json:"isActive"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
+}
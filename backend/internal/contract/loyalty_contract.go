@@ -0,0 +1,20 @@
+package contract
+
+// Response contracts
+
+// LoyaltyPointEntryRes is one line of a customer's points ledger, points are negative when taken off the balance
+type LoyaltyPointEntryRes struct {
+	ID              string  `json:"id"`
+	Type            string  `json:"type"`
+	Points          int     `json:"points"`
+	BalanceAfter    int     `json:"balanceAfter"`
+	RemainingPoints int     `json:"remainingPoints"`
+	ExpiresAt       *string `json:"expiresAt"`
+	TransactionID   *string `json:"transactionId"`
+	InvoiceNumber   *string `json:"invoiceNumber"`
+	RefundID        *string `json:"refundId"`
+	Note            *string `json:"note"`
+	CreatedBy       *string `json:"createdBy"`
+	CreatorName     *string `json:"creatorName"`
+	CreatedAt       string  `json:"createdAt"`
+}
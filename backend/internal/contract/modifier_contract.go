@@ -0,0 +1,63 @@
+package contract
+
+type ModifierReq struct {
+	// Keeps an existing modifier, new modifiers are sent without it
+	ID          *string `json:"id" validate:"omitempty,uuid"`
+	Name        string  `json:"name" validate:"required,max=64"`
+	Price       float64 `json:"price" validate:"gte=0"`
+	EnableStock bool    `json:"enableStock"`
+	StockQty    *int    `json:"stockQty" validate:"omitempty,gte=0"`
+	IsActive    *bool   `json:"isActive"`
+}
+
+type CreateModifierGroupReq struct {
+	Name          string        `json:"name" validate:"required,max=64"`
+	MinSelections int           `json:"minSelections" validate:"gte=0"`
+	MaxSelections int           `json:"maxSelections" validate:"required,gte=1"`
+	IsActive      *bool         `json:"isActive"`
+	Modifiers     []ModifierReq `json:"modifiers" validate:"required,min=1,dive"`
+	CategoryIDs   []string      `json:"categoryIds" validate:"omitempty,dive,uuid"`
+	ProductIDs    []string      `json:"productIds" validate:"omitempty,dive,uuid"`
+}
+
+type UpdateModifierGroupReq struct {
+	Name          *string `json:"name" validate:"omitempty,max=64"`
+	MinSelections *int    `json:"minSelections" validate:"omitempty,gte=0"`
+	MaxSelections *int    `json:"maxSelections" validate:"omitempty,gte=1"`
+	IsActive      *bool   `json:"isActive"`
+	SortOrder     *int    `json:"sortOrder" validate:"omitempty,gte=0"`
+	// Replaces the modifiers when sent, listed modifiers without an ID are added and missing ones removed
+	Modifiers   *[]ModifierReq `json:"modifiers" validate:"omitempty,min=1,dive"`
+	CategoryIDs *[]string      `json:"categoryIds" validate:"omitempty,dive,uuid"`
+	ProductIDs  *[]string      `json:"productIds" validate:"omitempty,dive,uuid"`
+}
+
+type ListModifierGroupsReq struct {
+	// Only the active groups offered on this product
+	ProductID *string `json:"productId" validate:"omitempty,uuid"`
+}
+
+type ModifierRes struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	EnableStock bool    `json:"enableStock"`
+	StockQty    *int    `json:"stockQty"`
+	IsActive    bool    `json:"isActive"`
+	SortOrder   int     `json:"sortOrder"`
+}
+
+type ModifierGroupRes struct {
+	ID            string        `json:"id"`
+	BusinessID    string        `json:"businessId"`
+	Name          string        `json:"name"`
+	MinSelections int           `json:"minSelections"`
+	MaxSelections int           `json:"maxSelections"`
+	IsActive      bool          `json:"isActive"`
+	SortOrder     int           `json:"sortOrder"`
+	Modifiers     []ModifierRes `json:"modifiers"`
+	CategoryIDs   []string      `json:"categoryIds"`
+	ProductIDs    []string      `json:"productIds"`
+	CreatedAt     string        `json:"createdAt"`
+	UpdatedAt     string        `json:"updatedAt"`
+}
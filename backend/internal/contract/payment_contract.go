@@ -0,0 +1,21 @@
+package contract
+
+// Response contracts
+
+type PaymentChargeRes struct {
+	ID                  string   `json:"id"`
+	TransactionID       string   `json:"transactionId"`
+	Provider            string   `json:"provider"`
+	Reference           string   `json:"reference"`
+	Amount              float64  `json:"amount"`
+	Status              string   `json:"status"`
+	Method              *string  `json:"method"`
+	CheckoutURL         *string  `json:"checkoutUrl"`
+	QrString            *string  `json:"qrString"`
+	PaidAmount          *float64 `json:"paidAmount"`
+	PaidAt              *string  `json:"paidAt,omitempty"`
+	ExpiresAt           *string  `json:"expiresAt,omitempty"`
+	NeedsReconciliation bool     `json:"needsReconciliation"`
+	ReconciliationNote  *string  `json:"reconciliationNote,omitempty"`
+	CreatedAt           string   `json:"createdAt"`
+}
@@ -48,8 +48,78 @@ type ProductRes struct {
 	BarcodeValue  *string      `json:"barcodeValue"`
 	BarcodeType   *string      `json:"barcodeType"`
 	Cost          *float64     `json:"cost"`
-	CreatedAt     string       `json:"createdAt"`
-	UpdatedAt     string       `json:"updatedAt"`
+	HasVariants   bool         `json:"hasVariants"`
+	// Option groups and the variants generated from them, empty for products without variants
+	OptionGroups []ProductOptionGroupRes `json:"optionGroups"`
+	Variants     []ProductVariantRes     `json:"variants"`
+	// Ingredients used for one unit, the cost is worked out from them when set
+	HasRecipe bool                   `json:"hasRecipe"`
+	Recipe    []ProductRecipeItemRes `json:"recipe"`
+	CreatedAt string                 `json:"createdAt"`
+	UpdatedAt string                 `json:"updatedAt"`
+}
+
+type ProductRecipeItemRes struct {
+	IngredientID   string  `json:"ingredientId"`
+	IngredientName string  `json:"ingredientName"`
+	Unit           string  `json:"unit"`
+	Quantity       float64 `json:"quantity"`
+	// Quantity times the ingredient cost
+	Cost float64 `json:"cost"`
+}
+
+type ProductOptionGroupRes struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariantRes struct {
+	ID           string   `json:"id"`
+	Options      []string `json:"options"`
+	Label        string   `json:"label"`
+	SKU          *string  `json:"sku"`
+	Price        float64  `json:"price"`
+	Cost         *float64 `json:"cost"`
+	StockQty     *int     `json:"stockQty"`
+	BarcodeValue *string  `json:"barcodeValue"`
+	BarcodeType  *string  `json:"barcodeType"`
+	IsActive     bool     `json:"isActive"`
+	// Only set in sales reports
+	QuantitySold int `json:"quantitySold"`
+}
+
+// ProductOptionGroupReq is one option of a product, e.g. Size with the values S, M and L
+type ProductOptionGroupReq struct {
+	Name   string   `json:"name" validate:"required,max=64"`
+	Values []string `json:"values" validate:"required,min=1,max=20,dive,required,max=64"`
+}
+
+// SetProductOptionsReq replaces the option groups of a product. A variant is generated for every
+// combination of values, existing combinations keep their price and stock. No groups removes the variants.
+type SetProductOptionsReq struct {
+	Groups []ProductOptionGroupReq `json:"groups" validate:"omitempty,max=3,dive"`
+}
+
+// ProductRecipeItemReq is an ingredient used for one unit of the product, e.g. 18 g of coffee beans
+type ProductRecipeItemReq struct {
+	IngredientID string  `json:"ingredientId" validate:"required,uuid"`
+	Quantity     float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// SetProductRecipeReq replaces the recipe of a product. No items removes the recipe.
+type SetProductRecipeReq struct {
+	Items []ProductRecipeItemReq `json:"items" validate:"omitempty,max=50,dive"`
+}
+
+type UpdateProductVariantReq struct {
+	SKU          *string  `json:"sku" validate:"omitempty,max=64"`
+	Price        *float64 `json:"price" validate:"omitempty,gte=0"`
+	Cost         *float64 `json:"cost" validate:"omitempty,gte=0"`
+	StockQty     *int     `json:"stockQty" validate:"omitempty,gte=0"`
+	BarcodeValue *string  `json:"barcodeValue" validate:"omitempty,max=36"`
+	BarcodeType  *string  `json:"barcodeType" validate:"omitempty,oneof=ean13 ean8 upc"`
+	IsActive     *bool    `json:"isActive"`
 }
 
 type ListProductsReq struct {
@@ -68,3 +138,38 @@ type ListProductsRes struct {
 type ToggleProductStatusReq struct {
 	IsActive bool `json:"isActive"`
 }
+
+// RecordStockMovementReq records stock that came in or went out outside of sales and refunds.
+// Quantity is the change, negative when stock goes out, except for a stock take where it is the counted quantity.
+type RecordStockMovementReq struct {
+	VariantID *string `json:"variantId" validate:"omitempty,uuid"`
+	Type      string  `json:"type" validate:"required,oneof=receipt transfer adjustment stock_take"`
+	Quantity  int     `json:"quantity"`
+	// Document the stock came with or went out on, e.g. a supplier invoice or transfer note
+	Reference *string `json:"reference" validate:"omitempty,max=64"`
+	Reason    *string `json:"reason" validate:"omitempty,max=255"`
+}
+
+type ListStockMovementsReq struct {
+	// Only the movements of this variant
+	VariantID *string `json:"variantId" validate:"omitempty,uuid"`
+}
+
+// StockMovementRes is one line of a product's stock ledger, quantities are negative when stock went out
+type StockMovementRes struct {
+	ID            string  `json:"id"`
+	ProductID     string  `json:"productId"`
+	VariantID     *string `json:"variantId"`
+	VariantLabel  *string `json:"variantLabel"`
+	Type          string  `json:"type"`
+	Quantity      int     `json:"quantity"`
+	BalanceAfter  int     `json:"balanceAfter"`
+	TransactionID *string `json:"transactionId"`
+	InvoiceNumber *string `json:"invoiceNumber"`
+	RefundID      *string `json:"refundId"`
+	Reference     *string `json:"reference"`
+	Reason        *string `json:"reason"`
+	CreatedBy     *string `json:"createdBy"`
+	CreatorName   *string `json:"creatorName"`
+	CreatedAt     string  `json:"createdAt"`
+}
@@ -0,0 +1,62 @@
+package contract
+
+type CreatePromotionReq struct {
+	Name           string   `json:"name" validate:"required,max=64"`
+	Type           string   `json:"type" validate:"required,oneof=buy_x_get_y bundle_price percentage amount"`
+	Value          float64  `json:"value" validate:"required,gt=0"`
+	BuyQuantity    *int     `json:"buyQuantity" validate:"omitempty,gte=1"`
+	GetQuantity    *int     `json:"getQuantity" validate:"omitempty,gte=1"`
+	MinSpend       float64  `json:"minSpend" validate:"gte=0"`
+	StartsAt       *string  `json:"startsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt         *string  `json:"endsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	DailyStartTime *string  `json:"dailyStartTime" validate:"omitempty,datetime=15:04"`
+	DailyEndTime   *string  `json:"dailyEndTime" validate:"omitempty,datetime=15:04"`
+	Stacking       *string  `json:"stacking" validate:"omitempty,oneof=stackable exclusive"`
+	AppliesToAll   *bool    `json:"appliesToAll"`
+	IsActive       *bool    `json:"isActive"`
+	CategoryIDs    []string `json:"categoryIds" validate:"omitempty,dive,uuid"`
+	ProductIDs     []string `json:"productIds" validate:"omitempty,dive,uuid"`
+}
+
+// UpdatePromotionReq changes only the fields sent, an empty string clears a schedule field
+type UpdatePromotionReq struct {
+	Name           *string   `json:"name" validate:"omitempty,max=64"`
+	Type           *string   `json:"type" validate:"omitempty,oneof=buy_x_get_y bundle_price percentage amount"`
+	Value          *float64  `json:"value" validate:"omitempty,gt=0"`
+	BuyQuantity    *int      `json:"buyQuantity" validate:"omitempty,gte=1"`
+	GetQuantity    *int      `json:"getQuantity" validate:"omitempty,gte=1"`
+	MinSpend       *float64  `json:"minSpend" validate:"omitempty,gte=0"`
+	StartsAt       *string   `json:"startsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt         *string   `json:"endsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	DailyStartTime *string   `json:"dailyStartTime" validate:"omitempty,datetime=15:04"`
+	DailyEndTime   *string   `json:"dailyEndTime" validate:"omitempty,datetime=15:04"`
+	Stacking       *string   `json:"stacking" validate:"omitempty,oneof=stackable exclusive"`
+	AppliesToAll   *bool     `json:"appliesToAll"`
+	IsActive       *bool     `json:"isActive"`
+	SortOrder      *int      `json:"sortOrder" validate:"omitempty,gte=0"`
+	CategoryIDs    *[]string `json:"categoryIds" validate:"omitempty,dive,uuid"`
+	ProductIDs     *[]string `json:"productIds" validate:"omitempty,dive,uuid"`
+}
+
+type PromotionRes struct {
+	ID             string   `json:"id"`
+	BusinessID     string   `json:"businessId"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Value          float64  `json:"value"`
+	BuyQuantity    *int     `json:"buyQuantity"`
+	GetQuantity    *int     `json:"getQuantity"`
+	MinSpend       float64  `json:"minSpend"`
+	StartsAt       *string  `json:"startsAt"`
+	EndsAt         *string  `json:"endsAt"`
+	DailyStartTime *string  `json:"dailyStartTime"`
+	DailyEndTime   *string  `json:"dailyEndTime"`
+	Stacking       string   `json:"stacking"`
+	AppliesToAll   bool     `json:"appliesToAll"`
+	IsActive       bool     `json:"isActive"`
+	SortOrder      int      `json:"sortOrder"`
+	CategoryIDs    []string `json:"categoryIds"`
+	ProductIDs     []string `json:"productIds"`
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt"`
+}
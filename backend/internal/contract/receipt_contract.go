@@ -0,0 +1,33 @@
+package contract
+
+// EscposReceiptQuery selects the printer the receipt is rendered for
+type EscposReceiptQuery struct {
+	PaperWidth int    `query:"paperWidth" validate:"omitempty,oneof=58 80"`
+	CodePage   string `query:"codePage" validate:"omitempty,oneof=pc437 pc850 pc858 wpc1252"`
+}
+
+// PublicReceiptQuery selects the layout of a receipt opened through its public link
+type PublicReceiptQuery struct {
+	Layout string `query:"layout" validate:"omitempty,oneof=receipt invoice"`
+}
+
+// SendReceiptReq sends a digital receipt, the recipient is an email address or a WhatsApp number
+type SendReceiptReq struct {
+	Channel   string `json:"channel" validate:"required,oneof=email whatsapp"`
+	Recipient string `json:"recipient" validate:"required,max=255"`
+}
+
+type ReceiptDeliveryRes struct {
+	ID            string  `json:"id"`
+	TransactionID string  `json:"transactionId"`
+	Channel       string  `json:"channel"`
+	Recipient     string  `json:"recipient"`
+	Status        string  `json:"status"`
+	Provider      string  `json:"provider"`
+	Reference     *string `json:"reference"`
+	Error         *string `json:"error"`
+	SentBy        string  `json:"sentBy"`
+	SenderName    string  `json:"senderName"`
+	SentAt        *string `json:"sentAt"`
+	CreatedAt     string  `json:"createdAt"`
+}
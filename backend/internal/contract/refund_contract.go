@@ -0,0 +1,46 @@
+package contract
+
+// Request contracts
+
+type RefundItemReq struct {
+	TransactionItemID string `json:"transactionItemId" validate:"required,uuid"`
+	Quantity          int    `json:"quantity" validate:"required,min=1"`
+}
+
+type CreateRefundReq struct {
+	Items []RefundItemReq `json:"items" validate:"required,min=1,dive"`
+	// Credit takes the refund off what the customer still owes on a credit sale,
+	// points gives back loyalty points redeemed on the sale and gift_card puts the money back on the gift cards used.
+	// On a credit sale any method takes the refund off what is still owed first and pays out only the rest.
+	Method  string `json:"method" validate:"required,oneof=cash qris bank_transfer card credit points gift_card"`
+	Reason  string `json:"reason" validate:"required,max=500"`
+	Restock bool   `json:"restock"`
+}
+
+// Response contracts
+
+type RefundItemRes struct {
+	ID                string  `json:"id"`
+	TransactionItemID string  `json:"transactionItemId"`
+	ProductID         *string `json:"productId"`
+	ProductName       string  `json:"productName"`
+	VariantID         *string `json:"variantId"`
+	VariantLabel      *string `json:"variantLabel"`
+	Price             float64 `json:"price"`
+	Quantity          int     `json:"quantity"`
+	Subtotal          float64 `json:"subtotal"`
+}
+
+type RefundRes struct {
+	ID            string          `json:"id"`
+	TransactionID string          `json:"transactionId"`
+	CreatedBy     string          `json:"createdBy"`
+	CreatorName   string          `json:"creatorName"`
+	Method        string          `json:"method"`
+	Amount        float64         `json:"amount"`
+	CreditAmount  float64         `json:"creditAmount"`
+	Reason        string          `json:"reason"`
+	Restock       bool            `json:"restock"`
+	CreatedAt     string          `json:"createdAt"`
+	Items         []RefundItemRes `json:"items"`
+}
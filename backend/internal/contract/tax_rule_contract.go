@@ -0,0 +1,40 @@
+package contract
+
+type CreateTaxRuleReq struct {
+	Name         string   `json:"name" validate:"required,max=64"`
+	Kind         string   `json:"kind" validate:"required,oneof=tax service_charge"`
+	Rate         float64  `json:"rate" validate:"required,gt=0,lte=100"`
+	IsInclusive  bool     `json:"isInclusive"`
+	AppliesToAll *bool    `json:"appliesToAll"`
+	IsActive     *bool    `json:"isActive"`
+	CategoryIDs  []string `json:"categoryIds" validate:"omitempty,dive,uuid"`
+	ProductIDs   []string `json:"productIds" validate:"omitempty,dive,uuid"`
+}
+
+type UpdateTaxRuleReq struct {
+	Name         *string   `json:"name" validate:"omitempty,max=64"`
+	Kind         *string   `json:"kind" validate:"omitempty,oneof=tax service_charge"`
+	Rate         *float64  `json:"rate" validate:"omitempty,gt=0,lte=100"`
+	IsInclusive  *bool     `json:"isInclusive"`
+	AppliesToAll *bool     `json:"appliesToAll"`
+	IsActive     *bool     `json:"isActive"`
+	SortOrder    *int      `json:"sortOrder" validate:"omitempty,gte=0"`
+	CategoryIDs  *[]string `json:"categoryIds" validate:"omitempty,dive,uuid"`
+	ProductIDs   *[]string `json:"productIds" validate:"omitempty,dive,uuid"`
+}
+
+type TaxRuleRes struct {
+	ID           string   `json:"id"`
+	BusinessID   string   `json:"businessId"`
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	Rate         float64  `json:"rate"`
+	IsInclusive  bool     `json:"isInclusive"`
+	AppliesToAll bool     `json:"appliesToAll"`
+	IsActive     bool     `json:"isActive"`
+	SortOrder    int      `json:"sortOrder"`
+	CategoryIDs  []string `json:"categoryIds"`
+	ProductIDs   []string `json:"productIds"`
+	CreatedAt    string   `json:"createdAt"`
+	UpdatedAt    string   `json:"updatedAt"`
+}
@@ -2,52 +2,186 @@ package contract
 
 // Request contracts
 
+type DiscountReq struct {
+	Type   string  `json:"type" validate:"required,oneof=flat percentage"`
+	Value  float64 `json:"value" validate:"required,gt=0"`
+	Reason string  `json:"reason" validate:"required,max=255"`
+}
+
 type TransactionItemReq struct {
 	ProductID string `json:"productId" validate:"required,uuid"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	// Required for products with variants
+	VariantID *string `json:"variantId" validate:"omitempty,uuid"`
+	// Modifiers chosen for each unit, checked against the groups offered on the product
+	ModifierIDs []string     `json:"modifierIds" validate:"omitempty,dive,uuid"`
+	Quantity    int          `json:"quantity" validate:"required,min=1"`
+	Discount    *DiscountReq `json:"discount" validate:"omitempty"`
+}
+
+// PaymentReq is one payment of a bill, credit puts the amount on the customer's tab,
+// points pays with the customer's loyalty points at the business's point value and
+// gift_card spends the balance of the gift card whose code is sent as the reference
+type PaymentReq struct {
+	Method    string  `json:"method" validate:"required,oneof=cash qris bank_transfer card credit points gift_card"`
+	Amount    float64 `json:"amount" validate:"required,gt=0"`
+	Reference *string `json:"reference" validate:"omitempty,max=255"`
 }
 
 type CreateTransactionReq struct {
 	Items []TransactionItemReq `json:"items" validate:"required,min=1,dive"`
+	// Customer from the customer directory, optional
+	CustomerID *string `json:"customerId" validate:"omitempty,uuid"`
+	// Order level discount, applied after line discounts
+	Discount *DiscountReq `json:"discount" validate:"omitempty"`
+	// Voucher code, taken off after the order discount
+	VoucherCode *string `json:"voucherCode" validate:"omitempty,max=32"`
+	// Required when a discount goes over the cashier's limit. Defaults to the current user when omitted.
+	ApproverID  *string `json:"approverId" validate:"omitempty,uuid"`
+	ApproverPin *string `json:"approverPin" validate:"omitempty,numeric,len=6"`
 	// For cash payment
 	ReceivedAmount *float64 `json:"receivedAmount" validate:"omitempty,min=0"`
 	IsCashPaid     bool     `json:"isCashPaid" validate:"omitempty"`
+	// For split or non-cash payment, takes precedence over receivedAmount
+	Payments []PaymentReq `json:"payments" validate:"omitempty,dive"`
 }
 
 type UpdateTransactionReq struct {
 	Items []TransactionItemReq `json:"items" validate:"required,min=1,dive"`
+	// Customer from the customer directory, optional
+	CustomerID *string `json:"customerId" validate:"omitempty,uuid"`
+	// Order level discount, applied after line discounts
+	Discount *DiscountReq `json:"discount" validate:"omitempty"`
+	// Voucher code, taken off after the order discount
+	VoucherCode *string `json:"voucherCode" validate:"omitempty,max=32"`
+	// Required when a discount goes over the cashier's limit. Defaults to the current user when omitted.
+	ApproverID  *string `json:"approverId" validate:"omitempty,uuid"`
+	ApproverPin *string `json:"approverPin" validate:"omitempty,numeric,len=6"`
 	// For cash payment
 	ReceivedAmount *float64 `json:"receivedAmount" validate:"omitempty,min=0"`
 	IsCashPaid     bool     `json:"isCashPaid" validate:"omitempty"`
+	// For split or non-cash payment, takes precedence over receivedAmount
+	Payments []PaymentReq `json:"payments" validate:"omitempty,dive"`
 }
 
 type PayTransactionReq struct {
-	ReceivedAmount float64 `json:"receivedAmount" validate:"required,min=0"`
+	// Single cash payment, kept for older clients
+	ReceivedAmount *float64 `json:"receivedAmount" validate:"omitempty,min=0"`
+	// For split or non-cash payment, takes precedence over receivedAmount
+	Payments []PaymentReq `json:"payments" validate:"omitempty,dive"`
+}
+
+type CancelTransactionReq struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+	// Required when cancelling a paid transaction. Defaults to the current user when omitted.
+	ApproverID  *string `json:"approverId" validate:"omitempty,uuid"`
+	ApproverPin *string `json:"approverPin" validate:"omitempty,numeric,len=6"`
 }
 
 // Response contracts
 
 type TransactionItemRes struct {
+	ID                  string                        `json:"id"`
+	ProductID           *string                       `json:"productId"`
+	ProductName         string                        `json:"productName"`
+	VariantID           *string                       `json:"variantId"`
+	VariantLabel        *string                       `json:"variantLabel"`
+	Modifiers           []TransactionItemModifierRes  `json:"modifiers"`
+	Price               float64                       `json:"price"`
+	Quantity            int                           `json:"quantity"`
+	GrossAmount         float64                       `json:"grossAmount"`
+	DiscountType        *string                       `json:"discountType"`
+	DiscountValue       *float64                      `json:"discountValue"`
+	DiscountReason      *string                       `json:"discountReason"`
+	DiscountAmount      float64                       `json:"discountAmount"`
+	OrderDiscountAmount float64                       `json:"orderDiscountAmount"`
+	PromotionAmount     float64                       `json:"promotionAmount"`
+	VoucherAmount       float64                       `json:"voucherAmount"`
+	Promotions          []TransactionItemPromotionRes `json:"promotions"`
+	Subtotal            float64                       `json:"subtotal"`
+	TaxAmount           float64                       `json:"taxAmount"`
+	RefundedQuantity    int                           `json:"refundedQuantity"`
+}
+
+type TransactionItemModifierRes struct {
+	ModifierID string  `json:"modifierId"`
+	GroupName  string  `json:"groupName"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+}
+
+type TransactionItemPromotionRes struct {
 	ID          string  `json:"id"`
-	ProductID   *string `json:"productId"`
-	ProductName string  `json:"productName"`
-	Price       float64 `json:"price"`
+	PromotionID *string `json:"promotionId"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
 	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	Amount      float64 `json:"amount"`
+}
+
+type TransactionPaymentRes struct {
+	ID        string  `json:"id"`
+	Method    string  `json:"method"`
+	Amount    float64 `json:"amount"`
+	Reference *string `json:"reference,omitempty"`
+	PaidAt    string  `json:"paidAt"`
+	// Repayment of the credit part, made after the sale
+	IsCreditRepayment bool `json:"isCreditRepayment"`
+}
+
+type TransactionTaxRes struct {
+	ID            string  `json:"id"`
+	TaxRuleID     *string `json:"taxRuleId"`
+	Name          string  `json:"name"`
+	Kind          string  `json:"kind"`
+	Rate          float64 `json:"rate"`
+	IsInclusive   bool    `json:"isInclusive"`
+	TaxableAmount float64 `json:"taxableAmount"`
+	Amount        float64 `json:"amount"`
 }
 
 type TransactionRes struct {
-	ID             string               `json:"id"`
-	BusinessID     string               `json:"businessId"`
-	CreatedBy      string               `json:"createdBy"`
-	CreatorName    string               `json:"creatorName"`
-	TotalAmount    float64              `json:"totalAmount"`
-	ReceivedAmount float64              `json:"receivedAmount"`
-	InvoiceNumber  string               `json:"invoiceNumber"`
-	ChangeAmount   float64              `json:"changeAmount"`
-	Status         string               `json:"status"`
-	PaidAt         *string              `json:"paidAt,omitempty"`
-	ExpiredAt      string               `json:"expiredAt"`
-	CreatedAt      string               `json:"createdAt"`
-	Items          []TransactionItemRes `json:"items"`
+	ID                  string                  `json:"id"`
+	BusinessID          string                  `json:"businessId"`
+	CreatedBy           string                  `json:"createdBy"`
+	CreatorName         string                  `json:"creatorName"`
+	GrossAmount         float64                 `json:"grossAmount"`
+	DiscountType        *string                 `json:"discountType"`
+	DiscountValue       *float64                `json:"discountValue"`
+	DiscountReason      *string                 `json:"discountReason"`
+	OrderDiscountAmount float64                 `json:"orderDiscountAmount"`
+	DiscountAmount      float64                 `json:"discountAmount"`
+	NetAmount           float64                 `json:"netAmount"`
+	DiscountApprovedBy  *string                 `json:"discountApprovedBy,omitempty"`
+	PromotionAmount     float64                 `json:"promotionAmount"`
+	VoucherCode         *string                 `json:"voucherCode"`
+	VoucherAmount       float64                 `json:"voucherAmount"`
+	TaxAmount           float64                 `json:"taxAmount"`
+	InclusiveTaxAmount  float64                 `json:"inclusiveTaxAmount"`
+	Taxes               []TransactionTaxRes     `json:"taxes"`
+	TotalAmount         float64                 `json:"totalAmount"`
+	RoundingAmount      float64                 `json:"roundingAmount"`
+	CreditAmount        float64                 `json:"creditAmount"`
+	OutstandingAmount   float64                 `json:"outstandingAmount"`
+	PointsEarned        int                     `json:"pointsEarned"`
+	PointsRedeemed      int                     `json:"pointsRedeemed"`
+	ReceivedAmount      float64                 `json:"receivedAmount"`
+	InvoiceNumber       string                  `json:"invoiceNumber"`
+	ChangeAmount        float64                 `json:"changeAmount"`
+	Status              string                  `json:"status"`
+	PaidAt              *string                 `json:"paidAt,omitempty"`
+	ExpiredAt           string                  `json:"expiredAt"`
+	CancelledAt         *string                 `json:"cancelledAt,omitempty"`
+	CancelledBy         *string                 `json:"cancelledBy,omitempty"`
+	CancelApprovedBy    *string                 `json:"cancelApprovedBy,omitempty"`
+	CancelReason        *string                 `json:"cancelReason,omitempty"`
+	CashSessionID       *string                 `json:"cashSessionId,omitempty"`
+	CustomerID          *string                 `json:"customerId"`
+	CustomerName        *string                 `json:"customerName"`
+	ReceiptURL          *string                 `json:"receiptUrl,omitempty"`
+	ReceiptURLExpiresAt *string                 `json:"receiptUrlExpiresAt,omitempty"`
+	CreatedAt           string                  `json:"createdAt"`
+	Items               []TransactionItemRes    `json:"items"`
+	Payments            []TransactionPaymentRes `json:"payments"`
+	RefundedAmount      float64                 `json:"refundedAmount"`
+	Refunds             []RefundRes             `json:"refunds"`
 }
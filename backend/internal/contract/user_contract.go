@@ -2,13 +2,69 @@ package contract
 
 // Current user
 type BusinessRes struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Code         string   `json:"code"`
-	Address      *string  `json:"address"`
-	EmployeeSize *string  `json:"employeeSize"`
-	Category     *string  `json:"category"`
-	Logo         *FileRes `json:"logo"`
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Code         string             `json:"code"`
+	Address      *string            `json:"address"`
+	EmployeeSize *string            `json:"employeeSize"`
+	Category     *string            `json:"category"`
+	Logo         *FileRes           `json:"logo"`
+	Qris         *QrisMerchantRes   `json:"qris"`
+	Invoice      *InvoiceFormatRes  `json:"invoice"`
+	Discount     *DiscountLimitRes  `json:"discount"`
+	CashRounding *CashRoundingRes   `json:"cashRounding"`
+	Receipt      *ReceiptRes        `json:"receipt"`
+	Credit       *CreditSettingsRes `json:"credit"`
+	Loyalty      *LoyaltyRulesRes   `json:"loyalty"`
+}
+
+type ReceiptRes struct {
+	Header *string `json:"header"`
+	Footer *string `json:"footer"`
+}
+
+// CreditSettingsRes holds the reminder sent for unpaid credit sales, a nil template uses the default
+type CreditSettingsRes struct {
+	ReminderTemplate *string `json:"reminderTemplate"`
+}
+
+// LoyaltyRulesRes holds how points are earned and redeemed, a nil expiry means points never expire
+type LoyaltyRulesRes struct {
+	Enabled          bool    `json:"enabled"`
+	SpendPerPoint    float64 `json:"spendPerPoint"`
+	PointValue       float64 `json:"pointValue"`
+	MinRedeemPoints  int     `json:"minRedeemPoints"`
+	PointsExpiryDays *int    `json:"pointsExpiryDays"`
+}
+
+type CashRoundingRes struct {
+	Mode string `json:"mode"`
+	Unit int    `json:"unit"`
+}
+
+type DiscountLimitRes struct {
+	CashierMaxPercent float64 `json:"cashierMaxPercent"`
+	ManagerMaxPercent float64 `json:"managerMaxPercent"`
+}
+
+type InvoiceFormatRes struct {
+	Prefix         string `json:"prefix"`
+	Separator      string `json:"separator"`
+	DateFormat     string `json:"dateFormat"`
+	CounterPadding int    `json:"counterPadding"`
+	ResetPeriod    string `json:"resetPeriod"`
+	Example        string `json:"example"`
+}
+
+type QrisMerchantRes struct {
+	Nmid             *string `json:"nmid"`
+	MerchantCriteria *string `json:"merchantCriteria"`
+	Mcc              *string `json:"mcc"`
+	MerchantCity     *string `json:"merchantCity"`
+	PostalCode       *string `json:"postalCode"`
+	AcquirerDomain   *string `json:"acquirerDomain"`
+	MerchantPan      *string `json:"merchantPan"`
+	MerchantID       *string `json:"merchantId"`
 }
 
 type RoleRes struct {
@@ -33,6 +89,7 @@ type UserRes struct {
 type EditCurrentUserReq struct {
 	Name                 *string `json:"name" validate:"omitempty,max=255"`
 	Image                *string `json:"image"`
+	Pin                  *string `json:"pin" validate:"omitempty,numeric,len=6"`
 	BusinessName         *string `json:"businessName" validate:"omitempty,max=255"`
 	BusinessCode         *string `json:"businessCode" validate:"omitempty,max=16"`
 	BusinessAddress      *string `json:"businessAddress"`
@@ -55,4 +112,45 @@ type EditCurrentUserBusinessReq struct {
 	Logo         *string `json:"logo"`
 	Category     *string `json:"category" validate:"omitempty,business_category"`
 	EmployeeSize *string `json:"employeeSize" validate:"omitempty,employee_size"`
+	// QRIS merchant data
+	QrisNmid             *string `json:"qrisNmid" validate:"omitempty,max=32"`
+	QrisMerchantCriteria *string `json:"qrisMerchantCriteria" validate:"omitempty,oneof=UMI UKE UME UBE URE"`
+	QrisMcc              *string `json:"qrisMcc" validate:"omitempty,numeric,len=4"`
+	QrisMerchantCity     *string `json:"qrisMerchantCity" validate:"omitempty,max=15"`
+	QrisPostalCode       *string `json:"qrisPostalCode" validate:"omitempty,numeric,max=10"`
+	QrisAcquirerDomain   *string `json:"qrisAcquirerDomain" validate:"omitempty,max=64"`
+	QrisMerchantPan      *string `json:"qrisMerchantPan" validate:"omitempty,numeric,max=19"`
+	QrisMerchantID       *string `json:"qrisMerchantId" validate:"omitempty,max=32"`
+	// Invoice number format, an empty date format leaves the date out
+	InvoicePrefix         *string `json:"invoicePrefix" validate:"omitempty,alphanum,max=10"`
+	InvoiceSeparator      *string `json:"invoiceSeparator" validate:"omitempty,oneof=- / ."`
+	InvoiceDateFormat     *string `json:"invoiceDateFormat" validate:"omitempty,oneof='' YYYYMMDD YYMMDD YYYYMM YYMM YYYY"`
+	InvoiceCounterPadding *int    `json:"invoiceCounterPadding" validate:"omitempty,min=1,max=10"`
+	InvoiceResetPeriod    *string `json:"invoiceResetPeriod" validate:"omitempty,oneof=daily monthly yearly never"`
+	// Largest discount per role without a manager or owner PIN, in percent
+	CashierMaxDiscountPercent *float64 `json:"cashierMaxDiscountPercent" validate:"omitempty,min=0,max=100"`
+	ManagerMaxDiscountPercent *float64 `json:"managerMaxDiscountPercent" validate:"omitempty,min=0,max=100"`
+	// Rounding of the cash part of a bill
+	CashRoundingMode *string `json:"cashRoundingMode" validate:"omitempty,oneof=none nearest up down"`
+	CashRoundingUnit *int    `json:"cashRoundingUnit" validate:"omitempty,oneof=100 500 1000"`
+	// Free text printed above the items and below the totals of a receipt
+	ReceiptHeader *string `json:"receiptHeader" validate:"omitempty,max=500"`
+	ReceiptFooter *string `json:"receiptFooter" validate:"omitempty,max=500"`
+	// Reminder for unpaid credit sales, {customer}, {business}, {amount} and {since} are filled in
+	CreditReminderTemplate *string `json:"creditReminderTemplate" validate:"omitempty,max=1000"`
+	// Loyalty rules, a point is earned per spendPerPoint rupiah and is worth pointValue rupiah when redeemed.
+	// Expiry days of 0 means points never expire.
+	LoyaltyEnabled          *bool    `json:"loyaltyEnabled"`
+	LoyaltySpendPerPoint    *float64 `json:"loyaltySpendPerPoint" validate:"omitempty,gt=0"`
+	LoyaltyPointValue       *float64 `json:"loyaltyPointValue" validate:"omitempty,gt=0"`
+	LoyaltyMinRedeemPoints  *int     `json:"loyaltyMinRedeemPoints" validate:"omitempty,min=0"`
+	LoyaltyPointsExpiryDays *int     `json:"loyaltyPointsExpiryDays" validate:"omitempty,min=0,max=3650"`
+}
+
+// QRIS
+type QrisRes struct {
+	Payload   string   `json:"payload"`
+	Image     string   `json:"image"` // PNG as data URL
+	Amount    *float64 `json:"amount,omitempty"`
+	ExpiredAt *string  `json:"expiredAt,omitempty"`
 }
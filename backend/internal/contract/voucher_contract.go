@@ -0,0 +1,71 @@
+package contract
+
+// CreateVoucherReq creates one voucher, the code is generated when not chosen
+type CreateVoucherReq struct {
+	Code             *string  `json:"code" validate:"omitempty,min=3,max=32,alphanum"`
+	Type             string   `json:"type" validate:"required,oneof=flat percentage"`
+	Value            float64  `json:"value" validate:"required,gt=0"`
+	MaxDiscount      *float64 `json:"maxDiscount" validate:"omitempty,gt=0"`
+	MinSpend         float64  `json:"minSpend" validate:"gte=0"`
+	UsageLimit       *int     `json:"usageLimit" validate:"omitempty,gte=1"`
+	PerCustomerLimit *int     `json:"perCustomerLimit" validate:"omitempty,gte=1"`
+	StartsAt         *string  `json:"startsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt           *string  `json:"endsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IsActive         *bool    `json:"isActive"`
+}
+
+// GenerateVouchersReq creates a batch of vouchers with random codes and the same rules
+type GenerateVouchersReq struct {
+	Count            int      `json:"count" validate:"required,gte=1,lte=1000"`
+	Prefix           string   `json:"prefix" validate:"omitempty,max=16,alphanum"`
+	Type             string   `json:"type" validate:"required,oneof=flat percentage"`
+	Value            float64  `json:"value" validate:"required,gt=0"`
+	MaxDiscount      *float64 `json:"maxDiscount" validate:"omitempty,gt=0"`
+	MinSpend         float64  `json:"minSpend" validate:"gte=0"`
+	UsageLimit       *int     `json:"usageLimit" validate:"omitempty,gte=1"`
+	PerCustomerLimit *int     `json:"perCustomerLimit" validate:"omitempty,gte=1"`
+	StartsAt         *string  `json:"startsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt           *string  `json:"endsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IsActive         *bool    `json:"isActive"`
+}
+
+// UpdateVoucherReq changes only the fields sent, an empty string clears a date.
+// The discount itself can't change once codes are handed out.
+type UpdateVoucherReq struct {
+	MinSpend         *float64 `json:"minSpend" validate:"omitempty,gte=0"`
+	UsageLimit       *int     `json:"usageLimit" validate:"omitempty,gte=1"`
+	PerCustomerLimit *int     `json:"perCustomerLimit" validate:"omitempty,gte=1"`
+	StartsAt         *string  `json:"startsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt           *string  `json:"endsAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IsActive         *bool    `json:"isActive"`
+}
+
+type ExportCodesQuery struct {
+	BatchID string `query:"batchId" validate:"omitempty,uuid"`
+}
+
+// Response contracts
+
+type VoucherRes struct {
+	ID               string   `json:"id"`
+	BusinessID       string   `json:"businessId"`
+	Code             string   `json:"code"`
+	BatchID          *string  `json:"batchId"`
+	Type             string   `json:"type"`
+	Value            float64  `json:"value"`
+	MaxDiscount      *float64 `json:"maxDiscount"`
+	MinSpend         float64  `json:"minSpend"`
+	UsageLimit       *int     `json:"usageLimit"`
+	PerCustomerLimit *int     `json:"perCustomerLimit"`
+	UsedCount        int      `json:"usedCount"`
+	StartsAt         *string  `json:"startsAt"`
+	EndsAt           *string  `json:"endsAt"`
+	IsActive         bool     `json:"isActive"`
+	CreatedAt        string   `json:"createdAt"`
+	UpdatedAt        string   `json:"updatedAt"`
+}
+
+type VoucherBatchRes struct {
+	BatchID  string       `json:"batchId"`
+	Vouchers []VoucherRes `json:"vouchers"`
+}
@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"app/internal/config"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// LOYALTY_EXPIRY_INTERVAL defines how often expired loyalty points are swept
+	// "0 30 * * * *" means every hour at minute 30
+	LOYALTY_EXPIRY_INTERVAL = "0 30 * * * *"
+)
+
+type LoyaltyExpiryCron struct {
+	cron           *cron.Cron
+	loyaltyUsecase *usecase.LoyaltyUsecase
+}
+
+func NewLoyaltyExpiryCron(ctx context.Context, loyaltyUsecase *usecase.LoyaltyUsecase) *LoyaltyExpiryCron {
+	c := cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+
+	expiryCron := &LoyaltyExpiryCron{
+		cron:           c,
+		loyaltyUsecase: loyaltyUsecase,
+	}
+
+	_, err := c.AddFunc(LOYALTY_EXPIRY_INTERVAL, expiryCron.expirePoints)
+	if err != nil {
+		logger.Log.Error("Failed to schedule loyalty expiry cron job", zap.Error(err))
+		return expiryCron
+	}
+
+	// Start cron in a goroutine
+	go func() {
+		c.Start()
+		logger.Log.Info("Loyalty expiry cron job started - will expire loyalty points every hour")
+
+		// Wait for context cancellation
+		<-ctx.Done()
+		c.Stop()
+		logger.Log.Info("Loyalty expiry cron job stopped")
+	}()
+
+	return expiryCron
+}
+
+// expirePoints sweeps expired loyalty points batch by batch until none are left
+func (t *LoyaltyExpiryCron) expirePoints() {
+	start := time.Now()
+	var total, batches int
+
+	for {
+		expired, err := t.loyaltyUsecase.ExpirePoints(config.LOYALTY_EXPIRY_BATCH_SIZE)
+		if err != nil {
+			logger.Log.Error("Failed to expire loyalty points",
+				zap.Int("expired", total),
+				zap.Int("batches", batches),
+				zap.Error(err))
+			return
+		}
+
+		total += expired
+		batches++
+
+		if expired < config.LOYALTY_EXPIRY_BATCH_SIZE {
+			break
+		}
+	}
+
+	if total == 0 {
+		logger.Log.Debug("No loyalty points to expire")
+		return
+	}
+
+	logger.Log.Info("Expired loyalty points",
+		zap.Int("expired", total),
+		zap.Int("batches", batches),
+		zap.Duration("duration", time.Since(start)))
+}
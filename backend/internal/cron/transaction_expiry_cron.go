@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"app/internal/config"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// TRANSACTION_EXPIRY_INTERVAL defines how often stale pending transactions are swept
+	// "0 * * * * *" means every minute at 0 seconds
+	TRANSACTION_EXPIRY_INTERVAL = "0 * * * * *"
+)
+
+type TransactionExpiryCron struct {
+	cron               *cron.Cron
+	transactionUsecase *usecase.TransactionUsecase
+}
+
+func NewTransactionExpiryCron(ctx context.Context, transactionUsecase *usecase.TransactionUsecase) *TransactionExpiryCron {
+	c := cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+
+	expiryCron := &TransactionExpiryCron{
+		cron:               c,
+		transactionUsecase: transactionUsecase,
+	}
+
+	_, err := c.AddFunc(TRANSACTION_EXPIRY_INTERVAL, expiryCron.expireTransactions)
+	if err != nil {
+		logger.Log.Error("Failed to schedule transaction expiry cron job", zap.Error(err))
+		return expiryCron
+	}
+
+	// Start cron in a goroutine
+	go func() {
+		c.Start()
+		logger.Log.Info("Transaction expiry cron job started - will expire stale pending transactions every minute")
+
+		// Wait for context cancellation
+		<-ctx.Done()
+		c.Stop()
+		logger.Log.Info("Transaction expiry cron job stopped")
+	}()
+
+	return expiryCron
+}
+
+// expireTransactions sweeps expired pending transactions batch by batch until none are left
+func (t *TransactionExpiryCron) expireTransactions() {
+	start := time.Now()
+	var total, batches int
+
+	for {
+		expired, err := t.transactionUsecase.ExpireStaleTransactions(config.TRANSACTION_EXPIRY_BATCH_SIZE)
+		if err != nil {
+			logger.Log.Error("Failed to expire stale transactions",
+				zap.Int("expired", total),
+				zap.Int("batches", batches),
+				zap.Error(err))
+			return
+		}
+
+		total += expired
+		batches++
+
+		if expired < config.TRANSACTION_EXPIRY_BATCH_SIZE {
+			break
+		}
+	}
+
+	if total == 0 {
+		logger.Log.Debug("No stale transactions to expire")
+		return
+	}
+
+	logger.Log.Info("Expired stale transactions",
+		zap.Int("expired", total),
+		zap.Int("batches", batches),
+		zap.Duration("duration", time.Since(start)))
+}
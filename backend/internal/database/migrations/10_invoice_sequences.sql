@@ -0,0 +1,35 @@
+-- +migrate Up
+
+-- Invoice number format, example INV-261017-0001
+ALTER TABLE businesses
+  ADD COLUMN invoice_prefix VARCHAR(10) NOT NULL DEFAULT 'INV',
+  ADD COLUMN invoice_separator VARCHAR(1) NOT NULL DEFAULT '-',
+  ADD COLUMN invoice_date_format VARCHAR(8) NOT NULL DEFAULT 'YYMMDD' CHECK (
+    invoice_date_format IN ('', 'YYYYMMDD', 'YYMMDD', 'YYYYMM', 'YYMM', 'YYYY')
+  ),
+  ADD COLUMN invoice_counter_padding SMALLINT NOT NULL DEFAULT 4 CHECK (invoice_counter_padding BETWEEN 1 AND 10),
+  ADD COLUMN invoice_reset_period VARCHAR(16) NOT NULL DEFAULT 'daily' CHECK (
+    invoice_reset_period IN ('daily', 'monthly', 'yearly', 'never')
+  );
+
+-- =========================================
+-- INVOICE SEQUENCES (one counter per business and reset period)
+-- =========================================
+CREATE TABLE invoice_sequences (
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  period_key VARCHAR(16) NOT NULL,
+  last_value BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (business_id, period_key)
+);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS invoice_sequences;
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS invoice_prefix,
+  DROP COLUMN IF EXISTS invoice_separator,
+  DROP COLUMN IF EXISTS invoice_date_format,
+  DROP COLUMN IF EXISTS invoice_counter_padding,
+  DROP COLUMN IF EXISTS invoice_reset_period;
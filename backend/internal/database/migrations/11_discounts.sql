@@ -0,0 +1,57 @@
+-- +migrate Up
+
+CREATE TYPE DISCOUNT_TYPE AS ENUM ('flat', 'percentage');
+
+-- Largest discount, as a percentage of the line, each role may give without a manager or owner PIN
+ALTER TABLE businesses
+  ADD COLUMN cashier_max_discount_percent NUMERIC(5,2) NOT NULL DEFAULT 10 CHECK (cashier_max_discount_percent BETWEEN 0 AND 100),
+  ADD COLUMN manager_max_discount_percent NUMERIC(5,2) NOT NULL DEFAULT 50 CHECK (manager_max_discount_percent BETWEEN 0 AND 100);
+
+-- Line discount plus the line's share of the order discount. subtotal stays the net amount
+ALTER TABLE transaction_items
+  ADD COLUMN gross_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (gross_amount >= 0),
+  ADD COLUMN discount_type DISCOUNT_TYPE,
+  ADD COLUMN discount_value NUMERIC(12,2) CHECK (discount_value >= 0),
+  ADD COLUMN discount_reason TEXT,
+  ADD COLUMN discount_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+  ADD COLUMN order_discount_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (order_discount_amount >= 0);
+
+UPDATE transaction_items SET gross_amount = subtotal;
+
+ALTER TABLE transactions
+  ADD COLUMN gross_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (gross_amount >= 0),
+  ADD COLUMN discount_type DISCOUNT_TYPE,
+  ADD COLUMN discount_value NUMERIC(12,2) CHECK (discount_value >= 0),
+  ADD COLUMN discount_reason TEXT,
+  ADD COLUMN order_discount_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (order_discount_amount >= 0),
+  ADD COLUMN discount_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+  ADD COLUMN net_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (net_amount >= 0),
+  ADD COLUMN discount_approved_by UUID REFERENCES users(id);
+
+UPDATE transactions SET gross_amount = total_amount, net_amount = total_amount;
+
+-- +migrate Down
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS gross_amount,
+  DROP COLUMN IF EXISTS discount_type,
+  DROP COLUMN IF EXISTS discount_value,
+  DROP COLUMN IF EXISTS discount_reason,
+  DROP COLUMN IF EXISTS order_discount_amount,
+  DROP COLUMN IF EXISTS discount_amount,
+  DROP COLUMN IF EXISTS net_amount,
+  DROP COLUMN IF EXISTS discount_approved_by;
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS gross_amount,
+  DROP COLUMN IF EXISTS discount_type,
+  DROP COLUMN IF EXISTS discount_value,
+  DROP COLUMN IF EXISTS discount_reason,
+  DROP COLUMN IF EXISTS discount_amount,
+  DROP COLUMN IF EXISTS order_discount_amount;
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS cashier_max_discount_percent,
+  DROP COLUMN IF EXISTS manager_max_discount_percent;
+
+DROP TYPE IF EXISTS DISCOUNT_TYPE;
@@ -0,0 +1,74 @@
+-- +migrate Up
+
+CREATE TYPE TAX_KIND AS ENUM ('tax', 'service_charge');
+
+-- Tax and service charge rules of a business, e.g. PPN 11%, PB1 10% or a 5% service charge
+CREATE TABLE tax_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  kind TAX_KIND NOT NULL DEFAULT 'tax',
+  rate NUMERIC(5,2) NOT NULL CHECK (rate > 0 AND rate <= 100),
+  is_inclusive BOOLEAN NOT NULL DEFAULT false,
+  applies_to_all BOOLEAN NOT NULL DEFAULT true,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  sort_order INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_tax_rules_business_id ON tax_rules(business_id);
+
+-- Categories and products a rule is limited to when it does not apply to all
+CREATE TABLE tax_rule_categories (
+  tax_rule_id UUID NOT NULL REFERENCES tax_rules(id) ON DELETE CASCADE,
+  category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  PRIMARY KEY (tax_rule_id, category_id)
+);
+
+CREATE TABLE tax_rule_products (
+  tax_rule_id UUID NOT NULL REFERENCES tax_rules(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (tax_rule_id, product_id)
+);
+
+-- Tax lines of a transaction, copied from the rules so later rule changes keep history intact
+CREATE TABLE transaction_taxes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  tax_rule_id UUID REFERENCES tax_rules(id) ON DELETE SET NULL,
+  name VARCHAR(64) NOT NULL,
+  kind TAX_KIND NOT NULL,
+  rate NUMERIC(5,2) NOT NULL,
+  is_inclusive BOOLEAN NOT NULL DEFAULT false,
+  taxable_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (taxable_amount >= 0),
+  amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_taxes_transaction_id ON transaction_taxes(transaction_id);
+
+-- Exclusive taxes and service charges are added on top of the subtotal, inclusive ones are already in it
+ALTER TABLE transaction_items
+  ADD COLUMN tax_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0);
+
+ALTER TABLE transactions
+  ADD COLUMN tax_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0),
+  ADD COLUMN inclusive_tax_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (inclusive_tax_amount >= 0);
+
+-- +migrate Down
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS tax_amount,
+  DROP COLUMN IF EXISTS inclusive_tax_amount;
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS tax_amount;
+
+DROP TABLE IF EXISTS transaction_taxes;
+DROP TABLE IF EXISTS tax_rule_products;
+DROP TABLE IF EXISTS tax_rule_categories;
+DROP TABLE IF EXISTS tax_rules;
+
+DROP TYPE IF EXISTS TAX_KIND;
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- Rounding of the cash part of a bill, small rupiah coins are rarely in circulation
+ALTER TABLE businesses
+  ADD COLUMN cash_rounding_mode VARCHAR(16) NOT NULL DEFAULT 'none' CHECK (
+    cash_rounding_mode IN ('none', 'nearest', 'up', 'down')
+  ),
+  ADD COLUMN cash_rounding_unit INT NOT NULL DEFAULT 100 CHECK (cash_rounding_unit IN (100, 500, 1000));
+
+-- Difference between the rounded cash due and the exact amount, negative when rounded down
+ALTER TABLE transactions
+  ADD COLUMN rounding_amount NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+-- +migrate Down
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS rounding_amount;
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS cash_rounding_mode,
+  DROP COLUMN IF EXISTS cash_rounding_unit;
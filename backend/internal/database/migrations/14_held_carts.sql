@@ -0,0 +1,23 @@
+-- +migrate Up
+
+-- =========================================
+-- HELD CARTS (parked carts shared by all terminals of a business)
+-- =========================================
+-- Items are kept as JSON, no stock is taken until the cart is resumed as a transaction
+CREATE TABLE held_carts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  note TEXT,
+  items JSONB NOT NULL DEFAULT '[]',
+  discount JSONB,
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_held_carts_business_id ON held_carts(business_id);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS held_carts;
@@ -0,0 +1,72 @@
+-- +migrate Up
+
+CREATE TYPE CASH_SESSION_STATUS AS ENUM ('open', 'closed');
+CREATE TYPE CASH_MOVEMENT_TYPE AS ENUM ('in', 'out');
+
+-- =========================================
+-- CASH SESSIONS (one cash drawer shift of a cashier)
+-- =========================================
+CREATE TABLE cash_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  opened_by UUID NOT NULL REFERENCES users(id),
+  closed_by UUID REFERENCES users(id),
+  status CASH_SESSION_STATUS NOT NULL DEFAULT 'open',
+  opening_float NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+  expected_cash NUMERIC(12,2),
+  counted_cash NUMERIC(12,2) CHECK (counted_cash >= 0),
+  variance NUMERIC(12,2),
+  opening_note TEXT,
+  closing_note TEXT,
+  z_report JSONB,
+  opened_at TIMESTAMP NOT NULL DEFAULT now(),
+  closed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_cash_sessions_business_id ON cash_sessions(business_id);
+
+-- A cashier can only have one drawer open at a time
+CREATE UNIQUE INDEX idx_cash_sessions_open_by_user ON cash_sessions(opened_by) WHERE status = 'open';
+
+-- =========================================
+-- CASH MOVEMENTS (cash put in or taken out of the drawer outside of sales)
+-- =========================================
+CREATE TABLE cash_movements (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  cash_session_id UUID NOT NULL REFERENCES cash_sessions(id) ON DELETE CASCADE,
+  type CASH_MOVEMENT_TYPE NOT NULL,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  reason TEXT NOT NULL,
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_cash_movements_cash_session_id ON cash_movements(cash_session_id);
+
+-- Sales and refunds are counted in the open session of the employee who made them
+ALTER TABLE transactions
+  ADD COLUMN cash_session_id UUID REFERENCES cash_sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_cash_session_id ON transactions(cash_session_id);
+
+ALTER TABLE refunds
+  ADD COLUMN cash_session_id UUID REFERENCES cash_sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_refunds_cash_session_id ON refunds(cash_session_id);
+
+-- +migrate Down
+
+ALTER TABLE refunds
+  DROP COLUMN IF EXISTS cash_session_id;
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS cash_session_id;
+
+DROP TABLE IF EXISTS cash_movements;
+DROP TABLE IF EXISTS cash_sessions;
+
+DROP TYPE IF EXISTS CASH_MOVEMENT_TYPE;
+DROP TYPE IF EXISTS CASH_SESSION_STATUS;
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+-- Free text printed above the items and below the totals of a receipt
+ALTER TABLE businesses
+  ADD COLUMN receipt_header TEXT,
+  ADD COLUMN receipt_footer TEXT;
+
+-- +migrate Down
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS receipt_header,
+  DROP COLUMN IF EXISTS receipt_footer;
@@ -0,0 +1,32 @@
+-- +migrate Up
+
+CREATE TYPE RECEIPT_CHANNEL AS ENUM ('email', 'whatsapp');
+CREATE TYPE RECEIPT_DELIVERY_STATUS AS ENUM ('pending', 'sent', 'failed');
+
+-- =========================================
+-- RECEIPT DELIVERIES (digital receipts sent to customers, one row per send)
+-- =========================================
+CREATE TABLE receipt_deliveries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  channel RECEIPT_CHANNEL NOT NULL,
+  recipient VARCHAR(255) NOT NULL,
+  status RECEIPT_DELIVERY_STATUS NOT NULL DEFAULT 'pending',
+  provider VARCHAR(32) NOT NULL,
+  reference VARCHAR(255),
+  error TEXT,
+  sent_by UUID NOT NULL REFERENCES users(id),
+  sent_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_receipt_deliveries_transaction_id ON receipt_deliveries(transaction_id);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS receipt_deliveries;
+
+DROP TYPE IF EXISTS RECEIPT_DELIVERY_STATUS;
+DROP TYPE IF EXISTS RECEIPT_CHANNEL;
@@ -0,0 +1,34 @@
+-- +migrate Up
+
+-- =========================================
+-- CUSTOMERS (customer directory of a business)
+-- =========================================
+-- Phone numbers are stored in international format without the plus sign, e.g. 6281234567890
+CREATE TABLE customers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  phone VARCHAR(15),
+  email VARCHAR(255),
+  notes TEXT,
+  tags JSONB NOT NULL DEFAULT '[]',
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_customers_business_id ON customers(business_id);
+CREATE UNIQUE INDEX idx_customers_business_id_phone ON customers(business_id, phone) WHERE phone IS NOT NULL;
+
+ALTER TABLE transactions
+  ADD COLUMN customer_id UUID REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_customer_id ON transactions(customer_id);
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_transactions_customer_id;
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS customer_id;
+
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,8 @@
+-- +migrate Up notransaction
+
+-- Sales on credit (kasbon), the customer pays later. Adding an enum value can't run inside a transaction.
+ALTER TYPE PAYMENT_METHOD ADD VALUE IF NOT EXISTS 'credit';
+
+-- +migrate Down
+
+-- Postgres can't drop a value from an enum, 'credit' is left in PAYMENT_METHOD
@@ -0,0 +1,45 @@
+-- +migrate Up
+
+-- Most a customer may owe at once, 0 means no credit
+ALTER TABLE customers
+  ADD COLUMN credit_limit NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (credit_limit >= 0);
+
+-- Part of the total put on credit and what is still owed of it
+ALTER TABLE transactions
+  ADD COLUMN credit_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (credit_amount >= 0),
+  ADD COLUMN outstanding_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (outstanding_amount >= 0 AND outstanding_amount <= credit_amount);
+
+CREATE INDEX idx_transactions_outstanding ON transactions(customer_id) WHERE outstanding_amount > 0;
+
+-- Repayments of a credit sale are payments of their own, taken in the drawer open at the time
+ALTER TABLE transaction_payments
+  ADD COLUMN is_credit_repayment BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN cash_session_id UUID REFERENCES cash_sessions(id) ON DELETE SET NULL,
+  ADD COLUMN created_by UUID REFERENCES users(id);
+
+CREATE INDEX idx_transaction_payments_cash_session_id ON transaction_payments(cash_session_id);
+
+-- Message sent to customers with an outstanding balance, NULL uses the default
+ALTER TABLE businesses
+  ADD COLUMN credit_reminder_template TEXT;
+
+-- +migrate Down
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS credit_reminder_template;
+
+DROP INDEX IF EXISTS idx_transaction_payments_cash_session_id;
+
+ALTER TABLE transaction_payments
+  DROP COLUMN IF EXISTS is_credit_repayment,
+  DROP COLUMN IF EXISTS cash_session_id,
+  DROP COLUMN IF EXISTS created_by;
+
+DROP INDEX IF EXISTS idx_transactions_outstanding;
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS credit_amount,
+  DROP COLUMN IF EXISTS outstanding_amount;
+
+ALTER TABLE customers
+  DROP COLUMN IF EXISTS credit_limit;
@@ -0,0 +1,8 @@
+-- +migrate Up notransaction
+
+-- Loyalty points redeemed at checkout. Adding an enum value can't run inside a transaction.
+ALTER TYPE PAYMENT_METHOD ADD VALUE IF NOT EXISTS 'points';
+
+-- +migrate Down
+
+-- Postgres can't drop a value from an enum, 'points' is left in PAYMENT_METHOD
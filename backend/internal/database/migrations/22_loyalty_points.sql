@@ -0,0 +1,64 @@
+-- +migrate Up
+
+CREATE TYPE LOYALTY_ENTRY_TYPE AS ENUM ('earn', 'redeem', 'reverse', 'restore', 'expire');
+
+-- Loyalty rules, a customer earns a point for every spend_per_point rupiah and a point is worth
+-- point_value rupiah when redeemed. NULL expiry days means points never expire.
+ALTER TABLE businesses
+  ADD COLUMN loyalty_enabled BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN loyalty_spend_per_point NUMERIC(12,2) NOT NULL DEFAULT 10000 CHECK (loyalty_spend_per_point > 0),
+  ADD COLUMN loyalty_point_value NUMERIC(12,2) NOT NULL DEFAULT 100 CHECK (loyalty_point_value > 0),
+  ADD COLUMN loyalty_min_redeem_points INT NOT NULL DEFAULT 0 CHECK (loyalty_min_redeem_points >= 0),
+  ADD COLUMN loyalty_points_expiry_days INT CHECK (loyalty_points_expiry_days > 0);
+
+ALTER TABLE customers
+  ADD COLUMN points_balance INT NOT NULL DEFAULT 0;
+
+ALTER TABLE transactions
+  ADD COLUMN points_earned INT NOT NULL DEFAULT 0 CHECK (points_earned >= 0),
+  ADD COLUMN points_redeemed INT NOT NULL DEFAULT 0 CHECK (points_redeemed >= 0);
+
+-- =========================================
+-- LOYALTY POINT ENTRIES (points ledger of a customer)
+-- =========================================
+-- Points are signed, balance_after is the customer balance once the entry is applied.
+-- Earned and restored points keep what is left of them in remaining_points so they can expire first in, first out.
+CREATE TABLE loyalty_point_entries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+  transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+  refund_id UUID REFERENCES refunds(id) ON DELETE SET NULL,
+  type LOYALTY_ENTRY_TYPE NOT NULL,
+  points INT NOT NULL,
+  balance_after INT NOT NULL,
+  remaining_points INT NOT NULL DEFAULT 0 CHECK (remaining_points >= 0),
+  expires_at TIMESTAMP,
+  note TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_loyalty_point_entries_customer_id ON loyalty_point_entries(customer_id, created_at);
+CREATE INDEX idx_loyalty_point_entries_transaction_id ON loyalty_point_entries(transaction_id);
+CREATE INDEX idx_loyalty_point_entries_expires_at ON loyalty_point_entries(expires_at) WHERE remaining_points > 0;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS loyalty_point_entries;
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS points_earned,
+  DROP COLUMN IF EXISTS points_redeemed;
+
+ALTER TABLE customers
+  DROP COLUMN IF EXISTS points_balance;
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS loyalty_enabled,
+  DROP COLUMN IF EXISTS loyalty_spend_per_point,
+  DROP COLUMN IF EXISTS loyalty_point_value,
+  DROP COLUMN IF EXISTS loyalty_min_redeem_points,
+  DROP COLUMN IF EXISTS loyalty_points_expiry_days;
+
+DROP TYPE IF EXISTS LOYALTY_ENTRY_TYPE;
@@ -0,0 +1,85 @@
+-- +migrate Up
+
+CREATE TYPE PROMOTION_TYPE AS ENUM ('buy_x_get_y', 'bundle_price', 'percentage', 'amount');
+CREATE TYPE PROMOTION_STACKING AS ENUM ('stackable', 'exclusive');
+
+-- Scheduled promotions applied automatically at checkout, e.g. buy 2 get 1 free, 3 for 10k or 20% off
+-- a category between 14:00 and 17:00. Value is the percent off for percentage and buy X get Y promotions,
+-- the amount off for amount promotions and the price of buy_quantity units for bundle promotions.
+CREATE TABLE promotions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  type PROMOTION_TYPE NOT NULL,
+  value NUMERIC(12,2) NOT NULL CHECK (value > 0),
+  buy_quantity INT CHECK (buy_quantity > 0),
+  get_quantity INT CHECK (get_quantity > 0),
+  min_spend NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (min_spend >= 0),
+  starts_at TIMESTAMP,
+  ends_at TIMESTAMP,
+  daily_start_time VARCHAR(5),
+  daily_end_time VARCHAR(5),
+  stacking PROMOTION_STACKING NOT NULL DEFAULT 'stackable',
+  applies_to_all BOOLEAN NOT NULL DEFAULT true,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  sort_order INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_promotions_business_id ON promotions(business_id);
+
+-- Categories and products a promotion is limited to when it does not apply to all
+CREATE TABLE promotion_categories (
+  promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+  category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  PRIMARY KEY (promotion_id, category_id)
+);
+
+CREATE TABLE promotion_products (
+  promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (promotion_id, product_id)
+);
+
+-- Promotions applied to a transaction line, copied from the rules so later rule changes keep history intact
+CREATE TABLE transaction_item_promotions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  transaction_item_id UUID NOT NULL REFERENCES transaction_items(id) ON DELETE CASCADE,
+  promotion_id UUID REFERENCES promotions(id) ON DELETE SET NULL,
+  name VARCHAR(64) NOT NULL,
+  type PROMOTION_TYPE NOT NULL,
+  quantity INT NOT NULL CHECK (quantity > 0),
+  amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_item_promotions_transaction_id ON transaction_item_promotions(transaction_id);
+CREATE INDEX idx_transaction_item_promotions_transaction_item_id ON transaction_item_promotions(transaction_item_id);
+CREATE INDEX idx_transaction_item_promotions_promotion_id ON transaction_item_promotions(promotion_id);
+
+-- Part of the discount amount given by promotions rather than by the cashier
+ALTER TABLE transaction_items
+  ADD COLUMN promotion_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (promotion_amount >= 0);
+
+ALTER TABLE transactions
+  ADD COLUMN promotion_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (promotion_amount >= 0);
+
+-- +migrate Down
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS promotion_amount;
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS promotion_amount;
+
+DROP TABLE IF EXISTS transaction_item_promotions;
+DROP TABLE IF EXISTS promotion_products;
+DROP TABLE IF EXISTS promotion_categories;
+DROP TABLE IF EXISTS promotions;
+
+DROP TYPE IF EXISTS PROMOTION_STACKING;
+DROP TYPE IF EXISTS PROMOTION_TYPE;
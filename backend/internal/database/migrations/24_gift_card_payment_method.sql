@@ -0,0 +1,8 @@
+-- +migrate Up notransaction
+
+-- Gift card balances spent at checkout. Adding an enum value can't run inside a transaction.
+ALTER TYPE PAYMENT_METHOD ADD VALUE IF NOT EXISTS 'gift_card';
+
+-- +migrate Down
+
+-- Postgres can't drop a value from an enum, 'gift_card' is left in PAYMENT_METHOD
@@ -0,0 +1,114 @@
+-- +migrate Up
+
+CREATE TYPE GIFT_CARD_ENTRY_TYPE AS ENUM ('issue', 'redeem', 'restore');
+
+-- =========================================
+-- VOUCHERS (discount codes entered at checkout)
+-- =========================================
+-- Value is an amount for flat vouchers and a percent for percentage vouchers, max_discount caps the latter.
+-- used_count is only changed while the row is locked, so concurrent checkouts can't go over usage_limit.
+-- Codes generated together share a batch_id so they can be exported together.
+CREATE TABLE vouchers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  code VARCHAR(32) NOT NULL,
+  batch_id UUID,
+  type DISCOUNT_TYPE NOT NULL,
+  value NUMERIC(12,2) NOT NULL CHECK (value > 0),
+  max_discount NUMERIC(12,2) CHECK (max_discount > 0),
+  min_spend NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (min_spend >= 0),
+  usage_limit INT CHECK (usage_limit > 0),
+  per_customer_limit INT CHECK (per_customer_limit > 0),
+  used_count INT NOT NULL DEFAULT 0 CHECK (used_count >= 0),
+  starts_at TIMESTAMP,
+  ends_at TIMESTAMP,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (business_id, code),
+  CHECK (usage_limit IS NULL OR used_count <= usage_limit),
+  CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_vouchers_batch_id ON vouchers(batch_id);
+
+-- One row per use of a voucher, reversed_at is set when the use is given back
+-- by cancelling, expiring, editing or fully refunding the sale
+CREATE TABLE voucher_redemptions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  voucher_id UUID NOT NULL REFERENCES vouchers(id) ON DELETE CASCADE,
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+  reversed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_voucher_redemptions_voucher_id ON voucher_redemptions(voucher_id, customer_id);
+CREATE UNIQUE INDEX idx_voucher_redemptions_transaction_id ON voucher_redemptions(transaction_id) WHERE reversed_at IS NULL;
+
+-- Voucher applied to the sale, its discount is spread over the lines like the order discount
+ALTER TABLE transactions
+  ADD COLUMN voucher_id UUID REFERENCES vouchers(id) ON DELETE SET NULL,
+  ADD COLUMN voucher_code VARCHAR(32),
+  ADD COLUMN voucher_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (voucher_amount >= 0);
+
+ALTER TABLE transaction_items
+  ADD COLUMN voucher_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (voucher_amount >= 0);
+
+-- =========================================
+-- GIFT CARDS (stored value spent as a payment method)
+-- =========================================
+CREATE TABLE gift_cards (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  code VARCHAR(32) NOT NULL,
+  batch_id UUID,
+  initial_balance NUMERIC(12,2) NOT NULL CHECK (initial_balance > 0),
+  balance NUMERIC(12,2) NOT NULL CHECK (balance >= 0),
+  expires_at TIMESTAMP,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (business_id, code)
+);
+
+CREATE INDEX idx_gift_cards_batch_id ON gift_cards(batch_id);
+
+-- Balance ledger of a gift card, amounts are signed and balance_after is the balance once applied
+CREATE TABLE gift_card_entries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  gift_card_id UUID NOT NULL REFERENCES gift_cards(id) ON DELETE CASCADE,
+  transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+  refund_id UUID REFERENCES refunds(id) ON DELETE SET NULL,
+  type GIFT_CARD_ENTRY_TYPE NOT NULL,
+  amount NUMERIC(12,2) NOT NULL,
+  balance_after NUMERIC(12,2) NOT NULL,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_gift_card_entries_gift_card_id ON gift_card_entries(gift_card_id, created_at);
+CREATE INDEX idx_gift_card_entries_transaction_id ON gift_card_entries(transaction_id);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS gift_card_entries;
+DROP TABLE IF EXISTS gift_cards;
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS voucher_amount;
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS voucher_id,
+  DROP COLUMN IF EXISTS voucher_code,
+  DROP COLUMN IF EXISTS voucher_amount;
+
+DROP TABLE IF EXISTS voucher_redemptions;
+DROP TABLE IF EXISTS vouchers;
+
+DROP TYPE IF EXISTS GIFT_CARD_ENTRY_TYPE;
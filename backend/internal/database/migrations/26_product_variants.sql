@@ -0,0 +1,68 @@
+-- +migrate Up
+
+-- Option groups of a product, e.g. Size with the values S, M and L. Value order is display order.
+CREATE TABLE product_option_groups (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  option_values JSONB NOT NULL DEFAULT '[]',
+  sort_order INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (product_id, name)
+);
+
+-- One row per combination of option values, generated from the option groups. Options holds one value
+-- per group in group order, label is the values joined for display, e.g. "M / Red".
+CREATE TABLE product_variants (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  options JSONB NOT NULL DEFAULT '[]',
+  label VARCHAR(255) NOT NULL,
+  sku VARCHAR(64),
+  price NUMERIC(12,2) NOT NULL CHECK (price >= 0),
+  cost NUMERIC(12,2) CHECK (cost >= 0),
+  stock_qty INT CHECK (stock_qty >= 0),
+  barcode_value VARCHAR(36),
+  barcode_type BARCODE_TYPE,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  sort_order INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_product_variants_product_id ON product_variants(product_id);
+CREATE UNIQUE INDEX idx_product_variants_business_id_sku ON product_variants(business_id, sku) WHERE sku IS NOT NULL;
+CREATE INDEX idx_product_variants_barcode_value ON product_variants(business_id, barcode_value);
+
+-- Products with variants are sold, priced and stocked per variant
+ALTER TABLE products
+  ADD COLUMN has_variants BOOLEAN NOT NULL DEFAULT false;
+
+-- Variant sold on a line, the label is copied so later changes keep history intact
+ALTER TABLE transaction_items
+  ADD COLUMN variant_id UUID REFERENCES product_variants(id) ON DELETE SET NULL,
+  ADD COLUMN variant_label VARCHAR(255);
+
+CREATE INDEX idx_transaction_items_variant_id ON transaction_items(variant_id);
+
+ALTER TABLE refund_items
+  ADD COLUMN variant_id UUID REFERENCES product_variants(id) ON DELETE SET NULL,
+  ADD COLUMN variant_label VARCHAR(255);
+
+-- +migrate Down
+
+ALTER TABLE refund_items
+  DROP COLUMN IF EXISTS variant_label,
+  DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS variant_label,
+  DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE products
+  DROP COLUMN IF EXISTS has_variants;
+
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_option_groups;
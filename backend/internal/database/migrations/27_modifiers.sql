@@ -0,0 +1,60 @@
+-- +migrate Up
+
+-- Choices attached to a sale line, e.g. "Extra shot", "Less sugar" or "Oat milk"
+CREATE TABLE modifier_groups (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  min_selections INT NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+  max_selections INT NOT NULL DEFAULT 1 CHECK (max_selections >= 1 AND max_selections >= min_selections),
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  sort_order INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_modifier_groups_business_id ON modifier_groups(business_id);
+
+-- Price is added to the unit price of the line, stock is only deducted when enabled
+CREATE TABLE modifiers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  price NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+  enable_stock BOOLEAN NOT NULL DEFAULT false,
+  stock_qty INT CHECK (stock_qty >= 0),
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  sort_order INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_modifiers_modifier_group_id ON modifiers(modifier_group_id);
+
+-- Categories and products a group is offered on
+CREATE TABLE modifier_group_categories (
+  modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+  category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  PRIMARY KEY (modifier_group_id, category_id)
+);
+
+CREATE TABLE modifier_group_products (
+  modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (modifier_group_id, product_id)
+);
+
+-- Chosen modifiers copied onto the line so receipts keep them after the menu changes
+ALTER TABLE transaction_items
+  ADD COLUMN modifiers JSONB NOT NULL DEFAULT '[]';
+
+-- +migrate Down
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS modifiers;
+
+DROP TABLE IF EXISTS modifier_group_products;
+DROP TABLE IF EXISTS modifier_group_categories;
+DROP TABLE IF EXISTS modifiers;
+DROP TABLE IF EXISTS modifier_groups;
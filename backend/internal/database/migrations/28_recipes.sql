@@ -0,0 +1,51 @@
+-- +migrate Up
+
+-- Ingredients a business keeps in stock, e.g. coffee beans in g or milk in ml
+CREATE TABLE ingredients (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  unit VARCHAR(16) NOT NULL,
+  -- Cost of one unit, e.g. per gram
+  cost NUMERIC(12,4) NOT NULL DEFAULT 0 CHECK (cost >= 0),
+  -- Can go below zero, a paid sale is never blocked on ingredients
+  stock_qty NUMERIC(14,3) NOT NULL DEFAULT 0,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (business_id, name)
+);
+
+CREATE INDEX idx_ingredients_business_id ON ingredients(business_id);
+
+-- Ingredients used for one unit of a product
+CREATE TABLE product_recipe_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  ingredient_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE RESTRICT,
+  quantity NUMERIC(12,3) NOT NULL CHECK (quantity > 0),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (product_id, ingredient_id)
+);
+
+CREATE INDEX idx_product_recipe_items_ingredient_id ON product_recipe_items(ingredient_id);
+
+-- The cost of a product with a recipe is worked out from its ingredients
+ALTER TABLE products
+  ADD COLUMN has_recipe BOOLEAN NOT NULL DEFAULT false;
+
+-- Ingredients used for one unit of the line, copied so a cancel or refund restores what was deducted
+ALTER TABLE transaction_items
+  ADD COLUMN ingredients JSONB NOT NULL DEFAULT '[]';
+
+-- +migrate Down
+
+ALTER TABLE transaction_items
+  DROP COLUMN IF EXISTS ingredients;
+
+ALTER TABLE products
+  DROP COLUMN IF EXISTS has_recipe;
+
+DROP TABLE IF EXISTS product_recipe_items;
+DROP TABLE IF EXISTS ingredients;
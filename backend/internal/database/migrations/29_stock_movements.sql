@@ -0,0 +1,44 @@
+-- +migrate Up
+
+CREATE TYPE STOCK_MOVEMENT_TYPE AS ENUM ('sale', 'refund', 'adjustment', 'receipt', 'transfer', 'stock_take');
+
+-- Append-only ledger of product and variant stock, every change to stock_qty is a row
+CREATE TABLE stock_movements (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  -- The label is copied so the history still reads after the variant is removed
+  variant_id UUID REFERENCES product_variants(id) ON DELETE SET NULL,
+  variant_label VARCHAR(255),
+  type STOCK_MOVEMENT_TYPE NOT NULL,
+  -- Negative when stock goes out
+  quantity INT NOT NULL,
+  balance_after INT NOT NULL,
+  -- Document behind the movement: a sale, a refund, or a free text reference such as a supplier invoice
+  transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+  refund_id UUID REFERENCES refunds(id) ON DELETE SET NULL,
+  reference VARCHAR(64),
+  reason TEXT,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, created_at);
+CREATE INDEX idx_stock_movements_transaction_id ON stock_movements(transaction_id);
+
+-- Stock on hand today opens the ledger
+INSERT INTO stock_movements (business_id, product_id, type, quantity, balance_after, reason)
+SELECT business_id, id, 'stock_take', stock_qty, stock_qty, 'Opening balance'
+FROM products
+WHERE stock_qty IS NOT NULL;
+
+INSERT INTO stock_movements (business_id, product_id, variant_id, variant_label, type, quantity, balance_after, reason)
+SELECT business_id, product_id, id, label, 'stock_take', stock_qty, stock_qty, 'Opening balance'
+FROM product_variants
+WHERE stock_qty IS NOT NULL;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS stock_movements;
+
+DROP TYPE IF EXISTS STOCK_MOVEMENT_TYPE;
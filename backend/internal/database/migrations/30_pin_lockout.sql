@@ -0,0 +1,12 @@
+-- +migrate Up
+
+-- Wrong approver PINs in a row, the PIN is locked for a while once too many are entered
+ALTER TABLE users
+  ADD COLUMN pin_failed_attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN pin_locked_until TIMESTAMP;
+
+-- +migrate Down
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS pin_failed_attempts,
+  DROP COLUMN IF EXISTS pin_locked_until;
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+-- Part of a refund taken off what the customer still owed on a credit sale, the rest is paid out
+ALTER TABLE refunds
+  ADD COLUMN credit_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (credit_amount >= 0);
+
+UPDATE refunds SET credit_amount = amount WHERE method = 'credit';
+
+-- +migrate Down
+
+ALTER TABLE refunds
+  DROP COLUMN IF EXISTS credit_amount;
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+-- Customer and voucher picked before the cart was parked, checked again when it is resumed
+ALTER TABLE held_carts
+  ADD COLUMN customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
+  ADD COLUMN voucher_code VARCHAR(32);
+
+-- +migrate Down
+
+ALTER TABLE held_carts
+  DROP COLUMN IF EXISTS customer_id,
+  DROP COLUMN IF EXISTS voucher_code;
@@ -0,0 +1,8 @@
+-- +migrate Up
+
+-- Speeds up the expiry sweeper which only looks at pending transactions
+CREATE INDEX idx_transactions_pending_expired_at ON transactions(expired_at) WHERE status = 'pending';
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_transactions_pending_expired_at;
@@ -0,0 +1,15 @@
+-- +migrate Up
+
+ALTER TABLE transactions
+ADD COLUMN cancelled_at TIMESTAMPTZ,
+ADD COLUMN cancelled_by UUID REFERENCES users(id),
+ADD COLUMN cancel_approved_by UUID REFERENCES users(id),
+ADD COLUMN cancel_reason TEXT;
+
+-- +migrate Down
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS cancelled_at,
+DROP COLUMN IF EXISTS cancelled_by,
+DROP COLUMN IF EXISTS cancel_approved_by,
+DROP COLUMN IF EXISTS cancel_reason;
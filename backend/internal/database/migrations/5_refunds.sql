@@ -0,0 +1,44 @@
+-- +migrate Up
+
+CREATE TYPE PAYMENT_METHOD AS ENUM ('cash', 'qris', 'bank_transfer', 'card');
+
+-- =========================================
+-- REFUNDS (returns against a paid transaction)
+-- =========================================
+CREATE TABLE refunds (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id),
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  created_by UUID NOT NULL REFERENCES users(id),
+  method PAYMENT_METHOD NOT NULL,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+  reason TEXT NOT NULL,
+  restock BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE refund_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  refund_id UUID NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+  transaction_item_id UUID NOT NULL REFERENCES transaction_items(id) ON DELETE CASCADE,
+  product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+  product_name VARCHAR(255) NOT NULL,
+  price NUMERIC(12,2) NOT NULL CHECK (price >= 0),
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  subtotal NUMERIC(12,2) NOT NULL CHECK (subtotal >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_refunds_transaction_id ON refunds(transaction_id);
+CREATE INDEX idx_refunds_business_id_created_at ON refunds(business_id, created_at);
+CREATE INDEX idx_refund_items_refund_id ON refund_items(refund_id);
+CREATE INDEX idx_refund_items_transaction_item_id ON refund_items(transaction_item_id);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+
+DROP TYPE IF EXISTS PAYMENT_METHOD;
@@ -0,0 +1,27 @@
+-- +migrate Up
+
+-- =========================================
+-- TRANSACTION PAYMENTS (one row per tender, a sale can be split across methods)
+-- =========================================
+CREATE TABLE transaction_payments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  method PAYMENT_METHOD NOT NULL,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  reference VARCHAR(255),
+  paid_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_payments_transaction_id ON transaction_payments(transaction_id);
+
+-- Existing paid transactions were all settled in cash
+INSERT INTO transaction_payments (transaction_id, method, amount, paid_at)
+SELECT id, 'cash', received_amount, COALESCE(paid_at, updated_at)
+FROM transactions
+WHERE status = 'paid' AND received_amount > 0;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS transaction_payments;
@@ -0,0 +1,24 @@
+-- +migrate Up
+
+-- Merchant data from the acquirer, used to generate static and dynamic QRIS payloads
+ALTER TABLE businesses
+  ADD COLUMN qris_nmid VARCHAR(32),
+  ADD COLUMN qris_merchant_criteria VARCHAR(3),
+  ADD COLUMN qris_mcc VARCHAR(4),
+  ADD COLUMN qris_merchant_city VARCHAR(15),
+  ADD COLUMN qris_postal_code VARCHAR(10),
+  ADD COLUMN qris_acquirer_domain VARCHAR(64),
+  ADD COLUMN qris_merchant_pan VARCHAR(19),
+  ADD COLUMN qris_merchant_id VARCHAR(32);
+
+-- +migrate Down
+
+ALTER TABLE businesses
+  DROP COLUMN IF EXISTS qris_nmid,
+  DROP COLUMN IF EXISTS qris_merchant_criteria,
+  DROP COLUMN IF EXISTS qris_mcc,
+  DROP COLUMN IF EXISTS qris_merchant_city,
+  DROP COLUMN IF EXISTS qris_postal_code,
+  DROP COLUMN IF EXISTS qris_acquirer_domain,
+  DROP COLUMN IF EXISTS qris_merchant_pan,
+  DROP COLUMN IF EXISTS qris_merchant_id;
@@ -0,0 +1,37 @@
+-- +migrate Up
+
+CREATE TYPE PAYMENT_CHARGE_STATUS AS ENUM ('pending', 'paid', 'expired', 'failed');
+
+-- =========================================
+-- PAYMENT CHARGES (payments collected through a payment gateway)
+-- =========================================
+CREATE TABLE payment_charges (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  business_id UUID NOT NULL REFERENCES businesses(id),
+  transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  provider VARCHAR(32) NOT NULL,
+  reference VARCHAR(255) NOT NULL,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  status PAYMENT_CHARGE_STATUS NOT NULL DEFAULT 'pending',
+  method PAYMENT_METHOD,
+  checkout_url TEXT,
+  qr_string TEXT,
+  paid_amount NUMERIC(12,2),
+  paid_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ,
+  needs_reconciliation BOOLEAN NOT NULL DEFAULT false,
+  reconciliation_note TEXT,
+  webhook_payload JSONB,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (provider, reference)
+);
+
+CREATE INDEX idx_payment_charges_transaction_id ON payment_charges(transaction_id);
+CREATE INDEX idx_payment_charges_needs_reconciliation ON payment_charges(business_id) WHERE needs_reconciliation;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS payment_charges;
+
+DROP TYPE IF EXISTS PAYMENT_CHARGE_STATUS;
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CashSessionHandler struct {
+	cashSessionUsecase *usecase.CashSessionUsecase
+}
+
+func NewCashSessionHandler(cashSessionUsecase *usecase.CashSessionUsecase) *CashSessionHandler {
+	return &CashSessionHandler{
+		cashSessionUsecase: cashSessionUsecase,
+	}
+}
+
+func (h *CashSessionHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	cashSessionGroup := app.Group("/cash-sessions", middleware.AuthGuard(db))
+	cashSessionGroup.Post("/", h.OpenCashSession)
+	cashSessionGroup.Get("/", h.ListCashSessions)
+	cashSessionGroup.Get("/current", h.GetCurrentCashSession)
+	cashSessionGroup.Get("/:id", h.GetCashSession)
+	cashSessionGroup.Post("/:id/movements", h.CreateCashMovement)
+	cashSessionGroup.Post("/:id/close", h.CloseCashSession)
+	cashSessionGroup.Get("/:id/z-report", h.GetZReport)
+}
+
+// @Tags Cash Sessions
+// @Summary Open cash session
+// @Description Open a cash drawer shift for the current user with a starting float. Sales and refunds made while it is open are counted in it
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.OpenCashSessionReq true "Open cash session request"
+// @Success 201 {object} util.BaseResponse{data=contract.CashSessionRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions [post]
+func (h *CashSessionHandler) OpenCashSession(c *fiber.Ctx) error {
+	var req contract.OpenCashSessionReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CASH_SESSION_ANY, config.MANAGE_CASH_SESSION_ORG, config.MANAGE_CASH_SESSION_SELF}, nil); err != nil {
+		return err
+	}
+
+	session, err := h.cashSessionUsecase.OpenSession(claims.ID, *claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(session))
+}
+
+// @Tags Cash Sessions
+// @Summary List cash sessions
+// @Description List cash drawer shifts, newest first. Owners see every shift of the business, cashiers only their own
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param pageSize query int false "Page size (default: 10, max: 100)"
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.CashSessionRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions [get]
+func (h *CashSessionHandler) ListCashSessions(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var openedBy *string
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CASH_SESSION_ANY, config.READ_CASH_SESSION_ORG}, nil); err != nil {
+		if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CASH_SESSION_SELF}, nil); err != nil {
+			return err
+		}
+		openedBy = &claims.ID
+	}
+
+	sessions, total, err := h.cashSessionUsecase.ListSessions(*claims.BusinessID, openedBy, queries.Page, queries.PageSize)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(sessions, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Cash Sessions
+// @Summary Get current cash session
+// @Description Get the cash drawer shift the current user has open
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} util.BaseResponse{data=contract.CashSessionRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions/current [get]
+func (h *CashSessionHandler) GetCurrentCashSession(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CASH_SESSION_ANY, config.READ_CASH_SESSION_ORG, config.READ_CASH_SESSION_SELF}, nil); err != nil {
+		return err
+	}
+
+	session, err := h.cashSessionUsecase.GetCurrentSession(claims.ID, *claims.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(session))
+}
+
+// @Tags Cash Sessions
+// @Summary Get cash session
+// @Description Get a cash drawer shift with its cash movements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Cash session ID"
+// @Success 200 {object} util.BaseResponse{data=contract.CashSessionRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions/{id} [get]
+func (h *CashSessionHandler) GetCashSession(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Cash session ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CASH_SESSION_ANY, config.READ_CASH_SESSION_ORG, config.READ_CASH_SESSION_SELF}, &sessionID); err != nil {
+		return err
+	}
+
+	session, err := h.cashSessionUsecase.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(session))
+}
+
+// @Tags Cash Sessions
+// @Summary Record cash movement
+// @Description Record cash put in or taken out of an open drawer, e.g. extra change or paying a supplier
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Cash session ID"
+// @Param request body contract.CreateCashMovementReq true "Cash movement request"
+// @Success 201 {object} util.BaseResponse{data=contract.CashSessionRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions/{id}/movements [post]
+func (h *CashSessionHandler) CreateCashMovement(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Cash session ID is required")
+	}
+
+	var req contract.CreateCashMovementReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CASH_SESSION_ANY, config.MANAGE_CASH_SESSION_ORG, config.MANAGE_CASH_SESSION_SELF}, &sessionID); err != nil {
+		return err
+	}
+
+	session, err := h.cashSessionUsecase.CreateMovement(claims.ID, sessionID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(session))
+}
+
+// @Tags Cash Sessions
+// @Summary Close cash session
+// @Description Close a drawer with the counted cash. Returns the Z-report with sales, payments by method, refunds, discounts and the variance against the expected cash
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Cash session ID"
+// @Param request body contract.CloseCashSessionReq true "Close cash session request"
+// @Success 200 {object} util.BaseResponse{data=contract.ZReportRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions/{id}/close [post]
+func (h *CashSessionHandler) CloseCashSession(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Cash session ID is required")
+	}
+
+	var req contract.CloseCashSessionReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CASH_SESSION_ANY, config.MANAGE_CASH_SESSION_ORG, config.MANAGE_CASH_SESSION_SELF}, &sessionID); err != nil {
+		return err
+	}
+
+	report, err := h.cashSessionUsecase.CloseSession(claims.ID, sessionID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(report))
+}
+
+// @Tags Cash Sessions
+// @Summary Get Z-report
+// @Description Get the Z-report of a closed drawer as it was at closing, or the running totals of an open one
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Cash session ID"
+// @Success 200 {object} util.BaseResponse{data=contract.ZReportRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /cash-sessions/{id}/z-report [get]
+func (h *CashSessionHandler) GetZReport(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Cash session ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.cashSessionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CASH_SESSION_ANY, config.READ_CASH_SESSION_ORG, config.READ_CASH_SESSION_SELF}, &sessionID); err != nil {
+		return err
+	}
+
+	report, err := h.cashSessionUsecase.GetZReport(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(report))
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CustomerCreditHandler struct {
+	customerCreditUsecase *usecase.CustomerCreditUsecase
+}
+
+func NewCustomerCreditHandler(customerCreditUsecase *usecase.CustomerCreditUsecase) *CustomerCreditHandler {
+	return &CustomerCreditHandler{
+		customerCreditUsecase: customerCreditUsecase,
+	}
+}
+
+func (h *CustomerCreditHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	creditGroup := app.Group("/customers/:id/credit", middleware.AuthGuard(db))
+	creditGroup.Get("/", h.GetCustomerCredit)
+	creditGroup.Post("/repayments", middleware.Idempotency(db), h.CreateRepayment)
+	creditGroup.Get("/reminder", h.GetCreditReminder)
+	creditGroup.Post("/reminder/send", h.SendCreditReminder)
+
+	reportGroup := app.Group("/credit", middleware.AuthGuard(db))
+	reportGroup.Get("/aging", h.GetCreditAging)
+}
+
+// @Tags Customer Credit
+// @Summary Get customer credit
+// @Description Get the outstanding balance, credit limit and unpaid credit sales (kasbon) of a customer, oldest first
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} util.BaseResponse{data=contract.CustomerCreditRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id}/credit [get]
+func (h *CustomerCreditHandler) GetCustomerCredit(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerCreditUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CREDIT_ANY, config.READ_CREDIT_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	credit, err := h.customerCreditUsecase.GetCustomerCredit(customerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(credit))
+}
+
+// @Tags Customer Credit
+// @Summary Record credit repayment
+// @Description Record a full or partial repayment of a customer's credit sales. The amount pays off the oldest sales first and is recorded as a payment on each sale it touches. Cash repayments count in the employee's open cash drawer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param Idempotency-Key header string false "Retry key, the first response is replayed for the same key and payload"
+// @Param request body contract.CreateCreditRepaymentReq true "Create credit repayment request"
+// @Success 201 {object} util.BaseResponse{data=contract.CreditRepaymentRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id}/credit/repayments [post]
+func (h *CustomerCreditHandler) CreateRepayment(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	var req contract.CreateCreditRepaymentReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.customerCreditUsecase.IsAllowedToAccess(claims, []config.Permission{config.REPAY_CREDIT_ANY, config.REPAY_CREDIT_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	repayment, err := h.customerCreditUsecase.CreateRepayment(claims.ID, *claims.BusinessID, customerID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(repayment))
+}
+
+// @Tags Customer Credit
+// @Summary Get credit reminder
+// @Description Fill in the business reminder template for a customer with an outstanding balance. The WhatsApp URL opens a chat with the message ready to send
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} util.BaseResponse{data=contract.CreditReminderRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id}/credit/reminder [get]
+func (h *CustomerCreditHandler) GetCreditReminder(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerCreditUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CREDIT_ANY, config.READ_CREDIT_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	reminder, err := h.customerCreditUsecase.GetCreditReminder(*claims.BusinessID, customerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(reminder))
+}
+
+// @Tags Customer Credit
+// @Summary Send credit reminder
+// @Description Send the credit reminder to the customer over WhatsApp
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} util.BaseResponse{data=contract.CreditReminderRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id}/credit/reminder/send [post]
+func (h *CustomerCreditHandler) SendCreditReminder(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerCreditUsecase.IsAllowedToAccess(claims, []config.Permission{config.REPAY_CREDIT_ANY, config.REPAY_CREDIT_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	reminder, err := h.customerCreditUsecase.SendCreditReminder(*claims.BusinessID, customerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(reminder))
+}
+
+// @Tags Customer Credit
+// @Summary Get credit aging report
+// @Description List customers with an outstanding balance, split into 0-30, 31-60, 61-90 and over 90 days since the credit sale
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} util.BaseResponse{data=contract.CreditAgingRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /credit/aging [get]
+func (h *CustomerCreditHandler) GetCreditAging(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerCreditUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CREDIT_ANY, config.READ_CREDIT_ORG}, nil); err != nil {
+		return err
+	}
+
+	report, err := h.customerCreditUsecase.GetCreditAging(*claims.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(report))
+}
@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CustomerHandler struct {
+	customerUsecase *usecase.CustomerUsecase
+}
+
+func NewCustomerHandler(customerUsecase *usecase.CustomerUsecase) *CustomerHandler {
+	return &CustomerHandler{
+		customerUsecase: customerUsecase,
+	}
+}
+
+func (h *CustomerHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	customerGroup := app.Group("/customers", middleware.AuthGuard(db))
+	customerGroup.Post("/", h.CreateCustomer)
+	customerGroup.Patch("/:id", h.UpdateCustomer)
+	customerGroup.Get("/:id", h.GetCustomer)
+	customerGroup.Get("/:id/transactions", h.ListCustomerTransactions)
+	customerGroup.Get("/:id/points", h.ListCustomerPoints)
+	customerGroup.Get("/", h.ListCustomers)
+	customerGroup.Delete("/:id", h.DeleteCustomer)
+}
+
+// @Tags Customers
+// @Summary Create customer
+// @Description Add a customer to the authenticated user's business. The phone number is stored in international format and must be unique per business
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreateCustomerReq true "Create customer request"
+// @Success 201 {object} util.BaseResponse{data=contract.CustomerRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers [post]
+func (h *CustomerHandler) CreateCustomer(c *fiber.Ctx) error {
+	var req contract.CreateCustomerReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CUSTOMER_ANY, config.MANAGE_CUSTOMER_ORG}, nil); err != nil {
+		return err
+	}
+
+	// Only owners decide who may buy on credit
+	if req.CreditLimit != nil {
+		if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CREDIT_ANY, config.MANAGE_CREDIT_ORG}, nil); err != nil {
+			return err
+		}
+	}
+
+	customer, err := h.customerUsecase.CreateCustomer(*claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(customer))
+}
+
+// @Tags Customers
+// @Summary Update customer
+// @Description Update an existing customer. An empty phone, email or notes clears it
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param request body contract.UpdateCustomerReq true "Update customer request"
+// @Success 200 {object} util.BaseResponse{data=contract.CustomerRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id} [patch]
+func (h *CustomerHandler) UpdateCustomer(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	var req contract.UpdateCustomerReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CUSTOMER_ANY, config.MANAGE_CUSTOMER_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	// Only owners decide who may buy on credit
+	if req.CreditLimit != nil {
+		if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_CREDIT_ANY, config.MANAGE_CREDIT_ORG}, &customerID); err != nil {
+			return err
+		}
+	}
+
+	customer, err := h.customerUsecase.UpdateCustomer(customerID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(customer))
+}
+
+// @Tags Customers
+// @Summary Get customer
+// @Description Get customer details by ID with lifetime spend, visit count and last visit from paid transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} util.BaseResponse{data=contract.CustomerRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id} [get]
+func (h *CustomerHandler) GetCustomer(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CUSTOMER_ANY, config.READ_CUSTOMER_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	customer, err := h.customerUsecase.GetCustomer(customerID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(customer))
+}
+
+// @Tags Customers
+// @Summary List customer transactions
+// @Description List the purchase history of a customer, newest first
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.TransactionRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id}/transactions [get]
+func (h *CustomerHandler) ListCustomerTransactions(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CUSTOMER_ANY, config.READ_CUSTOMER_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	transactions, total, err := h.customerUsecase.ListCustomerTransactions(customerID, queries.Page, queries.PageSize)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(transactions, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Customers
+// @Summary List customer points
+// @Description Points ledger of a customer, newest first. Every change to the points balance is an entry: earned, redeemed, reversed on refund or cancel, restored, or expired
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.LoyaltyPointEntryRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id}/points [get]
+func (h *CustomerHandler) ListCustomerPoints(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CUSTOMER_ANY, config.READ_CUSTOMER_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	entries, total, err := h.customerUsecase.ListCustomerPoints(customerID, queries.Page, queries.PageSize)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(entries, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Customers
+// @Summary List customers
+// @Description List customers of the authenticated user's business. Search matches name, email or phone number, e.g. 0812 also finds 62812...
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Name, email or phone number"
+// @Param tag query string false "Only customers with this tag"
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.CustomerRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers [get]
+func (h *CustomerHandler) ListCustomers(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_CUSTOMER_ANY, config.READ_CUSTOMER_ORG}, nil); err != nil {
+		return err
+	}
+
+	customers, total, err := h.customerUsecase.ListCustomers(*claims.BusinessID, queries.Page, queries.PageSize, queries.Search, c.Query("tag"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(customers, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Customers
+// @Summary Delete customer
+// @Description Delete a customer. Past transactions are kept without the customer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /customers/{id} [delete]
+func (h *CustomerHandler) DeleteCustomer(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+	if customerID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.customerUsecase.IsAllowedToAccess(claims, []config.Permission{config.DELETE_CUSTOMER_ANY, config.DELETE_CUSTOMER_ORG}, &customerID); err != nil {
+		return err
+	}
+
+	if err := h.customerUsecase.DeleteCustomer(customerID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
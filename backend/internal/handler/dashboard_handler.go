@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"app/internal/config"
+	"app/internal/contract"
 	"app/internal/middleware"
 	"app/internal/usecase"
+	"app/pkg/logger"
 	"app/pkg/util"
 
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -22,6 +26,8 @@ func NewDashboardHandler(dashboardUsecase *usecase.DashboardUsecase) *DashboardH
 func (h *DashboardHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
 	dashboardGroup := app.Group("/dashboard", middleware.AuthGuard(db))
 	dashboardGroup.Get("/summary", h.GetDashboardSummary)
+	dashboardGroup.Get("/taxes", h.GetTaxReport)
+	dashboardGroup.Get("/promotions", h.GetPromotionReport)
 }
 
 // @Tags Dashboard
@@ -45,3 +51,83 @@ func (h *DashboardHandler) GetDashboardSummary(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(summary))
 }
+
+// @Tags Dashboard
+// @Summary Get tax report
+// @Description Get the taxes and service charges collected per rule from paid transactions. Defaults to the current month
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "First day of the period (YYYY-MM-DD)"
+// @Param endDate query string false "Last day of the period (YYYY-MM-DD)"
+// @Success 200 {object} util.BaseResponse{data=contract.TaxReportRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /dashboard/taxes [get]
+func (h *DashboardHandler) GetTaxReport(c *fiber.Ctx) error {
+	var query contract.PeriodReportQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.dashboardUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TAX_ANY, config.READ_TAX_ORG}); err != nil {
+		return err
+	}
+
+	report, err := h.dashboardUsecase.GetTaxReport(*claims.BusinessID, &query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(report))
+}
+
+// @Tags Dashboard
+// @Summary Get promotion report
+// @Description Get the discount each promotion gave on paid transactions. Defaults to the current month
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "First day of the period (YYYY-MM-DD)"
+// @Param endDate query string false "Last day of the period (YYYY-MM-DD)"
+// @Success 200 {object} util.BaseResponse{data=contract.PromotionReportRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /dashboard/promotions [get]
+func (h *DashboardHandler) GetPromotionReport(c *fiber.Ctx) error {
+	var query contract.PeriodReportQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.dashboardUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PROMOTION_ANY, config.READ_PROMOTION_ORG}); err != nil {
+		return err
+	}
+
+	report, err := h.dashboardUsecase.GetPromotionReport(*claims.BusinessID, &query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(report))
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type GiftCardHandler struct {
+	giftCardUsecase *usecase.GiftCardUsecase
+}
+
+func NewGiftCardHandler(giftCardUsecase *usecase.GiftCardUsecase) *GiftCardHandler {
+	return &GiftCardHandler{
+		giftCardUsecase: giftCardUsecase,
+	}
+}
+
+func (h *GiftCardHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	giftCardGroup := app.Group("/gift-cards", middleware.AuthGuard(db))
+	giftCardGroup.Post("/", h.CreateGiftCard)
+	giftCardGroup.Post("/bulk", h.GenerateGiftCards)
+	giftCardGroup.Get("/", h.ListGiftCards)
+	giftCardGroup.Get("/export", h.ExportGiftCards)
+	giftCardGroup.Get("/code/:code", h.GetGiftCardByCode)
+	giftCardGroup.Get("/:id", h.GetGiftCard)
+	giftCardGroup.Get("/:id/entries", h.ListGiftCardEntries)
+	giftCardGroup.Patch("/:id", h.UpdateGiftCard)
+}
+
+// @Tags Gift Cards
+// @Summary Issue gift card
+// @Description Issue a gift card with a stored balance for the authenticated user's business. A code is generated when none is given
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreateGiftCardReq true "Issue gift card request"
+// @Success 201 {object} util.BaseResponse{data=contract.GiftCardRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards [post]
+func (h *GiftCardHandler) CreateGiftCard(c *fiber.Ctx) error {
+	var req contract.CreateGiftCardReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_GIFT_CARD_ANY, config.MANAGE_GIFT_CARD_ORG}, nil); err != nil {
+		return err
+	}
+
+	giftCard, err := h.giftCardUsecase.CreateGiftCard(claims.ID, *claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(giftCard))
+}
+
+// @Tags Gift Cards
+// @Summary Generate gift cards
+// @Description Issue a batch of up to 1000 gift cards with random unique codes and the same balance. The batch ID can be used to export the codes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.GenerateGiftCardsReq true "Generate gift cards request"
+// @Success 201 {object} util.BaseResponse{data=contract.GiftCardBatchRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards/bulk [post]
+func (h *GiftCardHandler) GenerateGiftCards(c *fiber.Ctx) error {
+	var req contract.GenerateGiftCardsReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_GIFT_CARD_ANY, config.MANAGE_GIFT_CARD_ORG}, nil); err != nil {
+		return err
+	}
+
+	batch, err := h.giftCardUsecase.GenerateGiftCards(claims.ID, *claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(batch))
+}
+
+// @Tags Gift Cards
+// @Summary Update gift card
+// @Description Change the expiry of a gift card or deactivate it. The balance only changes through sales and refunds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Gift card ID"
+// @Param request body contract.UpdateGiftCardReq true "Update gift card request"
+// @Success 200 {object} util.BaseResponse{data=contract.GiftCardRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards/{id} [patch]
+func (h *GiftCardHandler) UpdateGiftCard(c *fiber.Ctx) error {
+	giftCardID := c.Params("id")
+	if giftCardID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Gift card ID is required")
+	}
+
+	var req contract.UpdateGiftCardReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_GIFT_CARD_ANY, config.MANAGE_GIFT_CARD_ORG}, &giftCardID); err != nil {
+		return err
+	}
+
+	giftCard, err := h.giftCardUsecase.UpdateGiftCard(giftCardID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(giftCard))
+}
+
+// @Tags Gift Cards
+// @Summary Get gift card
+// @Description Get gift card details by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Gift card ID"
+// @Success 200 {object} util.BaseResponse{data=contract.GiftCardRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards/{id} [get]
+func (h *GiftCardHandler) GetGiftCard(c *fiber.Ctx) error {
+	giftCardID := c.Params("id")
+	if giftCardID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Gift card ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_GIFT_CARD_ANY, config.READ_GIFT_CARD_ORG}, &giftCardID); err != nil {
+		return err
+	}
+
+	giftCard, err := h.giftCardUsecase.GetGiftCardByID(giftCardID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(giftCard))
+}
+
+// @Tags Gift Cards
+// @Summary Get gift card by code
+// @Description Look up a gift card of the authenticated user's business by its code, e.g. to check the balance before paying with it
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Gift card code"
+// @Success 200 {object} util.BaseResponse{data=contract.GiftCardRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards/code/{code} [get]
+func (h *GiftCardHandler) GetGiftCardByCode(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_GIFT_CARD_ANY, config.READ_GIFT_CARD_ORG}, nil); err != nil {
+		return err
+	}
+
+	giftCard, err := h.giftCardUsecase.GetGiftCardByCode(*claims.BusinessID, c.Params("code"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(giftCard))
+}
+
+// @Tags Gift Cards
+// @Summary List gift cards
+// @Description List gift cards of the authenticated user's business, newest first
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Part of the code"
+// @Param batchId query string false "Only gift cards of this batch"
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.GiftCardRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards [get]
+func (h *GiftCardHandler) ListGiftCards(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_GIFT_CARD_ANY, config.READ_GIFT_CARD_ORG}, nil); err != nil {
+		return err
+	}
+
+	giftCards, total, err := h.giftCardUsecase.ListGiftCards(*claims.BusinessID, queries.Page, queries.PageSize, queries.Search, c.Query("batchId"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(giftCards, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Gift Cards
+// @Summary List gift card entries
+// @Description Balance ledger of a gift card, newest first. Every change to the balance is an entry: issued, redeemed on a sale, or restored on a refund or cancel
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Gift card ID"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.GiftCardEntryRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards/{id}/entries [get]
+func (h *GiftCardHandler) ListGiftCardEntries(c *fiber.Ctx) error {
+	giftCardID := c.Params("id")
+	if giftCardID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Gift card ID is required")
+	}
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_GIFT_CARD_ANY, config.READ_GIFT_CARD_ORG}, &giftCardID); err != nil {
+		return err
+	}
+
+	entries, total, err := h.giftCardUsecase.ListGiftCardEntries(giftCardID, queries.Page, queries.PageSize)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(entries, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Gift Cards
+// @Summary Export gift cards
+// @Description Download the gift cards of the authenticated user's business with their balances as CSV, e.g. to print the codes of a batch
+// @Produce text/csv
+// @Security BearerAuth
+// @Param batchId query string false "Only gift cards of this batch"
+// @Success 200 {file} file
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /gift-cards/export [get]
+func (h *GiftCardHandler) ExportGiftCards(c *fiber.Ctx) error {
+	var query contract.ExportCodesQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.giftCardUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_GIFT_CARD_ANY, config.MANAGE_GIFT_CARD_ORG}, nil); err != nil {
+		return err
+	}
+
+	document, fileName, err := h.giftCardUsecase.ExportGiftCards(*claims.BusinessID, &query)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	return c.Status(fiber.StatusOK).Send(document)
+}
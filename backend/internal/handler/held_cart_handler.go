@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type HeldCartHandler struct {
+	heldCartUsecase *usecase.HeldCartUsecase
+}
+
+func NewHeldCartHandler(heldCartUsecase *usecase.HeldCartUsecase) *HeldCartHandler {
+	return &HeldCartHandler{
+		heldCartUsecase: heldCartUsecase,
+	}
+}
+
+func (h *HeldCartHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	heldCartGroup := app.Group("/held-carts", middleware.AuthGuard(db))
+	heldCartGroup.Post("/", h.HoldCart)
+	heldCartGroup.Get("/", h.ListHeldCarts)
+	heldCartGroup.Get("/:id", h.GetHeldCart)
+	heldCartGroup.Post("/:id/resume", h.ResumeHeldCart)
+	heldCartGroup.Delete("/:id", h.DiscardHeldCart)
+}
+
+// @Tags Held Carts
+// @Summary Hold cart
+// @Description Park a named cart, e.g. "Table 4", for any terminal of the business to resume later. The customer and voucher picked are kept with it. Does not take stock or start the expiry
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.HoldCartReq true "Hold cart request"
+// @Success 201 {object} util.BaseResponse{data=contract.HeldCartRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /held-carts [post]
+func (h *HeldCartHandler) HoldCart(c *fiber.Ctx) error {
+	var req contract.HoldCartReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.heldCartUsecase.IsAllowedToAccess(claims, []config.Permission{config.CREATE_TRANSACTION_ANY, config.CREATE_TRANSACTION_ORG}, nil); err != nil {
+		return err
+	}
+
+	cart, err := h.heldCartUsecase.HoldCart(claims.ID, *claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(cart))
+}
+
+// @Tags Held Carts
+// @Summary List held carts
+// @Description List parked carts of the business, oldest first
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param pageSize query int false "Page size (default: 10, max: 100)"
+// @Param search query string false "Search by cart name"
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.HeldCartRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /held-carts [get]
+func (h *HeldCartHandler) ListHeldCarts(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.heldCartUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TRANSACTION_ANY, config.READ_TRANSACTION_ORG}, nil); err != nil {
+		return err
+	}
+
+	carts, total, err := h.heldCartUsecase.ListHeldCarts(*claims.BusinessID, queries.Page, queries.PageSize, queries.Search)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(carts, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Held Carts
+// @Summary Get held cart
+// @Description Get a parked cart by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Held cart ID"
+// @Success 200 {object} util.BaseResponse{data=contract.HeldCartRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /held-carts/{id} [get]
+func (h *HeldCartHandler) GetHeldCart(c *fiber.Ctx) error {
+	heldCartID := c.Params("id")
+	if heldCartID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Held cart ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.heldCartUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TRANSACTION_ANY, config.READ_TRANSACTION_ORG}, &heldCartID); err != nil {
+		return err
+	}
+
+	cart, err := h.heldCartUsecase.GetHeldCart(heldCartID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(cart))
+}
+
+// @Tags Held Carts
+// @Summary Resume held cart
+// @Description Check out a parked cart as a pending transaction at current prices. Takes stock, starts the 15-minute expiry and removes the held cart in the same database transaction, so it stays parked if checkout fails. The voucher is checked again
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Held cart ID"
+// @Param request body contract.ResumeHeldCartReq false "Resume held cart request"
+// @Success 201 {object} util.BaseResponse{data=contract.TransactionRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /held-carts/{id}/resume [post]
+func (h *HeldCartHandler) ResumeHeldCart(c *fiber.Ctx) error {
+	heldCartID := c.Params("id")
+	if heldCartID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Held cart ID is required")
+	}
+
+	var req contract.ResumeHeldCartReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			logger.Log.Warn("Failed to parse request body", zap.Error(err))
+			return err
+		}
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.heldCartUsecase.IsAllowedToAccess(claims, []config.Permission{config.CREATE_TRANSACTION_ANY, config.CREATE_TRANSACTION_ORG}, &heldCartID); err != nil {
+		return err
+	}
+
+	transaction, err := h.heldCartUsecase.ResumeHeldCart(claims.ID, *claims.BusinessID, heldCartID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(transaction))
+}
+
+// @Tags Held Carts
+// @Summary Discard held cart
+// @Description Remove a parked cart without checking it out
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Held cart ID"
+// @Success 200 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /held-carts/{id} [delete]
+func (h *HeldCartHandler) DiscardHeldCart(c *fiber.Ctx) error {
+	heldCartID := c.Params("id")
+	if heldCartID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Held cart ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.heldCartUsecase.IsAllowedToAccess(claims, []config.Permission{config.CREATE_TRANSACTION_ANY, config.CREATE_TRANSACTION_ORG}, &heldCartID); err != nil {
+		return err
+	}
+
+	if err := h.heldCartUsecase.DiscardHeldCart(heldCartID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IngredientHandler struct {
+	ingredientUsecase *usecase.IngredientUsecase
+}
+
+func NewIngredientHandler(ingredientUsecase *usecase.IngredientUsecase) *IngredientHandler {
+	return &IngredientHandler{
+		ingredientUsecase: ingredientUsecase,
+	}
+}
+
+func (h *IngredientHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	ingredientGroup := app.Group("/ingredients", middleware.AuthGuard(db))
+	ingredientGroup.Post("/", h.CreateIngredient)
+	ingredientGroup.Patch("/:id", h.UpdateIngredient)
+	ingredientGroup.Get("/:id", h.GetIngredient)
+	ingredientGroup.Get("/", h.ListIngredients)
+	ingredientGroup.Delete("/:id", h.DeleteIngredient)
+}
+
+// @Tags Ingredients
+// @Summary Create ingredient
+// @Description Create an ingredient kept in stock in its own unit, e.g. Coffee beans in g at 0.25 per g
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreateIngredientReq true "Create ingredient request"
+// @Success 201 {object} util.BaseResponse{data=contract.IngredientRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /ingredients [post]
+func (h *IngredientHandler) CreateIngredient(c *fiber.Ctx) error {
+	var req contract.CreateIngredientReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.ingredientUsecase.IsAllowedToAccess(claims, []config.Permission{config.CREATE_PRODUCT_ANY, config.CREATE_PRODUCT_ORG}, nil); err != nil {
+		return err
+	}
+
+	ingredient, err := h.ingredientUsecase.CreateIngredient(*claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(ingredient))
+}
+
+// @Tags Ingredients
+// @Summary Update ingredient
+// @Description Update an existing ingredient. A new cost is passed on to the cost of the products made with it, stock is set to the counted quantity
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ingredient ID"
+// @Param request body contract.UpdateIngredientReq true "Update ingredient request"
+// @Success 200 {object} util.BaseResponse{data=contract.IngredientRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /ingredients/{id} [patch]
+func (h *IngredientHandler) UpdateIngredient(c *fiber.Ctx) error {
+	ingredientID := c.Params("id")
+	if ingredientID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Ingredient ID is required")
+	}
+
+	var req contract.UpdateIngredientReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.ingredientUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &ingredientID); err != nil {
+		return err
+	}
+
+	ingredient, err := h.ingredientUsecase.UpdateIngredient(ingredientID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(ingredient))
+}
+
+// @Tags Ingredients
+// @Summary Get ingredient
+// @Description Get ingredient details by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ingredient ID"
+// @Success 200 {object} util.BaseResponse{data=contract.IngredientRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /ingredients/{id} [get]
+func (h *IngredientHandler) GetIngredient(c *fiber.Ctx) error {
+	ingredientID := c.Params("id")
+	if ingredientID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Ingredient ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.ingredientUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PRODUCT_ANY, config.READ_PRODUCT_ORG}, &ingredientID); err != nil {
+		return err
+	}
+
+	ingredient, err := h.ingredientUsecase.GetIngredientByID(ingredientID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(ingredient))
+}
+
+// @Tags Ingredients
+// @Summary List ingredients
+// @Description List the ingredients of the authenticated user's business by name, with their stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Ingredient name"
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.IngredientRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /ingredients [get]
+func (h *IngredientHandler) ListIngredients(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.ingredientUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PRODUCT_ANY, config.READ_PRODUCT_ORG}, nil); err != nil {
+		return err
+	}
+
+	ingredients, total, err := h.ingredientUsecase.ListIngredients(*claims.BusinessID, queries.Page, queries.PageSize, queries.Search)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(ingredients, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Ingredients
+// @Summary Delete ingredient
+// @Description Delete an ingredient that is not used in any recipe. Ingredients on past transactions are kept
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ingredient ID"
+// @Success 200 {object} util.BaseResponse
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /ingredients/{id} [delete]
+func (h *IngredientHandler) DeleteIngredient(c *fiber.Ctx) error {
+	ingredientID := c.Params("id")
+	if ingredientID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Ingredient ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.ingredientUsecase.IsAllowedToAccess(claims, []config.Permission{config.DELETE_PRODUCT_ANY, config.DELETE_PRODUCT_ORG}, &ingredientID); err != nil {
+		return err
+	}
+
+	if err := h.ingredientUsecase.DeleteIngredient(ingredientID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ModifierHandler struct {
+	modifierUsecase *usecase.ModifierUsecase
+}
+
+func NewModifierHandler(modifierUsecase *usecase.ModifierUsecase) *ModifierHandler {
+	return &ModifierHandler{
+		modifierUsecase: modifierUsecase,
+	}
+}
+
+func (h *ModifierHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	modifierGroup := app.Group("/modifier-groups", middleware.AuthGuard(db))
+	modifierGroup.Post("/", h.CreateModifierGroup)
+	modifierGroup.Patch("/:id", h.UpdateModifierGroup)
+	modifierGroup.Get("/:id", h.GetModifierGroup)
+	modifierGroup.Get("/", h.ListModifierGroups)
+	modifierGroup.Delete("/:id", h.DeleteModifierGroup)
+}
+
+// @Tags Modifiers
+// @Summary Create modifier group
+// @Description Create a group of modifiers offered on products or categories, e.g. Milk: Oat milk +8k with at most one choice, or Extras: Extra shot +5k
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreateModifierGroupReq true "Create modifier group request"
+// @Success 201 {object} util.BaseResponse{data=contract.ModifierGroupRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /modifier-groups [post]
+func (h *ModifierHandler) CreateModifierGroup(c *fiber.Ctx) error {
+	var req contract.CreateModifierGroupReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.modifierUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, nil); err != nil {
+		return err
+	}
+
+	group, err := h.modifierUsecase.CreateModifierGroup(*claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(group))
+}
+
+// @Tags Modifiers
+// @Summary Update modifier group
+// @Description Update an existing modifier group. Modifiers, categories and products are replaced only when sent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Modifier group ID"
+// @Param request body contract.UpdateModifierGroupReq true "Update modifier group request"
+// @Success 200 {object} util.BaseResponse{data=contract.ModifierGroupRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /modifier-groups/{id} [patch]
+func (h *ModifierHandler) UpdateModifierGroup(c *fiber.Ctx) error {
+	modifierGroupID := c.Params("id")
+	if modifierGroupID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Modifier group ID is required")
+	}
+
+	var req contract.UpdateModifierGroupReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.modifierUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &modifierGroupID); err != nil {
+		return err
+	}
+
+	group, err := h.modifierUsecase.UpdateModifierGroup(modifierGroupID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(group))
+}
+
+// @Tags Modifiers
+// @Summary Get modifier group
+// @Description Get modifier group details by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Modifier group ID"
+// @Success 200 {object} util.BaseResponse{data=contract.ModifierGroupRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /modifier-groups/{id} [get]
+func (h *ModifierHandler) GetModifierGroup(c *fiber.Ctx) error {
+	modifierGroupID := c.Params("id")
+	if modifierGroupID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Modifier group ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.modifierUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PRODUCT_ANY, config.READ_PRODUCT_ORG}, &modifierGroupID); err != nil {
+		return err
+	}
+
+	group, err := h.modifierUsecase.GetModifierGroupByID(modifierGroupID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(group))
+}
+
+// @Tags Modifiers
+// @Summary List modifier groups
+// @Description List the modifier groups of the authenticated user's business in display order. With a product, only the active groups offered on it are listed
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param productId query string false "Product ID"
+// @Success 200 {object} util.BaseResponse{data=[]contract.ModifierGroupRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /modifier-groups [get]
+func (h *ModifierHandler) ListModifierGroups(c *fiber.Ctx) error {
+	var req contract.ListModifierGroupsReq
+	if err := c.QueryParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request query", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.modifierUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PRODUCT_ANY, config.READ_PRODUCT_ORG}, nil); err != nil {
+		return err
+	}
+
+	groups, err := h.modifierUsecase.ListModifierGroups(*claims.BusinessID, req.ProductID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(groups))
+}
+
+// @Tags Modifiers
+// @Summary Delete modifier group
+// @Description Delete a modifier group and its modifiers. Modifiers on past transactions are kept
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Modifier group ID"
+// @Success 200 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /modifier-groups/{id} [delete]
+func (h *ModifierHandler) DeleteModifierGroup(c *fiber.Ctx) error {
+	modifierGroupID := c.Params("id")
+	if modifierGroupID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Modifier group ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.modifierUsecase.IsAllowedToAccess(claims, []config.Permission{config.DELETE_PRODUCT_ANY, config.DELETE_PRODUCT_ORG}, &modifierGroupID); err != nil {
+		return err
+	}
+
+	if err := h.modifierUsecase.DeleteModifierGroup(modifierGroupID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/util"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type PaymentHandler struct {
+	paymentUsecase *usecase.PaymentUsecase
+}
+
+func NewPaymentHandler(paymentUsecase *usecase.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (h *PaymentHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	chargeGroup := app.Group("/transactions/:id/charges", middleware.AuthGuard(db))
+	chargeGroup.Post("/", h.CreateCharge)
+	chargeGroup.Post("/:chargeId/check", h.CheckCharge)
+
+	// Called by the payment gateway, authenticated by the callback signature
+	app.Post("/webhooks/payments/:provider", h.HandleWebhook)
+}
+
+// @Tags Payments
+// @Summary Create payment charge
+// @Description Start a payment gateway charge for the total of a pending transaction. An active charge for the same amount is reused
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 201 {object} util.BaseResponse{data=contract.PaymentChargeRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 502 {object} util.BaseResponse
+// @Router /transactions/{id}/charges [post]
+func (h *PaymentHandler) CreateCharge(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.paymentUsecase.IsAllowedToAccess(claims, []config.Permission{config.PAY_TRANSACTION_ANY, config.PAY_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	charge, err := h.paymentUsecase.CreateCharge(*claims.BusinessID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(charge))
+}
+
+// @Tags Payments
+// @Summary Check payment charge
+// @Description Poll the payment gateway for a pending charge and settle the transaction when it has been paid
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param chargeId path string true "Payment charge ID"
+// @Success 200 {object} util.BaseResponse{data=contract.PaymentChargeRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 502 {object} util.BaseResponse
+// @Router /transactions/{id}/charges/{chargeId}/check [post]
+func (h *PaymentHandler) CheckCharge(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	chargeID := c.Params("chargeId")
+	if transactionID == "" || chargeID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID and charge ID are required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.paymentUsecase.IsAllowedToAccess(claims, []config.Permission{config.PAY_TRANSACTION_ANY, config.PAY_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	charge, err := h.paymentUsecase.CheckCharge(transactionID, chargeID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(charge))
+}
+
+// @Tags Payments
+// @Summary Payment webhook
+// @Description Receive a payment gateway callback. The signature is verified and repeated callbacks are ignored
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment provider" Enums(xendit, fake)
+// @Success 200 {object} util.BaseResponse
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Router /webhooks/payments/{provider} [post]
+func (h *PaymentHandler) HandleWebhook(c *fiber.Ctx) error {
+	header := http.Header{}
+	for key, values := range c.GetReqHeaders() {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	if err := h.paymentUsecase.HandleWebhook(c.Params("provider"), header, c.Body()); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
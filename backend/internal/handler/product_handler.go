@@ -30,6 +30,11 @@ func (h *ProductHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
 	productGroup.Get("/:id", h.GetProduct)
 	productGroup.Get("/", h.ListProducts)
 	productGroup.Delete("/:id", h.DeleteProduct)
+	productGroup.Put("/:id/options", h.SetProductOptions)
+	productGroup.Patch("/:id/variants/:variantId", h.UpdateProductVariant)
+	productGroup.Put("/:id/recipe", h.SetProductRecipe)
+	productGroup.Post("/:id/stock-movements", h.RecordStockMovement)
+	productGroup.Get("/:id/stock-movements", h.ListStockMovements)
 	// productGroup.Post("/:id/status", h.ToggleProductStatus)
 }
 
@@ -63,7 +68,7 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		return err
 	}
 
-	product, err := h.productUsecase.CreateProduct(*claims.BusinessID, &req)
+	product, err := h.productUsecase.CreateProduct(claims.ID, *claims.BusinessID, &req)
 	if err != nil {
 		return err
 	}
@@ -106,7 +111,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	if err := h.productUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &productID); err != nil {
 		return err
 	}
-	product, err := h.productUsecase.UpdateProduct(productID, &req)
+	product, err := h.productUsecase.UpdateProduct(claims.ID, productID, &req)
 	if err != nil {
 		return err
 	}
@@ -190,6 +195,141 @@ func (h *ProductHandler) ListProducts(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(products, queries.Page, queries.PageSize, total))
 }
 
+// @Tags Products
+// @Summary Set product options
+// @Description Replace the option groups of a product, e.g. Size: S/M/L and Color: Red/Blue, and generate a variant for every combination. Existing combinations keep their price, stock, SKU and barcode. Variants no longer in the options are deactivated, not deleted, so past sales and their stock are kept. Sending no groups deactivates every variant
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body contract.SetProductOptionsReq true "Set product options request"
+// @Success 200 {object} util.BaseResponse{data=contract.ProductRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /products/{id}/options [put]
+func (h *ProductHandler) SetProductOptions(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	if productID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Product ID is required")
+	}
+
+	var req contract.SetProductOptionsReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.productUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &productID); err != nil {
+		return err
+	}
+
+	product, err := h.productUsecase.SetProductOptions(productID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(product))
+}
+
+// @Tags Products
+// @Summary Set product recipe
+// @Description Replace the ingredients used for one unit of a product, e.g. 18 g Coffee beans and 150 ml Milk. The cost of the product and its variants is worked out from the ingredients, which are deducted from stock when a sale is paid. Sending no items removes the recipe
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body contract.SetProductRecipeReq true "Set product recipe request"
+// @Success 200 {object} util.BaseResponse{data=contract.ProductRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /products/{id}/recipe [put]
+func (h *ProductHandler) SetProductRecipe(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	if productID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Product ID is required")
+	}
+
+	var req contract.SetProductRecipeReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.productUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &productID); err != nil {
+		return err
+	}
+
+	product, err := h.productUsecase.SetProductRecipe(productID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(product))
+}
+
+// @Tags Products
+// @Summary Update product variant
+// @Description Update the price, cost, stock, SKU, barcode or status of one variant of a product
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body contract.UpdateProductVariantReq true "Update product variant request"
+// @Success 200 {object} util.BaseResponse{data=contract.ProductVariantRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /products/{id}/variants/{variantId} [patch]
+func (h *ProductHandler) UpdateProductVariant(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	variantID := c.Params("variantId")
+	if productID == "" || variantID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Product ID and variant ID are required")
+	}
+
+	var req contract.UpdateProductVariantReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.productUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &productID); err != nil {
+		return err
+	}
+
+	variant, err := h.productUsecase.UpdateProductVariant(claims.ID, productID, variantID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(variant))
+}
+
 // @Tags Products
 // @Summary Delete product
 // @Description Permanently delete a product from the database
@@ -257,3 +397,99 @@ func (h *ProductHandler) ToggleProductStatus(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
 }
+
+// @Tags Products
+// @Summary Record stock movement
+// @Description Record stock received from a supplier, transferred in or out, adjusted for damage or loss, or counted in a stock take. The quantity is the change and negative when stock goes out, for a stock take it is the counted quantity and the difference is recorded. Products with variants are stocked per variant
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body contract.RecordStockMovementReq true "Record stock movement request"
+// @Success 201 {object} util.BaseResponse{data=contract.StockMovementRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /products/{id}/stock-movements [post]
+func (h *ProductHandler) RecordStockMovement(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	if productID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Product ID is required")
+	}
+
+	var req contract.RecordStockMovementReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.productUsecase.IsAllowedToAccess(claims, []config.Permission{config.UPDATE_PRODUCT_ANY, config.UPDATE_PRODUCT_ORG}, &productID); err != nil {
+		return err
+	}
+
+	movement, err := h.productUsecase.RecordStockMovement(claims.ID, productID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(movement))
+}
+
+// @Tags Products
+// @Summary List stock movements
+// @Description Stock ledger of a product, newest first. Every change to the stock is a movement: sold or given back on a cancel, refunded, received, transferred, adjusted or counted
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variantId query string false "Only the movements of this variant"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.StockMovementRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /products/{id}/stock-movements [get]
+func (h *ProductHandler) ListStockMovements(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	if productID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Product ID is required")
+	}
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var req contract.ListStockMovementsReq
+	if err := c.QueryParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request query", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.productUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PRODUCT_ANY, config.READ_PRODUCT_ORG}, &productID); err != nil {
+		return err
+	}
+
+	movements, total, err := h.productUsecase.ListStockMovements(productID, req.VariantID, queries.Page, queries.PageSize)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(movements, queries.Page, queries.PageSize, total))
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PromotionHandler struct {
+	promotionUsecase *usecase.PromotionUsecase
+}
+
+func NewPromotionHandler(promotionUsecase *usecase.PromotionUsecase) *PromotionHandler {
+	return &PromotionHandler{
+		promotionUsecase: promotionUsecase,
+	}
+}
+
+func (h *PromotionHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	promotionGroup := app.Group("/promotions", middleware.AuthGuard(db))
+	promotionGroup.Post("/", h.CreatePromotion)
+	promotionGroup.Patch("/:id", h.UpdatePromotion)
+	promotionGroup.Get("/:id", h.GetPromotion)
+	promotionGroup.Get("/", h.ListPromotions)
+	promotionGroup.Delete("/:id", h.DeletePromotion)
+}
+
+// @Tags Promotions
+// @Summary Create promotion
+// @Description Create a promotion applied automatically at checkout for the authenticated user's business, e.g. buy 2 get 1 free, 3 for 10k or 20% off a category from 14:00 to 17:00
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreatePromotionReq true "Create promotion request"
+// @Success 201 {object} util.BaseResponse{data=contract.PromotionRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /promotions [post]
+func (h *PromotionHandler) CreatePromotion(c *fiber.Ctx) error {
+	var req contract.CreatePromotionReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.promotionUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_PROMOTION_ANY, config.MANAGE_PROMOTION_ORG}, nil); err != nil {
+		return err
+	}
+
+	promotion, err := h.promotionUsecase.CreatePromotion(*claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(promotion))
+}
+
+// @Tags Promotions
+// @Summary Update promotion
+// @Description Update an existing promotion. Categories and products are replaced only when sent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion ID"
+// @Param request body contract.UpdatePromotionReq true "Update promotion request"
+// @Success 200 {object} util.BaseResponse{data=contract.PromotionRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /promotions/{id} [patch]
+func (h *PromotionHandler) UpdatePromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	if promotionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Promotion ID is required")
+	}
+
+	var req contract.UpdatePromotionReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.promotionUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_PROMOTION_ANY, config.MANAGE_PROMOTION_ORG}, &promotionID); err != nil {
+		return err
+	}
+
+	promotion, err := h.promotionUsecase.UpdatePromotion(promotionID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(promotion))
+}
+
+// @Tags Promotions
+// @Summary Get promotion
+// @Description Get promotion details by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} util.BaseResponse{data=contract.PromotionRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /promotions/{id} [get]
+func (h *PromotionHandler) GetPromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	if promotionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Promotion ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.promotionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PROMOTION_ANY, config.READ_PROMOTION_ORG}, &promotionID); err != nil {
+		return err
+	}
+
+	promotion, err := h.promotionUsecase.GetPromotionByID(promotionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(promotion))
+}
+
+// @Tags Promotions
+// @Summary List promotions
+// @Description List all promotions of the authenticated user's business in calculation order
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} util.BaseResponse{data=[]contract.PromotionRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /promotions [get]
+func (h *PromotionHandler) ListPromotions(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.promotionUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_PROMOTION_ANY, config.READ_PROMOTION_ORG}, nil); err != nil {
+		return err
+	}
+
+	promotions, err := h.promotionUsecase.ListPromotions(*claims.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(promotions))
+}
+
+// @Tags Promotions
+// @Summary Delete promotion
+// @Description Delete a promotion. Promotions applied to past transactions are kept
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /promotions/{id} [delete]
+func (h *PromotionHandler) DeletePromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	if promotionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Promotion ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.promotionUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_PROMOTION_ANY, config.MANAGE_PROMOTION_ORG}, &promotionID); err != nil {
+		return err
+	}
+
+	if err := h.promotionUsecase.DeletePromotion(promotionID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReceiptHandler struct {
+	receiptUsecase *usecase.ReceiptUsecase
+}
+
+func NewReceiptHandler(receiptUsecase *usecase.ReceiptUsecase) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptUsecase: receiptUsecase,
+	}
+}
+
+func (h *ReceiptHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	receiptGroup := app.Group("/transactions/:id/receipt", middleware.AuthGuard(db))
+	receiptGroup.Get("/escpos", h.GetEscposReceipt)
+	receiptGroup.Post("/send", h.SendReceipt)
+	receiptGroup.Get("/deliveries", h.ListReceiptDeliveries)
+	receiptGroup.Post("/deliveries/:deliveryId/resend", h.ResendReceipt)
+
+	// Public links shared with customers, the signed token replaces the login
+	publicReceiptGroup := app.Group("/receipts")
+	publicReceiptGroup.Get("/:token", h.GetPublicReceipt)
+	publicReceiptGroup.Get("/:token/pdf", h.GetPublicReceiptPDF)
+}
+
+// @Tags Receipts
+// @Summary Get ESC/POS receipt
+// @Description Render a transaction as raw ESC/POS bytes for a 58mm or 80mm thermal printer. A local print bridge can send the response body straight to the printer
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param paperWidth query int false "Paper width in mm, 58 or 80 (default: 58)"
+// @Param codePage query string false "Printer code page, pc437, pc850, pc858 or wpc1252 (default: pc437)"
+// @Success 200 {file} binary
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/receipt/escpos [get]
+func (h *ReceiptHandler) GetEscposReceipt(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	var query contract.EscposReceiptQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.receiptUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TRANSACTION_ANY, config.READ_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	data, err := h.receiptUsecase.RenderEscpos(*claims.BusinessID, transactionID, &query)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// @Tags Receipts
+// @Summary Send receipt
+// @Description Send the receipt of a paid transaction to a customer email address or WhatsApp number. The send is recorded with its delivery status, a failed send can be resent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param request body contract.SendReceiptReq true "Send receipt request"
+// @Success 201 {object} util.BaseResponse{data=contract.ReceiptDeliveryRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/receipt/send [post]
+func (h *ReceiptHandler) SendReceipt(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	var req contract.SendReceiptReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.receiptUsecase.IsAllowedToAccess(claims, []config.Permission{config.CREATE_TRANSACTION_ANY, config.CREATE_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	delivery, err := h.receiptUsecase.SendReceipt(claims.ID, *claims.BusinessID, transactionID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(delivery))
+}
+
+// @Tags Receipts
+// @Summary List receipt deliveries
+// @Description List the receipts sent for a transaction with their delivery status, newest first
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} util.BaseResponse{data=[]contract.ReceiptDeliveryRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/receipt/deliveries [get]
+func (h *ReceiptHandler) ListReceiptDeliveries(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.receiptUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TRANSACTION_ANY, config.READ_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	deliveries, err := h.receiptUsecase.ListReceiptDeliveries(transactionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(deliveries))
+}
+
+// @Tags Receipts
+// @Summary Resend receipt
+// @Description Send a receipt again to the channel and recipient of an earlier send. The resend is recorded as a new delivery
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param deliveryId path string true "Receipt delivery ID"
+// @Success 201 {object} util.BaseResponse{data=contract.ReceiptDeliveryRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/receipt/deliveries/{deliveryId}/resend [post]
+func (h *ReceiptHandler) ResendReceipt(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	deliveryID := c.Params("deliveryId")
+	if transactionID == "" || deliveryID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID and delivery ID are required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.receiptUsecase.IsAllowedToAccess(claims, []config.Permission{config.CREATE_TRANSACTION_ANY, config.CREATE_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	delivery, err := h.receiptUsecase.ResendReceipt(claims.ID, *claims.BusinessID, transactionID, deliveryID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(delivery))
+}
+
+// @Tags Receipts
+// @Summary Get public receipt
+// @Description Open the receipt or A4 invoice of a transaction as an HTML page through the signed link returned by GET /transactions/{id}. No login is needed
+// @Produce html
+// @Param token path string true "Receipt token"
+// @Param layout query string false "receipt or invoice (default: receipt)"
+// @Success 200 {string} string "HTML page"
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /receipts/{token} [get]
+func (h *ReceiptHandler) GetPublicReceipt(c *fiber.Ctx) error {
+	token := c.Params("token")
+
+	var query contract.PublicReceiptQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	page, err := h.receiptUsecase.RenderPublicHTML(token, &query)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(page)
+}
+
+// @Tags Receipts
+// @Summary Get public receipt PDF
+// @Description Download the receipt or A4 invoice of a transaction as a PDF through the signed link returned by GET /transactions/{id}. No login is needed
+// @Produce application/pdf
+// @Param token path string true "Receipt token"
+// @Param layout query string false "receipt or invoice (default: receipt)"
+// @Success 200 {file} binary
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /receipts/{token}/pdf [get]
+func (h *ReceiptHandler) GetPublicReceiptPDF(c *fiber.Ctx) error {
+	token := c.Params("token")
+
+	var query contract.PublicReceiptQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	document, fileName, err := h.receiptUsecase.RenderPublicPDF(token, &query)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", fileName))
+	return c.Status(fiber.StatusOK).Send(document)
+}
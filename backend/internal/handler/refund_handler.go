@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RefundHandler struct {
+	refundUsecase *usecase.RefundUsecase
+}
+
+func NewRefundHandler(refundUsecase *usecase.RefundUsecase) *RefundHandler {
+	return &RefundHandler{
+		refundUsecase: refundUsecase,
+	}
+}
+
+func (h *RefundHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	refundGroup := app.Group("/transactions/:id/refunds", middleware.AuthGuard(db))
+	refundGroup.Post("/", h.CreateRefund)
+	refundGroup.Get("/", h.ListRefunds)
+}
+
+// @Tags Refunds
+// @Summary Create refund
+// @Description Refund some or all items of a paid transaction, optionally putting them back into stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param request body contract.CreateRefundReq true "Create refund request"
+// @Success 201 {object} util.BaseResponse{data=contract.RefundRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/refunds [post]
+func (h *RefundHandler) CreateRefund(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	var req contract.CreateRefundReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.refundUsecase.IsAllowedToAccess(claims, []config.Permission{config.REFUND_TRANSACTION_ANY, config.REFUND_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	refund, err := h.refundUsecase.CreateRefund(claims.ID, *claims.BusinessID, transactionID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(refund))
+}
+
+// @Tags Refunds
+// @Summary List refunds
+// @Description List the refund history of a transaction
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} util.BaseResponse{data=[]contract.RefundRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/refunds [get]
+func (h *RefundHandler) ListRefunds(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.refundUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TRANSACTION_ANY, config.READ_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	refunds, err := h.refundUsecase.ListRefunds(transactionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(refunds))
+}
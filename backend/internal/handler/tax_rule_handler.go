@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TaxRuleHandler struct {
+	taxRuleUsecase *usecase.TaxRuleUsecase
+}
+
+func NewTaxRuleHandler(taxRuleUsecase *usecase.TaxRuleUsecase) *TaxRuleHandler {
+	return &TaxRuleHandler{
+		taxRuleUsecase: taxRuleUsecase,
+	}
+}
+
+func (h *TaxRuleHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	taxRuleGroup := app.Group("/tax-rules", middleware.AuthGuard(db))
+	taxRuleGroup.Post("/", h.CreateTaxRule)
+	taxRuleGroup.Patch("/:id", h.UpdateTaxRule)
+	taxRuleGroup.Get("/:id", h.GetTaxRule)
+	taxRuleGroup.Get("/", h.ListTaxRules)
+	taxRuleGroup.Delete("/:id", h.DeleteTaxRule)
+}
+
+// @Tags Tax Rules
+// @Summary Create tax rule
+// @Description Create a tax or service charge rule for the authenticated user's business, e.g. PPN 11%, PB1 10% or a 5% service charge
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreateTaxRuleReq true "Create tax rule request"
+// @Success 201 {object} util.BaseResponse{data=contract.TaxRuleRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /tax-rules [post]
+func (h *TaxRuleHandler) CreateTaxRule(c *fiber.Ctx) error {
+	var req contract.CreateTaxRuleReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.taxRuleUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_TAX_ANY, config.MANAGE_TAX_ORG}, nil); err != nil {
+		return err
+	}
+
+	rule, err := h.taxRuleUsecase.CreateTaxRule(*claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(rule))
+}
+
+// @Tags Tax Rules
+// @Summary Update tax rule
+// @Description Update an existing tax rule. Categories and products are replaced only when sent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax rule ID"
+// @Param request body contract.UpdateTaxRuleReq true "Update tax rule request"
+// @Success 200 {object} util.BaseResponse{data=contract.TaxRuleRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /tax-rules/{id} [patch]
+func (h *TaxRuleHandler) UpdateTaxRule(c *fiber.Ctx) error {
+	taxRuleID := c.Params("id")
+	if taxRuleID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Tax rule ID is required")
+	}
+
+	var req contract.UpdateTaxRuleReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.taxRuleUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_TAX_ANY, config.MANAGE_TAX_ORG}, &taxRuleID); err != nil {
+		return err
+	}
+
+	rule, err := h.taxRuleUsecase.UpdateTaxRule(taxRuleID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(rule))
+}
+
+// @Tags Tax Rules
+// @Summary Get tax rule
+// @Description Get tax rule details by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax rule ID"
+// @Success 200 {object} util.BaseResponse{data=contract.TaxRuleRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /tax-rules/{id} [get]
+func (h *TaxRuleHandler) GetTaxRule(c *fiber.Ctx) error {
+	taxRuleID := c.Params("id")
+	if taxRuleID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Tax rule ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.taxRuleUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TAX_ANY, config.READ_TAX_ORG}, &taxRuleID); err != nil {
+		return err
+	}
+
+	rule, err := h.taxRuleUsecase.GetTaxRuleByID(taxRuleID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(rule))
+}
+
+// @Tags Tax Rules
+// @Summary List tax rules
+// @Description List all tax and service charge rules of the authenticated user's business in calculation order
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} util.BaseResponse{data=[]contract.TaxRuleRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /tax-rules [get]
+func (h *TaxRuleHandler) ListTaxRules(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.taxRuleUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_TAX_ANY, config.READ_TAX_ORG}, nil); err != nil {
+		return err
+	}
+
+	rules, err := h.taxRuleUsecase.ListTaxRules(*claims.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(rules))
+}
+
+// @Tags Tax Rules
+// @Summary Delete tax rule
+// @Description Delete a tax rule. Tax lines of past transactions are kept
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax rule ID"
+// @Success 200 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /tax-rules/{id} [delete]
+func (h *TaxRuleHandler) DeleteTaxRule(c *fiber.Ctx) error {
+	taxRuleID := c.Params("id")
+	if taxRuleID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Tax rule ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.taxRuleUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_TAX_ANY, config.MANAGE_TAX_ORG}, &taxRuleID); err != nil {
+		return err
+	}
+
+	if err := h.taxRuleUsecase.DeleteTaxRule(taxRuleID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(nil))
+}
@@ -25,11 +25,13 @@ func NewTransactionHandler(transactionUsecase *usecase.TransactionUsecase) *Tran
 
 func (h *TransactionHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
 	transactionGroup := app.Group("/transactions", middleware.AuthGuard(db))
-	transactionGroup.Post("/", h.CreateTransaction)
+	transactionGroup.Post("/", middleware.Idempotency(db), h.CreateTransaction)
 	transactionGroup.Get("/", h.ListTransactions)
 	transactionGroup.Get("/:id", h.GetTransaction)
-	transactionGroup.Put("/:id", h.UpdateTransaction)
-	transactionGroup.Post("/:id/pay", h.PayTransaction)
+	transactionGroup.Put("/:id", middleware.Idempotency(db), h.UpdateTransaction)
+	transactionGroup.Post("/:id/pay", middleware.Idempotency(db), h.PayTransaction)
+	transactionGroup.Get("/:id/qris", h.GetTransactionQris)
+	transactionGroup.Post("/:id/cancel", h.CancelTransaction)
 }
 
 // @Tags Transactions
@@ -38,10 +40,13 @@ func (h *TransactionHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param Idempotency-Key header string false "Retry key, the first response is replayed for the same key and payload"
 // @Param request body contract.CreateTransactionReq true "Create transaction request"
 // @Success 201 {object} util.BaseResponse{data=contract.TransactionRes}
 // @Failure 400 {object} util.BaseResponse
 // @Failure 401 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 422 {object} util.BaseResponse
 // @Failure 500 {object} util.BaseResponse
 // @Router /transactions [post]
 func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
@@ -141,11 +146,14 @@ func (h *TransactionHandler) GetTransaction(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Transaction ID"
+// @Param Idempotency-Key header string false "Retry key, the first response is replayed for the same key and payload"
 // @Param request body contract.UpdateTransactionReq true "Update transaction request"
 // @Success 200 {object} util.BaseResponse{data=contract.TransactionRes}
 // @Failure 400 {object} util.BaseResponse
 // @Failure 401 {object} util.BaseResponse
 // @Failure 404 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 422 {object} util.BaseResponse
 // @Failure 500 {object} util.BaseResponse
 // @Router /transactions/{id} [put]
 func (h *TransactionHandler) UpdateTransaction(c *fiber.Ctx) error {
@@ -181,16 +189,19 @@ func (h *TransactionHandler) UpdateTransaction(c *fiber.Ctx) error {
 
 // @Tags Transactions
 // @Summary Pay transaction
-// @Description Finalize transaction with cash payment
+// @Description Finalize transaction with one or more payments (cash, QRIS, bank transfer, card). Change is given from the cash portion only
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Transaction ID"
+// @Param Idempotency-Key header string false "Retry key, the first response is replayed for the same key and payload"
 // @Param request body contract.PayTransactionReq true "Pay transaction request"
 // @Success 200 {object} util.BaseResponse{data=contract.TransactionRes}
 // @Failure 400 {object} util.BaseResponse
 // @Failure 401 {object} util.BaseResponse
 // @Failure 404 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 422 {object} util.BaseResponse
 // @Failure 500 {object} util.BaseResponse
 // @Router /transactions/{id}/pay [post]
 func (h *TransactionHandler) PayTransaction(c *fiber.Ctx) error {
@@ -223,3 +234,83 @@ func (h *TransactionHandler) PayTransaction(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(transaction))
 }
+
+// @Tags Transactions
+// @Summary Get transaction QRIS
+// @Description Generate a dynamic QRIS payload and PNG image (data URL) with the amount of a pending transaction
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} util.BaseResponse{data=contract.QrisRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/qris [get]
+func (h *TransactionHandler) GetTransactionQris(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.transactionUsecase.IsAllowedToAccess(claims, []config.Permission{config.PAY_TRANSACTION_ANY, config.PAY_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	qris, err := h.transactionUsecase.GetTransactionQris(*claims.BusinessID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(qris))
+}
+
+// @Tags Transactions
+// @Summary Cancel transaction
+// @Description Void a pending or paid transaction and restore its stock. Paid transactions require a manager or owner PIN
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Param request body contract.CancelTransactionReq true "Cancel transaction request"
+// @Success 200 {object} util.BaseResponse{data=contract.TransactionRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 429 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /transactions/{id}/cancel [post]
+func (h *TransactionHandler) CancelTransaction(c *fiber.Ctx) error {
+	transactionID := c.Params("id")
+	if transactionID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction ID is required")
+	}
+
+	var req contract.CancelTransactionReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.transactionUsecase.IsAllowedToAccess(claims, []config.Permission{config.CANCEL_TRANSACTION_ANY, config.CANCEL_TRANSACTION_ORG}, &transactionID); err != nil {
+		return err
+	}
+
+	transaction, err := h.transactionUsecase.CancelTransaction(claims.ID, *claims.BusinessID, transactionID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(transaction))
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"app/internal/config"
 	"app/internal/contract"
 	"app/internal/middleware"
 	"app/internal/usecase"
@@ -30,6 +31,7 @@ func (h *UserHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
 	currentUserGroup.Put("", h.EditCurrentUser)
 	currentUserGroup.Put("/password", h.EditCurrentUserPassword)
 	currentUserGroup.Put("/business", h.EditCurrentUserBusiness)
+	currentUserGroup.Get("/business/qris", h.GetCurrentUserBusinessQris)
 
 }
 
@@ -120,7 +122,7 @@ func (h *UserHandler) EditCurrentUserPassword(c *fiber.Ctx) error {
 
 // @Tags User
 // @Summary Edit current user business
-// @Description Change authenticated user's business
+// @Description Change authenticated user's business. Only the owner can change it, as it holds the discount caps, cash rounding, invoice numbering, loyalty and QRIS settings
 // @Accept json
 // @Produce json
 // @Security BearerAuth
@@ -128,6 +130,7 @@ func (h *UserHandler) EditCurrentUserPassword(c *fiber.Ctx) error {
 // @Success 200 {object} util.BaseResponse
 // @Failure 400 {object} util.BaseResponse
 // @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
 // @Failure 500 {object} util.BaseResponse
 // @Router /users/current/business [put]
 func (h *UserHandler) EditCurrentUserBusiness(c *fiber.Ctx) error {
@@ -143,6 +146,12 @@ func (h *UserHandler) EditCurrentUserBusiness(c *fiber.Ctx) error {
 	}
 
 	claims := middleware.GetAuthClaims(c)
+
+	// Cashiers and managers must not raise their own discount caps or change how sales are settled
+	if err := h.userUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_BUSINESS_ANY, config.MANAGE_BUSINESS_ORG}, nil); err != nil {
+		return err
+	}
+
 	res, err := h.userUsecase.EditBusiness(claims.ID, *claims.BusinessID, &req)
 	if err != nil {
 		return err
@@ -150,3 +159,28 @@ func (h *UserHandler) EditCurrentUserBusiness(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(res))
 }
+
+// @Tags User
+// @Summary Get current business QRIS
+// @Description Generate the static QRIS payload and PNG image (data URL) of the current business
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} util.BaseResponse{data=contract.QrisRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /users/current/business/qris [get]
+func (h *UserHandler) GetCurrentUserBusinessQris(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+	if claims.BusinessID == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "No business selected")
+	}
+
+	res, err := h.userUsecase.GetBusinessQris(*claims.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(res))
+}
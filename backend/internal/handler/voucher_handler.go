@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/usecase"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type VoucherHandler struct {
+	voucherUsecase *usecase.VoucherUsecase
+}
+
+func NewVoucherHandler(voucherUsecase *usecase.VoucherUsecase) *VoucherHandler {
+	return &VoucherHandler{
+		voucherUsecase: voucherUsecase,
+	}
+}
+
+func (h *VoucherHandler) RegisterRoutes(app *fiber.App, db *gorm.DB) {
+	voucherGroup := app.Group("/vouchers", middleware.AuthGuard(db))
+	voucherGroup.Post("/", h.CreateVoucher)
+	voucherGroup.Post("/bulk", h.GenerateVouchers)
+	voucherGroup.Get("/", h.ListVouchers)
+	voucherGroup.Get("/export", h.ExportVouchers)
+	voucherGroup.Get("/code/:code", h.GetVoucherByCode)
+	voucherGroup.Get("/:id", h.GetVoucher)
+	voucherGroup.Patch("/:id", h.UpdateVoucher)
+}
+
+// @Tags Vouchers
+// @Summary Create voucher
+// @Description Create a voucher for the authenticated user's business. A code is generated when none is given
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.CreateVoucherReq true "Create voucher request"
+// @Success 201 {object} util.BaseResponse{data=contract.VoucherRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 409 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers [post]
+func (h *VoucherHandler) CreateVoucher(c *fiber.Ctx) error {
+	var req contract.CreateVoucherReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_VOUCHER_ANY, config.MANAGE_VOUCHER_ORG}, nil); err != nil {
+		return err
+	}
+
+	voucher, err := h.voucherUsecase.CreateVoucher(claims.ID, *claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(voucher))
+}
+
+// @Tags Vouchers
+// @Summary Generate vouchers
+// @Description Generate a batch of up to 1000 vouchers with random unique codes and the same terms. The batch ID can be used to export the codes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body contract.GenerateVouchersReq true "Generate vouchers request"
+// @Success 201 {object} util.BaseResponse{data=contract.VoucherBatchRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers/bulk [post]
+func (h *VoucherHandler) GenerateVouchers(c *fiber.Ctx) error {
+	var req contract.GenerateVouchersReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_VOUCHER_ANY, config.MANAGE_VOUCHER_ORG}, nil); err != nil {
+		return err
+	}
+
+	batch, err := h.voucherUsecase.GenerateVouchers(claims.ID, *claims.BusinessID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(util.ToSuccessResponse(batch))
+}
+
+// @Tags Vouchers
+// @Summary Update voucher
+// @Description Update the terms of a voucher. The code and discount can't be changed once issued
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Voucher ID"
+// @Param request body contract.UpdateVoucherReq true "Update voucher request"
+// @Success 200 {object} util.BaseResponse{data=contract.VoucherRes}
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers/{id} [patch]
+func (h *VoucherHandler) UpdateVoucher(c *fiber.Ctx) error {
+	voucherID := c.Params("id")
+	if voucherID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Voucher ID is required")
+	}
+
+	var req contract.UpdateVoucherReq
+	if err := c.BodyParser(&req); err != nil {
+		logger.Log.Warn("Failed to parse request body", zap.Error(err))
+		return err
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_VOUCHER_ANY, config.MANAGE_VOUCHER_ORG}, &voucherID); err != nil {
+		return err
+	}
+
+	voucher, err := h.voucherUsecase.UpdateVoucher(voucherID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(voucher))
+}
+
+// @Tags Vouchers
+// @Summary Get voucher
+// @Description Get voucher details by ID
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Voucher ID"
+// @Success 200 {object} util.BaseResponse{data=contract.VoucherRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers/{id} [get]
+func (h *VoucherHandler) GetVoucher(c *fiber.Ctx) error {
+	voucherID := c.Params("id")
+	if voucherID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Voucher ID is required")
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_VOUCHER_ANY, config.READ_VOUCHER_ORG}, &voucherID); err != nil {
+		return err
+	}
+
+	voucher, err := h.voucherUsecase.GetVoucherByID(voucherID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(voucher))
+}
+
+// @Tags Vouchers
+// @Summary Get voucher by code
+// @Description Look up a voucher of the authenticated user's business by its code, e.g. to check it at the till
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Voucher code"
+// @Success 200 {object} util.BaseResponse{data=contract.VoucherRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 404 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers/code/{code} [get]
+func (h *VoucherHandler) GetVoucherByCode(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_VOUCHER_ANY, config.READ_VOUCHER_ORG}, nil); err != nil {
+		return err
+	}
+
+	voucher, err := h.voucherUsecase.GetVoucherByCode(*claims.BusinessID, c.Params("code"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToSuccessResponse(voucher))
+}
+
+// @Tags Vouchers
+// @Summary List vouchers
+// @Description List vouchers of the authenticated user's business, newest first
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Param search query string false "Part of the code"
+// @Param batchId query string false "Only vouchers of this batch"
+// @Success 200 {object} util.PaginatedResponse{data=[]contract.VoucherRes}
+// @Failure 401 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers [get]
+func (h *VoucherHandler) ListVouchers(c *fiber.Ctx) error {
+	claims := middleware.GetAuthClaims(c)
+
+	queries, err := util.ParsePaginationQueries(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.READ_VOUCHER_ANY, config.READ_VOUCHER_ORG}, nil); err != nil {
+		return err
+	}
+
+	vouchers, total, err := h.voucherUsecase.ListVouchers(*claims.BusinessID, queries.Page, queries.PageSize, queries.Search, c.Query("batchId"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(util.ToPaginatedResponse(vouchers, queries.Page, queries.PageSize, total))
+}
+
+// @Tags Vouchers
+// @Summary Export vouchers
+// @Description Download the vouchers of the authenticated user's business as CSV, e.g. to print or mail the codes of a batch
+// @Produce text/csv
+// @Security BearerAuth
+// @Param batchId query string false "Only vouchers of this batch"
+// @Success 200 {file} file
+// @Failure 400 {object} util.BaseResponse
+// @Failure 401 {object} util.BaseResponse
+// @Failure 403 {object} util.BaseResponse
+// @Failure 500 {object} util.BaseResponse
+// @Router /vouchers/export [get]
+func (h *VoucherHandler) ExportVouchers(c *fiber.Ctx) error {
+	var query contract.ExportCodesQuery
+	if err := c.QueryParser(&query); err != nil {
+		logger.Log.Warn("Failed to parse query", zap.Error(err))
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateStruct(&query); err != nil {
+		logger.Log.Warn("Validation error", zap.Error(err))
+		return err
+	}
+
+	claims := middleware.GetAuthClaims(c)
+
+	if err := h.voucherUsecase.IsAllowedToAccess(claims, []config.Permission{config.MANAGE_VOUCHER_ANY, config.MANAGE_VOUCHER_ORG}, nil); err != nil {
+		return err
+	}
+
+	document, fileName, err := h.voucherUsecase.ExportVouchers(*claims.BusinessID, &query)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	return c.Status(fiber.StatusOK).Send(document)
+}
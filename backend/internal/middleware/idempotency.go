@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"app/internal/config"
+	"app/internal/model"
+	"app/pkg/logger"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Idempotency replays the first response of a request sent again with the same Idempotency-Key header.
+// Keys are scoped per business and only bind to the exact request they were first used with.
+// Requests without the header pass through untouched. Must run after AuthGuard.
+func Idempotency(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(config.IDEMPOTENCY_KEY_HEADER)
+		claims := GetAuthClaims(c)
+		if key == "" || claims.BusinessID == nil {
+			return c.Next()
+		}
+
+		if len(key) > 255 {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency key must be at most 255 characters")
+		}
+
+		requestHash := hashRequest(c)
+		record, claimed, err := claimIdempotencyKey(db, *claims.BusinessID, key, requestHash)
+		if err != nil {
+			logger.Log.Error("Failed to claim idempotency key", zap.Error(err))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to process request")
+		}
+
+		if !claimed {
+			return replayIdempotentResponse(c, record, requestHash)
+		}
+
+		// Failed requests release the key so the client can retry with it
+		if err := c.Next(); err != nil {
+			releaseIdempotencyKey(db, record)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			releaseIdempotencyKey(db, record)
+			return nil
+		}
+
+		now := time.Now()
+		body := append([]byte(nil), c.Response().Body()...)
+		if err := db.Model(record).Updates(map[string]any{
+			"response_status": status,
+			"response_body":   body,
+			"completed_at":    now,
+		}).Error; err != nil {
+			logger.Log.Error("Failed to store idempotent response", zap.Error(err), zap.String("key", key))
+		}
+
+		return nil
+	}
+}
+
+// claimIdempotencyKey inserts the key for this request. When the key is taken it returns the existing record instead.
+// Expired keys and keys left unfinished past the lock timeout are taken over.
+func claimIdempotencyKey(db *gorm.DB, businessID, key, requestHash string) (*model.IdempotencyKey, bool, error) {
+	now := time.Now()
+	record := &model.IdempotencyKey{
+		BusinessID:  businessID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(config.IDEMPOTENCY_KEY_TTL),
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing model.IdempotencyKey
+	if err := db.Where("business_id = ? AND key = ?", businessID, key).First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	stale := existing.ExpiresAt.Before(now) ||
+		(existing.CompletedAt == nil && existing.CreatedAt.Add(config.IDEMPOTENCY_KEY_LOCK_TIMEOUT).Before(now))
+	if !stale {
+		return &existing, false, nil
+	}
+
+	// Only one request can win the takeover, the others see the fresh record on their next try
+	takeover := db.Model(&model.IdempotencyKey{}).
+		Where("id = ? AND created_at = ?", existing.ID, existing.CreatedAt).
+		Updates(map[string]any{
+			"request_hash":    requestHash,
+			"response_status": nil,
+			"response_body":   nil,
+			"completed_at":    nil,
+			"expires_at":      record.ExpiresAt,
+			"created_at":      now,
+		})
+	if takeover.Error != nil {
+		return nil, false, takeover.Error
+	}
+	if takeover.RowsAffected == 0 {
+		return &existing, false, nil
+	}
+
+	existing.RequestHash = requestHash
+	existing.ResponseStatus = nil
+	existing.ResponseBody = nil
+	existing.CompletedAt = nil
+	existing.ExpiresAt = record.ExpiresAt
+	existing.CreatedAt = now
+	return &existing, true, nil
+}
+
+func replayIdempotentResponse(c *fiber.Ctx, record *model.IdempotencyKey, requestHash string) error {
+	if record.RequestHash != requestHash {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Idempotency key was already used for a different request")
+	}
+
+	if record.CompletedAt == nil || record.ResponseStatus == nil {
+		return fiber.NewError(fiber.StatusConflict, "A request with this idempotency key is still being processed")
+	}
+
+	c.Set("Idempotent-Replayed", "true")
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(*record.ResponseStatus).Send(record.ResponseBody)
+}
+
+func releaseIdempotencyKey(db *gorm.DB, record *model.IdempotencyKey) {
+	if err := db.Where("id = ? AND completed_at IS NULL", record.ID).Delete(&model.IdempotencyKey{}).Error; err != nil {
+		logger.Log.Error("Failed to release idempotency key", zap.Error(err), zap.String("key", record.Key))
+	}
+}
+
+// hashRequest fingerprints the route and body the key was used with
+func hashRequest(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
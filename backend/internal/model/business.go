@@ -1,15 +1,58 @@
 package model
 
-import "time"
+import (
+	"app/internal/config"
+	"time"
+)
 
 type Business struct {
-	ID           string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	Name         string    `gorm:"type:text;not null" json:"name"`
-	Code         string    `gorm:"type:text;not null" json:"code"`
-	Address      *string   `gorm:"type:text" json:"address,omitempty"`
-	Logo         *string   `gorm:"type:text" json:"logo,omitempty"`
-	EmployeeSize *string   `gorm:"type:varchar(32)" json:"employee_size,omitempty"`
-	Category     *string   `gorm:"type:varchar(32)" json:"category,omitempty"`
-	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"not null;default:now()" json:"updated_at"`
+	ID           string  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name         string  `gorm:"type:text;not null" json:"name"`
+	Code         string  `gorm:"type:text;not null" json:"code"`
+	Address      *string `gorm:"type:text" json:"address,omitempty"`
+	Logo         *string `gorm:"type:text" json:"logo,omitempty"`
+	EmployeeSize *string `gorm:"type:varchar(32)" json:"employee_size,omitempty"`
+	Category     *string `gorm:"type:varchar(32)" json:"category,omitempty"`
+
+	// QRIS merchant data
+	QrisNmid             *string `gorm:"type:varchar(32)" json:"qris_nmid,omitempty"`
+	QrisMerchantCriteria *string `gorm:"type:varchar(3)" json:"qris_merchant_criteria,omitempty"`
+	QrisMcc              *string `gorm:"type:varchar(4)" json:"qris_mcc,omitempty"`
+	QrisMerchantCity     *string `gorm:"type:varchar(15)" json:"qris_merchant_city,omitempty"`
+	QrisPostalCode       *string `gorm:"type:varchar(10)" json:"qris_postal_code,omitempty"`
+	QrisAcquirerDomain   *string `gorm:"type:varchar(64)" json:"qris_acquirer_domain,omitempty"`
+	QrisMerchantPan      *string `gorm:"type:varchar(19)" json:"qris_merchant_pan,omitempty"`
+	QrisMerchantID       *string `gorm:"type:varchar(32)" json:"qris_merchant_id,omitempty"`
+
+	// Invoice number format
+	InvoicePrefix         string                    `gorm:"type:varchar(10);not null;default:'INV'" json:"invoice_prefix"`
+	InvoiceSeparator      string                    `gorm:"type:varchar(1);not null;default:'-'" json:"invoice_separator"`
+	InvoiceDateFormat     string                    `gorm:"type:varchar(8);not null;default:'YYMMDD'" json:"invoice_date_format"`
+	InvoiceCounterPadding int                       `gorm:"type:smallint;not null;default:4" json:"invoice_counter_padding"`
+	InvoiceResetPeriod    config.InvoiceResetPeriod `gorm:"type:varchar(16);not null;default:'daily'" json:"invoice_reset_period"`
+
+	// Largest discount per role without a manager or owner PIN, in percent
+	CashierMaxDiscountPercent float64 `gorm:"type:numeric(5,2);not null;default:10" json:"cashier_max_discount_percent"`
+	ManagerMaxDiscountPercent float64 `gorm:"type:numeric(5,2);not null;default:50" json:"manager_max_discount_percent"`
+
+	// Rounding of the cash part of a bill
+	CashRoundingMode config.CashRoundingMode `gorm:"type:varchar(16);not null;default:'none'" json:"cash_rounding_mode"`
+	CashRoundingUnit int                     `gorm:"not null;default:100" json:"cash_rounding_unit"`
+
+	// Free text printed above the items and below the totals of a receipt
+	ReceiptHeader *string `gorm:"type:text" json:"receipt_header,omitempty"`
+	ReceiptFooter *string `gorm:"type:text" json:"receipt_footer,omitempty"`
+
+	// Message sent to customers with an outstanding credit balance, nil uses the default
+	CreditReminderTemplate *string `gorm:"type:text" json:"credit_reminder_template,omitempty"`
+
+	// Loyalty rules, a point is earned per spend per point rupiah and is worth point value rupiah when redeemed
+	LoyaltyEnabled          bool    `gorm:"not null;default:false" json:"loyalty_enabled"`
+	LoyaltySpendPerPoint    float64 `gorm:"type:numeric(12,2);not null;default:10000" json:"loyalty_spend_per_point"`
+	LoyaltyPointValue       float64 `gorm:"type:numeric(12,2);not null;default:100" json:"loyalty_point_value"`
+	LoyaltyMinRedeemPoints  int     `gorm:"not null;default:0" json:"loyalty_min_redeem_points"`
+	LoyaltyPointsExpiryDays *int    `json:"loyalty_points_expiry_days,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
 }
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type CashMovement struct {
+	ID            string                  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	CashSessionID string                  `gorm:"type:uuid;not null;index:idx_cash_movements_cash_session_id" json:"cash_session_id"`
+	Type          config.CashMovementType `gorm:"type:cash_movement_type;not null" json:"type"`
+	Amount        float64                 `gorm:"type:numeric(12,2);not null;check:amount > 0" json:"amount"`
+	Reason        string                  `gorm:"type:text;not null" json:"reason"`
+	CreatedBy     string                  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt     time.Time               `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time               `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	CashSession CashSession `gorm:"foreignKey:CashSessionID;constraint:OnDelete:CASCADE" json:"-"`
+	Creator     User        `gorm:"foreignKey:CreatedBy" json:"-"`
+}
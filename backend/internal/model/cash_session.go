@@ -0,0 +1,61 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type CashSession struct {
+	ID           string                   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID   string                   `gorm:"type:uuid;not null;index:idx_cash_sessions_business_id" json:"business_id"`
+	OpenedBy     string                   `gorm:"type:uuid;not null" json:"opened_by"`
+	ClosedBy     *string                  `gorm:"type:uuid" json:"closed_by,omitempty"`
+	Status       config.CashSessionStatus `gorm:"type:cash_session_status;not null;default:'open'" json:"status"`
+	OpeningFloat float64                  `gorm:"type:numeric(12,2);not null;default:0" json:"opening_float"`
+	ExpectedCash *float64                 `gorm:"type:numeric(12,2)" json:"expected_cash,omitempty"`
+	CountedCash  *float64                 `gorm:"type:numeric(12,2)" json:"counted_cash,omitempty"`
+	Variance     *float64                 `gorm:"type:numeric(12,2)" json:"variance,omitempty"`
+	OpeningNote  *string                  `gorm:"type:text" json:"opening_note,omitempty"`
+	ClosingNote  *string                  `gorm:"type:text" json:"closing_note,omitempty"`
+	ZReport      *ZReport                 `gorm:"type:jsonb;serializer:json" json:"z_report,omitempty"`
+	OpenedAt     time.Time                `gorm:"not null;default:now()" json:"opened_at"`
+	ClosedAt     *time.Time               `gorm:"type:timestamp" json:"closed_at,omitempty"`
+	CreatedAt    time.Time                `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time                `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business  Business       `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Opener    User           `gorm:"foreignKey:OpenedBy" json:"-"`
+	Closer    *User          `gorm:"foreignKey:ClosedBy" json:"-"`
+	Movements []CashMovement `gorm:"foreignKey:CashSessionID;constraint:OnDelete:CASCADE" json:"movements,omitempty"`
+}
+
+// ZReport is the end of shift summary, kept as it was when the session was closed
+type ZReport struct {
+	Transactions int64            `json:"transactions"`
+	GrossSales   float64          `json:"grossSales"`
+	Discounts    float64          `json:"discounts"`
+	Taxes        float64          `json:"taxes"`
+	Rounding     float64          `json:"rounding"`
+	NetSales     float64          `json:"netSales"`
+	Payments     []ZReportPayment `json:"payments"`
+	ChangeGiven  float64          `json:"changeGiven"`
+	Refunds      float64          `json:"refunds"`
+	CashRefunds  float64          `json:"cashRefunds"`
+	OpeningFloat float64          `json:"openingFloat"`
+	CashIn       float64          `json:"cashIn"`
+	CashOut      float64          `json:"cashOut"`
+	// Repayments of earlier credit sales, the cash ones are in the drawer
+	CreditRepayments     float64  `json:"creditRepayments"`
+	CashCreditRepayments float64  `json:"cashCreditRepayments"`
+	ExpectedCash         float64  `json:"expectedCash"`
+	CountedCash          *float64 `json:"countedCash"`
+	Variance             *float64 `json:"variance"`
+}
+
+// ZReportPayment is the total taken with one payment method
+type ZReportPayment struct {
+	Method config.PaymentMethod `json:"method"`
+	Count  int64                `json:"count"`
+	Amount float64              `json:"amount"`
+}
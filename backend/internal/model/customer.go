@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type Customer struct {
+	ID         string   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID string   `gorm:"type:uuid;not null;index:idx_customers_business_id" json:"business_id"`
+	Name       string   `gorm:"type:varchar(255);not null" json:"name"`
+	Phone      *string  `gorm:"type:varchar(15)" json:"phone,omitempty"`
+	Email      *string  `gorm:"type:varchar(255)" json:"email,omitempty"`
+	Notes      *string  `gorm:"type:text" json:"notes,omitempty"`
+	Tags       []string `gorm:"type:jsonb;not null;default:'[]';serializer:json" json:"tags"`
+	// Most the customer may owe on credit sales at once, 0 means no credit
+	CreditLimit float64 `gorm:"type:numeric(12,2);not null;default:0" json:"credit_limit"`
+	// Loyalty points the customer can redeem, kept in step with the points ledger
+	PointsBalance int       `gorm:"not null;default:0" json:"points_balance"`
+	CreatedAt     time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business Business `gorm:"foreignKey:BusinessID" json:"-"`
+}
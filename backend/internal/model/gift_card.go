@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type GiftCard struct {
+	ID             string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID     string     `gorm:"type:uuid;not null;uniqueIndex:idx_gift_cards_business_id_code" json:"business_id"`
+	Code           string     `gorm:"type:varchar(32);not null;uniqueIndex:idx_gift_cards_business_id_code" json:"code"`
+	BatchID        *string    `gorm:"type:uuid;index:idx_gift_cards_batch_id" json:"batch_id,omitempty"`
+	InitialBalance float64    `gorm:"type:numeric(12,2);not null" json:"initial_balance"`
+	Balance        float64    `gorm:"type:numeric(12,2);not null;check:balance >= 0" json:"balance"`
+	ExpiresAt      *time.Time `gorm:"type:timestamp" json:"expires_at,omitempty"`
+	IsActive       bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedBy      *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business Business `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Creator  *User    `gorm:"foreignKey:CreatedBy" json:"-"`
+}
+
+// IsUsableAt reports whether the balance of the card can be spent at the time
+func (g GiftCard) IsUsableAt(now time.Time) bool {
+	return g.IsActive && (g.ExpiresAt == nil || now.Before(*g.ExpiresAt))
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type GiftCardEntry struct {
+	ID            string                   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	GiftCardID    string                   `gorm:"type:uuid;not null;index:idx_gift_card_entries_gift_card_id" json:"gift_card_id"`
+	TransactionID *string                  `gorm:"type:uuid;index:idx_gift_card_entries_transaction_id" json:"transaction_id,omitempty"`
+	RefundID      *string                  `gorm:"type:uuid" json:"refund_id,omitempty"`
+	Type          config.GiftCardEntryType `gorm:"type:gift_card_entry_type;not null" json:"type"`
+	Amount        float64                  `gorm:"type:numeric(12,2);not null" json:"amount"`
+	BalanceAfter  float64                  `gorm:"type:numeric(12,2);not null" json:"balance_after"`
+	CreatedBy     *string                  `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt     time.Time                `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relations
+	GiftCard    GiftCard     `gorm:"foreignKey:GiftCardID;constraint:OnDelete:CASCADE" json:"-"`
+	Transaction *Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:SET NULL" json:"-"`
+	Creator     *User        `gorm:"foreignKey:CreatedBy" json:"-"`
+}
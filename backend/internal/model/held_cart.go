@@ -0,0 +1,43 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type HeldCart struct {
+	ID          string            `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID  string            `gorm:"type:uuid;not null;index:idx_held_carts_business_id" json:"business_id"`
+	Name        string            `gorm:"type:varchar(64);not null" json:"name"`
+	Note        *string           `gorm:"type:text" json:"note,omitempty"`
+	Items       []HeldCartItem    `gorm:"type:jsonb;not null;serializer:json" json:"items"`
+	Discount    *HeldCartDiscount `gorm:"type:jsonb;serializer:json" json:"discount,omitempty"`
+	CustomerID  *string           `gorm:"type:uuid" json:"customer_id,omitempty"`
+	VoucherCode *string           `gorm:"type:varchar(32)" json:"voucher_code,omitempty"`
+	CreatedBy   string            `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time         `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time         `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business Business  `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Creator  User      `gorm:"foreignKey:CreatedBy" json:"-"`
+	Customer *Customer `gorm:"foreignKey:CustomerID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+// HeldCartItem is a cart line, name and price are what the cashier saw when parking it
+type HeldCartItem struct {
+	ProductID    string                    `json:"productId"`
+	ProductName  string                    `json:"productName"`
+	VariantID    *string                   `json:"variantId,omitempty"`
+	VariantLabel *string                   `json:"variantLabel,omitempty"`
+	Modifiers    []TransactionItemModifier `json:"modifiers,omitempty"`
+	Price        float64                   `json:"price"`
+	Quantity     int                       `json:"quantity"`
+	Discount     *HeldCartDiscount         `json:"discount,omitempty"`
+}
+
+type HeldCartDiscount struct {
+	Type   config.DiscountType `json:"type"`
+	Value  float64             `json:"value"`
+	Reason string              `json:"reason"`
+}
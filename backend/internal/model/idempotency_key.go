@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	ID             string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID     string     `gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_keys_business_id_key" json:"business_id"`
+	Key            string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_business_id_key" json:"key"`
+	RequestHash    string     `gorm:"type:varchar(64);not null" json:"request_hash"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	ResponseBody   []byte     `gorm:"type:bytea" json:"-"`
+	CompletedAt    *time.Time `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	ExpiresAt      time.Time  `gorm:"type:timestamptz;not null" json:"expires_at"`
+	CreatedAt      time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type Ingredient struct {
+	ID         string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID string `gorm:"type:uuid;not null;index:idx_ingredients_business_id" json:"business_id"`
+	Name       string `gorm:"type:varchar(64);not null" json:"name"`
+	Unit       string `gorm:"type:varchar(16);not null" json:"unit"`
+	// Cost of one unit, e.g. per gram
+	Cost float64 `gorm:"type:numeric(12,4);not null;default:0;check:cost >= 0" json:"cost"`
+	// Can go below zero, a paid sale is never blocked on ingredients
+	StockQty  float64   `gorm:"type:numeric(14,3);not null;default:0" json:"stock_qty"`
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business Business `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+}
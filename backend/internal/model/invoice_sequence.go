@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type InvoiceSequence struct {
+	BusinessID string    `gorm:"type:uuid;primaryKey" json:"business_id"`
+	PeriodKey  string    `gorm:"type:varchar(16);primaryKey" json:"period_key"`
+	LastValue  int64     `gorm:"not null;default:0" json:"last_value"`
+	UpdatedAt  time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type LoyaltyPointEntry struct {
+	ID              string                  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID      string                  `gorm:"type:uuid;not null" json:"business_id"`
+	CustomerID      string                  `gorm:"type:uuid;not null;index:idx_loyalty_point_entries_customer_id" json:"customer_id"`
+	TransactionID   *string                 `gorm:"type:uuid;index:idx_loyalty_point_entries_transaction_id" json:"transaction_id,omitempty"`
+	RefundID        *string                 `gorm:"type:uuid" json:"refund_id,omitempty"`
+	Type            config.LoyaltyEntryType `gorm:"type:loyalty_entry_type;not null" json:"type"`
+	Points          int                     `gorm:"not null" json:"points"`
+	BalanceAfter    int                     `gorm:"not null" json:"balance_after"`
+	RemainingPoints int                     `gorm:"not null;default:0" json:"remaining_points"`
+	ExpiresAt       *time.Time              `gorm:"type:timestamp" json:"expires_at,omitempty"`
+	Note            *string                 `gorm:"type:text" json:"note,omitempty"`
+	CreatedBy       *string                 `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt       time.Time               `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relations
+	Business    Business     `gorm:"foreignKey:BusinessID" json:"-"`
+	Customer    Customer     `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"-"`
+	Transaction *Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:SET NULL" json:"-"`
+	Creator     *User        `gorm:"foreignKey:CreatedBy" json:"-"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type Modifier struct {
+	ID              string  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID      string  `gorm:"type:uuid;not null" json:"business_id"`
+	ModifierGroupID string  `gorm:"type:uuid;not null;index:idx_modifiers_modifier_group_id" json:"modifier_group_id"`
+	Name            string  `gorm:"type:varchar(64);not null" json:"name"`
+	Price           float64 `gorm:"type:numeric(12,2);not null;default:0;check:price >= 0" json:"price"`
+	// Stock of the modifier itself, e.g. oat milk portions
+	EnableStock bool      `gorm:"not null;default:false" json:"enable_stock"`
+	StockQty    *int      `gorm:"check:stock_qty >= 0" json:"stock_qty"`
+	IsActive    bool      `gorm:"not null;default:true" json:"is_active"`
+	SortOrder   int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business      Business      `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	ModifierGroup ModifierGroup `gorm:"foreignKey:ModifierGroupID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type ModifierGroup struct {
+	ID            string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID    string    `gorm:"type:uuid;not null;index:idx_modifier_groups_business_id" json:"business_id"`
+	Name          string    `gorm:"type:varchar(64);not null" json:"name"`
+	MinSelections int       `gorm:"not null;default:0;check:min_selections >= 0" json:"min_selections"`
+	MaxSelections int       `gorm:"not null;default:1;check:max_selections >= 1" json:"max_selections"`
+	IsActive      bool      `gorm:"not null;default:true" json:"is_active"`
+	SortOrder     int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt     time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business   Business   `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Modifiers  []Modifier `gorm:"foreignKey:ModifierGroupID;constraint:OnDelete:CASCADE" json:"modifiers,omitempty"`
+	Categories []Category `gorm:"many2many:modifier_group_categories" json:"categories,omitempty"`
+	Products   []Product  `gorm:"many2many:modifier_group_products" json:"products,omitempty"`
+}
+
+// AppliesTo reports whether the group is offered on the product
+func (g ModifierGroup) AppliesTo(product Product) bool {
+	for _, p := range g.Products {
+		if p.ID == product.ID {
+			return true
+		}
+	}
+
+	if product.CategoryID != nil {
+		for _, c := range g.Categories {
+			if c.ID == *product.CategoryID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type PaymentCharge struct {
+	ID                  string                     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID          string                     `gorm:"type:uuid;not null" json:"business_id"`
+	TransactionID       string                     `gorm:"type:uuid;not null;index:idx_payment_charges_transaction_id" json:"transaction_id"`
+	Provider            string                     `gorm:"type:varchar(32);not null" json:"provider"`
+	Reference           string                     `gorm:"type:varchar(255);not null" json:"reference"`
+	Amount              float64                    `gorm:"type:numeric(12,2);not null;check:amount > 0" json:"amount"`
+	Status              config.PaymentChargeStatus `gorm:"type:payment_charge_status;not null;default:'pending'" json:"status"`
+	Method              *config.PaymentMethod      `gorm:"type:payment_method" json:"method,omitempty"`
+	CheckoutURL         *string                    `gorm:"type:text" json:"checkout_url,omitempty"`
+	QrString            *string                    `gorm:"type:text" json:"qr_string,omitempty"`
+	PaidAmount          *float64                   `gorm:"type:numeric(12,2)" json:"paid_amount,omitempty"`
+	PaidAt              *time.Time                 `gorm:"type:timestamptz" json:"paid_at,omitempty"`
+	ExpiresAt           *time.Time                 `gorm:"type:timestamptz" json:"expires_at,omitempty"`
+	NeedsReconciliation bool                       `gorm:"not null;default:false" json:"needs_reconciliation"`
+	ReconciliationNote  *string                    `gorm:"type:text" json:"reconciliation_note,omitempty"`
+	WebhookPayload      *string                    `gorm:"type:jsonb" json:"-"`
+	CreatedAt           time.Time                  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt           time.Time                  `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business    Business    `gorm:"foreignKey:BusinessID" json:"-"`
+	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -20,10 +20,17 @@ type Product struct {
 	Cost          *float64            `gorm:"type:numeric(12,2);not null;check:cost >= 0" json:"cost"`
 	BarcodeValue  *string             `gorm:"type:varchar(36)" json:"barcode_value,omitempty"`
 	BarcodeType   *config.BarcodeType `gorm:"type:barcode_type" json:"barcode_type,omitempty"`
-	CreatedAt     time.Time           `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt     time.Time           `gorm:"not null;default:now()" json:"updated_at"`
+	// Sold, priced and stocked per variant instead of on the product itself
+	HasVariants bool `gorm:"not null;default:false" json:"has_variants"`
+	// Made from ingredients, the cost is worked out from the recipe
+	HasRecipe bool      `gorm:"not null;default:false" json:"has_recipe"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
 
 	// Relations
-	Business Business  `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
-	Category *Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"-"`
+	Business     Business             `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Category     *Category            `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"-"`
+	OptionGroups []ProductOptionGroup `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"option_groups,omitempty"`
+	Variants     []ProductVariant     `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"variants,omitempty"`
+	RecipeItems  []ProductRecipeItem  `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"recipe_items,omitempty"`
 }
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type ProductOptionGroup struct {
+	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProductID string    `gorm:"type:uuid;not null;uniqueIndex:idx_product_option_groups_product_id_name" json:"product_id"`
+	Name      string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_product_option_groups_product_id_name" json:"name"`
+	Values    []string  `gorm:"column:option_values;type:jsonb;not null;default:'[]';serializer:json" json:"values"`
+	SortOrder int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Product Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ProductRecipeItem is an ingredient used for one unit of a product, e.g. 18 g of coffee beans
+type ProductRecipeItem struct {
+	ID           string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProductID    string    `gorm:"type:uuid;not null" json:"product_id"`
+	IngredientID string    `gorm:"type:uuid;not null;index:idx_product_recipe_items_ingredient_id" json:"ingredient_id"`
+	Quantity     float64   `gorm:"type:numeric(12,3);not null;check:quantity > 0" json:"quantity"`
+	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Product    Product    `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+	Ingredient Ingredient `gorm:"foreignKey:IngredientID;constraint:OnDelete:RESTRICT" json:"ingredient"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"app/internal/config"
+	"strings"
+	"time"
+)
+
+type ProductVariant struct {
+	ID         string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID string `gorm:"type:uuid;not null" json:"business_id"`
+	ProductID  string `gorm:"type:uuid;not null;index:idx_product_variants_product_id" json:"product_id"`
+	// One value per option group of the product, in group order
+	Options      []string            `gorm:"type:jsonb;not null;default:'[]';serializer:json" json:"options"`
+	Label        string              `gorm:"type:varchar(255);not null" json:"label"`
+	SKU          *string             `gorm:"column:sku;type:varchar(64)" json:"sku,omitempty"`
+	Price        float64             `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
+	Cost         *float64            `gorm:"type:numeric(12,2);check:cost >= 0" json:"cost,omitempty"`
+	StockQty     *int                `gorm:"check:stock_qty >= 0" json:"stock_qty"`
+	BarcodeValue *string             `gorm:"type:varchar(36)" json:"barcode_value,omitempty"`
+	BarcodeType  *config.BarcodeType `gorm:"type:barcode_type" json:"barcode_type,omitempty"`
+	IsActive     bool                `gorm:"not null;default:true" json:"is_active"`
+	SortOrder    int                 `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt    time.Time           `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time           `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business Business `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Product  Product  `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// VariantLabel joins option values for display, e.g. "M / Red"
+func VariantLabel(options []string) string {
+	return strings.Join(options, " / ")
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type Promotion struct {
+	ID         string               `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID string               `gorm:"type:uuid;not null;index:idx_promotions_business_id" json:"business_id"`
+	Name       string               `gorm:"type:varchar(64);not null" json:"name"`
+	Type       config.PromotionType `gorm:"type:promotion_type;not null" json:"type"`
+	// Percent off for percentage and buy X get Y, amount off for amount, price of BuyQuantity units for bundle price
+	Value       float64 `gorm:"type:numeric(12,2);not null;check:value > 0" json:"value"`
+	BuyQuantity *int    `json:"buy_quantity,omitempty"`
+	GetQuantity *int    `json:"get_quantity,omitempty"`
+	// Smallest cart amount before promotions the promotion needs
+	MinSpend float64 `gorm:"type:numeric(12,2);not null;default:0" json:"min_spend"`
+
+	// Schedule, dates bound the whole promotion and daily times (HH:MM) limit it to part of each day
+	StartsAt       *time.Time `gorm:"type:timestamp" json:"starts_at,omitempty"`
+	EndsAt         *time.Time `gorm:"type:timestamp" json:"ends_at,omitempty"`
+	DailyStartTime *string    `gorm:"type:varchar(5)" json:"daily_start_time,omitempty"`
+	DailyEndTime   *string    `gorm:"type:varchar(5)" json:"daily_end_time,omitempty"`
+
+	// Exclusive promotions only take lines no other promotion has touched and keep later promotions off them
+	Stacking     config.PromotionStacking `gorm:"type:promotion_stacking;not null;default:'stackable'" json:"stacking"`
+	AppliesToAll bool                     `gorm:"not null;default:true" json:"applies_to_all"`
+	IsActive     bool                     `gorm:"not null;default:true" json:"is_active"`
+	SortOrder    int                      `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt    time.Time                `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time                `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations, only used when the promotion does not apply to all products
+	Business   Business   `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Categories []Category `gorm:"many2many:promotion_categories" json:"categories,omitempty"`
+	Products   []Product  `gorm:"many2many:promotion_products" json:"products,omitempty"`
+}
+
+// AppliesTo reports whether the product is eligible for the promotion
+func (p Promotion) AppliesTo(product Product) bool {
+	if p.AppliesToAll {
+		return true
+	}
+
+	for _, pr := range p.Products {
+		if pr.ID == product.ID {
+			return true
+		}
+	}
+
+	if product.CategoryID != nil {
+		for _, c := range p.Categories {
+			if c.ID == *product.CategoryID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsRunningAt reports whether the promotion is scheduled at the time.
+// A daily window that ends before it starts runs past midnight, e.g. 22:00 to 02:00.
+func (p Promotion) IsRunningAt(now time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+
+	if p.DailyStartTime == nil || p.DailyEndTime == nil {
+		return true
+	}
+
+	clock := now.Format("15:04")
+	start, end := *p.DailyStartTime, *p.DailyEndTime
+	if start <= end {
+		return clock >= start && clock < end
+	}
+	return clock >= start || clock < end
+}
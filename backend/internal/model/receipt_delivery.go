@@ -0,0 +1,27 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type ReceiptDelivery struct {
+	ID            string                       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID    string                       `gorm:"type:uuid;not null" json:"business_id"`
+	TransactionID string                       `gorm:"type:uuid;not null;index:idx_receipt_deliveries_transaction_id" json:"transaction_id"`
+	Channel       config.ReceiptChannel        `gorm:"type:receipt_channel;not null" json:"channel"`
+	Recipient     string                       `gorm:"type:varchar(255);not null" json:"recipient"`
+	Status        config.ReceiptDeliveryStatus `gorm:"type:receipt_delivery_status;not null;default:'pending'" json:"status"`
+	Provider      string                       `gorm:"type:varchar(32);not null" json:"provider"`
+	Reference     *string                      `gorm:"type:varchar(255)" json:"reference,omitempty"`
+	Error         *string                      `gorm:"type:text" json:"error,omitempty"`
+	SentBy        string                       `gorm:"type:uuid;not null" json:"sent_by"`
+	SentAt        *time.Time                   `gorm:"type:timestamp" json:"sent_at,omitempty"`
+	CreatedAt     time.Time                    `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time                    `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business    Business    `gorm:"foreignKey:BusinessID" json:"-"`
+	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+	Sender      User        `gorm:"foreignKey:SentBy" json:"-"`
+}
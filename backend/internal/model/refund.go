@@ -0,0 +1,32 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type Refund struct {
+	ID            string               `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID    string               `gorm:"type:uuid;not null" json:"business_id"`
+	TransactionID string               `gorm:"type:uuid;not null;index:idx_refunds_transaction_id" json:"transaction_id"`
+	CreatedBy     string               `gorm:"type:uuid;not null" json:"created_by"`
+	Method        config.PaymentMethod `gorm:"type:payment_method;not null" json:"method"`
+	Amount        float64              `gorm:"type:numeric(12,2);not null;check:amount >= 0" json:"amount"`
+	CreditAmount  float64              `gorm:"type:numeric(12,2);not null;default:0" json:"credit_amount"`
+	Reason        string               `gorm:"type:text;not null" json:"reason"`
+	Restock       bool                 `gorm:"not null;default:false" json:"restock"`
+	CashSessionID *string              `gorm:"type:uuid;index:idx_refunds_cash_session_id" json:"cash_session_id,omitempty"`
+	CreatedAt     time.Time            `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time            `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business    Business     `gorm:"foreignKey:BusinessID" json:"-"`
+	Transaction Transaction  `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+	Creator     User         `gorm:"foreignKey:CreatedBy" json:"-"`
+	Items       []RefundItem `gorm:"foreignKey:RefundID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// PaidOutAmount is what the refund actually gave back in the refund method
+func (r Refund) PaidOutAmount() float64 {
+	return r.Amount - r.CreditAmount
+}
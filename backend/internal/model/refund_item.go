@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+type RefundItem struct {
+	ID                string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	RefundID          string    `gorm:"type:uuid;not null;index:idx_refund_items_refund_id" json:"refund_id"`
+	TransactionItemID string    `gorm:"type:uuid;not null;index:idx_refund_items_transaction_item_id" json:"transaction_item_id"`
+	ProductID         *string   `gorm:"type:uuid" json:"product_id,omitempty"`
+	ProductName       string    `gorm:"type:varchar(255);not null" json:"product_name"`
+	VariantID         *string   `gorm:"type:uuid" json:"variant_id,omitempty"`
+	VariantLabel      *string   `gorm:"type:varchar(255)" json:"variant_label,omitempty"`
+	Price             float64   `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
+	Quantity          int       `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Subtotal          float64   `gorm:"type:numeric(12,2);not null;check:subtotal >= 0" json:"subtotal"`
+	CreatedAt         time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Refund          Refund          `gorm:"foreignKey:RefundID;constraint:OnDelete:CASCADE" json:"-"`
+	TransactionItem TransactionItem `gorm:"foreignKey:TransactionItemID;constraint:OnDelete:CASCADE" json:"-"`
+	Product         *Product        `gorm:"foreignKey:ProductID;constraint:OnDelete:SET NULL" json:"-"`
+	Variant         *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:SET NULL" json:"-"`
+}
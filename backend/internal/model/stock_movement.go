@@ -0,0 +1,31 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+// StockMovement is one line of the stock ledger of a product or one of its variants.
+// Rows are only ever added, the stock on hand is the balance after the latest one.
+// Modifier and ingredient stock is kept on their own rows and is not part of the ledger.
+type StockMovement struct {
+	ID            string                   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID    string                   `gorm:"type:uuid;not null" json:"business_id"`
+	ProductID     string                   `gorm:"type:uuid;not null;index:idx_stock_movements_product_id" json:"product_id"`
+	VariantID     *string                  `gorm:"type:uuid" json:"variant_id,omitempty"`
+	VariantLabel  *string                  `gorm:"type:varchar(255)" json:"variant_label,omitempty"`
+	Type          config.StockMovementType `gorm:"type:stock_movement_type;not null" json:"type"`
+	Quantity      int                      `gorm:"not null" json:"quantity"`
+	BalanceAfter  int                      `gorm:"not null" json:"balance_after"`
+	TransactionID *string                  `gorm:"type:uuid;index:idx_stock_movements_transaction_id" json:"transaction_id,omitempty"`
+	RefundID      *string                  `gorm:"type:uuid" json:"refund_id,omitempty"`
+	Reference     *string                  `gorm:"type:varchar(64)" json:"reference,omitempty"`
+	Reason        *string                  `gorm:"type:text" json:"reason,omitempty"`
+	CreatedBy     *string                  `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt     time.Time                `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relations
+	Product     Product      `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+	Transaction *Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:SET NULL" json:"-"`
+	Creator     *User        `gorm:"foreignKey:CreatedBy" json:"-"`
+}
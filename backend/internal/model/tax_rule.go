@@ -0,0 +1,48 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type TaxRule struct {
+	ID           string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID   string         `gorm:"type:uuid;not null;index:idx_tax_rules_business_id" json:"business_id"`
+	Name         string         `gorm:"type:varchar(64);not null" json:"name"`
+	Kind         config.TaxKind `gorm:"type:tax_kind;not null;default:'tax'" json:"kind"`
+	Rate         float64        `gorm:"type:numeric(5,2);not null;check:rate > 0 AND rate <= 100" json:"rate"`
+	IsInclusive  bool           `gorm:"not null;default:false" json:"is_inclusive"`
+	AppliesToAll bool           `gorm:"not null;default:true" json:"applies_to_all"`
+	IsActive     bool           `gorm:"not null;default:true" json:"is_active"`
+	SortOrder    int            `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt    time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations, only used when the rule does not apply to all products
+	Business   Business   `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Categories []Category `gorm:"many2many:tax_rule_categories" json:"categories,omitempty"`
+	Products   []Product  `gorm:"many2many:tax_rule_products" json:"products,omitempty"`
+}
+
+// AppliesTo reports whether the rule taxes the product
+func (r TaxRule) AppliesTo(product Product) bool {
+	if r.AppliesToAll {
+		return true
+	}
+
+	for _, p := range r.Products {
+		if p.ID == product.ID {
+			return true
+		}
+	}
+
+	if product.CategoryID != nil {
+		for _, c := range r.Categories {
+			if c.ID == *product.CategoryID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
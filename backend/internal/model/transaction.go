@@ -6,21 +6,64 @@ import (
 )
 
 type Transaction struct {
-	ID             string                   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	BusinessID     string                   `gorm:"type:uuid;not null;index:idx_transactions_business_id" json:"business_id"`
-	CreatedBy      string                   `gorm:"type:uuid;not null" json:"created_by"`
-	TotalAmount    float64                  `gorm:"type:numeric(12,2);not null;check:total_amount >= 0" json:"total_amount"`
-	ReceivedAmount float64                  `gorm:"type:numeric(12,2);not null;default:0;check:received_amount >= 0" json:"received_amount"`
-	ChangeAmount   float64                  `gorm:"type:numeric(12,2);not null;default:0;check:change_amount >= 0" json:"change_amount"`
-	Status         config.TransactionStatus `gorm:"type:transaction_status;not null;default:'pending'" json:"status"`
-	InvoiceNumber  string                   `gorm:"type:varchar(36)" json:"invoice_number,omitempty"`
-	PaidAt         *time.Time               `gorm:"type:timestamp" json:"paid_at,omitempty"`
-	ExpiredAt      time.Time                `gorm:"type:timestamp;not null" json:"expired_at"`
-	CreatedAt      time.Time                `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt      time.Time                `gorm:"not null;default:now()" json:"updated_at"`
+	ID               string                   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID       string                   `gorm:"type:uuid;not null;index:idx_transactions_business_id" json:"business_id"`
+	CreatedBy        string                   `gorm:"type:uuid;not null" json:"created_by"`
+	TotalAmount      float64                  `gorm:"type:numeric(12,2);not null;check:total_amount >= 0" json:"total_amount"`
+	ReceivedAmount   float64                  `gorm:"type:numeric(12,2);not null;default:0;check:received_amount >= 0" json:"received_amount"`
+	ChangeAmount     float64                  `gorm:"type:numeric(12,2);not null;default:0;check:change_amount >= 0" json:"change_amount"`
+	Status           config.TransactionStatus `gorm:"type:transaction_status;not null;default:'pending'" json:"status"`
+	InvoiceNumber    string                   `gorm:"type:varchar(36)" json:"invoice_number,omitempty"`
+	PaidAt           *time.Time               `gorm:"type:timestamp" json:"paid_at,omitempty"`
+	ExpiredAt        time.Time                `gorm:"type:timestamp;not null" json:"expired_at"`
+	CancelledAt      *time.Time               `gorm:"type:timestamp" json:"cancelled_at,omitempty"`
+	CancelledBy      *string                  `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CancelApprovedBy *string                  `gorm:"type:uuid" json:"cancel_approved_by,omitempty"`
+	CancelReason     *string                  `gorm:"type:text" json:"cancel_reason,omitempty"`
+	CashSessionID    *string                  `gorm:"type:uuid;index:idx_transactions_cash_session_id" json:"cash_session_id,omitempty"`
+	CustomerID       *string                  `gorm:"type:uuid;index:idx_transactions_customer_id" json:"customer_id,omitempty"`
+
+	// Discounts, total amount is what the customer pays
+	GrossAmount         float64              `gorm:"type:numeric(12,2);not null;default:0" json:"gross_amount"`
+	DiscountType        *config.DiscountType `gorm:"type:discount_type" json:"discount_type,omitempty"`
+	DiscountValue       *float64             `gorm:"type:numeric(12,2)" json:"discount_value,omitempty"`
+	DiscountReason      *string              `gorm:"type:text" json:"discount_reason,omitempty"`
+	OrderDiscountAmount float64              `gorm:"type:numeric(12,2);not null;default:0" json:"order_discount_amount"`
+	DiscountAmount      float64              `gorm:"type:numeric(12,2);not null;default:0" json:"discount_amount"`
+	NetAmount           float64              `gorm:"type:numeric(12,2);not null;default:0" json:"net_amount"`
+	DiscountApprovedBy  *string              `gorm:"type:uuid" json:"discount_approved_by,omitempty"`
+	// Part of the discount amount given by promotions
+	PromotionAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"promotion_amount"`
+
+	// Voucher code entered at checkout and the part of the discount amount it gave
+	VoucherID     *string `gorm:"type:uuid" json:"voucher_id,omitempty"`
+	VoucherCode   *string `gorm:"type:varchar(32)" json:"voucher_code,omitempty"`
+	VoucherAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"voucher_amount"`
+
+	// Taxes, exclusive ones are added to the total, inclusive ones are already in the prices
+	TaxAmount          float64 `gorm:"type:numeric(12,2);not null;default:0" json:"tax_amount"`
+	InclusiveTaxAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"inclusive_tax_amount"`
+
+	// Cash rounding, the customer pays total amount plus rounding amount
+	RoundingAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"rounding_amount"`
+
+	// Credit sale (kasbon), the part of the total the customer pays later and what is still owed
+	CreditAmount      float64 `gorm:"type:numeric(12,2);not null;default:0" json:"credit_amount"`
+	OutstandingAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"outstanding_amount"`
+
+	// Loyalty points earned on the sale and redeemed to pay for it
+	PointsEarned   int `gorm:"not null;default:0" json:"points_earned"`
+	PointsRedeemed int `gorm:"not null;default:0" json:"points_redeemed"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
 
 	// Relations
-	Business Business          `gorm:"foreignKey:BusinessID" json:"-"`
-	Creator  User              `gorm:"foreignKey:CreatedBy" json:"-"`
-	Items    []TransactionItem `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+	Business Business             `gorm:"foreignKey:BusinessID" json:"-"`
+	Creator  User                 `gorm:"foreignKey:CreatedBy" json:"-"`
+	Customer *Customer            `gorm:"foreignKey:CustomerID;constraint:OnDelete:SET NULL" json:"-"`
+	Items    []TransactionItem    `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+	Payments []TransactionPayment `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"payments,omitempty"`
+	Taxes    []TransactionTax     `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"taxes,omitempty"`
+	Refunds  []Refund             `gorm:"foreignKey:TransactionID" json:"refunds,omitempty"`
 }
@@ -1,19 +1,71 @@
 package model
 
-import "time"
+import (
+	"app/internal/config"
+	"time"
+)
 
 type TransactionItem struct {
-	ID            string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	TransactionID string    `gorm:"type:uuid;not null;index:idx_transaction_items_transaction_id" json:"transaction_id"`
-	ProductID     *string   `gorm:"type:uuid" json:"product_id,omitempty"`
-	ProductName   string    `gorm:"type:varchar(255);not null" json:"product_name"`
-	Price         float64   `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
-	Quantity      int       `gorm:"not null;check:quantity > 0" json:"quantity"`
-	Subtotal      float64   `gorm:"type:numeric(12,2);not null;check:subtotal >= 0" json:"subtotal"`
-	CreatedAt     time.Time `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt     time.Time `gorm:"not null;default:now()" json:"updated_at"`
+	ID            string  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TransactionID string  `gorm:"type:uuid;not null;index:idx_transaction_items_transaction_id" json:"transaction_id"`
+	ProductID     *string `gorm:"type:uuid" json:"product_id,omitempty"`
+	ProductName   string  `gorm:"type:varchar(255);not null" json:"product_name"`
+	VariantID     *string `gorm:"type:uuid;index:idx_transaction_items_variant_id" json:"variant_id,omitempty"`
+	VariantLabel  *string `gorm:"type:varchar(255)" json:"variant_label,omitempty"`
+	// Unit price including the price of the chosen modifiers
+	Price    float64 `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
+	Quantity int     `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Subtotal float64 `gorm:"type:numeric(12,2);not null;check:subtotal >= 0" json:"subtotal"`
+	// Modifiers chosen for each unit, copied so receipts keep them after the menu changes
+	Modifiers []TransactionItemModifier `gorm:"type:jsonb;not null;default:'[]';serializer:json" json:"modifiers"`
+	// Ingredients used for each unit, deducted when the sale is paid
+	Ingredients []TransactionItemIngredient `gorm:"type:jsonb;not null;default:'[]';serializer:json" json:"ingredients"`
+
+	// Discounts, subtotal is gross amount minus discount amount
+	GrossAmount         float64              `gorm:"type:numeric(12,2);not null;default:0" json:"gross_amount"`
+	DiscountType        *config.DiscountType `gorm:"type:discount_type" json:"discount_type,omitempty"`
+	DiscountValue       *float64             `gorm:"type:numeric(12,2)" json:"discount_value,omitempty"`
+	DiscountReason      *string              `gorm:"type:text" json:"discount_reason,omitempty"`
+	DiscountAmount      float64              `gorm:"type:numeric(12,2);not null;default:0" json:"discount_amount"`
+	OrderDiscountAmount float64              `gorm:"type:numeric(12,2);not null;default:0" json:"order_discount_amount"`
+	// Part of the discount amount given by promotions
+	PromotionAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"promotion_amount"`
+	// Share of the voucher discount, part of the discount amount
+	VoucherAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"voucher_amount"`
+
+	// Exclusive taxes and service charges on top of the subtotal
+	TaxAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"tax_amount"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
 
 	// Relations
-	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
-	Product     *Product    `gorm:"foreignKey:ProductID;constraint:OnDelete:SET NULL" json:"-"`
+	Transaction Transaction                `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+	Product     *Product                   `gorm:"foreignKey:ProductID;constraint:OnDelete:SET NULL" json:"-"`
+	Variant     *ProductVariant            `gorm:"foreignKey:VariantID;constraint:OnDelete:SET NULL" json:"-"`
+	Promotions  []TransactionItemPromotion `gorm:"foreignKey:TransactionItemID;constraint:OnDelete:CASCADE" json:"promotions,omitempty"`
+}
+
+// DisplayName is the product name with the variant sold, e.g. "T-Shirt (M / Red)"
+func (i TransactionItem) DisplayName() string {
+	if i.VariantLabel == nil || *i.VariantLabel == "" {
+		return i.ProductName
+	}
+	return i.ProductName + " (" + *i.VariantLabel + ")"
+}
+
+// TransactionItemModifier is a modifier as it was when the line was sold
+type TransactionItemModifier struct {
+	ModifierID string  `json:"modifierId"`
+	GroupName  string  `json:"groupName"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+}
+
+// TransactionItemIngredient is a recipe ingredient as it was when the line was sold
+type TransactionItemIngredient struct {
+	IngredientID string  `json:"ingredientId"`
+	Name         string  `json:"name"`
+	Unit         string  `json:"unit"`
+	Quantity     float64 `json:"quantity"`
 }
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type TransactionItemPromotion struct {
+	ID                string               `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TransactionID     string               `gorm:"type:uuid;not null;index:idx_transaction_item_promotions_transaction_id" json:"transaction_id"`
+	TransactionItemID string               `gorm:"type:uuid;not null;index:idx_transaction_item_promotions_transaction_item_id" json:"transaction_item_id"`
+	PromotionID       *string              `gorm:"type:uuid;index:idx_transaction_item_promotions_promotion_id" json:"promotion_id,omitempty"`
+	Name              string               `gorm:"type:varchar(64);not null" json:"name"`
+	Type              config.PromotionType `gorm:"type:promotion_type;not null" json:"type"`
+	Quantity          int                  `gorm:"not null" json:"quantity"`
+	Amount            float64              `gorm:"type:numeric(12,2);not null" json:"amount"`
+	CreatedAt         time.Time            `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt         time.Time            `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Transaction     Transaction     `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+	TransactionItem TransactionItem `gorm:"foreignKey:TransactionItemID;constraint:OnDelete:CASCADE" json:"-"`
+	Promotion       *Promotion      `gorm:"foreignKey:PromotionID;constraint:OnDelete:SET NULL" json:"-"`
+}
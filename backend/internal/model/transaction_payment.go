@@ -0,0 +1,26 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type TransactionPayment struct {
+	ID            string               `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TransactionID string               `gorm:"type:uuid;not null;index:idx_transaction_payments_transaction_id" json:"transaction_id"`
+	Method        config.PaymentMethod `gorm:"type:payment_method;not null" json:"method"`
+	Amount        float64              `gorm:"type:numeric(12,2);not null;check:amount > 0" json:"amount"`
+	Reference     *string              `gorm:"type:varchar(255)" json:"reference,omitempty"`
+	PaidAt        time.Time            `gorm:"not null;default:now()" json:"paid_at"`
+
+	// Repayment of a credit sale, taken in the drawer open at the time
+	IsCreditRepayment bool    `gorm:"not null;default:false" json:"is_credit_repayment"`
+	CashSessionID     *string `gorm:"type:uuid;index:idx_transaction_payments_cash_session_id" json:"cash_session_id,omitempty"`
+	CreatedBy         *string `gorm:"type:uuid" json:"created_by,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type TransactionTax struct {
+	ID            string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TransactionID string         `gorm:"type:uuid;not null;index:idx_transaction_taxes_transaction_id" json:"transaction_id"`
+	TaxRuleID     *string        `gorm:"type:uuid" json:"tax_rule_id,omitempty"`
+	Name          string         `gorm:"type:varchar(64);not null" json:"name"`
+	Kind          config.TaxKind `gorm:"type:tax_kind;not null" json:"kind"`
+	Rate          float64        `gorm:"type:numeric(5,2);not null" json:"rate"`
+	IsInclusive   bool           `gorm:"not null;default:false" json:"is_inclusive"`
+	TaxableAmount float64        `gorm:"type:numeric(12,2);not null;default:0" json:"taxable_amount"`
+	Amount        float64        `gorm:"type:numeric(12,2);not null;default:0" json:"amount"`
+	CreatedAt     time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+	TaxRule     *TaxRule    `gorm:"foreignKey:TaxRuleID;constraint:OnDelete:SET NULL" json:"-"`
+}
@@ -13,6 +13,8 @@ type User struct {
 	BusinessID             *string         `gorm:"type:uuid" json:"business_id"`
 	PasswordHash           *string         `gorm:"type:text" json:"-"`
 	PinHash                *string         `gorm:"type:text" json:"-"`
+	PinFailedAttempts      int             `gorm:"not null;default:0" json:"-"`
+	PinLockedUntil         *time.Time      `json:"-"`
 	GoogleImage            *string         `gorm:"type:text" json:"google_image,omitempty"`
 	Image                  *string         `gorm:"type:text" json:"image,omitempty"`
 	IsVerified             bool            `gorm:"not null;default:false" json:"is_verified"`
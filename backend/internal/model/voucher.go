@@ -0,0 +1,60 @@
+package model
+
+import (
+	"app/internal/config"
+	"time"
+)
+
+type Voucher struct {
+	ID         string  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID string  `gorm:"type:uuid;not null;uniqueIndex:idx_vouchers_business_id_code" json:"business_id"`
+	Code       string  `gorm:"type:varchar(32);not null;uniqueIndex:idx_vouchers_business_id_code" json:"code"`
+	BatchID    *string `gorm:"type:uuid;index:idx_vouchers_batch_id" json:"batch_id,omitempty"`
+	// Amount for flat vouchers, percent for percentage vouchers which MaxDiscount caps
+	Type        config.DiscountType `gorm:"type:discount_type;not null" json:"type"`
+	Value       float64             `gorm:"type:numeric(12,2);not null;check:value > 0" json:"value"`
+	MaxDiscount *float64            `gorm:"type:numeric(12,2)" json:"max_discount,omitempty"`
+	MinSpend    float64             `gorm:"type:numeric(12,2);not null;default:0" json:"min_spend"`
+
+	// Usage, nil limits mean unlimited
+	UsageLimit       *int `json:"usage_limit,omitempty"`
+	PerCustomerLimit *int `json:"per_customer_limit,omitempty"`
+	UsedCount        int  `gorm:"not null;default:0" json:"used_count"`
+
+	StartsAt  *time.Time `gorm:"type:timestamp" json:"starts_at,omitempty"`
+	EndsAt    *time.Time `gorm:"type:timestamp" json:"ends_at,omitempty"`
+	IsActive  bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedBy *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business Business `gorm:"foreignKey:BusinessID;constraint:OnDelete:CASCADE" json:"-"`
+	Creator  *User    `gorm:"foreignKey:CreatedBy" json:"-"`
+}
+
+// IsValidAt reports whether the voucher can be used at the time
+func (v Voucher) IsValidAt(now time.Time) bool {
+	if !v.IsActive {
+		return false
+	}
+	if v.StartsAt != nil && now.Before(*v.StartsAt) {
+		return false
+	}
+	if v.EndsAt != nil && !now.Before(*v.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// DiscountOn returns the discount the voucher gives on an amount
+func (v Voucher) DiscountOn(amount float64) float64 {
+	discount := v.Value
+	if v.Type == config.DISCOUNT_TYPE_PERCENTAGE {
+		discount = amount * v.Value / 100
+		if v.MaxDiscount != nil {
+			discount = min(discount, *v.MaxDiscount)
+		}
+	}
+	return min(discount, amount)
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type VoucherRedemption struct {
+	ID            string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BusinessID    string     `gorm:"type:uuid;not null" json:"business_id"`
+	VoucherID     string     `gorm:"type:uuid;not null;index:idx_voucher_redemptions_voucher_id" json:"voucher_id"`
+	TransactionID string     `gorm:"type:uuid;not null" json:"transaction_id"`
+	CustomerID    *string    `gorm:"type:uuid;index:idx_voucher_redemptions_voucher_id" json:"customer_id,omitempty"`
+	Amount        float64    `gorm:"type:numeric(12,2);not null" json:"amount"`
+	ReversedAt    *time.Time `gorm:"type:timestamp" json:"reversed_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Relations
+	Business    Business    `gorm:"foreignKey:BusinessID" json:"-"`
+	Voucher     Voucher     `gorm:"foreignKey:VoucherID;constraint:OnDelete:CASCADE" json:"-"`
+	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+	Customer    *Customer   `gorm:"foreignKey:CustomerID;constraint:OnDelete:SET NULL" json:"-"`
+}
@@ -18,8 +18,8 @@ func (r *BusinessRepository) CreateBusiness(business *model.Business) error {
 	return r.db.Create(business).Error
 }
 
-func (r *BusinessRepository) UpdateBusiness(business *model.Business) error {
-	return r.db.Save(business).Error
+func (r *BusinessRepository) UpdateBusiness(tx *gorm.DB, business *model.Business) error {
+	return tx.Save(business).Error
 }
 
 func (r *BusinessRepository) GetBusinessByID(id string) (*model.Business, error) {
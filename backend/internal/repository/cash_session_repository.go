@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"app/internal/config"
+	"app/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CashSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewCashSessionRepository(db *gorm.DB) *CashSessionRepository {
+	return &CashSessionRepository{db: db}
+}
+
+func (r *CashSessionRepository) CreateSession(session *model.CashSession) error {
+	return r.db.Omit(clause.Associations).Create(session).Error
+}
+
+func (r *CashSessionRepository) UpdateSession(tx *gorm.DB, session *model.CashSession) error {
+	return tx.Omit(clause.Associations).Save(session).Error
+}
+
+func (r *CashSessionRepository) CreateMovement(tx *gorm.DB, movement *model.CashMovement) error {
+	return tx.Omit(clause.Associations).Create(movement).Error
+}
+
+func (r *CashSessionRepository) GetSessionByID(id string) (*model.CashSession, error) {
+	var session model.CashSession
+	err := r.db.Where("id = ?", id).
+		Preload("Opener").
+		Preload("Closer").
+		Preload("Movements", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *CashSessionRepository) GetSessionByIDAndBusinessID(id, businessID string) (*model.CashSession, error) {
+	var session model.CashSession
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetOpenSessionByUserID returns the drawer the user currently has open, nil when there is none
+func (r *CashSessionRepository) GetOpenSessionByUserID(userID, businessID string) (*model.CashSession, error) {
+	var session model.CashSession
+	err := r.db.Where("opened_by = ? AND business_id = ? AND status = ?", userID, businessID, config.CASH_SESSION_STATUS_OPEN).
+		First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetSessionForUpdate locks the session row until the transaction ends
+func (r *CashSessionRepository) GetSessionForUpdate(tx *gorm.DB, id string) (*model.CashSession, error) {
+	var session model.CashSession
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions lists the sessions of a business, newest first. openedBy limits it to one employee.
+func (r *CashSessionRepository) ListSessions(businessID string, openedBy *string, page, pageSize int) ([]model.CashSession, int64, error) {
+	var sessions []model.CashSession
+	var total int64
+
+	query := r.db.Model(&model.CashSession{}).Where("business_id = ?", businessID)
+	if openedBy != nil {
+		query = query.Where("opened_by = ?", *openedBy)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Opener").
+		Preload("Closer").
+		Order("opened_at DESC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+// CashSessionTotals are the sales, payments, refunds and drawer movements of a session
+type CashSessionTotals struct {
+	Transactions int64
+	GrossSales   float64
+	Discounts    float64
+	Taxes        float64
+	Rounding     float64
+	NetSales     float64
+	ChangeGiven  float64
+	Payments     []model.ZReportPayment
+	Refunds      float64
+	CashRefunds  float64
+	CashIn       float64
+	CashOut      float64
+	// Repayments of earlier credit sales taken in this session
+	CreditRepayments     float64
+	CashCreditRepayments float64
+}
+
+// GetSessionTotals sums what happened in a session. Only paid transactions count, voided sales gave their cash back.
+func (r *CashSessionRepository) GetSessionTotals(db *gorm.DB, sessionID string) (*CashSessionTotals, error) {
+	var totals CashSessionTotals
+	var payments []model.ZReportPayment
+
+	err := db.Model(&model.Transaction{}).
+		Select("COUNT(*) as transactions, "+
+			"COALESCE(SUM(gross_amount), 0) as gross_sales, "+
+			"COALESCE(SUM(discount_amount), 0) as discounts, "+
+			"COALESCE(SUM(tax_amount), 0) as taxes, "+
+			"COALESCE(SUM(rounding_amount), 0) as rounding, "+
+			"COALESCE(SUM(total_amount + rounding_amount), 0) as net_sales, "+
+			"COALESCE(SUM(change_amount), 0) as change_given").
+		Where("cash_session_id = ? AND status = ?", sessionID, config.TRANSACTION_STATUS_PAID).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model(&model.TransactionPayment{}).
+		Select("transaction_payments.method, COUNT(*) as count, COALESCE(SUM(transaction_payments.amount), 0) as amount").
+		Joins("JOIN transactions ON transactions.id = transaction_payments.transaction_id").
+		Where("transactions.cash_session_id = ? AND transactions.status = ?", sessionID, config.TRANSACTION_STATUS_PAID).
+		Where("transaction_payments.is_credit_repayment = ?", false).
+		Group("transaction_payments.method").
+		Order("transaction_payments.method ASC").
+		Scan(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds struct {
+		Refunds     float64
+		CashRefunds float64
+	}
+	err = db.Model(&model.Refund{}).
+		Select("COALESCE(SUM(amount), 0) as refunds, "+
+			"COALESCE(SUM(CASE WHEN method = ? THEN amount - credit_amount ELSE 0 END), 0) as cash_refunds", config.PAYMENT_METHOD_CASH).
+		Where("cash_session_id = ?", sessionID).
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var movements struct {
+		CashIn  float64
+		CashOut float64
+	}
+	err = db.Model(&model.CashMovement{}).
+		Select("COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) as cash_in, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) as cash_out", config.CASH_MOVEMENT_TYPE_IN, config.CASH_MOVEMENT_TYPE_OUT).
+		Where("cash_session_id = ?", sessionID).
+		Scan(&movements).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var repayments struct {
+		CreditRepayments     float64
+		CashCreditRepayments float64
+	}
+	err = db.Model(&model.TransactionPayment{}).
+		Select("COALESCE(SUM(amount), 0) as credit_repayments, "+
+			"COALESCE(SUM(CASE WHEN method = ? THEN amount ELSE 0 END), 0) as cash_credit_repayments", config.PAYMENT_METHOD_CASH).
+		Where("cash_session_id = ? AND is_credit_repayment = ?", sessionID, true).
+		Scan(&repayments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals.Payments = payments
+	totals.Refunds = refunds.Refunds
+	totals.CashRefunds = refunds.CashRefunds
+	totals.CashIn = movements.CashIn
+	totals.CashOut = movements.CashOut
+	totals.CreditRepayments = repayments.CreditRepayments
+	totals.CashCreditRepayments = repayments.CashCreditRepayments
+
+	return &totals, nil
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"app/internal/config"
+	"app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomerRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// CustomerStats sums up the paid transactions of a customer
+type CustomerStats struct {
+	LifetimeSpend      float64
+	VisitCount         int64
+	LastVisitAt        *time.Time
+	OutstandingBalance float64
+}
+
+// CreditAgingLine is what one customer still owes, split by how long ago the credit sales were made
+type CreditAgingLine struct {
+	CustomerID   string
+	CustomerName string
+	Phone        *string
+	Current      float64 `gorm:"column:current_amount"`
+	Days31To60   float64 `gorm:"column:days_31_to_60"`
+	Days61To90   float64 `gorm:"column:days_61_to_90"`
+	Over90Days   float64 `gorm:"column:over_90_days"`
+	Total        float64
+	OldestAt     time.Time
+}
+
+func (r *CustomerRepository) CreateCustomer(customer *model.Customer) error {
+	return r.db.Omit("Business").Create(customer).Error
+}
+
+// UpdateCustomer saves the customer details, the points balance only moves with the points ledger
+func (r *CustomerRepository) UpdateCustomer(customer *model.Customer) error {
+	return r.db.Omit("Business", "PointsBalance").Save(customer).Error
+}
+
+func (r *CustomerRepository) GetCustomerByID(id string) (*model.Customer, error) {
+	var customer model.Customer
+	err := r.db.Where("id = ?", id).First(&customer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *CustomerRepository) GetCustomerByIDAndBusinessID(id, businessID string) (*model.Customer, error) {
+	var customer model.Customer
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&customer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *CustomerRepository) GetCustomerByPhone(businessID, phone string) (*model.Customer, error) {
+	var customer model.Customer
+	err := r.db.Where("business_id = ? AND phone = ?", businessID, phone).First(&customer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// ListCustomers searches by name or email, and by phone digits when phoneSearch is set
+func (r *CustomerRepository) ListCustomers(businessID string, page, pageSize int, search, phoneSearch, tag string) ([]model.Customer, int64, error) {
+	var customers []model.Customer
+	var total int64
+
+	query := r.db.Model(&model.Customer{}).Where("business_id = ?", businessID)
+	if search != "" {
+		condition := r.db.Where("name ILIKE ?", "%"+search+"%").Or("email ILIKE ?", "%"+search+"%")
+		if phoneSearch != "" {
+			condition = condition.Or("phone LIKE ?", "%"+phoneSearch+"%")
+		}
+		query = query.Where(condition)
+	}
+	if tag != "" {
+		query = query.Where("tags @> jsonb_build_array(?::text)", tag)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("name ASC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&customers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return customers, total, nil
+}
+
+// GetCustomerStats counts paid transactions as visits, refunds are taken off the spend
+func (r *CustomerRepository) GetCustomerStats(customerID string) (*CustomerStats, error) {
+	var stats CustomerStats
+	err := r.db.Model(&model.Transaction{}).
+		Select("COALESCE(SUM(total_amount + rounding_amount), 0) AS lifetime_spend, COUNT(*) AS visit_count, MAX(paid_at) AS last_visit_at").
+		Where("customer_id = ? AND status = ?", customerID, config.TRANSACTION_STATUS_PAID).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunded float64
+	err = r.db.Model(&model.Refund{}).
+		Select("COALESCE(SUM(refunds.amount), 0)").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("transactions.customer_id = ?", customerID).
+		Scan(&refunded).Error
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding, err := r.GetOutstandingBalance(r.db, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.LifetimeSpend -= refunded
+	stats.OutstandingBalance = outstanding
+	return &stats, nil
+}
+
+// GetCustomerForUpdate locks the customer row until the surrounding database transaction ends
+func (r *CustomerRepository) GetCustomerForUpdate(tx *gorm.DB, id, businessID string) (*model.Customer, error) {
+	var customer model.Customer
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND business_id = ?", id, businessID).
+		First(&customer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetOutstandingBalance sums what the customer still owes on paid credit sales
+func (r *CustomerRepository) GetOutstandingBalance(db *gorm.DB, customerID string) (float64, error) {
+	var balance float64
+	err := db.Model(&model.Transaction{}).
+		Select("COALESCE(SUM(outstanding_amount), 0)").
+		Where("customer_id = ? AND status = ? AND outstanding_amount > 0", customerID, config.TRANSACTION_STATUS_PAID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// ListOutstandingTransactions lists the unpaid credit sales of a customer, oldest first.
+// Rows are locked when called with a database transaction so repayments are not applied twice.
+func (r *CustomerRepository) ListOutstandingTransactions(db *gorm.DB, customerID string, lock bool) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+
+	query := db.Model(&model.Transaction{})
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	err := query.
+		Where("customer_id = ? AND status = ? AND outstanding_amount > 0", customerID, config.TRANSACTION_STATUS_PAID).
+		Order("paid_at ASC, created_at ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// CreateRepayments stores repayments of credit sales
+func (r *CustomerRepository) CreateRepayments(tx *gorm.DB, payments []model.TransactionPayment) error {
+	return tx.Omit("Transaction").Create(&payments).Error
+}
+
+// GetCreditAging lists customers with an outstanding balance, split into 0-30, 31-60, 61-90 and over 90 days
+func (r *CustomerRepository) GetCreditAging(businessID string, now time.Time) ([]CreditAgingLine, error) {
+	var lines []CreditAgingLine
+
+	days30 := now.AddDate(0, 0, -30)
+	days60 := now.AddDate(0, 0, -60)
+	days90 := now.AddDate(0, 0, -90)
+
+	err := r.db.Model(&model.Transaction{}).
+		Select("customers.id AS customer_id, customers.name AS customer_name, customers.phone, "+
+			"COALESCE(SUM(CASE WHEN transactions.paid_at >= ? THEN transactions.outstanding_amount ELSE 0 END), 0) AS current_amount, "+
+			"COALESCE(SUM(CASE WHEN transactions.paid_at < ? AND transactions.paid_at >= ? THEN transactions.outstanding_amount ELSE 0 END), 0) AS days_31_to_60, "+
+			"COALESCE(SUM(CASE WHEN transactions.paid_at < ? AND transactions.paid_at >= ? THEN transactions.outstanding_amount ELSE 0 END), 0) AS days_61_to_90, "+
+			"COALESCE(SUM(CASE WHEN transactions.paid_at < ? THEN transactions.outstanding_amount ELSE 0 END), 0) AS over_90_days, "+
+			"SUM(transactions.outstanding_amount) AS total, "+
+			"MIN(transactions.paid_at) AS oldest_at",
+			days30, days30, days60, days60, days90, days90).
+		Joins("JOIN customers ON customers.id = transactions.customer_id").
+		Where("transactions.business_id = ? AND transactions.status = ? AND transactions.outstanding_amount > 0", businessID, config.TRANSACTION_STATUS_PAID).
+		Group("customers.id, customers.name, customers.phone").
+		Order("total DESC, customers.name ASC").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func (r *CustomerRepository) DeleteCustomer(id string) error {
+	return r.db.Where("id = ?", id).Delete(&model.Customer{}).Error
+}
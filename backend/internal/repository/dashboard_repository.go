@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"app/internal/config"
 	"app/internal/model"
 	"time"
 
@@ -20,31 +21,56 @@ type PeriodStats struct {
 	Sales        float64
 	Transactions int64
 	Profit       float64
+	Refunds      float64
+	Discounts    float64
+	Taxes        float64
+	Rounding     float64
+	// Credit sales count as sales, the money they bring in is counted when repaid.
+	// Loyalty points redeemed bring in no money at all.
+	CreditSales      float64
+	CreditRepayments float64
+	CashReceived     float64
 }
 
 // GetPeriodStats gets aggregated stats for a time period
 func (r *DashboardRepository) GetPeriodStats(businessID string, start, end time.Time) (*PeriodStats, error) {
 	var result struct {
-		TotalSales  float64
-		TotalCount  int64
-		TotalProfit float64
+		TotalSales     float64
+		TotalCount     int64
+		TotalProfit    float64
+		TotalDiscounts float64
+		TotalTaxes     float64
+		TotalRounding  float64
+		TotalCredit    float64
 	}
 
-	// Subquery to get transaction items with their costs
+	// Subquery to get transaction items with their costs, a variant's own cost comes before the product's
 	subQuery := r.db.Model(&model.TransactionItem{}).
 		Select("transaction_items.transaction_id, transaction_items.subtotal, "+
-			"COALESCE(products.cost, 0) * transaction_items.quantity as item_cost").
+			"COALESCE(product_variants.cost, products.cost, 0) * transaction_items.quantity as item_cost").
 		Joins("LEFT JOIN products ON products.id = transaction_items.product_id").
+		Joins("LEFT JOIN product_variants ON product_variants.id = transaction_items.variant_id").
 		Where("products.business_id = ?", businessID)
 
-	// Main query to aggregate transaction data
+	// Taxes collected for the government are not profit, service charges are
+	taxQuery := r.db.Model(&model.TransactionTax{}).
+		Select("transaction_id, SUM(amount) as total_tax").
+		Where("kind = ?", config.TAX_KIND_TAX).
+		Group("transaction_id")
+
+	// Main query to aggregate transaction data, sales include the cash rounding actually collected
 	err := r.db.Model(&model.Transaction{}).
-		Select("COALESCE(SUM(transactions.total_amount), 0) as total_sales, "+
+		Select("COALESCE(SUM(transactions.total_amount + transactions.rounding_amount), 0) as total_sales, "+
 			"COUNT(*) as total_count, "+
-			"COALESCE(SUM(transactions.total_amount - items.total_cost), 0) as total_profit").
+			"COALESCE(SUM(transactions.total_amount + transactions.rounding_amount - COALESCE(taxes.total_tax, 0) - items.total_cost), 0) as total_profit, "+
+			"COALESCE(SUM(transactions.discount_amount), 0) as total_discounts, "+
+			"COALESCE(SUM(taxes.total_tax), 0) as total_taxes, "+
+			"COALESCE(SUM(transactions.rounding_amount), 0) as total_rounding, "+
+			"COALESCE(SUM(transactions.credit_amount), 0) as total_credit").
 		Joins("LEFT JOIN (SELECT transaction_id, SUM(item_cost) as total_cost FROM (?) as costs GROUP BY transaction_id) as items ON items.transaction_id = transactions.id", subQuery).
+		Joins("LEFT JOIN (?) as taxes ON taxes.transaction_id = transactions.id", taxQuery).
 		Where("transactions.business_id = ?", businessID).
-		Where("transactions.status = ?", "paid").
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
 		Where("transactions.created_at >= ? AND transactions.created_at < ?", start, end).
 		Scan(&result).Error
 
@@ -52,20 +78,179 @@ func (r *DashboardRepository) GetPeriodStats(businessID string, start, end time.
 		return nil, err
 	}
 
+	refunds, err := r.getPeriodRefunds(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Points and gift cards were paid for before, spending them brings no money in
+	storedValueMethods := []config.PaymentMethod{config.PAYMENT_METHOD_POINTS, config.PAYMENT_METHOD_GIFT_CARD}
+
+	var storedValuePaid float64
+	err = r.db.Model(&model.TransactionPayment{}).
+		Select("COALESCE(SUM(transaction_payments.amount), 0)").
+		Joins("JOIN transactions ON transactions.id = transaction_payments.transaction_id").
+		Where("transactions.business_id = ?", businessID).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("transaction_payments.method IN ?", storedValueMethods).
+		Where("transactions.created_at >= ? AND transactions.created_at < ?", start, end).
+		Scan(&storedValuePaid).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var creditRepayments float64
+	err = r.db.Model(&model.TransactionPayment{}).
+		Select("COALESCE(SUM(transaction_payments.amount), 0)").
+		Joins("JOIN transactions ON transactions.id = transaction_payments.transaction_id").
+		Where("transactions.business_id = ?", businessID).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("transaction_payments.is_credit_repayment = ?", true).
+		Where("transaction_payments.paid_at >= ? AND transaction_payments.paid_at < ?", start, end).
+		Scan(&creditRepayments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Sales and profit are net of refunds made in the period. Restocked goods give their cost back.
+	// Money received leaves out what was sold on credit or paid with points or gift cards, and refunds to those paid nothing back.
+	sales := result.TotalSales - refunds.TotalAmount
 	return &PeriodStats{
-		Sales:        result.TotalSales,
-		Transactions: result.TotalCount,
-		Profit:       result.TotalProfit,
+		Sales:            sales,
+		Transactions:     result.TotalCount,
+		Profit:           result.TotalProfit - refunds.TotalAmount + refunds.RestockedCost,
+		Refunds:          refunds.TotalAmount,
+		Discounts:        result.TotalDiscounts,
+		Taxes:            result.TotalTaxes,
+		Rounding:         result.TotalRounding,
+		CreditSales:      result.TotalCredit,
+		CreditRepayments: creditRepayments,
+		CashReceived:     sales - result.TotalCredit - storedValuePaid + refunds.CreditAmount + refunds.StoredValueAmount + creditRepayments,
 	}, nil
 }
 
+// TaxReportLine is the tax collected by one rule in a time period
+type TaxReportLine struct {
+	TaxRuleID     *string
+	Name          string
+	Kind          config.TaxKind
+	Rate          float64
+	IsInclusive   bool
+	Transactions  int64
+	TaxableAmount float64
+	Amount        float64
+}
+
+// GetTaxReport sums the tax lines of paid transactions in a time period.
+// Lines are grouped by rule and rate, so a rate change shows up as its own line.
+func (r *DashboardRepository) GetTaxReport(businessID string, start, end time.Time) ([]TaxReportLine, error) {
+	var lines []TaxReportLine
+
+	err := r.db.Model(&model.TransactionTax{}).
+		Select("transaction_taxes.tax_rule_id, transaction_taxes.name, transaction_taxes.kind, "+
+			"transaction_taxes.rate, transaction_taxes.is_inclusive, "+
+			"COUNT(DISTINCT transaction_taxes.transaction_id) as transactions, "+
+			"COALESCE(SUM(transaction_taxes.taxable_amount), 0) as taxable_amount, "+
+			"COALESCE(SUM(transaction_taxes.amount), 0) as amount").
+		Joins("JOIN transactions ON transactions.id = transaction_taxes.transaction_id").
+		Where("transactions.business_id = ?", businessID).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("transactions.created_at >= ? AND transactions.created_at < ?", start, end).
+		Group("transaction_taxes.tax_rule_id, transaction_taxes.name, transaction_taxes.kind, transaction_taxes.rate, transaction_taxes.is_inclusive").
+		Order("transaction_taxes.kind DESC, transaction_taxes.name ASC").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// PromotionReportLine is the discount given by one promotion in a time period
+type PromotionReportLine struct {
+	PromotionID  *string
+	Name         string
+	Type         config.PromotionType
+	Transactions int64
+	Quantity     int64
+	Amount       float64
+}
+
+// GetPromotionReport sums the promotions applied to paid transactions in a time period
+func (r *DashboardRepository) GetPromotionReport(businessID string, start, end time.Time) ([]PromotionReportLine, error) {
+	var lines []PromotionReportLine
+
+	err := r.db.Model(&model.TransactionItemPromotion{}).
+		Select("transaction_item_promotions.promotion_id, transaction_item_promotions.name, transaction_item_promotions.type, "+
+			"COUNT(DISTINCT transaction_item_promotions.transaction_id) as transactions, "+
+			"COALESCE(SUM(transaction_item_promotions.quantity), 0) as quantity, "+
+			"COALESCE(SUM(transaction_item_promotions.amount), 0) as amount").
+		Joins("JOIN transactions ON transactions.id = transaction_item_promotions.transaction_id").
+		Where("transactions.business_id = ?", businessID).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("transactions.created_at >= ? AND transactions.created_at < ?", start, end).
+		Group("transaction_item_promotions.promotion_id, transaction_item_promotions.name, transaction_item_promotions.type").
+		Order("amount DESC, transaction_item_promotions.name ASC").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+type periodRefunds struct {
+	TotalAmount       float64
+	CreditAmount      float64
+	StoredValueAmount float64
+	RestockedCost     float64
+}
+
+// getPeriodRefunds sums refunds of paid transactions made in a time period
+func (r *DashboardRepository) getPeriodRefunds(businessID string, start, end time.Time) (*periodRefunds, error) {
+	var result periodRefunds
+
+	err := r.db.Model(&model.Refund{}).
+		Select("COALESCE(SUM(refunds.amount), 0) as total_amount, "+
+			"COALESCE(SUM(refunds.credit_amount), 0) as credit_amount, "+
+			"COALESCE(SUM(CASE WHEN refunds.method IN ? THEN refunds.amount - refunds.credit_amount ELSE 0 END), 0) as stored_value_amount",
+			[]config.PaymentMethod{config.PAYMENT_METHOD_POINTS, config.PAYMENT_METHOD_GIFT_CARD}).
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("refunds.business_id = ?", businessID).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("refunds.created_at >= ? AND refunds.created_at < ?", start, end).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&model.RefundItem{}).
+		Select("COALESCE(SUM(COALESCE(product_variants.cost, products.cost, 0) * refund_items.quantity), 0) as restocked_cost").
+		Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Joins("LEFT JOIN products ON products.id = refund_items.product_id").
+		Joins("LEFT JOIN product_variants ON product_variants.id = refund_items.variant_id").
+		Where("refunds.business_id = ?", businessID).
+		Where("refunds.restock = ?", true).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("refunds.created_at >= ? AND refunds.created_at < ?", start, end).
+		Scan(&result.RestockedCost).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // GetLatestTransactions retrieves the latest N transactions
 func (r *DashboardRepository) GetLatestTransactions(businessID string, limit int) ([]model.Transaction, error) {
 	var transactions []model.Transaction
 
 	err := r.db.Where("business_id = ?", businessID).
 		Preload("Items").
+		Preload("Items.Promotions").
 		Preload("Creator").
+		Preload("Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("kind DESC, name ASC") }).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&transactions).Error
@@ -77,10 +262,16 @@ func (r *DashboardRepository) GetLatestTransactions(businessID string, limit int
 	return transactions, nil
 }
 
-// ProductSales represents product with sales count
+// ProductSales represents product with sales count, variants sold are grouped under their product
 type ProductSales struct {
 	Product      model.Product
 	QuantitySold int
+	Variants     []VariantSales
+}
+
+type VariantSales struct {
+	Variant      model.ProductVariant
+	QuantitySold int
 }
 
 // GetTopProducts retrieves top N products by quantity sold
@@ -90,12 +281,13 @@ func (r *DashboardRepository) GetTopProducts(businessID string, limit int) ([]Pr
 		QuantitySold int
 	}
 
-	// Get top products by quantity sold
+	// Get top products by quantity sold, net of returned quantities
 	err := r.db.Model(&model.TransactionItem{}).
-		Select("transaction_items.product_id, SUM(transaction_items.quantity) as quantity_sold").
+		Select("transaction_items.product_id, SUM(transaction_items.quantity - COALESCE(returned.quantity, 0)) as quantity_sold").
 		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("LEFT JOIN (SELECT transaction_item_id, SUM(quantity) as quantity FROM refund_items GROUP BY transaction_item_id) as returned ON returned.transaction_item_id = transaction_items.id").
 		Where("transactions.business_id = ?", businessID).
-		Where("transactions.status = ?", "paid").
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
 		Where("transaction_items.product_id IS NOT NULL").
 		Group("transaction_items.product_id").
 		Order("quantity_sold DESC").
@@ -116,11 +308,73 @@ func (r *DashboardRepository) GetTopProducts(businessID string, limit int) ([]Pr
 			continue
 		}
 
+		variants, err := r.getVariantSales(businessID, product.ID)
+		if err != nil {
+			return nil, err
+		}
+
 		productSales = append(productSales, ProductSales{
 			Product:      product,
 			QuantitySold: result.QuantitySold,
+			Variants:     variants,
 		})
 	}
 
 	return productSales, nil
 }
+
+// getVariantSales breaks the quantity sold of a product down by variant, best selling first
+func (r *DashboardRepository) getVariantSales(businessID, productID string) ([]VariantSales, error) {
+	var results []struct {
+		VariantID    string
+		QuantitySold int
+	}
+
+	err := r.db.Model(&model.TransactionItem{}).
+		Select("transaction_items.variant_id, SUM(transaction_items.quantity - COALESCE(returned.quantity, 0)) as quantity_sold").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("LEFT JOIN (SELECT transaction_item_id, SUM(quantity) as quantity FROM refund_items GROUP BY transaction_item_id) as returned ON returned.transaction_item_id = transaction_items.id").
+		Where("transactions.business_id = ?", businessID).
+		Where("transactions.status = ?", config.TRANSACTION_STATUS_PAID).
+		Where("transaction_items.product_id = ?", productID).
+		Where("transaction_items.variant_id IS NOT NULL").
+		Group("transaction_items.variant_id").
+		Order("quantity_sold DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.VariantID
+	}
+
+	var variants []model.ProductVariant
+	if err := r.db.Where("id IN ?", ids).Find(&variants).Error; err != nil {
+		return nil, err
+	}
+
+	variantMap := make(map[string]model.ProductVariant, len(variants))
+	for _, variant := range variants {
+		variantMap[variant.ID] = variant
+	}
+
+	variantSales := make([]VariantSales, 0, len(results))
+	for _, result := range results {
+		variant, ok := variantMap[result.VariantID]
+		if !ok {
+			continue
+		}
+		variantSales = append(variantSales, VariantSales{
+			Variant:      variant,
+			QuantitySold: result.QuantitySold,
+		})
+	}
+
+	return variantSales, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GiftCardRepository struct {
+	db *gorm.DB
+}
+
+func NewGiftCardRepository(db *gorm.DB) *GiftCardRepository {
+	return &GiftCardRepository{db: db}
+}
+
+func (r *GiftCardRepository) CreateGiftCards(tx *gorm.DB, giftCards []model.GiftCard) error {
+	return tx.Omit("Business", "Creator").Create(&giftCards).Error
+}
+
+// UpdateGiftCard saves the gift card settings, the balance only moves with the balance ledger
+func (r *GiftCardRepository) UpdateGiftCard(giftCard *model.GiftCard) error {
+	return r.db.Omit("Business", "Creator", "Balance", "InitialBalance").Save(giftCard).Error
+}
+
+func (r *GiftCardRepository) GetGiftCardByID(id string) (*model.GiftCard, error) {
+	var giftCard model.GiftCard
+	err := r.db.Where("id = ?", id).First(&giftCard).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &giftCard, nil
+}
+
+func (r *GiftCardRepository) GetGiftCardByIDAndBusinessID(id, businessID string) (*model.GiftCard, error) {
+	var giftCard model.GiftCard
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&giftCard).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &giftCard, nil
+}
+
+func (r *GiftCardRepository) GetGiftCardByCode(businessID, code string) (*model.GiftCard, error) {
+	var giftCard model.GiftCard
+	err := r.db.Where("business_id = ? AND code = ?", businessID, code).First(&giftCard).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &giftCard, nil
+}
+
+// GetGiftCardForUpdate locks the gift card row until the surrounding database transaction ends
+func (r *GiftCardRepository) GetGiftCardForUpdate(tx *gorm.DB, businessID, code string) (*model.GiftCard, error) {
+	var giftCard model.GiftCard
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_id = ? AND code = ?", businessID, code).
+		First(&giftCard).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &giftCard, nil
+}
+
+// GetGiftCardForUpdateByID locks the gift card row until the surrounding database transaction ends
+func (r *GiftCardRepository) GetGiftCardForUpdateByID(tx *gorm.DB, id string) (*model.GiftCard, error) {
+	var giftCard model.GiftCard
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&giftCard).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &giftCard, nil
+}
+
+// FindExistingCodes returns the codes that are already taken in the business
+func (r *GiftCardRepository) FindExistingCodes(businessID string, codes []string) ([]string, error) {
+	var existing []string
+	err := r.db.Model(&model.GiftCard{}).
+		Where("business_id = ? AND code IN ?", businessID, codes).
+		Pluck("code", &existing).Error
+	return existing, err
+}
+
+// UpdateBalance sets the balance of a locked gift card
+func (r *GiftCardRepository) UpdateBalance(tx *gorm.DB, giftCardID string, balance float64) error {
+	return tx.Model(&model.GiftCard{}).
+		Where("id = ?", giftCardID).
+		Updates(map[string]any{"balance": balance, "updated_at": time.Now()}).Error
+}
+
+// CreateEntries stores lines of gift card balance ledgers
+func (r *GiftCardRepository) CreateEntries(tx *gorm.DB, entries []model.GiftCardEntry) error {
+	return tx.Omit("GiftCard", "Transaction", "Creator").Create(&entries).Error
+}
+
+// ListTransactionEntries lists the ledger lines recorded against a transaction, oldest first
+func (r *GiftCardRepository) ListTransactionEntries(tx *gorm.DB, transactionID string) ([]model.GiftCardEntry, error) {
+	var entries []model.GiftCardEntry
+	err := tx.Where("transaction_id = ?", transactionID).
+		Order("created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GiftCardRepository) ListGiftCards(businessID string, page, pageSize int, search, batchID string) ([]model.GiftCard, int64, error) {
+	var giftCards []model.GiftCard
+	var total int64
+
+	query := r.db.Model(&model.GiftCard{}).Where("business_id = ?", businessID)
+	if search != "" {
+		query = query.Where("code LIKE ?", "%"+search+"%")
+	}
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("created_at DESC, code ASC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&giftCards).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return giftCards, total, nil
+}
+
+// ListGiftCardsForExport lists all gift cards of a business, or of one generated batch
+func (r *GiftCardRepository) ListGiftCardsForExport(businessID, batchID string) ([]model.GiftCard, error) {
+	var giftCards []model.GiftCard
+
+	query := r.db.Where("business_id = ?", businessID)
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+
+	if err := query.Order("created_at ASC, code ASC").Find(&giftCards).Error; err != nil {
+		return nil, err
+	}
+	return giftCards, nil
+}
+
+// ListEntriesByGiftCardID lists the balance ledger of a gift card, newest first
+func (r *GiftCardRepository) ListEntriesByGiftCardID(giftCardID string, page, pageSize int) ([]model.GiftCardEntry, int64, error) {
+	var entries []model.GiftCardEntry
+	var total int64
+
+	query := r.db.Model(&model.GiftCardEntry{}).Where("gift_card_id = ?", giftCardID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Transaction").
+		Preload("Creator").
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"app/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HeldCartRepository struct {
+	db *gorm.DB
+}
+
+func NewHeldCartRepository(db *gorm.DB) *HeldCartRepository {
+	return &HeldCartRepository{db: db}
+}
+
+func (r *HeldCartRepository) CreateHeldCart(cart *model.HeldCart) error {
+	return r.db.Omit("Business", "Creator", "Customer").Create(cart).Error
+}
+
+func (r *HeldCartRepository) GetHeldCartByID(id string) (*model.HeldCart, error) {
+	var cart model.HeldCart
+	err := r.db.Where("id = ?", id).Preload("Creator").Preload("Customer").First(&cart).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *HeldCartRepository) GetHeldCartByIDAndBusinessID(id, businessID string) (*model.HeldCart, error) {
+	var cart model.HeldCart
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&cart).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *HeldCartRepository) ListHeldCarts(businessID string, page, pageSize int, search string) ([]model.HeldCart, int64, error) {
+	var carts []model.HeldCart
+	var total int64
+
+	query := r.db.Model(&model.HeldCart{}).Where("business_id = ?", businessID)
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Creator").
+		Preload("Customer").
+		Order("created_at ASC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&carts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return carts, total, nil
+}
+
+// GetHeldCartForUpdate locks the cart until tx ends, so only one terminal can resume it
+func (r *HeldCartRepository) GetHeldCartForUpdate(tx *gorm.DB, id string) (*model.HeldCart, error) {
+	var cart model.HeldCart
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&cart).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// ClaimHeldCart removes a locked cart inside tx, it is only gone once tx commits
+func (r *HeldCartRepository) ClaimHeldCart(tx *gorm.DB, id string) error {
+	return tx.Where("id = ?", id).Delete(&model.HeldCart{}).Error
+}
+
+// DeleteHeldCart removes the cart and reports whether this call was the one that removed it,
+// so two terminals cannot resume the same cart
+func (r *HeldCartRepository) DeleteHeldCart(id string) (bool, error) {
+	result := r.db.Where("id = ?", id).Delete(&model.HeldCart{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
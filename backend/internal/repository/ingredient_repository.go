@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"app/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IngredientRepository struct {
+	db *gorm.DB
+}
+
+func NewIngredientRepository(db *gorm.DB) *IngredientRepository {
+	return &IngredientRepository{db: db}
+}
+
+func (r *IngredientRepository) CreateIngredient(ingredient *model.Ingredient) error {
+	return r.db.Create(ingredient).Error
+}
+
+// UpdateIngredient saves the ingredient and works out the cost of the products made with it again
+func (r *IngredientRepository) UpdateIngredient(ingredient *model.Ingredient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(ingredient).Error; err != nil {
+			return err
+		}
+
+		var productIDs []string
+		err := tx.Model(&model.ProductRecipeItem{}).
+			Where("ingredient_id = ?", ingredient.ID).
+			Distinct().
+			Pluck("product_id", &productIDs).Error
+		if err != nil {
+			return err
+		}
+
+		return recalculateRecipeCosts(tx, productIDs)
+	})
+}
+
+func (r *IngredientRepository) GetIngredientByID(id string) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := r.db.Where("id = ?", id).First(&ingredient).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+func (r *IngredientRepository) GetIngredientByIDAndBusinessID(id string, businessID string) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&ingredient).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+func (r *IngredientRepository) FindIngredientByName(businessID, name string) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := r.db.Where("business_id = ? AND name = ?", businessID, name).First(&ingredient).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+func (r *IngredientRepository) GetIngredientsByIDs(ids []string) ([]*model.Ingredient, error) {
+	var ingredients []*model.Ingredient
+	err := r.db.Where("id IN ?", ids).Find(&ingredients).Error
+	if err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+func (r *IngredientRepository) ListIngredients(businessID string, page, pageSize int, search string) ([]model.Ingredient, int64, error) {
+	var ingredients []model.Ingredient
+	var total int64
+
+	query := r.db.Model(&model.Ingredient{}).Where("business_id = ?", businessID)
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("name ASC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&ingredients).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ingredients, total, nil
+}
+
+// CountRecipesUsingIngredient counts the products whose recipe has the ingredient
+func (r *IngredientRepository) CountRecipesUsingIngredient(id string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ProductRecipeItem{}).
+		Where("ingredient_id = ?", id).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *IngredientRepository) DeleteIngredient(id string) error {
+	return r.db.Where("id = ?", id).
+		Delete(&model.Ingredient{}).Error
+}
+
+// AdjustStock adds delta to the ingredient stock, a negative delta takes it out
+func (r *IngredientRepository) AdjustStock(tx *gorm.DB, ingredientID string, delta float64) error {
+	return tx.Model(&model.Ingredient{}).
+		Where("id = ?", ingredientID).
+		UpdateColumns(map[string]any{
+			"stock_qty":  gorm.Expr("stock_qty + ?", delta),
+			"updated_at": gorm.Expr("now()"),
+		}).Error
+}
+
+// recalculateRecipeCosts sets the cost of the products with a recipe, and of their variants,
+// to the cost of the ingredients used for one unit
+func recalculateRecipeCosts(tx *gorm.DB, productIDs []string) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	err := tx.Exec(`
+		UPDATE products SET cost = COALESCE((
+			SELECT SUM(product_recipe_items.quantity * ingredients.cost)
+			FROM product_recipe_items
+			JOIN ingredients ON ingredients.id = product_recipe_items.ingredient_id
+			WHERE product_recipe_items.product_id = products.id
+		), 0), updated_at = now()
+		WHERE id IN ? AND has_recipe`, productIDs).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE product_variants SET cost = products.cost, updated_at = now()
+		FROM products
+		WHERE product_variants.product_id = products.id AND products.id IN ? AND products.has_recipe`, productIDs).Error
+}
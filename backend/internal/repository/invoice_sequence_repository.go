@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+type InvoiceSequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceSequenceRepository(db *gorm.DB) *InvoiceSequenceRepository {
+	return &InvoiceSequenceRepository{db: db}
+}
+
+// NextValue increments and returns the counter of the period in a single statement.
+// The counter row stays locked until tx ends, and a rollback gives the number back.
+// A period without a counter yet starts after the highest number already issued with
+// the prefix, so numbers issued under another reset period are not repeated.
+func (r *InvoiceSequenceRepository) NextValue(tx *gorm.DB, businessID, periodKey, issuedPrefix string) (int64, error) {
+	var values []int64
+	err := tx.Raw(`
+		UPDATE invoice_sequences
+		SET last_value = last_value + 1, updated_at = now()
+		WHERE business_id = ? AND period_key = ?
+		RETURNING last_value
+	`, businessID, periodKey).Scan(&values).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(values) > 0 {
+		return values[0], nil
+	}
+
+	issued, err := r.maxIssuedValue(tx, businessID, issuedPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var value int64
+	err = tx.Raw(`
+		INSERT INTO invoice_sequences (business_id, period_key, last_value)
+		VALUES (?, ?, ?)
+		ON CONFLICT (business_id, period_key)
+		DO UPDATE SET last_value = invoice_sequences.last_value + 1, updated_at = now()
+		RETURNING last_value
+	`, businessID, periodKey, issued+1).Scan(&value).Error
+	return value, err
+}
+
+// DeleteByBusinessID drops the counters of the business, the next sale seeds a new one
+func (r *InvoiceSequenceRepository) DeleteByBusinessID(tx *gorm.DB, businessID string) error {
+	return tx.Exec("DELETE FROM invoice_sequences WHERE business_id = ?", businessID).Error
+}
+
+// maxIssuedValue returns the highest counter of the invoice numbers starting with the prefix.
+// Postgres counts characters, not bytes, and the prefix is compared as is rather than as a pattern.
+func (r *InvoiceSequenceRepository) maxIssuedValue(tx *gorm.DB, businessID, prefix string) (int64, error) {
+	length := utf8.RuneCountInString(prefix)
+
+	var value int64
+	err := tx.Raw(`
+		SELECT COALESCE(MAX(CAST(SUBSTRING(invoice_number FROM ?) AS BIGINT)), 0)
+		FROM transactions
+		WHERE business_id = ? AND LEFT(invoice_number, ?) = ? AND SUBSTRING(invoice_number FROM ?) ~ '^[0-9]{1,18}$'
+	`, length+1, businessID, length, prefix, length+1).Scan(&value).Error
+	return value, err
+}
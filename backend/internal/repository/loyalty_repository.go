@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"app/internal/config"
+	"app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoyaltyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyRepository(db *gorm.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db}
+}
+
+// CreateEntry stores one line of a customer's points ledger
+func (r *LoyaltyRepository) CreateEntry(tx *gorm.DB, entry *model.LoyaltyPointEntry) error {
+	return tx.Omit("Business", "Customer", "Transaction", "Creator").Create(entry).Error
+}
+
+// UpdatePointsBalance sets the points balance of a customer
+func (r *LoyaltyRepository) UpdatePointsBalance(tx *gorm.DB, customerID string, balance int) error {
+	return tx.Model(&model.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{"points_balance": balance, "updated_at": time.Now()}).Error
+}
+
+// UpdateRemainingPoints sets what is left of an earned or restored entry
+func (r *LoyaltyRepository) UpdateRemainingPoints(tx *gorm.DB, entryID string, remaining int) error {
+	return tx.Model(&model.LoyaltyPointEntry{}).
+		Where("id = ?", entryID).
+		Update("remaining_points", remaining).Error
+}
+
+// ListAvailableEntries locks the unexpired entries of a customer that still have points left,
+// the ones expiring first come first and entries that never expire come last
+func (r *LoyaltyRepository) ListAvailableEntries(tx *gorm.DB, customerID string, now time.Time) ([]model.LoyaltyPointEntry, error) {
+	var entries []model.LoyaltyPointEntry
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("customer_id = ? AND remaining_points > 0", customerID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("expires_at ASC NULLS LAST, created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SumTransactionPoints sums the points of one entry type recorded against a transaction
+func (r *LoyaltyRepository) SumTransactionPoints(tx *gorm.DB, transactionID string, entryType config.LoyaltyEntryType) (int, error) {
+	var points int
+	err := tx.Model(&model.LoyaltyPointEntry{}).
+		Select("COALESCE(SUM(points), 0)").
+		Where("transaction_id = ? AND type = ?", transactionID, entryType).
+		Scan(&points).Error
+	return points, err
+}
+
+// LockExpiredEntries locks a batch of entries with points left whose expiry has passed.
+// Rows already locked by another instance are skipped, so concurrent sweepers never expire the same points.
+func (r *LoyaltyRepository) LockExpiredEntries(tx *gorm.DB, now time.Time, limit int) ([]model.LoyaltyPointEntry, error) {
+	var entries []model.LoyaltyPointEntry
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("remaining_points > 0 AND expires_at <= ?", now).
+		Order("customer_id ASC, expires_at ASC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListEntriesByCustomerID lists the points ledger of a customer, newest first
+func (r *LoyaltyRepository) ListEntriesByCustomerID(customerID string, page, pageSize int) ([]model.LoyaltyPointEntry, int64, error) {
+	var entries []model.LoyaltyPointEntry
+	var total int64
+
+	query := r.db.Model(&model.LoyaltyPointEntry{}).Where("customer_id = ?", customerID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Transaction").
+		Preload("Creator").
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"app/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ModifierRepository struct {
+	db *gorm.DB
+}
+
+func NewModifierRepository(db *gorm.DB) *ModifierRepository {
+	return &ModifierRepository{db: db}
+}
+
+func (r *ModifierRepository) CreateModifierGroup(tx *gorm.DB, group *model.ModifierGroup) error {
+	return tx.Omit("Modifiers", "Categories", "Products").Create(group).Error
+}
+
+func (r *ModifierRepository) UpdateModifierGroup(tx *gorm.DB, group *model.ModifierGroup) error {
+	return tx.Omit("Modifiers", "Categories", "Products").Save(group).Error
+}
+
+// ReplaceModifierGroupTargets sets the categories and products a group is offered on
+func (r *ModifierRepository) ReplaceModifierGroupTargets(tx *gorm.DB, groupID string, categoryIDs, productIDs []string) error {
+	if err := tx.Exec("DELETE FROM modifier_group_categories WHERE modifier_group_id = ?", groupID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM modifier_group_products WHERE modifier_group_id = ?", groupID).Error; err != nil {
+		return err
+	}
+
+	for _, categoryID := range categoryIDs {
+		if err := tx.Exec("INSERT INTO modifier_group_categories (modifier_group_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING", groupID, categoryID).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, productID := range productIDs {
+		if err := tx.Exec("INSERT INTO modifier_group_products (modifier_group_id, product_id) VALUES (?, ?) ON CONFLICT DO NOTHING", groupID, productID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReplaceModifiers saves the modifiers of a group and deletes the ones no longer listed.
+// Sold lines keep their own copy, so deleting a modifier does not touch history.
+func (r *ModifierRepository) ReplaceModifiers(tx *gorm.DB, groupID string, modifiers []model.Modifier) error {
+	keepIDs := make([]string, 0, len(modifiers))
+	for _, modifier := range modifiers {
+		if modifier.ID != "" {
+			keepIDs = append(keepIDs, modifier.ID)
+		}
+	}
+
+	query := tx.Where("modifier_group_id = ?", groupID)
+	if len(keepIDs) > 0 {
+		query = query.Where("id NOT IN ?", keepIDs)
+	}
+	if err := query.Delete(&model.Modifier{}).Error; err != nil {
+		return err
+	}
+
+	for i := range modifiers {
+		modifiers[i].ModifierGroupID = groupID
+		if err := tx.Omit(clause.Associations).Save(&modifiers[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ModifierRepository) GetModifierGroupByID(id string) (*model.ModifierGroup, error) {
+	var group model.ModifierGroup
+	err := r.db.Where("id = ?", id).
+		Preload("Modifiers", orderBySortOrder).
+		Preload("Categories").
+		Preload("Products").
+		First(&group).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *ModifierRepository) GetModifierGroupByIDAndBusinessID(id string, businessID string) (*model.ModifierGroup, error) {
+	var group model.ModifierGroup
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&group).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *ModifierRepository) ListModifierGroups(businessID string) ([]model.ModifierGroup, error) {
+	var groups []model.ModifierGroup
+	err := r.db.Where("business_id = ?", businessID).
+		Preload("Modifiers", orderBySortOrder).
+		Preload("Categories").
+		Preload("Products").
+		Order("sort_order ASC, created_at ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// ListActiveModifierGroups returns the groups offered at checkout in display order
+func (r *ModifierRepository) ListActiveModifierGroups(businessID string) ([]model.ModifierGroup, error) {
+	var groups []model.ModifierGroup
+	err := r.db.Where("business_id = ? AND is_active = ?", businessID, true).
+		Preload("Modifiers", orderBySortOrder).
+		Preload("Categories").
+		Preload("Products").
+		Order("sort_order ASC, created_at ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *ModifierRepository) GetMaxSortOrder(businessID string) (int, error) {
+	var result struct {
+		MaxSortOrder *int
+	}
+	err := r.db.Model(&model.ModifierGroup{}).
+		Select("MAX(sort_order) as max_sort_order").
+		Where("business_id = ?", businessID).
+		Scan(&result).Error
+	if err != nil {
+		return 0, err
+	}
+	if result.MaxSortOrder == nil {
+		return 0, nil
+	}
+	return *result.MaxSortOrder, nil
+}
+
+func (r *ModifierRepository) DeleteModifierGroup(id string) error {
+	return r.db.Where("id = ?", id).
+		Delete(&model.ModifierGroup{}).Error
+}
+
+func (r *ModifierRepository) GetModifiersByIDs(ids []string) ([]*model.Modifier, error) {
+	var modifiers []*model.Modifier
+	err := r.db.Where("id IN ?", ids).Find(&modifiers).Error
+	if err != nil {
+		return nil, err
+	}
+	return modifiers, nil
+}
+
+func (r *ModifierRepository) DecreaseStock(tx *gorm.DB, modifierID string, quantity int) error {
+	result := tx.Exec(
+		"UPDATE modifiers SET stock_qty = stock_qty - ?, updated_at = now() WHERE id = ? AND stock_qty >= ?",
+		quantity, modifierID, quantity,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound // No rows updated means insufficient stock
+	}
+	return nil
+}
+
+func (r *ModifierRepository) IncreaseStock(tx *gorm.DB, modifierID string, quantity int) error {
+	return tx.Model(&model.Modifier{}).
+		Where("id = ?", modifierID).
+		UpdateColumn("stock_qty", gorm.Expr("stock_qty + ?", quantity)).Error
+}
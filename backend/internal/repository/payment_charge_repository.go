@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"app/internal/config"
+	"app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentChargeRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentChargeRepository(db *gorm.DB) *PaymentChargeRepository {
+	return &PaymentChargeRepository{db: db}
+}
+
+func (r *PaymentChargeRepository) CreateCharge(charge *model.PaymentCharge) error {
+	return r.db.Create(charge).Error
+}
+
+func (r *PaymentChargeRepository) GetChargeByIDAndTransactionID(id, transactionID string) (*model.PaymentCharge, error) {
+	var charge model.PaymentCharge
+	err := r.db.Where("id = ? AND transaction_id = ?", id, transactionID).First(&charge).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// GetActiveChargeByTransactionID returns the latest pending charge that has not expired yet
+func (r *PaymentChargeRepository) GetActiveChargeByTransactionID(transactionID string, now time.Time) (*model.PaymentCharge, error) {
+	var charge model.PaymentCharge
+	err := r.db.Where("transaction_id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)", transactionID, config.PAYMENT_CHARGE_STATUS_PENDING, now).
+		Order("created_at DESC").
+		First(&charge).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// GetChargeForUpdate locks the charge row until the surrounding database transaction ends
+func (r *PaymentChargeRepository) GetChargeForUpdate(tx *gorm.DB, provider, reference string) (*model.PaymentCharge, error) {
+	var charge model.PaymentCharge
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND reference = ?", provider, reference).
+		First(&charge).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &charge, nil
+}
+
+func (r *PaymentChargeRepository) UpdateCharge(tx *gorm.DB, charge *model.PaymentCharge) error {
+	return tx.Omit(clause.Associations).Save(charge).Error
+}
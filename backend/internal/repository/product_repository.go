@@ -4,6 +4,7 @@ import (
 	"app/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository struct {
@@ -14,17 +15,24 @@ func NewProductRepository(db *gorm.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (r *ProductRepository) CreateProduct(product *model.Product) error {
-	return r.db.Create(product).Error
+// CreateProduct creates the product without stock, its opening stock is set through SetStock so the ledger starts with it
+func (r *ProductRepository) CreateProduct(tx *gorm.DB, product *model.Product) error {
+	return tx.Omit("StockQty").Create(product).Error
 }
 
-func (r *ProductRepository) UpdateProduct(product *model.Product) error {
-	return r.db.Save(product).Error
+// UpdateProduct saves the product's own columns. Variants are changed through their own methods and stock through the ledger.
+func (r *ProductRepository) UpdateProduct(tx *gorm.DB, product *model.Product) error {
+	return tx.Omit(clause.Associations, "StockQty").Save(product).Error
 }
 
 func (r *ProductRepository) GetProductByID(id string) (*model.Product, error) {
 	var product model.Product
-	err := r.db.Preload("Category").Where("id = ?", id).First(&product).Error
+	err := r.db.Preload("Category").
+		Preload("OptionGroups", orderBySortOrder).
+		Preload("Variants", orderBySortOrder).
+		Preload("RecipeItems.Ingredient").
+		Where("id = ?", id).
+		First(&product).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -55,8 +63,10 @@ func (r *ProductRepository) ListProducts(businessID string, page, pageSize int,
 		Where("business_id = ?", businessID)
 
 	// Add search filter if search term is provided
+	// Variants are listed under their product, so a variant SKU or barcode finds the product
 	if search != "" {
-		query = query.Where("name ILIKE ?", "%"+search+"%")
+		query = query.Where("name ILIKE ? OR EXISTS (SELECT 1 FROM product_variants WHERE product_variants.product_id = products.id AND (product_variants.sku ILIKE ? OR product_variants.barcode_value = ?))",
+			"%"+search+"%", "%"+search+"%", search)
 	}
 
 	// Add isActive filter if provided
@@ -81,6 +91,9 @@ func (r *ProductRepository) ListProducts(businessID string, page, pageSize int,
 	// Fetch paginated records
 	err = query.
 		Preload("Category").
+		Preload("OptionGroups", orderBySortOrder).
+		Preload("Variants", orderBySortOrder).
+		Preload("RecipeItems.Ingredient").
 		Order("created_at DESC").
 		Limit(pageSize).
 		Offset(offset).
@@ -105,30 +118,225 @@ func (r *ProductRepository) ToggleProductStatus(id string, isActive bool) error
 
 func (r *ProductRepository) GetProductsByIDs(ids []string) ([]*model.Product, error) {
 	var products []*model.Product
-	err := r.db.Where("id IN ?", ids).Find(&products).Error
+	err := r.db.Where("id IN ?", ids).
+		Preload("RecipeItems.Ingredient").
+		Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-func (r *ProductRepository) DecreaseStock(tx *gorm.DB, productID string, quantity int) error {
+func (r *ProductRepository) GetVariantsByIDs(ids []string) ([]*model.ProductVariant, error) {
+	var variants []*model.ProductVariant
+	err := r.db.Where("id IN ?", ids).Find(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *ProductRepository) GetVariantByIDAndProductID(id, productID string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := r.db.Where("id = ? AND product_id = ?", id, productID).Preload("Product").First(&variant).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// FindVariantBySKU returns a variant of the business with the SKU, used to keep SKUs unique
+func (r *ProductRepository) FindVariantBySKU(businessID, sku string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := r.db.Where("business_id = ? AND sku = ?", businessID, sku).First(&variant).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateVariant saves the variant's own columns, stock is changed through the ledger
+func (r *ProductRepository) UpdateVariant(tx *gorm.DB, variant *model.ProductVariant) error {
+	return tx.Omit(clause.Associations, "StockQty").Save(variant).Error
+}
+
+// SaveProductOptions replaces the option groups of a product and brings its variants in line with them.
+// Variants with an ID are kept with their price and stock, others are created and the rest deactivated.
+// Removed variants are never deleted, sales lines still point at them and their stock stays on the ledger.
+func (r *ProductRepository) SaveProductOptions(productID string, groups []model.ProductOptionGroup, variants []model.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductOptionGroup{}).Error; err != nil {
+			return err
+		}
+
+		if len(groups) > 0 {
+			if err := tx.Create(&groups).Error; err != nil {
+				return err
+			}
+		}
+
+		keptIDs := make([]string, 0, len(variants))
+		var created []model.ProductVariant
+		for _, variant := range variants {
+			if variant.ID == "" {
+				created = append(created, variant)
+				continue
+			}
+
+			keptIDs = append(keptIDs, variant.ID)
+			err := tx.Model(&model.ProductVariant{ID: variant.ID}).
+				Where("product_id = ?", productID).
+				Select("Options", "Label", "SortOrder").
+				Updates(&variant).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		removed := tx.Model(&model.ProductVariant{}).Where("product_id = ?", productID)
+		if len(keptIDs) > 0 {
+			removed = removed.Where("id NOT IN ?", keptIDs)
+		}
+		if err := removed.Updates(map[string]any{"is_active": false, "updated_at": gorm.Expr("now()")}).Error; err != nil {
+			return err
+		}
+
+		if len(created) > 0 {
+			if err := tx.Create(&created).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.Product{}).
+			Where("id = ?", productID).
+			Updates(map[string]any{"has_variants": len(variants) > 0, "updated_at": gorm.Expr("now()")}).Error
+	})
+}
+
+// SaveProductRecipe replaces the recipe of a product and works out its cost from the ingredients.
+// Without recipe items the product keeps its last cost and can be costed by hand again.
+func (r *ProductRepository) SaveProductRecipe(productID string, items []model.ProductRecipeItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductRecipeItem{}).Error; err != nil {
+			return err
+		}
+
+		if len(items) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&items).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&model.Product{}).
+			Where("id = ?", productID).
+			Updates(map[string]any{"has_recipe": len(items) > 0, "updated_at": gorm.Expr("now()")}).Error
+		if err != nil {
+			return err
+		}
+
+		return recalculateRecipeCosts(tx, []string{productID})
+	})
+}
+
+// stockLevel is the stock of a product or variant row as read while moving it
+type stockLevel struct {
+	StockQty *int
+	Label    *string
+}
+
+// MoveStock adds the quantity of the movement to the stock of its product, or of its variant, and records the
+// movement with the balance after it. Taking out more than is in stock returns gorm.ErrRecordNotFound.
+// Stock that is not counted yet is left alone when goods come back in.
+func (r *ProductRepository) MoveStock(tx *gorm.DB, movement *model.StockMovement) error {
 	// Using raw SQL to ensure atomic operation with stock validation
-	result := tx.Exec(
-		"UPDATE products SET stock_qty = stock_qty - ?, updated_at = now() WHERE id = ? AND stock_qty >= ?",
-		quantity, productID, quantity,
-	)
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
+	query := "UPDATE products SET stock_qty = stock_qty + ?, updated_at = now() WHERE id = ? AND stock_qty + ? >= 0 RETURNING stock_qty"
+	id := movement.ProductID
+	if movement.VariantID != nil {
+		query = "UPDATE product_variants SET stock_qty = stock_qty + ?, updated_at = now() WHERE id = ? AND stock_qty + ? >= 0 RETURNING stock_qty, label"
+		id = *movement.VariantID
+	}
+
+	var levels []stockLevel
+	if err := tx.Raw(query, movement.Quantity, id, movement.Quantity).Scan(&levels).Error; err != nil {
+		return err
+	}
+	if len(levels) == 0 {
+		if movement.Quantity > 0 {
+			return nil
+		}
 		return gorm.ErrRecordNotFound // No rows updated means insufficient stock
 	}
-	return nil
+
+	movement.BalanceAfter = *levels[0].StockQty
+	movement.VariantLabel = levels[0].Label
+	return tx.Omit(clause.Associations).Create(movement).Error
+}
+
+// SetStock sets the stock of the product, or of its variant, to a counted quantity and records the difference as the movement
+func (r *ProductRepository) SetStock(tx *gorm.DB, movement *model.StockMovement, quantity int) error {
+	table, query := "products", "SELECT stock_qty FROM products WHERE id = ? FOR UPDATE"
+	id := movement.ProductID
+	if movement.VariantID != nil {
+		table, query = "product_variants", "SELECT stock_qty, label FROM product_variants WHERE id = ? FOR UPDATE"
+		id = *movement.VariantID
+	}
+
+	var levels []stockLevel
+	if err := tx.Raw(query, id).Scan(&levels).Error; err != nil {
+		return err
+	}
+	if len(levels) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Exec("UPDATE "+table+" SET stock_qty = ?, updated_at = now() WHERE id = ?", quantity, id).Error; err != nil {
+		return err
+	}
+
+	movement.Quantity = quantity
+	if levels[0].StockQty != nil {
+		movement.Quantity -= *levels[0].StockQty
+	}
+	movement.BalanceAfter = quantity
+	movement.VariantLabel = levels[0].Label
+	return tx.Omit(clause.Associations).Create(movement).Error
+}
+
+// ListStockMovements lists the stock ledger of a product, or of one of its variants, newest first
+func (r *ProductRepository) ListStockMovements(productID string, variantID *string, page, pageSize int) ([]model.StockMovement, int64, error) {
+	var movements []model.StockMovement
+	var total int64
+
+	query := r.db.Model(&model.StockMovement{}).Where("product_id = ?", productID)
+	if variantID != nil && *variantID != "" {
+		query = query.Where("variant_id = ?", *variantID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Transaction").
+		Preload("Creator").
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&movements).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
 }
 
-func (r *ProductRepository) IncreaseStock(tx *gorm.DB, productID string, quantity int) error {
-	return tx.Model(&model.Product{}).
-		Where("id = ?", productID).
-		UpdateColumn("stock_qty", gorm.Expr("stock_qty + ?", quantity)).Error
+func orderBySortOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC")
 }
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+func (r *PromotionRepository) CreatePromotion(tx *gorm.DB, promotion *model.Promotion) error {
+	return tx.Omit("Categories", "Products").Create(promotion).Error
+}
+
+func (r *PromotionRepository) UpdatePromotion(tx *gorm.DB, promotion *model.Promotion) error {
+	return tx.Omit("Categories", "Products").Save(promotion).Error
+}
+
+// ReplacePromotionTargets sets the categories and products a promotion is limited to
+func (r *PromotionRepository) ReplacePromotionTargets(tx *gorm.DB, promotionID string, categoryIDs, productIDs []string) error {
+	if err := tx.Exec("DELETE FROM promotion_categories WHERE promotion_id = ?", promotionID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM promotion_products WHERE promotion_id = ?", promotionID).Error; err != nil {
+		return err
+	}
+
+	for _, categoryID := range categoryIDs {
+		if err := tx.Exec("INSERT INTO promotion_categories (promotion_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING", promotionID, categoryID).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, productID := range productIDs {
+		if err := tx.Exec("INSERT INTO promotion_products (promotion_id, product_id) VALUES (?, ?) ON CONFLICT DO NOTHING", promotionID, productID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) GetPromotionByID(id string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := r.db.Where("id = ?", id).
+		Preload("Categories").
+		Preload("Products").
+		First(&promotion).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *PromotionRepository) GetPromotionByIDAndBusinessID(id string, businessID string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&promotion).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *PromotionRepository) ListPromotions(businessID string) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := r.db.Where("business_id = ?", businessID).
+		Preload("Categories").
+		Preload("Products").
+		Order("sort_order ASC, created_at ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// ListActivePromotions returns the promotions that have not ended in calculation order,
+// daily windows are checked by the caller
+func (r *PromotionRepository) ListActivePromotions(businessID string, now time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := r.db.Where("business_id = ? AND is_active = ?", businessID, true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Preload("Categories").
+		Preload("Products").
+		Order("sort_order ASC, created_at ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *PromotionRepository) GetMaxSortOrder(businessID string) (int, error) {
+	var result struct {
+		MaxSortOrder *int
+	}
+	err := r.db.Model(&model.Promotion{}).
+		Select("MAX(sort_order) as max_sort_order").
+		Where("business_id = ?", businessID).
+		Scan(&result).Error
+	if err != nil {
+		return 0, err
+	}
+	if result.MaxSortOrder == nil {
+		return 0, nil
+	}
+	return *result.MaxSortOrder, nil
+}
+
+func (r *PromotionRepository) DeletePromotion(id string) error {
+	return r.db.Where("id = ?", id).
+		Delete(&model.Promotion{}).Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"app/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ReceiptDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewReceiptDeliveryRepository(db *gorm.DB) *ReceiptDeliveryRepository {
+	return &ReceiptDeliveryRepository{db: db}
+}
+
+func (r *ReceiptDeliveryRepository) CreateReceiptDelivery(delivery *model.ReceiptDelivery) error {
+	return r.db.Omit("Business", "Transaction", "Sender").Create(delivery).Error
+}
+
+func (r *ReceiptDeliveryRepository) UpdateReceiptDelivery(delivery *model.ReceiptDelivery) error {
+	return r.db.Omit("Business", "Transaction", "Sender").Save(delivery).Error
+}
+
+func (r *ReceiptDeliveryRepository) GetReceiptDeliveryByIDAndTransactionID(id, transactionID string) (*model.ReceiptDelivery, error) {
+	var delivery model.ReceiptDelivery
+	err := r.db.Where("id = ? AND transaction_id = ?", id, transactionID).Preload("Sender").First(&delivery).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *ReceiptDeliveryRepository) ListReceiptDeliveriesByTransactionID(transactionID string) ([]model.ReceiptDelivery, error) {
+	var deliveries []model.ReceiptDelivery
+	err := r.db.Where("transaction_id = ?", transactionID).
+		Preload("Sender").
+		Order("created_at DESC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"app/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RefundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+func (r *RefundRepository) CreateRefund(tx *gorm.DB, refund *model.Refund) error {
+	return tx.Create(refund).Error
+}
+
+func (r *RefundRepository) GetRefundByID(id string) (*model.Refund, error) {
+	var refund model.Refund
+	err := r.db.Where("id = ?", id).Preload("Items").Preload("Creator").First(&refund).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *RefundRepository) ListRefundsByTransactionID(transactionID string) ([]model.Refund, error) {
+	var refunds []model.Refund
+	err := r.db.Where("transaction_id = ?", transactionID).
+		Preload("Items").
+		Preload("Creator").
+		Order("created_at ASC").
+		Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// RefundedLine is what has already been refunded of one transaction item
+type RefundedLine struct {
+	TransactionItemID string
+	Quantity          int
+	Amount            float64
+}
+
+// GetRefundedLines returns the quantity and amount already refunded per transaction item
+func (r *RefundRepository) GetRefundedLines(tx *gorm.DB, transactionID string) (map[string]RefundedLine, error) {
+	var rows []RefundedLine
+
+	err := tx.Model(&model.RefundItem{}).
+		Select("refund_items.transaction_item_id, SUM(refund_items.quantity) as quantity, SUM(refund_items.subtotal) as amount").
+		Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+		Where("refunds.transaction_id = ?", transactionID).
+		Group("refund_items.transaction_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]RefundedLine, len(rows))
+	for _, row := range rows {
+		lines[row.TransactionItemID] = row
+	}
+	return lines, nil
+}
+
+func (r *RefundRepository) CountRefundsByTransactionID(tx *gorm.DB, transactionID string) (int64, error) {
+	var count int64
+	err := tx.Model(&model.Refund{}).Where("transaction_id = ?", transactionID).Count(&count).Error
+	return count, err
+}
+
+// GetRefundedAmount sums the refunds made against a transaction
+func (r *RefundRepository) GetRefundedAmount(tx *gorm.DB, transactionID string) (float64, error) {
+	var amount float64
+	err := tx.Model(&model.Refund{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("transaction_id = ?", transactionID).
+		Scan(&amount).Error
+	return amount, err
+}
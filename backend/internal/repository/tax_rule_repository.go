@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"app/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type TaxRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRuleRepository(db *gorm.DB) *TaxRuleRepository {
+	return &TaxRuleRepository{db: db}
+}
+
+func (r *TaxRuleRepository) CreateTaxRule(tx *gorm.DB, rule *model.TaxRule) error {
+	return tx.Omit("Categories", "Products").Create(rule).Error
+}
+
+func (r *TaxRuleRepository) UpdateTaxRule(tx *gorm.DB, rule *model.TaxRule) error {
+	return tx.Omit("Categories", "Products").Save(rule).Error
+}
+
+// ReplaceTaxRuleTargets sets the categories and products a rule is limited to
+func (r *TaxRuleRepository) ReplaceTaxRuleTargets(tx *gorm.DB, ruleID string, categoryIDs, productIDs []string) error {
+	if err := tx.Exec("DELETE FROM tax_rule_categories WHERE tax_rule_id = ?", ruleID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM tax_rule_products WHERE tax_rule_id = ?", ruleID).Error; err != nil {
+		return err
+	}
+
+	for _, categoryID := range categoryIDs {
+		if err := tx.Exec("INSERT INTO tax_rule_categories (tax_rule_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING", ruleID, categoryID).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, productID := range productIDs {
+		if err := tx.Exec("INSERT INTO tax_rule_products (tax_rule_id, product_id) VALUES (?, ?) ON CONFLICT DO NOTHING", ruleID, productID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *TaxRuleRepository) GetTaxRuleByID(id string) (*model.TaxRule, error) {
+	var rule model.TaxRule
+	err := r.db.Where("id = ?", id).
+		Preload("Categories").
+		Preload("Products").
+		First(&rule).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *TaxRuleRepository) GetTaxRuleByIDAndBusinessID(id string, businessID string) (*model.TaxRule, error) {
+	var rule model.TaxRule
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&rule).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *TaxRuleRepository) ListTaxRules(businessID string) ([]model.TaxRule, error) {
+	var rules []model.TaxRule
+	err := r.db.Where("business_id = ?", businessID).
+		Preload("Categories").
+		Preload("Products").
+		Order("sort_order ASC, created_at ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ListActiveTaxRules returns the rules applied at checkout in calculation order
+func (r *TaxRuleRepository) ListActiveTaxRules(businessID string) ([]model.TaxRule, error) {
+	var rules []model.TaxRule
+	err := r.db.Where("business_id = ? AND is_active = ?", businessID, true).
+		Preload("Categories").
+		Preload("Products").
+		Order("sort_order ASC, created_at ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *TaxRuleRepository) GetMaxSortOrder(businessID string) (int, error) {
+	var result struct {
+		MaxSortOrder *int
+	}
+	err := r.db.Model(&model.TaxRule{}).
+		Select("MAX(sort_order) as max_sort_order").
+		Where("business_id = ?", businessID).
+		Scan(&result).Error
+	if err != nil {
+		return 0, err
+	}
+	if result.MaxSortOrder == nil {
+		return 0, nil
+	}
+	return *result.MaxSortOrder, nil
+}
+
+func (r *TaxRuleRepository) DeleteTaxRule(id string) error {
+	return r.db.Where("id = ?", id).
+		Delete(&model.TaxRule{}).Error
+}
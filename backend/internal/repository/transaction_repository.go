@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"app/internal/config"
 	"app/internal/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository struct {
@@ -20,7 +23,17 @@ func (r *TransactionRepository) CreateTransaction(tx *model.Transaction) error {
 
 func (r *TransactionRepository) GetTransactionByID(id string) (*model.Transaction, error) {
 	var transaction model.Transaction
-	err := r.db.Where("id = ?", id).Preload("Items").Preload("Creator").First(&transaction).Error
+	err := r.db.Where("id = ?", id).
+		Preload("Items").
+		Preload("Items.Promotions").
+		Preload("Creator").
+		Preload("Customer").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("paid_at ASC") }).
+		Preload("Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("kind DESC, name ASC") }).
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Refunds.Items").
+		Preload("Refunds.Creator").
+		First(&transaction).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -54,16 +67,69 @@ func (r *TransactionRepository) GetTransactionByIDAndBusinessID(id, businessID s
 	return &transaction, nil
 }
 
+// GetTransactionForUpdate locks the transaction row until the surrounding database transaction ends
+func (r *TransactionRepository) GetTransactionForUpdate(tx *gorm.DB, id, businessID string) (*model.Transaction, error) {
+	var transaction model.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND business_id = ?", id, businessID).
+		Preload("Items").
+		Preload("Refunds").
+		First(&transaction).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &transaction, nil
+}
+
 func (r *TransactionRepository) UpdateTransaction(tx *model.Transaction) error {
 	return r.db.Save(tx).Error
 }
 
+// SaveTransaction saves the transaction row only, its associations are written separately
+func (r *TransactionRepository) SaveTransaction(tx *gorm.DB, transaction *model.Transaction) error {
+	return tx.Omit(clause.Associations).Save(transaction).Error
+}
+
+// CountCreditRepayments counts the repayments taken on a credit sale after it was paid
+func (r *TransactionRepository) CountCreditRepayments(tx *gorm.DB, transactionID string) (int64, error) {
+	var count int64
+	err := tx.Model(&model.TransactionPayment{}).
+		Where("transaction_id = ? AND is_credit_repayment = ?", transactionID, true).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *TransactionRepository) UpdateTransactionStatus(id, status string) error {
 	return r.db.Model(&model.Transaction{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
 
+func (r *TransactionRepository) UpdateTransactionsStatus(tx *gorm.DB, ids []string, status config.TransactionStatus) error {
+	return tx.Model(&model.Transaction{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{"status": status, "updated_at": time.Now()}).Error
+}
+
+// LockExpiredPendingTransactions locks a batch of pending transactions whose expiry has passed.
+// Rows already locked by another instance are skipped, so concurrent sweepers never pick the same transaction.
+func (r *TransactionRepository) LockExpiredPendingTransactions(tx *gorm.DB, now time.Time, limit int) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND expired_at < ?", config.TRANSACTION_STATUS_PENDING, now).
+		Order("expired_at ASC").
+		Limit(limit).
+		Preload("Items").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 func (r *TransactionRepository) ListTransactionsByBusinessID(businessID string, page, pageSize int, search string) ([]model.Transaction, int64, error) {
 	var transactions []model.Transaction
 	var total int64
@@ -84,7 +150,11 @@ func (r *TransactionRepository) ListTransactionsByBusinessID(businessID string,
 	// Get paginated results with items preloaded
 	err := query.
 		Preload("Items").
+		Preload("Items.Promotions").
 		Preload("Creator").
+		Preload("Customer").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("paid_at ASC") }).
+		Preload("Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("kind DESC, name ASC") }).
 		Order("created_at DESC").
 		Limit(pageSize).
 		Offset(offset).
@@ -96,3 +166,32 @@ func (r *TransactionRepository) ListTransactionsByBusinessID(businessID string,
 
 	return transactions, total, nil
 }
+
+// ListTransactionsByCustomerID lists the purchase history of a customer, newest first
+func (r *TransactionRepository) ListTransactionsByCustomerID(customerID string, page, pageSize int) ([]model.Transaction, int64, error) {
+	var transactions []model.Transaction
+	var total int64
+
+	query := r.db.Model(&model.Transaction{}).Where("customer_id = ?", customerID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Preload("Items").
+		Preload("Items.Promotions").
+		Preload("Creator").
+		Preload("Customer").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("paid_at ASC") }).
+		Preload("Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("kind DESC, name ASC") }).
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
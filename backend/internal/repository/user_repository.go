@@ -3,6 +3,7 @@ package repository
 import (
 	"app/internal/config"
 	"app/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -97,6 +98,30 @@ func (r *UserRepository) UpdateUser(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
+// RecordFailedPin counts a wrong PIN in a single statement. The maxAttempts-th one in a row
+// locks the PIN until lockedUntil and starts the count over. Returns the lock, nil when not locked.
+func (r *UserRepository) RecordFailedPin(userID string, maxAttempts int, lockedUntil time.Time) (*time.Time, error) {
+	var locks []*time.Time
+	err := r.db.Raw(`
+		UPDATE users
+		SET pin_failed_attempts = CASE WHEN pin_failed_attempts + 1 >= ? THEN 0 ELSE pin_failed_attempts + 1 END,
+			pin_locked_until = CASE WHEN pin_failed_attempts + 1 >= ? THEN ? ELSE pin_locked_until END
+		WHERE id = ?
+		RETURNING pin_locked_until
+	`, maxAttempts, maxAttempts, lockedUntil, userID).Scan(&locks).Error
+	if err != nil || len(locks) == 0 || locks[0] == nil || !locks[0].After(time.Now()) {
+		return nil, err
+	}
+	return locks[0], nil
+}
+
+// ResetFailedPins clears the wrong PIN count after a correct PIN
+func (r *UserRepository) ResetFailedPins(userID string) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND (pin_failed_attempts > 0 OR pin_locked_until IS NOT NULL)", userID).
+		Updates(map[string]any{"pin_failed_attempts": 0, "pin_locked_until": nil}).Error
+}
+
 func (r *UserRepository) UpdateUserPassword(userID, hashedPassword string) error {
 	return r.db.Model(&model.User{}).Where("id = ?", userID).Update("password_hash", hashedPassword).Error
 }
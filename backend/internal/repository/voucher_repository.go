@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) *VoucherRepository {
+	return &VoucherRepository{db: db}
+}
+
+func (r *VoucherRepository) CreateVouchers(tx *gorm.DB, vouchers []model.Voucher) error {
+	return tx.Omit("Business", "Creator").Create(&vouchers).Error
+}
+
+// UpdateVoucher saves the voucher settings, the used count only moves with redemptions
+func (r *VoucherRepository) UpdateVoucher(voucher *model.Voucher) error {
+	return r.db.Omit("Business", "Creator", "UsedCount").Save(voucher).Error
+}
+
+func (r *VoucherRepository) GetVoucherByID(id string) (*model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Where("id = ?", id).First(&voucher).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *VoucherRepository) GetVoucherByIDAndBusinessID(id, businessID string) (*model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Where("id = ? AND business_id = ?", id, businessID).First(&voucher).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *VoucherRepository) GetVoucherByCode(businessID, code string) (*model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Where("business_id = ? AND code = ?", businessID, code).First(&voucher).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// GetVoucherForUpdate locks the voucher row until the surrounding database transaction ends
+func (r *VoucherRepository) GetVoucherForUpdate(tx *gorm.DB, id string) (*model.Voucher, error) {
+	var voucher model.Voucher
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&voucher).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// FindExistingCodes returns the codes that are already taken in the business
+func (r *VoucherRepository) FindExistingCodes(businessID string, codes []string) ([]string, error) {
+	var existing []string
+	err := r.db.Model(&model.Voucher{}).
+		Where("business_id = ? AND code IN ?", businessID, codes).
+		Pluck("code", &existing).Error
+	return existing, err
+}
+
+func (r *VoucherRepository) ListVouchers(businessID string, page, pageSize int, search, batchID string) ([]model.Voucher, int64, error) {
+	var vouchers []model.Voucher
+	var total int64
+
+	query := r.db.Model(&model.Voucher{}).Where("business_id = ?", businessID)
+	if search != "" {
+		query = query.Where("code LIKE ?", "%"+search+"%")
+	}
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("created_at DESC, code ASC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&vouchers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return vouchers, total, nil
+}
+
+// ListVouchersForExport lists all vouchers of a business, or of one generated batch
+func (r *VoucherRepository) ListVouchersForExport(businessID, batchID string) ([]model.Voucher, error) {
+	var vouchers []model.Voucher
+
+	query := r.db.Where("business_id = ?", businessID)
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+
+	if err := query.Order("created_at ASC, code ASC").Find(&vouchers).Error; err != nil {
+		return nil, err
+	}
+	return vouchers, nil
+}
+
+// AddUsedCount moves the used count of a locked voucher
+func (r *VoucherRepository) AddUsedCount(tx *gorm.DB, voucherID string, delta int) error {
+	return tx.Model(&model.Voucher{}).
+		Where("id = ?", voucherID).
+		Updates(map[string]any{"used_count": gorm.Expr("used_count + ?", delta), "updated_at": time.Now()}).Error
+}
+
+// CountCustomerRedemptions counts the uses of a voucher by a customer that were not given back
+func (r *VoucherRepository) CountCustomerRedemptions(tx *gorm.DB, voucherID, customerID string) (int64, error) {
+	var count int64
+	err := tx.Model(&model.VoucherRedemption{}).
+		Where("voucher_id = ? AND customer_id = ? AND reversed_at IS NULL", voucherID, customerID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *VoucherRepository) CreateRedemption(tx *gorm.DB, redemption *model.VoucherRedemption) error {
+	return tx.Omit("Business", "Voucher", "Transaction", "Customer").Create(redemption).Error
+}
+
+// GetActiveRedemption returns the use of a voucher by a transaction that was not given back
+func (r *VoucherRepository) GetActiveRedemption(tx *gorm.DB, transactionID string) (*model.VoucherRedemption, error) {
+	var redemption model.VoucherRedemption
+	err := tx.Where("transaction_id = ? AND reversed_at IS NULL", transactionID).First(&redemption).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+func (r *VoucherRepository) ReverseRedemption(tx *gorm.DB, redemptionID string, now time.Time) error {
+	return tx.Model(&model.VoucherRedemption{}).
+		Where("id = ?", redemptionID).
+		Updates(map[string]any{"reversed_at": now, "updated_at": now}).Error
+}
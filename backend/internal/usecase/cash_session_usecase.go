@@ -0,0 +1,394 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CashSessionUsecase struct {
+	cashSessionRepo *repository.CashSessionRepository
+	db              *gorm.DB
+}
+
+func NewCashSessionUsecase(cashSessionRepo *repository.CashSessionRepository, db *gorm.DB) *CashSessionUsecase {
+	return &CashSessionUsecase{
+		cashSessionRepo: cashSessionRepo,
+		db:              db,
+	}
+}
+
+// OpenSession opens a cash drawer for the user with the starting float
+func (u *CashSessionUsecase) OpenSession(userID, businessID string, req *contract.OpenCashSessionReq) (*contract.CashSessionRes, error) {
+	current, err := u.cashSessionRepo.GetOpenSessionByUserID(userID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get open cash session", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to open cash session")
+	}
+
+	if current != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "You already have an open cash session. Close it first")
+	}
+
+	session := &model.CashSession{
+		BusinessID:   businessID,
+		OpenedBy:     userID,
+		Status:       config.CASH_SESSION_STATUS_OPEN,
+		OpeningFloat: roundMoney(req.OpeningFloat),
+		OpeningNote:  req.Note,
+		OpenedAt:     time.Now(),
+	}
+
+	// The unique index on open sessions catches a second terminal opening at the same time
+	if err := u.cashSessionRepo.CreateSession(session); err != nil {
+		logger.Log.Error("Failed to open cash session", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusConflict, "Failed to open cash session. You may already have one open")
+	}
+
+	return u.GetSession(session.ID)
+}
+
+// GetCurrentSession returns the drawer the user has open
+func (u *CashSessionUsecase) GetCurrentSession(userID, businessID string) (*contract.CashSessionRes, error) {
+	session, err := u.cashSessionRepo.GetOpenSessionByUserID(userID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get open cash session", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	if session == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "You have no open cash session")
+	}
+
+	return u.GetSession(session.ID)
+}
+
+// CreateMovement records cash put in or taken out of an open drawer.
+// The session is locked like in CloseSession, so no movement lands after the Z-report.
+func (u *CashSessionUsecase) CreateMovement(userID, sessionID string, req *contract.CreateCashMovementReq) (*contract.CashSessionRes, error) {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	session, err := u.cashSessionRepo.GetSessionForUpdate(tx, sessionID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get cash session", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	if session == nil {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusNotFound, "Cash session not found")
+	}
+
+	if session.Status != config.CASH_SESSION_STATUS_OPEN {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Cash session is already closed")
+	}
+
+	movement := &model.CashMovement{
+		CashSessionID: sessionID,
+		Type:          config.CashMovementType(req.Type),
+		Amount:        roundMoney(req.Amount),
+		Reason:        req.Reason,
+		CreatedBy:     userID,
+	}
+
+	if err := u.cashSessionRepo.CreateMovement(tx, movement); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create cash movement", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record cash movement")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit cash movement", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record cash movement")
+	}
+
+	return u.GetSession(sessionID)
+}
+
+// CloseSession counts the drawer, works out the variance and keeps the Z-report
+func (u *CashSessionUsecase) CloseSession(userID, sessionID string, req *contract.CloseCashSessionReq) (*contract.ZReportRes, error) {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	session, err := u.cashSessionRepo.GetSessionForUpdate(tx, sessionID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get cash session", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	if session == nil {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusNotFound, "Cash session not found")
+	}
+
+	if session.Status != config.CASH_SESSION_STATUS_OPEN {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Cash session is already closed")
+	}
+
+	totals, err := u.cashSessionRepo.GetSessionTotals(tx, sessionID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get cash session totals", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to close cash session")
+	}
+
+	report := buildZReport(*session, *totals)
+	counted := roundMoney(req.CountedCash)
+	variance := roundMoney(counted - report.ExpectedCash)
+	report.CountedCash = &counted
+	report.Variance = &variance
+
+	now := time.Now()
+	session.Status = config.CASH_SESSION_STATUS_CLOSED
+	session.ClosedBy = &userID
+	session.ClosedAt = &now
+	session.ClosingNote = req.Note
+	session.ExpectedCash = &report.ExpectedCash
+	session.CountedCash = &counted
+	session.Variance = &variance
+	session.ZReport = &report
+
+	if err := u.cashSessionRepo.UpdateSession(tx, session); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to close cash session", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to close cash session")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to close cash session")
+	}
+
+	return u.GetZReport(sessionID)
+}
+
+func (u *CashSessionUsecase) GetSession(sessionID string) (*contract.CashSessionRes, error) {
+	session, err := u.cashSessionRepo.GetSessionByID(sessionID)
+	if err != nil {
+		logger.Log.Error("Failed to get cash session", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	if session == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Cash session not found")
+	}
+
+	return util.ToPointer(buildCashSessionRes(*session)), nil
+}
+
+func (u *CashSessionUsecase) ListSessions(businessID string, openedBy *string, page, pageSize int) ([]contract.CashSessionRes, int64, error) {
+	sessions, total, err := u.cashSessionRepo.ListSessions(businessID, openedBy, page, pageSize)
+	if err != nil {
+		logger.Log.Error("Failed to list cash sessions", zap.Error(err), zap.String("businessID", businessID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list cash sessions")
+	}
+
+	results := make([]contract.CashSessionRes, len(sessions))
+	for i, session := range sessions {
+		results[i] = buildCashSessionRes(session)
+	}
+
+	return results, total, nil
+}
+
+// GetZReport returns the Z-report kept at closing, or the running totals while the session is open
+func (u *CashSessionUsecase) GetZReport(sessionID string) (*contract.ZReportRes, error) {
+	session, err := u.cashSessionRepo.GetSessionByID(sessionID)
+	if err != nil {
+		logger.Log.Error("Failed to get cash session", zap.Error(err), zap.String("sessionID", sessionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	if session == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Cash session not found")
+	}
+
+	report := session.ZReport
+	if report == nil {
+		totals, err := u.cashSessionRepo.GetSessionTotals(u.db, sessionID)
+		if err != nil {
+			logger.Log.Error("Failed to get cash session totals", zap.Error(err), zap.String("sessionID", sessionID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session report")
+		}
+		report = util.ToPointer(buildZReport(*session, *totals))
+	}
+
+	return util.ToPointer(buildZReportRes(*session, *report)), nil
+}
+
+func (u *CashSessionUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, sessionID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG || scope == config.PERMISSION_SCOPE_SELF {
+		if sessionID != nil {
+			session, err := u.cashSessionRepo.GetSessionByIDAndBusinessID(*sessionID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get cash session", zap.Error(err), zap.String("sessionID", *sessionID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+			}
+
+			if session == nil {
+				logger.Log.Warn("Cash session not found", zap.String("sessionID", *sessionID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+
+			// Cashiers only handle their own drawer
+			if scope == config.PERMISSION_SCOPE_SELF && session.OpenedBy != claims.ID {
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildZReport works out the expected cash of the drawer from the session totals
+func buildZReport(session model.CashSession, totals repository.CashSessionTotals) model.ZReport {
+	var cashPayments float64
+	for _, payment := range totals.Payments {
+		if payment.Method == config.PAYMENT_METHOD_CASH {
+			cashPayments += payment.Amount
+		}
+	}
+
+	// Credit sales bring no cash into the drawer until they are repaid
+	expected := session.OpeningFloat + cashPayments - totals.ChangeGiven - totals.CashRefunds + totals.CashIn - totals.CashOut + totals.CashCreditRepayments
+
+	payments := totals.Payments
+	if payments == nil {
+		payments = []model.ZReportPayment{}
+	}
+
+	return model.ZReport{
+		Transactions:         totals.Transactions,
+		GrossSales:           totals.GrossSales,
+		Discounts:            totals.Discounts,
+		Taxes:                totals.Taxes,
+		Rounding:             totals.Rounding,
+		NetSales:             totals.NetSales,
+		Payments:             payments,
+		ChangeGiven:          totals.ChangeGiven,
+		Refunds:              totals.Refunds,
+		CashRefunds:          totals.CashRefunds,
+		OpeningFloat:         session.OpeningFloat,
+		CashIn:               totals.CashIn,
+		CashOut:              totals.CashOut,
+		CreditRepayments:     totals.CreditRepayments,
+		CashCreditRepayments: totals.CashCreditRepayments,
+		ExpectedCash:         roundMoney(expected),
+	}
+}
+
+// buildZReportRes builds Z-report response
+func buildZReportRes(session model.CashSession, report model.ZReport) contract.ZReportRes {
+	payments := make([]contract.ZReportPaymentRes, len(report.Payments))
+	for i, payment := range report.Payments {
+		payments[i] = contract.ZReportPaymentRes{
+			Method: string(payment.Method),
+			Count:  payment.Count,
+			Amount: payment.Amount,
+		}
+	}
+
+	var closedAt *string
+	if session.ClosedAt != nil {
+		closedAt = util.ToPointer(session.ClosedAt.Format(time.RFC3339))
+	}
+
+	return contract.ZReportRes{
+		SessionID:            session.ID,
+		OpenedBy:             session.OpenedBy,
+		OpenerName:           session.Opener.Name,
+		ClosedBy:             session.ClosedBy,
+		OpenedAt:             session.OpenedAt.Format(time.RFC3339),
+		ClosedAt:             closedAt,
+		Transactions:         report.Transactions,
+		GrossSales:           report.GrossSales,
+		Discounts:            report.Discounts,
+		Taxes:                report.Taxes,
+		Rounding:             report.Rounding,
+		NetSales:             report.NetSales,
+		Payments:             payments,
+		ChangeGiven:          report.ChangeGiven,
+		Refunds:              report.Refunds,
+		CashRefunds:          report.CashRefunds,
+		OpeningFloat:         report.OpeningFloat,
+		CashIn:               report.CashIn,
+		CashOut:              report.CashOut,
+		CreditRepayments:     report.CreditRepayments,
+		CashCreditRepayments: report.CashCreditRepayments,
+		ExpectedCash:         report.ExpectedCash,
+		CountedCash:          report.CountedCash,
+		Variance:             report.Variance,
+	}
+}
+
+// buildCashSessionRes builds cash session response
+func buildCashSessionRes(session model.CashSession) contract.CashSessionRes {
+	movements := make([]contract.CashMovementRes, len(session.Movements))
+	for i, movement := range session.Movements {
+		movements[i] = contract.CashMovementRes{
+			ID:        movement.ID,
+			Type:      string(movement.Type),
+			Amount:    movement.Amount,
+			Reason:    movement.Reason,
+			CreatedBy: movement.CreatedBy,
+			CreatedAt: movement.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	var closerName *string
+	if session.Closer != nil {
+		closerName = &session.Closer.Name
+	}
+
+	var closedAt *string
+	if session.ClosedAt != nil {
+		closedAt = util.ToPointer(session.ClosedAt.Format(time.RFC3339))
+	}
+
+	return contract.CashSessionRes{
+		ID:           session.ID,
+		BusinessID:   session.BusinessID,
+		Status:       string(session.Status),
+		OpenedBy:     session.OpenedBy,
+		OpenerName:   session.Opener.Name,
+		ClosedBy:     session.ClosedBy,
+		CloserName:   closerName,
+		OpeningFloat: session.OpeningFloat,
+		ExpectedCash: session.ExpectedCash,
+		CountedCash:  session.CountedCash,
+		Variance:     session.Variance,
+		OpeningNote:  session.OpeningNote,
+		ClosingNote:  session.ClosingNote,
+		OpenedAt:     session.OpenedAt.Format(time.RFC3339),
+		ClosedAt:     closedAt,
+		Movements:    movements,
+	}
+}
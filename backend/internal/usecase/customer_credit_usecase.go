@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultCreditReminderTemplate is used when the business has not written its own reminder
+const defaultCreditReminderTemplate = "Hi {customer}, this is a friendly reminder from {business} that you have an outstanding balance of {amount} since {since}. Thank you!"
+
+type CustomerCreditUsecase struct {
+	customerRepo      *repository.CustomerRepository
+	businessRepo      *repository.BusinessRepository
+	cashSessionRepo   *repository.CashSessionRepository
+	messagingProvider MessagingProvider
+	db                *gorm.DB
+}
+
+func NewCustomerCreditUsecase(
+	customerRepo *repository.CustomerRepository,
+	businessRepo *repository.BusinessRepository,
+	cashSessionRepo *repository.CashSessionRepository,
+	messagingProvider MessagingProvider,
+	db *gorm.DB,
+) *CustomerCreditUsecase {
+	return &CustomerCreditUsecase{
+		customerRepo:      customerRepo,
+		businessRepo:      businessRepo,
+		cashSessionRepo:   cashSessionRepo,
+		messagingProvider: messagingProvider,
+		db:                db,
+	}
+}
+
+// GetCustomerCredit returns the outstanding balance of a customer with the unpaid credit sales
+func (u *CustomerCreditUsecase) GetCustomerCredit(customerID string) (*contract.CustomerCreditRes, error) {
+	customer, err := u.customerRepo.GetCustomerByID(customerID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	if customer == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Customer not found")
+	}
+
+	transactions, err := u.customerRepo.ListOutstandingTransactions(u.db, customer.ID, false)
+	if err != nil {
+		logger.Log.Error("Failed to list outstanding transactions", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get outstanding balance")
+	}
+
+	return util.ToPointer(buildCustomerCreditRes(*customer, transactions, time.Now())), nil
+}
+
+// CreateRepayment records a repayment of a customer's credit sales. The amount pays off the oldest sales first,
+// each sale it touches gets a payment of its own.
+func (u *CustomerCreditUsecase) CreateRepayment(userID, businessID, customerID string, req *contract.CreateCreditRepaymentReq) (*contract.CreditRepaymentRes, error) {
+	amount := roundMoney(req.Amount)
+
+	// Cash repayments go into the drawer the employee has open
+	cashSession, err := u.cashSessionRepo.GetOpenSessionByUserID(userID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get open cash session", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	customer, err := u.customerRepo.GetCustomerForUpdate(tx, customerID, businessID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	if customer == nil {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusNotFound, "Customer not found")
+	}
+
+	transactions, err := u.customerRepo.ListOutstandingTransactions(tx, customer.ID, true)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to list outstanding transactions", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record repayment")
+	}
+
+	var outstanding float64
+	for _, transaction := range transactions {
+		outstanding += transaction.OutstandingAmount
+	}
+	outstanding = roundMoney(outstanding)
+
+	if outstanding == 0 {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Customer has no outstanding balance")
+	}
+
+	if amount > outstanding {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Repayment exceeds the outstanding balance. Outstanding: %.2f, Repayment: %.2f", outstanding, amount))
+	}
+
+	now := time.Now()
+	method := config.PaymentMethod(req.Method)
+	remaining := amount
+	payments := make([]model.TransactionPayment, 0, len(transactions))
+	for i := range transactions {
+		if remaining <= 0 {
+			break
+		}
+
+		transaction := &transactions[i]
+		applied := math.Min(remaining, transaction.OutstandingAmount)
+		transaction.OutstandingAmount = roundMoney(transaction.OutstandingAmount - applied)
+		remaining = roundMoney(remaining - applied)
+
+		if err := tx.Model(&model.Transaction{}).Where("id = ?", transaction.ID).Update("outstanding_amount", transaction.OutstandingAmount).Error; err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to update outstanding amount", zap.Error(err), zap.String("transactionID", transaction.ID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record repayment")
+		}
+
+		payment := model.TransactionPayment{
+			TransactionID:     transaction.ID,
+			Method:            method,
+			Amount:            applied,
+			Reference:         req.Reference,
+			PaidAt:            now,
+			IsCreditRepayment: true,
+			CreatedBy:         &userID,
+		}
+		if cashSession != nil {
+			payment.CashSessionID = &cashSession.ID
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := u.customerRepo.CreateRepayments(tx, payments); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create repayments", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record repayment")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit repayment", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record repayment")
+	}
+
+	invoiceNumbers := make(map[string]string, len(transactions))
+	outstandingAmounts := make(map[string]float64, len(transactions))
+	for _, transaction := range transactions {
+		invoiceNumbers[transaction.ID] = transaction.InvoiceNumber
+		outstandingAmounts[transaction.ID] = transaction.OutstandingAmount
+	}
+
+	lines := make([]contract.CreditRepaymentLineRes, len(payments))
+	for i, payment := range payments {
+		lines[i] = contract.CreditRepaymentLineRes{
+			PaymentID:         payment.ID,
+			TransactionID:     payment.TransactionID,
+			InvoiceNumber:     invoiceNumbers[payment.TransactionID],
+			Amount:            payment.Amount,
+			OutstandingAmount: outstandingAmounts[payment.TransactionID],
+		}
+	}
+
+	// Sales that are paid off drop out of the balance
+	var stillOutstanding []model.Transaction
+	for _, transaction := range transactions {
+		if transaction.OutstandingAmount > 0 {
+			stillOutstanding = append(stillOutstanding, transaction)
+		}
+	}
+
+	return &contract.CreditRepaymentRes{
+		Method:    string(method),
+		Amount:    amount,
+		Reference: req.Reference,
+		PaidAt:    now.Format(time.RFC3339),
+		Lines:     lines,
+		Credit:    buildCustomerCreditRes(*customer, stillOutstanding, now),
+	}, nil
+}
+
+// GetCreditAging lists what every customer still owes, split by the age of the credit sales
+func (u *CustomerCreditUsecase) GetCreditAging(businessID string) (*contract.CreditAgingRes, error) {
+	now := time.Now()
+	agingLines, err := u.customerRepo.GetCreditAging(businessID, now)
+	if err != nil {
+		logger.Log.Error("Failed to get credit aging", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get credit aging report")
+	}
+
+	var totals contract.CreditAgingTotalsRes
+	lines := make([]contract.CreditAgingLineRes, len(agingLines))
+	for i, line := range agingLines {
+		lines[i] = contract.CreditAgingLineRes{
+			CustomerID:   line.CustomerID,
+			CustomerName: line.CustomerName,
+			Phone:        line.Phone,
+			Current:      roundMoney(line.Current),
+			Days31To60:   roundMoney(line.Days31To60),
+			Days61To90:   roundMoney(line.Days61To90),
+			Over90Days:   roundMoney(line.Over90Days),
+			Total:        roundMoney(line.Total),
+			OldestAt:     line.OldestAt.Format(time.RFC3339),
+		}
+
+		totals.Current += line.Current
+		totals.Days31To60 += line.Days31To60
+		totals.Days61To90 += line.Days61To90
+		totals.Over90Days += line.Over90Days
+		totals.Total += line.Total
+	}
+
+	totals.Current = roundMoney(totals.Current)
+	totals.Days31To60 = roundMoney(totals.Days31To60)
+	totals.Days61To90 = roundMoney(totals.Days61To90)
+	totals.Over90Days = roundMoney(totals.Over90Days)
+	totals.Total = roundMoney(totals.Total)
+
+	return &contract.CreditAgingRes{
+		AsOf:   now.Format(time.RFC3339),
+		Lines:  lines,
+		Totals: totals,
+	}, nil
+}
+
+// GetCreditReminder fills in the business reminder template for a customer with an outstanding balance
+func (u *CustomerCreditUsecase) GetCreditReminder(businessID, customerID string) (*contract.CreditReminderRes, error) {
+	customer, err := u.customerRepo.GetCustomerByID(customerID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	if customer == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Customer not found")
+	}
+
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil || business == nil {
+		logger.Log.Error("Failed to get business", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	transactions, err := u.customerRepo.ListOutstandingTransactions(u.db, customer.ID, false)
+	if err != nil {
+		logger.Log.Error("Failed to list outstanding transactions", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get outstanding balance")
+	}
+
+	if len(transactions) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Customer has no outstanding balance")
+	}
+
+	credit := buildCustomerCreditRes(*customer, transactions, time.Now())
+	message := renderCreditReminder(*business, *customer, credit.OutstandingBalance, transactions[0])
+
+	res := &contract.CreditReminderRes{
+		CustomerID:         customer.ID,
+		Phone:              customer.Phone,
+		OutstandingBalance: credit.OutstandingBalance,
+		Message:            message,
+	}
+	if customer.Phone != nil {
+		// wa.me reads spaces as %20, not as the + of query encoding
+		text := strings.ReplaceAll(url.QueryEscape(message), "+", "%20")
+		res.WhatsappURL = util.ToPointer(fmt.Sprintf("https://wa.me/%s?text=%s", *customer.Phone, text))
+	}
+
+	return res, nil
+}
+
+// SendCreditReminder sends the reminder to the customer over WhatsApp
+func (u *CustomerCreditUsecase) SendCreditReminder(businessID, customerID string) (*contract.CreditReminderRes, error) {
+	res, err := u.GetCreditReminder(businessID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Phone == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Customer has no phone number")
+	}
+
+	messageID, err := u.messagingProvider.SendWhatsApp(WhatsAppMessage{
+		To:   *res.Phone,
+		Body: res.Message,
+	})
+	if err != nil {
+		logger.Log.Error("Failed to send credit reminder", zap.Error(err), zap.String("customerID", customerID), zap.String("provider", u.messagingProvider.Name()))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to send reminder")
+	}
+
+	res.ProviderMessageID = &messageID
+	return res, nil
+}
+
+func (u *CustomerCreditUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, customerID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if customerID != nil {
+			customer, err := u.customerRepo.GetCustomerByIDAndBusinessID(*customerID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *customerID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+			}
+
+			if customer == nil {
+				logger.Log.Warn("Customer not found", zap.String("customerID", *customerID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderCreditReminder fills in {customer}, {business}, {amount} and {since} of the reminder template
+func renderCreditReminder(business model.Business, customer model.Customer, balance float64, oldest model.Transaction) string {
+	template := defaultCreditReminderTemplate
+	if business.CreditReminderTemplate != nil && strings.TrimSpace(*business.CreditReminderTemplate) != "" {
+		template = *business.CreditReminderTemplate
+	}
+
+	since := oldest.CreatedAt
+	if oldest.PaidAt != nil {
+		since = *oldest.PaidAt
+	}
+
+	return strings.NewReplacer(
+		"{customer}", customer.Name,
+		"{business}", business.Name,
+		"{amount}", formatMoney(balance),
+		"{since}", since.Format("02 Jan 2006"),
+	).Replace(template)
+}
+
+// buildCustomerCreditRes builds the credit balance of a customer from the unpaid credit sales
+func buildCustomerCreditRes(customer model.Customer, transactions []model.Transaction, now time.Time) contract.CustomerCreditRes {
+	var outstanding float64
+	results := make([]contract.CreditTransactionRes, len(transactions))
+	for i, transaction := range transactions {
+		outstanding += transaction.OutstandingAmount
+
+		soldAt := transaction.CreatedAt
+		if transaction.PaidAt != nil {
+			soldAt = *transaction.PaidAt
+		}
+
+		results[i] = contract.CreditTransactionRes{
+			TransactionID:     transaction.ID,
+			InvoiceNumber:     transaction.InvoiceNumber,
+			PaidAt:            soldAt.Format(time.RFC3339),
+			TotalAmount:       transaction.TotalAmount,
+			CreditAmount:      transaction.CreditAmount,
+			OutstandingAmount: transaction.OutstandingAmount,
+			AgeDays:           int(now.Sub(soldAt).Hours() / 24),
+		}
+	}
+	outstanding = roundMoney(outstanding)
+
+	return contract.CustomerCreditRes{
+		CustomerID:         customer.ID,
+		CustomerName:       customer.Name,
+		Phone:              customer.Phone,
+		CreditLimit:        customer.CreditLimit,
+		OutstandingBalance: outstanding,
+		AvailableCredit:    max(roundMoney(customer.CreditLimit-outstanding), 0),
+		Transactions:       results,
+	}
+}
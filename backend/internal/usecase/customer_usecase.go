@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type CustomerUsecase struct {
+	customerRepo    *repository.CustomerRepository
+	transactionRepo *repository.TransactionRepository
+	loyaltyRepo     *repository.LoyaltyRepository
+}
+
+func NewCustomerUsecase(
+	customerRepo *repository.CustomerRepository,
+	transactionRepo *repository.TransactionRepository,
+	loyaltyRepo *repository.LoyaltyRepository,
+) *CustomerUsecase {
+	return &CustomerUsecase{
+		customerRepo:    customerRepo,
+		transactionRepo: transactionRepo,
+		loyaltyRepo:     loyaltyRepo,
+	}
+}
+
+func (u *CustomerUsecase) CreateCustomer(businessID string, req *contract.CreateCustomerReq) (*contract.CustomerRes, error) {
+	customer := &model.Customer{
+		BusinessID: businessID,
+		Name:       strings.TrimSpace(req.Name),
+		Email:      normalizeOptionalString(req.Email),
+		Notes:      normalizeOptionalString(req.Notes),
+		Tags:       normalizeTags(req.Tags),
+	}
+	if req.CreditLimit != nil {
+		customer.CreditLimit = roundMoney(*req.CreditLimit)
+	}
+	if customer.Name == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Name is required")
+	}
+
+	phone, err := u.validatePhone(businessID, "", req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	customer.Phone = phone
+
+	if err := u.customerRepo.CreateCustomer(customer); err != nil {
+		logger.Log.Error("Failed to create customer", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create customer")
+	}
+
+	return util.ToPointer(buildCustomerRes(customer)), nil
+}
+
+func (u *CustomerUsecase) UpdateCustomer(customerID string, req *contract.UpdateCustomerReq) (*contract.CustomerRes, error) {
+	customer, err := u.customerRepo.GetCustomerByID(customerID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	if customer == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Customer not found")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Name is required")
+		}
+		customer.Name = name
+	}
+	if req.Phone != nil {
+		phone, err := u.validatePhone(customer.BusinessID, customer.ID, req.Phone)
+		if err != nil {
+			return nil, err
+		}
+		customer.Phone = phone
+	}
+	if req.Email != nil {
+		customer.Email = normalizeOptionalString(req.Email)
+		if customer.Email != nil {
+			if err := util.Validator().Var(*customer.Email, "email"); err != nil {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "Email is not valid")
+			}
+		}
+	}
+	if req.Notes != nil {
+		customer.Notes = normalizeOptionalString(req.Notes)
+	}
+	if req.Tags != nil {
+		customer.Tags = normalizeTags(req.Tags)
+	}
+	// Lowering the limit below the current balance only blocks new credit sales
+	if req.CreditLimit != nil {
+		customer.CreditLimit = roundMoney(*req.CreditLimit)
+	}
+	customer.UpdatedAt = time.Now()
+
+	if err := u.customerRepo.UpdateCustomer(customer); err != nil {
+		logger.Log.Error("Failed to update customer", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update customer")
+	}
+
+	return util.ToPointer(buildCustomerRes(customer)), nil
+}
+
+// GetCustomer returns the customer with lifetime spend, visit count and last visit
+func (u *CustomerUsecase) GetCustomer(customerID string) (*contract.CustomerRes, error) {
+	customer, err := u.customerRepo.GetCustomerByID(customerID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	if customer == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Customer not found")
+	}
+
+	stats, err := u.customerRepo.GetCustomerStats(customer.ID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer stats", zap.Error(err), zap.String("customerID", customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	res := buildCustomerRes(customer)
+	res.Stats = &contract.CustomerStatsRes{
+		LifetimeSpend:      roundMoney(stats.LifetimeSpend),
+		VisitCount:         stats.VisitCount,
+		OutstandingBalance: roundMoney(stats.OutstandingBalance),
+	}
+	if stats.LastVisitAt != nil {
+		res.Stats.LastVisitAt = util.ToPointer(stats.LastVisitAt.Format(time.RFC3339))
+	}
+
+	return &res, nil
+}
+
+// ListCustomers searches by name, email or phone, optionally limited to a tag
+func (u *CustomerUsecase) ListCustomers(businessID string, page, pageSize int, search, tag string) ([]contract.CustomerRes, int64, error) {
+	search = strings.TrimSpace(search)
+
+	// Phone numbers are stored normalized, so 0812-3456 also finds 628123456...
+	var phoneSearch string
+	digits := strings.NewReplacer(" ", "", "-", "", "+", "").Replace(search)
+	if digits != "" && strings.Trim(digits, "0123456789") == "" {
+		phoneSearch = digits
+		if strings.HasPrefix(phoneSearch, "0") {
+			phoneSearch = "62" + phoneSearch[1:]
+		}
+	}
+
+	customers, total, err := u.customerRepo.ListCustomers(businessID, page, pageSize, search, phoneSearch, strings.TrimSpace(tag))
+	if err != nil {
+		logger.Log.Error("Failed to list customers", zap.Error(err), zap.String("businessID", businessID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list customers")
+	}
+
+	results := make([]contract.CustomerRes, len(customers))
+	for i := range customers {
+		results[i] = buildCustomerRes(&customers[i])
+	}
+
+	return results, total, nil
+}
+
+// ListCustomerTransactions returns the purchase history of a customer
+func (u *CustomerUsecase) ListCustomerTransactions(customerID string, page, pageSize int) ([]contract.TransactionRes, int64, error) {
+	transactions, total, err := u.transactionRepo.ListTransactionsByCustomerID(customerID, page, pageSize)
+	if err != nil {
+		logger.Log.Error("Failed to list customer transactions", zap.Error(err), zap.String("customerID", customerID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list transactions")
+	}
+
+	results := make([]contract.TransactionRes, len(transactions))
+	for i, transaction := range transactions {
+		results[i] = buildTransactionRes(transaction)
+	}
+
+	return results, total, nil
+}
+
+// ListCustomerPoints returns the points ledger of a customer, newest first
+func (u *CustomerUsecase) ListCustomerPoints(customerID string, page, pageSize int) ([]contract.LoyaltyPointEntryRes, int64, error) {
+	entries, total, err := u.loyaltyRepo.ListEntriesByCustomerID(customerID, page, pageSize)
+	if err != nil {
+		logger.Log.Error("Failed to list loyalty point entries", zap.Error(err), zap.String("customerID", customerID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list loyalty points")
+	}
+
+	results := make([]contract.LoyaltyPointEntryRes, len(entries))
+	for i, entry := range entries {
+		results[i] = buildLoyaltyPointEntryRes(entry)
+	}
+
+	return results, total, nil
+}
+
+// DeleteCustomer removes the customer, past transactions keep their data without the link
+func (u *CustomerUsecase) DeleteCustomer(customerID string) error {
+	if err := u.customerRepo.DeleteCustomer(customerID); err != nil {
+		logger.Log.Error("Failed to delete customer", zap.Error(err), zap.String("customerID", customerID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete customer")
+	}
+
+	return nil
+}
+
+func (u *CustomerUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, customerID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if customerID != nil {
+			customer, err := u.customerRepo.GetCustomerByIDAndBusinessID(*customerID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *customerID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+			}
+
+			if customer == nil {
+				logger.Log.Warn("Customer not found", zap.String("customerID", *customerID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePhone normalizes the phone number and makes sure no other customer of the business uses it.
+// An empty phone clears it.
+func (u *CustomerUsecase) validatePhone(businessID, customerID string, phone *string) (*string, error) {
+	if phone == nil || strings.TrimSpace(*phone) == "" {
+		return nil, nil
+	}
+
+	number, ok := util.NormalizePhoneNumber(*phone)
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Phone number is not valid")
+	}
+
+	existing, err := u.customerRepo.GetCustomerByPhone(businessID, number)
+	if err != nil {
+		logger.Log.Error("Failed to get customer by phone", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+	if existing != nil && existing.ID != customerID {
+		return nil, fiber.NewError(fiber.StatusConflict, "Another customer already uses this phone number")
+	}
+
+	return &number, nil
+}
+
+// normalizeOptionalString trims the value, an empty value becomes nil
+func normalizeOptionalString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// normalizeTags trims tags and drops empty and duplicate ones, keeping the given order
+func normalizeTags(tags []string) []string {
+	results := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		results = append(results, tag)
+	}
+	return results
+}
+
+// buildCustomerRes builds customer response
+func buildCustomerRes(customer *model.Customer) contract.CustomerRes {
+	tags := customer.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return contract.CustomerRes{
+		ID:            customer.ID,
+		BusinessID:    customer.BusinessID,
+		Name:          customer.Name,
+		Phone:         customer.Phone,
+		Email:         customer.Email,
+		Notes:         customer.Notes,
+		Tags:          tags,
+		CreditLimit:   customer.CreditLimit,
+		PointsBalance: customer.PointsBalance,
+		CreatedAt:     customer.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     customer.UpdatedAt.Format(time.RFC3339),
+	}
+}
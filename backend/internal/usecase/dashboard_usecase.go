@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"app/internal/config"
 	"app/internal/contract"
+	"app/internal/middleware"
 	"app/internal/model"
 	"app/internal/repository"
 	"app/pkg/logger"
@@ -96,9 +98,16 @@ func (u *DashboardUsecase) GetDashboardSummary(businessID string) (*contract.Das
 	// Build response
 	return &contract.DashboardSummaryRes{
 		Today: contract.DayStatsRes{
-			Sales:        todayStats.Sales,
-			Transactions: todayStats.Transactions,
-			Profit:       todayStats.Profit,
+			Sales:            todayStats.Sales,
+			Transactions:     todayStats.Transactions,
+			Profit:           todayStats.Profit,
+			Refunds:          todayStats.Refunds,
+			Discounts:        todayStats.Discounts,
+			Taxes:            todayStats.Taxes,
+			Rounding:         todayStats.Rounding,
+			CreditSales:      todayStats.CreditSales,
+			CreditRepayments: todayStats.CreditRepayments,
+			CashReceived:     todayStats.CashReceived,
 			CompareYesterday: &contract.ComparisonRes{
 				SalesPercent:        calculatePercentChange(yesterdayStats.Sales, todayStats.Sales),
 				TransactionsPercent: calculatePercentChange(float64(yesterdayStats.Transactions), float64(todayStats.Transactions)),
@@ -106,9 +115,16 @@ func (u *DashboardUsecase) GetDashboardSummary(businessID string) (*contract.Das
 			},
 		},
 		ThisWeek: contract.WeekStatsRes{
-			Sales:        thisWeekStats.Sales,
-			Transactions: thisWeekStats.Transactions,
-			Profit:       thisWeekStats.Profit,
+			Sales:            thisWeekStats.Sales,
+			Transactions:     thisWeekStats.Transactions,
+			Profit:           thisWeekStats.Profit,
+			Refunds:          thisWeekStats.Refunds,
+			Discounts:        thisWeekStats.Discounts,
+			Taxes:            thisWeekStats.Taxes,
+			Rounding:         thisWeekStats.Rounding,
+			CreditSales:      thisWeekStats.CreditSales,
+			CreditRepayments: thisWeekStats.CreditRepayments,
+			CashReceived:     thisWeekStats.CashReceived,
 			CompareLastWeek: &contract.ComparisonRes{
 				SalesPercent:        calculatePercentChange(lastWeekStats.Sales, thisWeekStats.Sales),
 				TransactionsPercent: calculatePercentChange(float64(lastWeekStats.Transactions), float64(thisWeekStats.Transactions)),
@@ -120,6 +136,124 @@ func (u *DashboardUsecase) GetDashboardSummary(businessID string) (*contract.Das
 	}, nil
 }
 
+// GetTaxReport returns the taxes and service charges collected per rule.
+// The period defaults to the current month up to today.
+func (u *DashboardUsecase) GetTaxReport(businessID string, query *contract.PeriodReportQuery) (*contract.TaxReportRes, error) {
+	start, end, err := parseReportPeriod(query)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := u.dashboardRepo.GetTaxReport(businessID, start, end)
+	if err != nil {
+		logger.Log.Error("Failed to get tax report", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get tax report")
+	}
+
+	res := &contract.TaxReportRes{
+		StartDate: start.Format(time.DateOnly),
+		EndDate:   end.Add(-24 * time.Hour).Format(time.DateOnly),
+		Lines:     make([]contract.TaxReportLineRes, len(lines)),
+	}
+	for i, line := range lines {
+		res.Lines[i] = contract.TaxReportLineRes{
+			TaxRuleID:     line.TaxRuleID,
+			Name:          line.Name,
+			Kind:          string(line.Kind),
+			Rate:          line.Rate,
+			IsInclusive:   line.IsInclusive,
+			Transactions:  line.Transactions,
+			TaxableAmount: line.TaxableAmount,
+			Amount:        line.Amount,
+		}
+
+		if line.Kind == config.TAX_KIND_SERVICE_CHARGE {
+			res.TotalServiceCharge += line.Amount
+		} else {
+			res.TotalTax += line.Amount
+		}
+	}
+	res.TotalTax = roundMoney(res.TotalTax)
+	res.TotalServiceCharge = roundMoney(res.TotalServiceCharge)
+
+	return res, nil
+}
+
+// GetPromotionReport returns how much each promotion cost in discounts.
+// The period defaults to the current month up to today.
+func (u *DashboardUsecase) GetPromotionReport(businessID string, query *contract.PeriodReportQuery) (*contract.PromotionReportRes, error) {
+	start, end, err := parseReportPeriod(query)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := u.dashboardRepo.GetPromotionReport(businessID, start, end)
+	if err != nil {
+		logger.Log.Error("Failed to get promotion report", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get promotion report")
+	}
+
+	res := &contract.PromotionReportRes{
+		StartDate: start.Format(time.DateOnly),
+		EndDate:   end.Add(-24 * time.Hour).Format(time.DateOnly),
+		Lines:     make([]contract.PromotionReportLineRes, len(lines)),
+	}
+	for i, line := range lines {
+		res.Lines[i] = contract.PromotionReportLineRes{
+			PromotionID:  line.PromotionID,
+			Name:         line.Name,
+			Type:         string(line.Type),
+			Transactions: line.Transactions,
+			Quantity:     line.Quantity,
+			Amount:       line.Amount,
+		}
+		res.TotalAmount += line.Amount
+	}
+	res.TotalAmount = roundMoney(res.TotalAmount)
+
+	return res, nil
+}
+
+// parseReportPeriod turns the inclusive report dates into a [start, end) time range
+func parseReportPeriod(query *contract.PeriodReportQuery) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if query.StartDate != "" {
+		date, err := time.ParseInLocation(time.DateOnly, query.StartDate, now.Location())
+		if err != nil {
+			return start, end, fiber.NewError(fiber.StatusBadRequest, "Invalid start date")
+		}
+		start = date
+	}
+
+	if query.EndDate != "" {
+		date, err := time.ParseInLocation(time.DateOnly, query.EndDate, now.Location())
+		if err != nil {
+			return start, end, fiber.NewError(fiber.StatusBadRequest, "Invalid end date")
+		}
+		end = date
+	}
+
+	if end.Before(start) {
+		return start, end, fiber.NewError(fiber.StatusBadRequest, "End date must not be before start date")
+	}
+
+	return start, end.Add(24 * time.Hour), nil
+}
+
+// IsAllowedToAccess checks if user has permission to read a report, reports are always scoped to the user's business
+func (u *DashboardUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	return nil
+}
+
 // calculatePercentChange calculates percentage change between two values
 func calculatePercentChange(oldValue, newValue float64) float64 {
 	if oldValue == 0 {
@@ -135,39 +269,7 @@ func calculatePercentChange(oldValue, newValue float64) float64 {
 func buildTransactionList(transactions []model.Transaction) []contract.TransactionRes {
 	results := make([]contract.TransactionRes, len(transactions))
 	for i, transaction := range transactions {
-		items := make([]contract.TransactionItemRes, len(transaction.Items))
-		for j, item := range transaction.Items {
-			items[j] = contract.TransactionItemRes{
-				ID:          item.ID,
-				ProductID:   item.ProductID,
-				ProductName: item.ProductName,
-				Price:       item.Price,
-				Quantity:    item.Quantity,
-				Subtotal:    item.Subtotal,
-			}
-		}
-
-		var paidAtStr *string
-		if transaction.PaidAt != nil {
-			str := transaction.PaidAt.Format(time.RFC3339)
-			paidAtStr = &str
-		}
-
-		results[i] = contract.TransactionRes{
-			ID:             transaction.ID,
-			BusinessID:     transaction.BusinessID,
-			InvoiceNumber:  transaction.InvoiceNumber,
-			CreatedBy:      transaction.CreatedBy,
-			CreatorName:    transaction.Creator.Name,
-			TotalAmount:    transaction.TotalAmount,
-			ReceivedAmount: transaction.ReceivedAmount,
-			ChangeAmount:   transaction.ChangeAmount,
-			Status:         string(transaction.Status),
-			PaidAt:         paidAtStr,
-			ExpiredAt:      transaction.ExpiredAt.Format(time.RFC3339),
-			CreatedAt:      transaction.CreatedAt.Format(time.RFC3339),
-			Items:          items,
-		}
+		results[i] = buildTransactionRes(transaction)
 	}
 	return results
 }
@@ -177,6 +279,10 @@ func buildProductList(productSales []repository.ProductSales, storage *storage.R
 	results := make([]contract.ProductRes, len(productSales))
 	for i, ps := range productSales {
 		results[i] = buildProductRes(ps.Product, ps.QuantitySold, storage)
+		results[i].Variants = make([]contract.ProductVariantRes, len(ps.Variants))
+		for j, vs := range ps.Variants {
+			results[i].Variants[j] = buildProductVariantRes(vs.Variant, vs.QuantitySold)
+		}
 	}
 	return results
 }
@@ -221,6 +327,7 @@ func buildProductRes(product model.Product, quantitySold int, storage *storage.R
 		BarcodeValue:  product.BarcodeValue,
 		BarcodeType:   util.ToPointer(string(util.ToValue(product.BarcodeType))),
 		Cost:          product.Cost,
+		HasVariants:   product.HasVariants,
 		CreatedAt:     product.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:     product.UpdatedAt.Format(time.RFC3339),
 	}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakePaymentProvider settles nothing by itself, payments are simulated by posting a webhook.
+// It is meant for local development and tests, charges only live in memory.
+type FakePaymentProvider struct {
+	callbackToken string
+	mu            sync.Mutex
+	charges       map[string]PaymentChargeResult
+}
+
+// fakeWebhookReq is the callback body accepted by the fake provider
+type fakeWebhookReq struct {
+	Reference  string   `json:"reference"`
+	Status     string   `json:"status"`
+	Method     *string  `json:"method"`
+	PaidAmount *float64 `json:"paidAmount"`
+}
+
+func NewFakePaymentProvider(callbackToken string) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		callbackToken: callbackToken,
+		charges:       make(map[string]PaymentChargeResult),
+	}
+}
+
+func (p *FakePaymentProvider) Name() string {
+	return string(config.PAYMENT_PROVIDER_FAKE)
+}
+
+func (p *FakePaymentProvider) CreateCharge(req PaymentChargeReq) (*PaymentChargeResult, error) {
+	result := PaymentChargeResult{
+		Reference: "fake-" + req.ExternalID,
+		Status:    config.PAYMENT_CHARGE_STATUS_PENDING,
+		ExpiresAt: &req.ExpiresAt,
+	}
+
+	p.mu.Lock()
+	p.charges[result.Reference] = result
+	p.mu.Unlock()
+
+	return &result, nil
+}
+
+func (p *FakePaymentProvider) GetChargeStatus(reference string) (*PaymentChargeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, ok := p.charges[reference]
+	if !ok {
+		return nil, fmt.Errorf("fake charge %s not found", reference)
+	}
+	return &result, nil
+}
+
+// VerifyWebhook accepts callbacks carrying the configured token in X-Callback-Token
+func (p *FakePaymentProvider) VerifyWebhook(header http.Header, body []byte) (*PaymentChargeResult, error) {
+	token := header.Get(xenditCallbackTokenHeader)
+	if p.callbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.callbackToken)) != 1 {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var req fakeWebhookReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	result := PaymentChargeResult{
+		Reference:  req.Reference,
+		Status:     config.PaymentChargeStatus(req.Status),
+		PaidAmount: req.PaidAmount,
+	}
+	if req.Method != nil {
+		method := config.PaymentMethod(*req.Method)
+		if !isGatewayPaymentMethod(method) {
+			return nil, fmt.Errorf("payment method %s cannot be settled by a gateway", method)
+		}
+		result.Method = &method
+	}
+	if result.Status == config.PAYMENT_CHARGE_STATUS_PAID {
+		now := time.Now()
+		result.PaidAt = &now
+	}
+
+	p.mu.Lock()
+	if _, ok := p.charges[result.Reference]; ok {
+		p.charges[result.Reference] = result
+	}
+	p.mu.Unlock()
+
+	return &result, nil
+}
@@ -0,0 +1,535 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type GiftCardUsecase struct {
+	giftCardRepo *repository.GiftCardRepository
+	db           *gorm.DB
+}
+
+func NewGiftCardUsecase(giftCardRepo *repository.GiftCardRepository, db *gorm.DB) *GiftCardUsecase {
+	return &GiftCardUsecase{
+		giftCardRepo: giftCardRepo,
+		db:           db,
+	}
+}
+
+// CreateGiftCard issues one gift card with a chosen or generated code
+func (u *GiftCardUsecase) CreateGiftCard(userID, businessID string, req *contract.CreateGiftCardReq) (*contract.GiftCardRes, error) {
+	var code string
+	if req.Code != nil {
+		code = util.NormalizeCode(*req.Code)
+		existing, err := u.giftCardRepo.FindExistingCodes(businessID, []string{code})
+		if err != nil {
+			logger.Log.Error("Failed to check gift card code", zap.Error(err), zap.String("businessID", businessID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create gift card")
+		}
+		if len(existing) > 0 {
+			return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Gift card code %s already exists", code))
+		}
+	} else {
+		codes, err := generateUniqueCodes("", 1, func(codes []string) ([]string, error) {
+			return u.giftCardRepo.FindExistingCodes(businessID, codes)
+		})
+		if err != nil {
+			logger.Log.Error("Failed to generate gift card code", zap.Error(err), zap.String("businessID", businessID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create gift card")
+		}
+		code = codes[0]
+	}
+
+	expiresAt, err := parseScheduleTime(req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	giftCards, err := u.issueGiftCards(userID, businessID, []string{code}, nil, roundMoney(req.Balance), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.ToPointer(buildGiftCardRes(giftCards[0])), nil
+}
+
+// GenerateGiftCards issues a batch of gift cards with random codes that can be exported together
+func (u *GiftCardUsecase) GenerateGiftCards(userID, businessID string, req *contract.GenerateGiftCardsReq) (*contract.GiftCardBatchRes, error) {
+	expiresAt, err := parseScheduleTime(req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := generateUniqueCodes(util.NormalizeCode(req.Prefix), req.Count, func(codes []string) ([]string, error) {
+		return u.giftCardRepo.FindExistingCodes(businessID, codes)
+	})
+	if err != nil {
+		logger.Log.Error("Failed to generate gift card codes", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to generate gift cards")
+	}
+
+	batchID := uuid.NewString()
+	giftCards, err := u.issueGiftCards(userID, businessID, codes, &batchID, roundMoney(req.Balance), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &contract.GiftCardBatchRes{
+		BatchID:   batchID,
+		GiftCards: make([]contract.GiftCardRes, len(giftCards)),
+	}
+	for i, giftCard := range giftCards {
+		res.GiftCards[i] = buildGiftCardRes(giftCard)
+	}
+
+	return res, nil
+}
+
+// issueGiftCards creates the gift cards with their opening ledger entry
+func (u *GiftCardUsecase) issueGiftCards(userID, businessID string, codes []string, batchID *string, balance float64, expiresAt *time.Time) ([]model.GiftCard, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Expiry must be in the future")
+	}
+
+	giftCards := make([]model.GiftCard, len(codes))
+	for i, code := range codes {
+		giftCards[i] = model.GiftCard{
+			BusinessID:     businessID,
+			Code:           code,
+			BatchID:        batchID,
+			InitialBalance: balance,
+			Balance:        balance,
+			ExpiresAt:      expiresAt,
+			IsActive:       true,
+			CreatedBy:      &userID,
+		}
+	}
+
+	tx := u.db.Begin()
+	defer handlePanic(tx)
+
+	if err := u.giftCardRepo.CreateGiftCards(tx, giftCards); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create gift cards", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to issue gift cards")
+	}
+
+	entries := make([]model.GiftCardEntry, len(giftCards))
+	for i, giftCard := range giftCards {
+		entries[i] = model.GiftCardEntry{
+			GiftCardID:   giftCard.ID,
+			Type:         config.GIFT_CARD_ENTRY_TYPE_ISSUE,
+			Amount:       balance,
+			BalanceAfter: balance,
+			CreatedBy:    &userID,
+		}
+	}
+
+	if err := u.giftCardRepo.CreateEntries(tx, entries); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create gift card entries", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to issue gift cards")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit gift cards", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to issue gift cards")
+	}
+
+	return giftCards, nil
+}
+
+func (u *GiftCardUsecase) UpdateGiftCard(giftCardID string, req *contract.UpdateGiftCardReq) (*contract.GiftCardRes, error) {
+	giftCard, err := u.giftCardRepo.GetGiftCardByID(giftCardID)
+	if err != nil {
+		logger.Log.Error("Failed to get gift card", zap.Error(err), zap.String("giftCardID", giftCardID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get gift card")
+	}
+
+	if giftCard == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Gift card not found")
+	}
+
+	if req.ExpiresAt != nil {
+		if giftCard.ExpiresAt, err = parseScheduleTime(req.ExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		giftCard.IsActive = *req.IsActive
+	}
+
+	if err := u.giftCardRepo.UpdateGiftCard(giftCard); err != nil {
+		logger.Log.Error("Failed to update gift card", zap.Error(err), zap.String("giftCardID", giftCardID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update gift card")
+	}
+
+	return u.GetGiftCardByID(giftCardID)
+}
+
+func (u *GiftCardUsecase) GetGiftCardByID(giftCardID string) (*contract.GiftCardRes, error) {
+	giftCard, err := u.giftCardRepo.GetGiftCardByID(giftCardID)
+	if err != nil {
+		logger.Log.Error("Failed to get gift card", zap.Error(err), zap.String("giftCardID", giftCardID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get gift card")
+	}
+
+	if giftCard == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Gift card not found")
+	}
+
+	return util.ToPointer(buildGiftCardRes(*giftCard)), nil
+}
+
+// GetGiftCardByCode looks up a code as typed at the till, e.g. to check the balance before paying
+func (u *GiftCardUsecase) GetGiftCardByCode(businessID, code string) (*contract.GiftCardRes, error) {
+	giftCard, err := u.giftCardRepo.GetGiftCardByCode(businessID, util.NormalizeCode(code))
+	if err != nil {
+		logger.Log.Error("Failed to get gift card", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get gift card")
+	}
+
+	if giftCard == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Gift card not found")
+	}
+
+	return util.ToPointer(buildGiftCardRes(*giftCard)), nil
+}
+
+func (u *GiftCardUsecase) ListGiftCards(businessID string, page, pageSize int, search, batchID string) ([]contract.GiftCardRes, int64, error) {
+	giftCards, total, err := u.giftCardRepo.ListGiftCards(businessID, page, pageSize, util.NormalizeCode(search), batchID)
+	if err != nil {
+		logger.Log.Error("Failed to list gift cards", zap.Error(err), zap.String("businessID", businessID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list gift cards")
+	}
+
+	results := make([]contract.GiftCardRes, len(giftCards))
+	for i, giftCard := range giftCards {
+		results[i] = buildGiftCardRes(giftCard)
+	}
+
+	return results, total, nil
+}
+
+func (u *GiftCardUsecase) ListGiftCardEntries(giftCardID string, page, pageSize int) ([]contract.GiftCardEntryRes, int64, error) {
+	entries, total, err := u.giftCardRepo.ListEntriesByGiftCardID(giftCardID, page, pageSize)
+	if err != nil {
+		logger.Log.Error("Failed to list gift card entries", zap.Error(err), zap.String("giftCardID", giftCardID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list gift card entries")
+	}
+
+	results := make([]contract.GiftCardEntryRes, len(entries))
+	for i, entry := range entries {
+		results[i] = buildGiftCardEntryRes(entry)
+	}
+
+	return results, total, nil
+}
+
+// ExportGiftCards writes the gift cards of a business, or of one batch, as CSV
+func (u *GiftCardUsecase) ExportGiftCards(businessID string, query *contract.ExportCodesQuery) ([]byte, string, error) {
+	giftCards, err := u.giftCardRepo.ListGiftCardsForExport(businessID, query.BatchID)
+	if err != nil {
+		logger.Log.Error("Failed to list gift cards", zap.Error(err), zap.String("businessID", businessID))
+		return nil, "", fiber.NewError(fiber.StatusInternalServerError, "Failed to export gift cards")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"code", "initial_balance", "balance", "expires_at", "is_active"})
+	for _, giftCard := range giftCards {
+		writer.Write([]string{
+			giftCard.Code,
+			formatCSVAmount(giftCard.InitialBalance),
+			formatCSVAmount(giftCard.Balance),
+			formatCSVOptionalTime(giftCard.ExpiresAt),
+			strconv.FormatBool(giftCard.IsActive),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logger.Log.Error("Failed to write gift cards CSV", zap.Error(err))
+		return nil, "", fiber.NewError(fiber.StatusInternalServerError, "Failed to export gift cards")
+	}
+
+	fileName := "gift-cards.csv"
+	if query.BatchID != "" {
+		fileName = fmt.Sprintf("gift-cards-%s.csv", query.BatchID)
+	}
+
+	return buf.Bytes(), fileName, nil
+}
+
+// ApplyPaidTransaction takes the gift card payments of a paid transaction off the cards' balances.
+// Each card is locked while it is charged, so two sales at once can't spend the same balance.
+func (u *GiftCardUsecase) ApplyPaidTransaction(tx *gorm.DB, transaction *model.Transaction, userID *string) error {
+	amounts := make(map[string]float64)
+	for _, payment := range transaction.Payments {
+		if payment.Method != config.PAYMENT_METHOD_GIFT_CARD {
+			continue
+		}
+
+		if payment.Reference == nil || util.NormalizeCode(*payment.Reference) == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "Enter the gift card code as the payment reference")
+		}
+		amounts[util.NormalizeCode(*payment.Reference)] += payment.Amount
+	}
+
+	if len(amounts) == 0 {
+		return nil
+	}
+
+	// Lock the cards in a fixed order so concurrent sales paying with the same cards can't deadlock
+	codes := make([]string, 0, len(amounts))
+	for code := range amounts {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	now := time.Now()
+	for _, code := range codes {
+		amount := roundMoney(amounts[code])
+
+		giftCard, err := u.giftCardRepo.GetGiftCardForUpdate(tx, transaction.BusinessID, code)
+		if err != nil {
+			logger.Log.Error("Failed to get gift card", zap.Error(err), zap.String("businessID", transaction.BusinessID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get gift card")
+		}
+
+		if giftCard == nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Gift card %s not found", code))
+		}
+
+		if !giftCard.IsUsableAt(now) {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Gift card %s is inactive or expired", code))
+		}
+
+		if amount > giftCard.Balance {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Not enough balance on gift card %s. Balance: %.2f, Paid: %.2f", code, giftCard.Balance, amount))
+		}
+
+		if err := u.addEntry(tx, giftCard, &model.GiftCardEntry{
+			TransactionID: &transaction.ID,
+			Type:          config.GIFT_CARD_ENTRY_TYPE_REDEEM,
+			Amount:        -amount,
+			CreatedBy:     userID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreRefund puts a refund paid out as gift card balance back on the cards the transaction was paid with
+func (u *GiftCardUsecase) RestoreRefund(tx *gorm.DB, transaction *model.Transaction, refund *model.Refund) error {
+	if refund.Method != config.PAYMENT_METHOD_GIFT_CARD || refund.PaidOutAmount() <= 0 {
+		return nil
+	}
+
+	remaining, err := u.restorableAmounts(tx, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	var total float64
+	for _, r := range remaining {
+		total += r.amount
+	}
+	if roundMoney(refund.PaidOutAmount()) > roundMoney(total) {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Refund exceeds what was paid by gift card. Restorable: %.2f", roundMoney(total)))
+	}
+
+	return u.restore(tx, transaction, remaining, refund.PaidOutAmount(), &refund.ID, &refund.CreatedBy)
+}
+
+// RestoreCancel puts back everything a cancelled transaction took off gift cards
+func (u *GiftCardUsecase) RestoreCancel(tx *gorm.DB, transaction *model.Transaction, userID string) error {
+	remaining, err := u.restorableAmounts(tx, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	var total float64
+	for _, r := range remaining {
+		total += r.amount
+	}
+
+	return u.restore(tx, transaction, remaining, total, nil, &userID)
+}
+
+type restorableGiftCard struct {
+	giftCardID string
+	amount     float64
+}
+
+// restorableAmounts returns, per card, what the transaction took off it and has not given back yet
+func (u *GiftCardUsecase) restorableAmounts(tx *gorm.DB, transactionID string) ([]restorableGiftCard, error) {
+	entries, err := u.giftCardRepo.ListTransactionEntries(tx, transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to list gift card entries", zap.Error(err), zap.String("transactionID", transactionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get gift card entries")
+	}
+
+	var results []restorableGiftCard
+	index := make(map[string]int)
+	for _, entry := range entries {
+		i, ok := index[entry.GiftCardID]
+		if !ok {
+			i = len(results)
+			index[entry.GiftCardID] = i
+			results = append(results, restorableGiftCard{giftCardID: entry.GiftCardID})
+		}
+		// Redeem entries are negative and restore entries positive, so what is left is minus the sum
+		results[i].amount -= entry.Amount
+	}
+
+	for i := range results {
+		results[i].amount = roundMoney(results[i].amount)
+	}
+
+	return results, nil
+}
+
+func (u *GiftCardUsecase) restore(tx *gorm.DB, transaction *model.Transaction, remaining []restorableGiftCard, amount float64, refundID, userID *string) error {
+	left := roundMoney(amount)
+	for _, r := range remaining {
+		if left <= 0 {
+			break
+		}
+		if r.amount <= 0 {
+			continue
+		}
+
+		restored := min(r.amount, left)
+
+		giftCard, err := u.giftCardRepo.GetGiftCardForUpdateByID(tx, r.giftCardID)
+		if err != nil || giftCard == nil {
+			logger.Log.Error("Failed to get gift card", zap.Error(err), zap.String("giftCardID", r.giftCardID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to restore gift card balance")
+		}
+
+		if err := u.addEntry(tx, giftCard, &model.GiftCardEntry{
+			TransactionID: &transaction.ID,
+			RefundID:      refundID,
+			Type:          config.GIFT_CARD_ENTRY_TYPE_RESTORE,
+			Amount:        restored,
+			CreatedBy:     userID,
+		}); err != nil {
+			return err
+		}
+
+		left = roundMoney(left - restored)
+	}
+
+	return nil
+}
+
+// addEntry moves the balance of a locked gift card and records the move in its ledger
+func (u *GiftCardUsecase) addEntry(tx *gorm.DB, giftCard *model.GiftCard, entry *model.GiftCardEntry) error {
+	giftCard.Balance = roundMoney(giftCard.Balance + entry.Amount)
+	if err := u.giftCardRepo.UpdateBalance(tx, giftCard.ID, giftCard.Balance); err != nil {
+		logger.Log.Error("Failed to update gift card balance", zap.Error(err), zap.String("giftCardID", giftCard.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update gift card balance")
+	}
+
+	entry.GiftCardID = giftCard.ID
+	entry.BalanceAfter = giftCard.Balance
+	if err := u.giftCardRepo.CreateEntries(tx, []model.GiftCardEntry{*entry}); err != nil {
+		logger.Log.Error("Failed to create gift card entry", zap.Error(err), zap.String("giftCardID", giftCard.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update gift card balance")
+	}
+
+	return nil
+}
+
+func (u *GiftCardUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, giftCardID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if giftCardID != nil {
+			giftCard, err := u.giftCardRepo.GetGiftCardByIDAndBusinessID(*giftCardID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get gift card", zap.Error(err), zap.String("giftCardID", *giftCardID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get gift card")
+			}
+
+			if giftCard == nil {
+				logger.Log.Warn("Gift card not found", zap.String("giftCardID", *giftCardID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildGiftCardRes builds gift card response
+func buildGiftCardRes(giftCard model.GiftCard) contract.GiftCardRes {
+	var expiresAt *string
+	if giftCard.ExpiresAt != nil {
+		str := giftCard.ExpiresAt.Format(time.RFC3339)
+		expiresAt = &str
+	}
+
+	return contract.GiftCardRes{
+		ID:             giftCard.ID,
+		BusinessID:     giftCard.BusinessID,
+		Code:           giftCard.Code,
+		BatchID:        giftCard.BatchID,
+		InitialBalance: giftCard.InitialBalance,
+		Balance:        giftCard.Balance,
+		ExpiresAt:      expiresAt,
+		IsActive:       giftCard.IsActive,
+		CreatedAt:      giftCard.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      giftCard.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func buildGiftCardEntryRes(entry model.GiftCardEntry) contract.GiftCardEntryRes {
+	var invoiceNumber *string
+	if entry.Transaction != nil {
+		invoiceNumber = &entry.Transaction.InvoiceNumber
+	}
+
+	var creatorName *string
+	if entry.Creator != nil {
+		creatorName = &entry.Creator.Name
+	}
+
+	return contract.GiftCardEntryRes{
+		ID:            entry.ID,
+		Type:          string(entry.Type),
+		Amount:        entry.Amount,
+		BalanceAfter:  entry.BalanceAfter,
+		TransactionID: entry.TransactionID,
+		InvoiceNumber: invoiceNumber,
+		RefundID:      entry.RefundID,
+		CreatedBy:     entry.CreatedBy,
+		CreatorName:   creatorName,
+		CreatedAt:     entry.CreatedAt.Format(time.RFC3339),
+	}
+}
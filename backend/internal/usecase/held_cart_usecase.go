@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type HeldCartUsecase struct {
+	heldCartRepo       *repository.HeldCartRepository
+	productRepo        *repository.ProductRepository
+	modifierUsecase    *ModifierUsecase
+	transactionUsecase *TransactionUsecase
+}
+
+func NewHeldCartUsecase(
+	heldCartRepo *repository.HeldCartRepository,
+	productRepo *repository.ProductRepository,
+	modifierUsecase *ModifierUsecase,
+	transactionUsecase *TransactionUsecase,
+) *HeldCartUsecase {
+	return &HeldCartUsecase{
+		heldCartRepo:       heldCartRepo,
+		productRepo:        productRepo,
+		modifierUsecase:    modifierUsecase,
+		transactionUsecase: transactionUsecase,
+	}
+}
+
+// HoldCart parks a cart under a name. Stock and expiry only start when it is resumed.
+func (u *HeldCartUsecase) HoldCart(userID, businessID string, req *contract.HoldCartReq) (*contract.HeldCartRes, error) {
+	productIDs := make([]string, len(req.Items))
+	var variantIDs []string
+	for i, item := range req.Items {
+		productIDs[i] = item.ProductID
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+
+	products, err := u.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		logger.Log.Error("Failed to fetch products", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+	}
+
+	productMap := make(map[string]*model.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	variantMap := make(map[string]*model.ProductVariant, len(variantIDs))
+	if len(variantIDs) > 0 {
+		variants, err := u.productRepo.GetVariantsByIDs(variantIDs)
+		if err != nil {
+			logger.Log.Error("Failed to fetch product variants", zap.Error(err))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+		}
+		for _, variant := range variants {
+			variantMap[variant.ID] = variant
+		}
+	}
+
+	items := make([]model.HeldCartItem, len(req.Items))
+	for i, item := range req.Items {
+		product, exists := productMap[item.ProductID]
+		if !exists || product.BusinessID != businessID {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s not found", item.ProductID))
+		}
+
+		heldItem := model.HeldCartItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Price:       product.Price,
+			Quantity:    item.Quantity,
+			Discount:    toHeldCartDiscount(item.Discount),
+		}
+
+		// The variant is checked again when the cart is resumed
+		if item.VariantID != nil {
+			variant, exists := variantMap[*item.VariantID]
+			if !exists || variant.ProductID != product.ID {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Variant %s of %s not found", *item.VariantID, product.Name))
+			}
+			heldItem.VariantID = &variant.ID
+			heldItem.VariantLabel = &variant.Label
+			heldItem.Price = variant.Price
+		}
+		items[i] = heldItem
+	}
+
+	// Modifiers are priced into the line like at checkout
+	lineModifiers, err := u.modifierUsecase.ResolveModifiers(businessID, req.Items, productMap)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Modifiers = lineModifiers[i]
+		for _, modifier := range lineModifiers[i] {
+			items[i].Price = roundMoney(items[i].Price + modifier.Price)
+		}
+	}
+
+	// The voucher is only checked when the cart is resumed, it may not be usable yet
+	customer, err := u.transactionUsecase.getCustomer(businessID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &model.HeldCart{
+		BusinessID:  businessID,
+		Name:        req.Name,
+		Note:        req.Note,
+		Items:       items,
+		Discount:    toHeldCartDiscount(req.Discount),
+		VoucherCode: emptyToNil(req.VoucherCode),
+		CreatedBy:   userID,
+	}
+	if customer != nil {
+		cart.CustomerID = &customer.ID
+	}
+
+	if err := u.heldCartRepo.CreateHeldCart(cart); err != nil {
+		logger.Log.Error("Failed to hold cart", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to hold cart")
+	}
+
+	return u.GetHeldCart(cart.ID)
+}
+
+func (u *HeldCartUsecase) GetHeldCart(heldCartID string) (*contract.HeldCartRes, error) {
+	cart, err := u.heldCartRepo.GetHeldCartByID(heldCartID)
+	if err != nil {
+		logger.Log.Error("Failed to get held cart", zap.Error(err), zap.String("heldCartID", heldCartID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get held cart")
+	}
+
+	if cart == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Held cart not found")
+	}
+
+	return buildHeldCartRes(*cart), nil
+}
+
+func (u *HeldCartUsecase) ListHeldCarts(businessID string, page, pageSize int, search string) ([]contract.HeldCartRes, int64, error) {
+	carts, total, err := u.heldCartRepo.ListHeldCarts(businessID, page, pageSize, search)
+	if err != nil {
+		logger.Log.Error("Failed to list held carts", zap.Error(err), zap.String("businessID", businessID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list held carts")
+	}
+
+	results := make([]contract.HeldCartRes, len(carts))
+	for i, cart := range carts {
+		results[i] = *buildHeldCartRes(cart)
+	}
+
+	return results, total, nil
+}
+
+// ResumeHeldCart turns the cart into a pending transaction at current prices and removes it.
+// The cart is locked and removed in the same database transaction as the sale, so it is resumed
+// once and stays parked when checkout fails.
+func (u *HeldCartUsecase) ResumeHeldCart(userID, businessID, heldCartID string, req *contract.ResumeHeldCartReq) (*contract.TransactionRes, error) {
+	cart, err := u.heldCartRepo.GetHeldCartByID(heldCartID)
+	if err != nil {
+		logger.Log.Error("Failed to get held cart", zap.Error(err), zap.String("heldCartID", heldCartID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get held cart")
+	}
+
+	if cart == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Held cart not found")
+	}
+
+	items := make([]contract.TransactionItemReq, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = contract.TransactionItemReq{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Discount:  toDiscountReq(item.Discount),
+		}
+		for _, modifier := range item.Modifiers {
+			items[i].ModifierIDs = append(items[i].ModifierIDs, modifier.ModifierID)
+		}
+	}
+
+	claim := func(tx *gorm.DB) error {
+		locked, err := u.heldCartRepo.GetHeldCartForUpdate(tx, cart.ID)
+		if err != nil {
+			logger.Log.Error("Failed to lock held cart", zap.Error(err), zap.String("heldCartID", heldCartID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to resume held cart")
+		}
+
+		if locked == nil {
+			return fiber.NewError(fiber.StatusConflict, "Held cart was already resumed or discarded")
+		}
+
+		if err := u.heldCartRepo.ClaimHeldCart(tx, cart.ID); err != nil {
+			logger.Log.Error("Failed to claim held cart", zap.Error(err), zap.String("heldCartID", heldCartID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to resume held cart")
+		}
+		return nil
+	}
+
+	return u.transactionUsecase.createTransaction(userID, businessID, &contract.CreateTransactionReq{
+		Items:       items,
+		CustomerID:  cart.CustomerID,
+		Discount:    toDiscountReq(cart.Discount),
+		VoucherCode: cart.VoucherCode,
+		ApproverID:  req.ApproverID,
+		ApproverPin: req.ApproverPin,
+	}, claim)
+}
+
+func (u *HeldCartUsecase) DiscardHeldCart(heldCartID string) error {
+	claimed, err := u.heldCartRepo.DeleteHeldCart(heldCartID)
+	if err != nil {
+		logger.Log.Error("Failed to discard held cart", zap.Error(err), zap.String("heldCartID", heldCartID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to discard held cart")
+	}
+
+	if !claimed {
+		return fiber.NewError(fiber.StatusNotFound, "Held cart not found")
+	}
+
+	return nil
+}
+
+func (u *HeldCartUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, heldCartID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if heldCartID != nil {
+			cart, err := u.heldCartRepo.GetHeldCartByIDAndBusinessID(*heldCartID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get held cart", zap.Error(err), zap.String("heldCartID", *heldCartID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get held cart")
+			}
+
+			if cart == nil {
+				logger.Log.Warn("Held cart not found", zap.String("heldCartID", *heldCartID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// toHeldCartDiscount converts a discount request to its stored form
+func toHeldCartDiscount(discount *contract.DiscountReq) *model.HeldCartDiscount {
+	if discount == nil {
+		return nil
+	}
+
+	return &model.HeldCartDiscount{
+		Type:   config.DiscountType(discount.Type),
+		Value:  discount.Value,
+		Reason: discount.Reason,
+	}
+}
+
+// toDiscountReq converts a stored discount back to a request
+func toDiscountReq(discount *model.HeldCartDiscount) *contract.DiscountReq {
+	if discount == nil {
+		return nil
+	}
+
+	return &contract.DiscountReq{
+		Type:   string(discount.Type),
+		Value:  discount.Value,
+		Reason: discount.Reason,
+	}
+}
+
+// buildHeldCartRes builds held cart response
+func buildHeldCartRes(cart model.HeldCart) *contract.HeldCartRes {
+	items := make([]contract.HeldCartItemRes, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = contract.HeldCartItemRes{
+			ProductID:    item.ProductID,
+			ProductName:  item.ProductName,
+			VariantID:    item.VariantID,
+			VariantLabel: item.VariantLabel,
+			Modifiers:    buildTransactionItemModifiersRes(item.Modifiers),
+			Price:        item.Price,
+			Quantity:     item.Quantity,
+			Discount:     toDiscountReq(item.Discount),
+		}
+	}
+
+	var customerName *string
+	if cart.Customer != nil {
+		customerName = &cart.Customer.Name
+	}
+
+	return &contract.HeldCartRes{
+		ID:           cart.ID,
+		BusinessID:   cart.BusinessID,
+		Name:         cart.Name,
+		Note:         cart.Note,
+		Items:        items,
+		Discount:     toDiscountReq(cart.Discount),
+		CustomerID:   cart.CustomerID,
+		CustomerName: customerName,
+		VoucherCode:  cart.VoucherCode,
+		CreatedBy:    cart.CreatedBy,
+		CreatorName:  cart.Creator.Name,
+		CreatedAt:    cart.CreatedAt.Format(time.RFC3339),
+	}
+}
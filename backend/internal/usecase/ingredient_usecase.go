@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IngredientUsecase struct {
+	ingredientRepo *repository.IngredientRepository
+}
+
+func NewIngredientUsecase(ingredientRepo *repository.IngredientRepository) *IngredientUsecase {
+	return &IngredientUsecase{
+		ingredientRepo: ingredientRepo,
+	}
+}
+
+func (u *IngredientUsecase) CreateIngredient(businessID string, req *contract.CreateIngredientReq) (*contract.IngredientRes, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := u.ensureNameIsFree(businessID, name, ""); err != nil {
+		return nil, err
+	}
+
+	ingredient := &model.Ingredient{
+		BusinessID: businessID,
+		Name:       name,
+		Unit:       strings.TrimSpace(req.Unit),
+		Cost:       req.Cost,
+		StockQty:   req.StockQty,
+		IsActive:   true,
+	}
+
+	if err := u.ingredientRepo.CreateIngredient(ingredient); err != nil {
+		logger.Log.Error("Failed to create ingredient", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create ingredient")
+	}
+
+	res := buildIngredientRes(ingredient)
+	return &res, nil
+}
+
+// UpdateIngredient updates an ingredient, a new cost is passed on to the products made with it
+func (u *IngredientUsecase) UpdateIngredient(ingredientID string, req *contract.UpdateIngredientReq) (*contract.IngredientRes, error) {
+	ingredient, err := u.ingredientRepo.GetIngredientByID(ingredientID)
+	if err != nil {
+		logger.Log.Error("Failed to get ingredient", zap.Error(err), zap.String("ingredientID", ingredientID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get ingredient")
+	}
+
+	if ingredient == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Ingredient not found")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := u.ensureNameIsFree(ingredient.BusinessID, name, ingredient.ID); err != nil {
+			return nil, err
+		}
+		ingredient.Name = name
+	}
+	if req.Unit != nil {
+		ingredient.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.Cost != nil {
+		ingredient.Cost = *req.Cost
+	}
+	if req.StockQty != nil {
+		ingredient.StockQty = *req.StockQty
+	}
+	if req.IsActive != nil {
+		ingredient.IsActive = *req.IsActive
+	}
+
+	if err := u.ingredientRepo.UpdateIngredient(ingredient); err != nil {
+		logger.Log.Error("Failed to update ingredient", zap.Error(err), zap.String("ingredientID", ingredientID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update ingredient")
+	}
+
+	res := buildIngredientRes(ingredient)
+	return &res, nil
+}
+
+func (u *IngredientUsecase) GetIngredientByID(ingredientID string) (*contract.IngredientRes, error) {
+	ingredient, err := u.ingredientRepo.GetIngredientByID(ingredientID)
+	if err != nil {
+		logger.Log.Error("Failed to get ingredient", zap.Error(err), zap.String("ingredientID", ingredientID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get ingredient")
+	}
+
+	if ingredient == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Ingredient not found")
+	}
+
+	res := buildIngredientRes(ingredient)
+	return &res, nil
+}
+
+func (u *IngredientUsecase) ListIngredients(businessID string, page, pageSize int, search string) ([]contract.IngredientRes, int64, error) {
+	ingredients, total, err := u.ingredientRepo.ListIngredients(businessID, page, pageSize, strings.TrimSpace(search))
+	if err != nil {
+		logger.Log.Error("Failed to list ingredients", zap.Error(err), zap.String("businessID", businessID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list ingredients")
+	}
+
+	results := make([]contract.IngredientRes, len(ingredients))
+	for i := range ingredients {
+		results[i] = buildIngredientRes(&ingredients[i])
+	}
+
+	return results, total, nil
+}
+
+// DeleteIngredient deletes an ingredient that no recipe uses anymore
+func (u *IngredientUsecase) DeleteIngredient(ingredientID string) error {
+	count, err := u.ingredientRepo.CountRecipesUsingIngredient(ingredientID)
+	if err != nil {
+		logger.Log.Error("Failed to count recipes", zap.Error(err), zap.String("ingredientID", ingredientID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete ingredient")
+	}
+
+	if count > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Ingredient is used in the recipe of %d products, remove it from them first", count))
+	}
+
+	if err := u.ingredientRepo.DeleteIngredient(ingredientID); err != nil {
+		logger.Log.Error("Failed to delete ingredient", zap.Error(err), zap.String("ingredientID", ingredientID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete ingredient")
+	}
+
+	return nil
+}
+
+// DeductStock takes the ingredients of the sold lines out of stock, once per unit sold.
+// Stock may go below zero, the sale has already been paid for.
+func (u *IngredientUsecase) DeductStock(tx *gorm.DB, items []model.TransactionItem) error {
+	return u.adjustStock(tx, items, nil, -1)
+}
+
+// RestoreStock puts the ingredients of the lines back into stock. Quantities are keyed by
+// transaction item ID, without them the full quantity of every line is restored.
+func (u *IngredientUsecase) RestoreStock(tx *gorm.DB, items []model.TransactionItem, quantities map[string]int) error {
+	return u.adjustStock(tx, items, quantities, 1)
+}
+
+func (u *IngredientUsecase) adjustStock(tx *gorm.DB, items []model.TransactionItem, quantities map[string]int, sign float64) error {
+	totals := make(map[string]float64)
+	for _, item := range items {
+		quantity := item.Quantity
+		if quantities != nil {
+			quantity = quantities[item.ID]
+		}
+
+		for _, ingredient := range item.Ingredients {
+			totals[ingredient.IngredientID] += ingredient.Quantity * float64(quantity)
+		}
+	}
+
+	// Same order every time so concurrent sales lock the rows alike
+	ingredientIDs := make([]string, 0, len(totals))
+	for ingredientID, total := range totals {
+		if total != 0 {
+			ingredientIDs = append(ingredientIDs, ingredientID)
+		}
+	}
+	sort.Strings(ingredientIDs)
+
+	for _, ingredientID := range ingredientIDs {
+		if err := u.ingredientRepo.AdjustStock(tx, ingredientID, sign*totals[ingredientID]); err != nil {
+			logger.Log.Error("Failed to update ingredient stock", zap.Error(err), zap.String("ingredientID", ingredientID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to update stock")
+		}
+	}
+	return nil
+}
+
+// ensureNameIsFree checks that no other ingredient of the business has the name
+func (u *IngredientUsecase) ensureNameIsFree(businessID, name, ingredientID string) error {
+	existing, err := u.ingredientRepo.FindIngredientByName(businessID, name)
+	if err != nil {
+		logger.Log.Error("Failed to find ingredient", zap.Error(err), zap.String("businessID", businessID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to check ingredient name")
+	}
+
+	if existing != nil && existing.ID != ingredientID {
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Ingredient %s already exists", name))
+	}
+	return nil
+}
+
+func (u *IngredientUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, ingredientID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if ingredientID != nil {
+			ingredient, err := u.ingredientRepo.GetIngredientByIDAndBusinessID(*ingredientID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get ingredient", zap.Error(err), zap.String("ingredientID", *ingredientID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get ingredient")
+			}
+
+			if ingredient == nil {
+				logger.Log.Warn("Ingredient not found", zap.String("ingredientID", *ingredientID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+func buildIngredientRes(ingredient *model.Ingredient) contract.IngredientRes {
+	return contract.IngredientRes{
+		ID:         ingredient.ID,
+		BusinessID: ingredient.BusinessID,
+		Name:       ingredient.Name,
+		Unit:       ingredient.Unit,
+		Cost:       ingredient.Cost,
+		StockQty:   ingredient.StockQty,
+		IsActive:   ingredient.IsActive,
+		CreatedAt:  ingredient.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  ingredient.UpdatedAt.Format(time.RFC3339),
+	}
+}
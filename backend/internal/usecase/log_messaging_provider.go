@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/pkg/logger"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LogMessagingProvider sends nothing, messages are logged and written as JSON files to a local directory.
+// It is meant for local development and tests.
+type LogMessagingProvider struct {
+	directory string
+}
+
+func NewLogMessagingProvider(directory string) *LogMessagingProvider {
+	return &LogMessagingProvider{directory: directory}
+}
+
+func (p *LogMessagingProvider) Name() string {
+	return string(config.MESSAGING_PROVIDER_LOG)
+}
+
+func (p *LogMessagingProvider) SendWhatsApp(msg WhatsAppMessage) (string, error) {
+	id := "log-" + uuid.NewString()
+	logger.Log.Info("WhatsApp message", zap.String("id", id), zap.String("to", msg.To), zap.String("body", msg.Body))
+
+	if p.directory == "" {
+		return id, nil
+	}
+
+	if err := os.MkdirAll(p.directory, 0o755); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(struct {
+		ID      string          `json:"id"`
+		SentAt  time.Time       `json:"sentAt"`
+		Message WhatsAppMessage `json:"message"`
+	}{id, time.Now(), msg}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(p.directory, id+".json"), data, 0o644); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
@@ -0,0 +1,432 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type LoyaltyUsecase struct {
+	loyaltyRepo  *repository.LoyaltyRepository
+	customerRepo *repository.CustomerRepository
+	businessRepo *repository.BusinessRepository
+	db           *gorm.DB
+}
+
+func NewLoyaltyUsecase(
+	loyaltyRepo *repository.LoyaltyRepository,
+	customerRepo *repository.CustomerRepository,
+	businessRepo *repository.BusinessRepository,
+	db *gorm.DB,
+) *LoyaltyUsecase {
+	return &LoyaltyUsecase{
+		loyaltyRepo:  loyaltyRepo,
+		customerRepo: customerRepo,
+		businessRepo: businessRepo,
+		db:           db,
+	}
+}
+
+// ApplyPaidTransaction redeems the points a sale was paid with and awards the points earned on it.
+// It runs in the database transaction that marks the sale paid, after its payments are stored.
+func (u *LoyaltyUsecase) ApplyPaidTransaction(tx *gorm.DB, transaction *model.Transaction, userID *string) error {
+	var pointsAmount float64
+	for _, payment := range transaction.Payments {
+		if payment.Method == config.PAYMENT_METHOD_POINTS {
+			pointsAmount += payment.Amount
+		}
+	}
+	pointsAmount = roundMoney(pointsAmount)
+
+	if transaction.CustomerID == nil {
+		if pointsAmount > 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "Choose a customer to pay with points")
+		}
+		return nil
+	}
+
+	business, err := u.businessRepo.GetBusinessByID(transaction.BusinessID)
+	if err != nil || business == nil {
+		logger.Log.Error("Failed to get business", zap.Error(err), zap.String("businessID", transaction.BusinessID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	if !business.LoyaltyEnabled {
+		if pointsAmount > 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "Loyalty points are not enabled")
+		}
+		return nil
+	}
+
+	// Lock the customer so two sales at once can't spend the same points
+	customer, err := u.customerRepo.GetCustomerForUpdate(tx, *transaction.CustomerID, transaction.BusinessID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *transaction.CustomerID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+	if customer == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer not found")
+	}
+
+	now := time.Now()
+	if pointsAmount > 0 {
+		points := pointsAmount / business.LoyaltyPointValue
+		redeemed := int(math.Round(points))
+		if math.Abs(points-float64(redeemed)) > 1e-6 {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Points payment must be a multiple of the point value. Point value: %.2f", business.LoyaltyPointValue))
+		}
+
+		if redeemed < business.LoyaltyMinRedeemPoints {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Redeem at least %d points", business.LoyaltyMinRedeemPoints))
+		}
+
+		if redeemed > customer.PointsBalance {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Not enough points. Balance: %d, Redeemed: %d", customer.PointsBalance, redeemed))
+		}
+
+		if err := u.takePoints(tx, customer, redeemed, now); err != nil {
+			return err
+		}
+
+		if err := u.addEntry(tx, customer, &model.LoyaltyPointEntry{
+			TransactionID: &transaction.ID,
+			Type:          config.LOYALTY_ENTRY_TYPE_REDEEM,
+			Points:        -redeemed,
+			CreatedBy:     userID,
+		}); err != nil {
+			return err
+		}
+		transaction.PointsRedeemed = redeemed
+	}
+
+	// Points are earned on what was paid with money, not on what was paid with points
+	earned := int(math.Floor(roundMoney(transaction.TotalAmount+transaction.RoundingAmount-pointsAmount)/business.LoyaltySpendPerPoint + 1e-9))
+	if earned > 0 {
+		if err := u.addEntry(tx, customer, &model.LoyaltyPointEntry{
+			TransactionID: &transaction.ID,
+			Type:          config.LOYALTY_ENTRY_TYPE_EARN,
+			Points:        earned,
+			ExpiresAt:     pointsExpiry(*business, now),
+			CreatedBy:     userID,
+		}); err != nil {
+			return err
+		}
+		transaction.PointsEarned = earned
+	}
+
+	if transaction.PointsEarned == 0 && transaction.PointsRedeemed == 0 {
+		return nil
+	}
+
+	err = tx.Model(&model.Transaction{}).
+		Where("id = ?", transaction.ID).
+		Updates(map[string]any{"points_earned": transaction.PointsEarned, "points_redeemed": transaction.PointsRedeemed}).Error
+	if err != nil {
+		logger.Log.Error("Failed to update transaction points", zap.Error(err), zap.String("transactionID", transaction.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record loyalty points")
+	}
+
+	return nil
+}
+
+// ReverseRefund takes back the points earned on the refunded part of a sale.
+// refundedAmount is the total refunded on the sale including this refund, a refund paid in points gives redeemed points back.
+func (u *LoyaltyUsecase) ReverseRefund(tx *gorm.DB, transaction *model.Transaction, refund *model.Refund, refundedAmount float64) error {
+	if transaction.CustomerID == nil || (transaction.PointsEarned == 0 && transaction.PointsRedeemed == 0) {
+		if refund.Method == config.PAYMENT_METHOD_POINTS {
+			return fiber.NewError(fiber.StatusBadRequest, "No points were redeemed on this transaction")
+		}
+		return nil
+	}
+
+	customer, err := u.customerRepo.GetCustomerForUpdate(tx, *transaction.CustomerID, transaction.BusinessID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *transaction.CustomerID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+	if customer == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if refund.Method == config.PAYMENT_METHOD_POINTS && refund.PaidOutAmount() > 0 {
+		if err := u.restoreRefundedPoints(tx, customer, transaction, refund, now); err != nil {
+			return err
+		}
+	}
+
+	if transaction.PointsEarned == 0 {
+		return nil
+	}
+
+	// Points earned are taken back in proportion to what has been refunded so far
+	target := transaction.PointsEarned
+	if transaction.TotalAmount > 0 && refundedAmount < transaction.TotalAmount {
+		target = int(math.Floor(float64(transaction.PointsEarned) * refundedAmount / transaction.TotalAmount))
+	}
+
+	reversed, err := u.loyaltyRepo.SumTransactionPoints(tx, transaction.ID, config.LOYALTY_ENTRY_TYPE_REVERSE)
+	if err != nil {
+		logger.Log.Error("Failed to sum reversed points", zap.Error(err), zap.String("transactionID", transaction.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to reverse loyalty points")
+	}
+
+	points := target + reversed
+	if points <= 0 {
+		return nil
+	}
+
+	return u.reversePoints(tx, customer, transaction, &refund.ID, &refund.CreatedBy, points, now)
+}
+
+// ReverseCancel takes back the points earned on a voided sale and gives back the points it was paid with
+func (u *LoyaltyUsecase) ReverseCancel(tx *gorm.DB, transaction *model.Transaction, userID string) error {
+	if transaction.CustomerID == nil || (transaction.PointsEarned == 0 && transaction.PointsRedeemed == 0) {
+		return nil
+	}
+
+	customer, err := u.customerRepo.GetCustomerForUpdate(tx, *transaction.CustomerID, transaction.BusinessID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *transaction.CustomerID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+	if customer == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if transaction.PointsEarned > 0 {
+		if err := u.reversePoints(tx, customer, transaction, nil, &userID, transaction.PointsEarned, now); err != nil {
+			return err
+		}
+	}
+
+	if transaction.PointsRedeemed > 0 {
+		business, err := u.businessRepo.GetBusinessByID(transaction.BusinessID)
+		if err != nil || business == nil {
+			logger.Log.Error("Failed to get business", zap.Error(err), zap.String("businessID", transaction.BusinessID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+		}
+
+		if err := u.addEntry(tx, customer, &model.LoyaltyPointEntry{
+			TransactionID: &transaction.ID,
+			Type:          config.LOYALTY_ENTRY_TYPE_RESTORE,
+			Points:        transaction.PointsRedeemed,
+			ExpiresAt:     pointsExpiry(*business, now),
+			CreatedBy:     &userID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExpirePoints expires one batch of earned points past their expiry.
+// It returns the number of ledger entries expired in this batch.
+func (u *LoyaltyUsecase) ExpirePoints(batchSize int) (int, error) {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	defer handlePanic(tx)
+
+	entries, err := u.loyaltyRepo.LockExpiredEntries(tx, time.Now(), batchSize)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if len(entries) == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	customers := make(map[string]*model.Customer)
+	for _, entry := range entries {
+		customer, exists := customers[entry.CustomerID]
+		if !exists {
+			customer, err = u.customerRepo.GetCustomerForUpdate(tx, entry.CustomerID, entry.BusinessID)
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			customers[entry.CustomerID] = customer
+		}
+		if customer == nil {
+			continue
+		}
+
+		if err := u.loyaltyRepo.UpdateRemainingPoints(tx, entry.ID, 0); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if err := u.addEntry(tx, customer, &model.LoyaltyPointEntry{
+			TransactionID: entry.TransactionID,
+			Type:          config.LOYALTY_ENTRY_TYPE_EXPIRE,
+			Points:        -entry.RemainingPoints,
+		}); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+// ============================================================================
+// Helper Functions
+// ============================================================================
+
+// addEntry writes a ledger entry and moves the customer balance with it.
+// Points added only count towards expiry once they cover any points the customer was short of.
+func (u *LoyaltyUsecase) addEntry(tx *gorm.DB, customer *model.Customer, entry *model.LoyaltyPointEntry) error {
+	customer.PointsBalance += entry.Points
+
+	entry.BusinessID = customer.BusinessID
+	entry.CustomerID = customer.ID
+	entry.BalanceAfter = customer.PointsBalance
+	if entry.Points > 0 {
+		entry.RemainingPoints = max(min(entry.Points, customer.PointsBalance), 0)
+	}
+
+	if err := u.loyaltyRepo.CreateEntry(tx, entry); err != nil {
+		logger.Log.Error("Failed to create loyalty point entry", zap.Error(err), zap.String("customerID", customer.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record loyalty points")
+	}
+
+	if err := u.loyaltyRepo.UpdatePointsBalance(tx, customer.ID, customer.PointsBalance); err != nil {
+		logger.Log.Error("Failed to update points balance", zap.Error(err), zap.String("customerID", customer.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record loyalty points")
+	}
+
+	return nil
+}
+
+// takePoints uses up points from the entries that expire first. Points already spent can't be taken again,
+// so fewer points are taken when the customer doesn't have enough left.
+func (u *LoyaltyUsecase) takePoints(tx *gorm.DB, customer *model.Customer, points int, now time.Time) error {
+	entries, err := u.loyaltyRepo.ListAvailableEntries(tx, customer.ID, now)
+	if err != nil {
+		logger.Log.Error("Failed to list available points", zap.Error(err), zap.String("customerID", customer.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record loyalty points")
+	}
+
+	for _, entry := range entries {
+		if points <= 0 {
+			break
+		}
+
+		taken := min(entry.RemainingPoints, points)
+		if err := u.loyaltyRepo.UpdateRemainingPoints(tx, entry.ID, entry.RemainingPoints-taken); err != nil {
+			logger.Log.Error("Failed to update remaining points", zap.Error(err), zap.String("entryID", entry.ID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to record loyalty points")
+		}
+		points -= taken
+	}
+
+	return nil
+}
+
+// reversePoints takes earned points back off the customer, the balance goes negative if they were already spent
+func (u *LoyaltyUsecase) reversePoints(tx *gorm.DB, customer *model.Customer, transaction *model.Transaction, refundID, userID *string, points int, now time.Time) error {
+	if err := u.takePoints(tx, customer, points, now); err != nil {
+		return err
+	}
+
+	return u.addEntry(tx, customer, &model.LoyaltyPointEntry{
+		TransactionID: &transaction.ID,
+		RefundID:      refundID,
+		Type:          config.LOYALTY_ENTRY_TYPE_REVERSE,
+		Points:        -points,
+		CreatedBy:     userID,
+	})
+}
+
+// restoreRefundedPoints gives back the points a refund is paid in, up to what was redeemed on the sale
+func (u *LoyaltyUsecase) restoreRefundedPoints(tx *gorm.DB, customer *model.Customer, transaction *model.Transaction, refund *model.Refund, now time.Time) error {
+	business, err := u.businessRepo.GetBusinessByID(transaction.BusinessID)
+	if err != nil || business == nil {
+		logger.Log.Error("Failed to get business", zap.Error(err), zap.String("businessID", transaction.BusinessID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	restored, err := u.loyaltyRepo.SumTransactionPoints(tx, transaction.ID, config.LOYALTY_ENTRY_TYPE_RESTORE)
+	if err != nil {
+		logger.Log.Error("Failed to sum restored points", zap.Error(err), zap.String("transactionID", transaction.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to restore loyalty points")
+	}
+
+	points := int(math.Ceil(roundMoney(refund.PaidOutAmount())/business.LoyaltyPointValue - 1e-9))
+	if points > transaction.PointsRedeemed-restored {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Refund exceeds the points redeemed. Redeemable: %d, Refund: %d", transaction.PointsRedeemed-restored, points))
+	}
+
+	return u.addEntry(tx, customer, &model.LoyaltyPointEntry{
+		TransactionID: &transaction.ID,
+		RefundID:      &refund.ID,
+		Type:          config.LOYALTY_ENTRY_TYPE_RESTORE,
+		Points:        points,
+		ExpiresAt:     pointsExpiry(*business, now),
+		CreatedBy:     &refund.CreatedBy,
+	})
+}
+
+// pointsExpiry is when points added now expire, nil when the business lets points last forever
+func pointsExpiry(business model.Business, now time.Time) *time.Time {
+	if business.LoyaltyPointsExpiryDays == nil {
+		return nil
+	}
+
+	expiresAt := now.AddDate(0, 0, *business.LoyaltyPointsExpiryDays)
+	return &expiresAt
+}
+
+// buildLoyaltyPointEntryRes builds points ledger entry response
+func buildLoyaltyPointEntryRes(entry model.LoyaltyPointEntry) contract.LoyaltyPointEntryRes {
+	var expiresAt *string
+	if entry.ExpiresAt != nil {
+		formatted := entry.ExpiresAt.Format(time.RFC3339)
+		expiresAt = &formatted
+	}
+
+	var invoiceNumber *string
+	if entry.Transaction != nil {
+		invoiceNumber = &entry.Transaction.InvoiceNumber
+	}
+
+	var creatorName *string
+	if entry.Creator != nil {
+		creatorName = &entry.Creator.Name
+	}
+
+	return contract.LoyaltyPointEntryRes{
+		ID:              entry.ID,
+		Type:            string(entry.Type),
+		Points:          entry.Points,
+		BalanceAfter:    entry.BalanceAfter,
+		RemainingPoints: entry.RemainingPoints,
+		ExpiresAt:       expiresAt,
+		TransactionID:   entry.TransactionID,
+		InvoiceNumber:   invoiceNumber,
+		RefundID:        entry.RefundID,
+		Note:            entry.Note,
+		CreatedBy:       entry.CreatedBy,
+		CreatorName:     creatorName,
+		CreatedAt:       entry.CreatedAt.Format(time.RFC3339),
+	}
+}
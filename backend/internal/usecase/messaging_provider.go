@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"app/internal/config"
+)
+
+// MessagingProvider delivers chat messages to customers, such as digital receipts over WhatsApp
+type MessagingProvider interface {
+	Name() string
+	// SendWhatsApp sends the message and returns the provider message ID
+	SendWhatsApp(msg WhatsAppMessage) (string, error)
+}
+
+type WhatsAppMessage struct {
+	To   string // Phone number in international format without the plus sign, e.g. 6281234567890
+	Body string
+	// Optional document sent with the body as its caption
+	DocumentURL  *string
+	DocumentName *string
+}
+
+// NewMessagingProvider returns the provider selected by MESSAGING_PROVIDER
+func NewMessagingProvider() MessagingProvider {
+	switch config.MessagingProviderName(config.Env.Messaging.Provider) {
+	case config.MESSAGING_PROVIDER_WHATSAPP_CLOUD:
+		return NewWhatsAppCloudMessagingProvider(config.Env.Messaging.WhatsappBaseURL, config.Env.Messaging.WhatsappPhoneNumberID, config.Env.Messaging.WhatsappAccessToken)
+	default:
+		return NewLogMessagingProvider(config.Env.Messaging.LogDirectory)
+	}
+}
@@ -0,0 +1,607 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ModifierUsecase struct {
+	modifierRepo *repository.ModifierRepository
+	categoryRepo *repository.CategoryRepository
+	productRepo  *repository.ProductRepository
+	db           *gorm.DB
+}
+
+func NewModifierUsecase(
+	modifierRepo *repository.ModifierRepository,
+	categoryRepo *repository.CategoryRepository,
+	productRepo *repository.ProductRepository,
+	db *gorm.DB,
+) *ModifierUsecase {
+	return &ModifierUsecase{
+		modifierRepo: modifierRepo,
+		categoryRepo: categoryRepo,
+		productRepo:  productRepo,
+		db:           db,
+	}
+}
+
+func (u *ModifierUsecase) CreateModifierGroup(businessID string, req *contract.CreateModifierGroupReq) (*contract.ModifierGroupRes, error) {
+	maxSortOrder, err := u.modifierRepo.GetMaxSortOrder(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get max sort_order", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create modifier group")
+	}
+
+	group := &model.ModifierGroup{
+		BusinessID:    businessID,
+		Name:          req.Name,
+		MinSelections: req.MinSelections,
+		MaxSelections: req.MaxSelections,
+		IsActive:      true,
+		SortOrder:     maxSortOrder + 1,
+	}
+	if req.IsActive != nil {
+		group.IsActive = *req.IsActive
+	}
+
+	modifiers, err := buildModifiers(businessID, nil, req.Modifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSelectionLimits(group, len(modifiers)); err != nil {
+		return nil, err
+	}
+
+	if err := u.validateTargets(businessID, req.CategoryIDs, req.ProductIDs); err != nil {
+		return nil, err
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.modifierRepo.CreateModifierGroup(tx, group); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create modifier group", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create modifier group")
+	}
+
+	if err := u.modifierRepo.ReplaceModifiers(tx, group.ID, modifiers); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to save modifiers", zap.Error(err), zap.String("modifierGroupID", group.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create modifier group")
+	}
+
+	if err := u.modifierRepo.ReplaceModifierGroupTargets(tx, group.ID, req.CategoryIDs, req.ProductIDs); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to set modifier group targets", zap.Error(err), zap.String("modifierGroupID", group.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create modifier group")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create modifier group")
+	}
+
+	return u.GetModifierGroupByID(group.ID)
+}
+
+func (u *ModifierUsecase) UpdateModifierGroup(modifierGroupID string, req *contract.UpdateModifierGroupReq) (*contract.ModifierGroupRes, error) {
+	group, err := u.modifierRepo.GetModifierGroupByID(modifierGroupID)
+	if err != nil {
+		logger.Log.Error("Failed to get modifier group", zap.Error(err), zap.String("modifierGroupID", modifierGroupID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get modifier group")
+	}
+
+	if group == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Modifier group not found")
+	}
+
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.MinSelections != nil {
+		group.MinSelections = *req.MinSelections
+	}
+	if req.MaxSelections != nil {
+		group.MaxSelections = *req.MaxSelections
+	}
+	if req.IsActive != nil {
+		group.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		group.SortOrder = *req.SortOrder
+	}
+
+	// Modifiers and targets are kept unless the request replaces them
+	modifiers := group.Modifiers
+	if req.Modifiers != nil {
+		modifiers, err = buildModifiers(group.BusinessID, group.Modifiers, *req.Modifiers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateSelectionLimits(group, len(modifiers)); err != nil {
+		return nil, err
+	}
+
+	categoryIDs := make([]string, len(group.Categories))
+	for i, category := range group.Categories {
+		categoryIDs[i] = category.ID
+	}
+	if req.CategoryIDs != nil {
+		categoryIDs = *req.CategoryIDs
+	}
+
+	productIDs := make([]string, len(group.Products))
+	for i, product := range group.Products {
+		productIDs[i] = product.ID
+	}
+	if req.ProductIDs != nil {
+		productIDs = *req.ProductIDs
+	}
+
+	if err := u.validateTargets(group.BusinessID, categoryIDs, productIDs); err != nil {
+		return nil, err
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.modifierRepo.UpdateModifierGroup(tx, group); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to update modifier group", zap.Error(err), zap.String("modifierGroupID", modifierGroupID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update modifier group")
+	}
+
+	if req.Modifiers != nil {
+		if err := u.modifierRepo.ReplaceModifiers(tx, group.ID, modifiers); err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to save modifiers", zap.Error(err), zap.String("modifierGroupID", modifierGroupID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update modifier group")
+		}
+	}
+
+	if err := u.modifierRepo.ReplaceModifierGroupTargets(tx, group.ID, categoryIDs, productIDs); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to set modifier group targets", zap.Error(err), zap.String("modifierGroupID", modifierGroupID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update modifier group")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update modifier group")
+	}
+
+	return u.GetModifierGroupByID(group.ID)
+}
+
+func (u *ModifierUsecase) GetModifierGroupByID(modifierGroupID string) (*contract.ModifierGroupRes, error) {
+	group, err := u.modifierRepo.GetModifierGroupByID(modifierGroupID)
+	if err != nil {
+		logger.Log.Error("Failed to get modifier group", zap.Error(err), zap.String("modifierGroupID", modifierGroupID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get modifier group")
+	}
+
+	if group == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Modifier group not found")
+	}
+
+	return buildModifierGroupRes(group), nil
+}
+
+// ListModifierGroups lists the groups of the business, or only the active ones offered on a product
+func (u *ModifierUsecase) ListModifierGroups(businessID string, productID *string) ([]contract.ModifierGroupRes, error) {
+	if productID == nil {
+		groups, err := u.modifierRepo.ListModifierGroups(businessID)
+		if err != nil {
+			logger.Log.Error("Failed to list modifier groups", zap.Error(err), zap.String("businessID", businessID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to list modifier groups")
+		}
+
+		results := make([]contract.ModifierGroupRes, len(groups))
+		for i := range groups {
+			results[i] = *buildModifierGroupRes(&groups[i])
+		}
+		return results, nil
+	}
+
+	product, err := u.productRepo.GetProductByIDAndBusinessID(*productID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get product", zap.Error(err), zap.String("productID", *productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get product")
+	}
+
+	if product == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Product not found")
+	}
+
+	groups, err := u.modifierRepo.ListActiveModifierGroups(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to list modifier groups", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to list modifier groups")
+	}
+
+	results := make([]contract.ModifierGroupRes, 0, len(groups))
+	for i := range groups {
+		if groups[i].AppliesTo(*product) {
+			results = append(results, *buildModifierGroupRes(&groups[i]))
+		}
+	}
+
+	return results, nil
+}
+
+func (u *ModifierUsecase) DeleteModifierGroup(modifierGroupID string) error {
+	if err := u.modifierRepo.DeleteModifierGroup(modifierGroupID); err != nil {
+		logger.Log.Error("Failed to delete modifier group", zap.Error(err), zap.String("modifierGroupID", modifierGroupID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete modifier group")
+	}
+
+	return nil
+}
+
+// ResolveModifiers checks the chosen modifiers of every cart line against the active groups offered on
+// its product and returns them as they are copied onto the line, in group order
+func (u *ModifierUsecase) ResolveModifiers(businessID string, items []contract.TransactionItemReq, productMap map[string]*model.Product) ([][]model.TransactionItemModifier, error) {
+	groups, err := u.modifierRepo.ListActiveModifierGroups(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to list modifier groups", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get modifiers")
+	}
+
+	// Stocked modifiers are checked once for the whole cart, they may be chosen on several lines
+	stocked := make([]model.Modifier, 0)
+	requested := make(map[string]int)
+
+	resolved := make([][]model.TransactionItemModifier, len(items))
+	for i, item := range items {
+		product := productMap[item.ProductID]
+
+		chosen := make(map[string]bool, len(item.ModifierIDs))
+		for _, modifierID := range item.ModifierIDs {
+			if chosen[modifierID] {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Modifier %s is chosen twice for %s", modifierID, product.Name))
+			}
+			chosen[modifierID] = true
+		}
+
+		modifiers := make([]model.TransactionItemModifier, 0, len(item.ModifierIDs))
+		for _, group := range groups {
+			if !group.AppliesTo(*product) {
+				continue
+			}
+
+			count := 0
+			for _, modifier := range group.Modifiers {
+				if !chosen[modifier.ID] {
+					continue
+				}
+				delete(chosen, modifier.ID)
+
+				if !modifier.IsActive {
+					return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Modifier %s is not available", modifier.Name))
+				}
+				if modifier.EnableStock && modifier.StockQty != nil {
+					if _, exists := requested[modifier.ID]; !exists {
+						stocked = append(stocked, modifier)
+					}
+					requested[modifier.ID] += item.Quantity
+				}
+
+				modifiers = append(modifiers, model.TransactionItemModifier{
+					ModifierID: modifier.ID,
+					GroupName:  group.Name,
+					Name:       modifier.Name,
+					Price:      modifier.Price,
+				})
+				count++
+			}
+
+			if count < group.MinSelections {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Choose at least %d %s for %s", group.MinSelections, group.Name, product.Name))
+			}
+			if count > group.MaxSelections {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Choose at most %d %s for %s", group.MaxSelections, group.Name, product.Name))
+			}
+		}
+
+		// Whatever is left does not belong to a group offered on the product
+		for _, modifierID := range item.ModifierIDs {
+			if chosen[modifierID] {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Modifier %s is not offered on %s", modifierID, product.Name))
+			}
+		}
+
+		resolved[i] = modifiers
+	}
+
+	for _, modifier := range stocked {
+		if *modifier.StockQty < requested[modifier.ID] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient stock for modifier %s. Available: %d, Requested: %d", modifier.Name, *modifier.StockQty, requested[modifier.ID]))
+		}
+	}
+
+	return resolved, nil
+}
+
+// DeductStock takes the chosen modifiers that keep stock out of stock, once per unit sold.
+// Modifier stock is a plain counter, it has no stock movements as the ledger is per product.
+func (u *ModifierUsecase) DeductStock(tx *gorm.DB, items []*model.TransactionItem) error {
+	stockMap, err := u.getStockedModifiers(collectModifierIDs(items))
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		for _, modifier := range item.Modifiers {
+			if !stockMap[modifier.ModifierID] {
+				continue
+			}
+
+			if err := u.modifierRepo.DecreaseStock(tx, modifier.ModifierID, item.Quantity); err != nil {
+				logger.Log.Error("Failed to update modifier stock", zap.Error(err), zap.String("modifierID", modifier.ModifierID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to update stock")
+			}
+		}
+	}
+	return nil
+}
+
+// RestoreStock puts the modifiers of a line back into stock for the given quantity.
+// Modifiers deleted since the sale are skipped.
+func (u *ModifierUsecase) RestoreStock(tx *gorm.DB, modifiers []model.TransactionItemModifier, quantity int) error {
+	if len(modifiers) == 0 {
+		return nil
+	}
+
+	modifierIDs := make([]string, len(modifiers))
+	for i, modifier := range modifiers {
+		modifierIDs[i] = modifier.ModifierID
+	}
+
+	stockMap, err := u.getStockedModifiers(modifierIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, modifierID := range modifierIDs {
+		if !stockMap[modifierID] {
+			continue
+		}
+
+		if err := u.modifierRepo.IncreaseStock(tx, modifierID, quantity); err != nil {
+			logger.Log.Error("Failed to restore modifier stock", zap.Error(err), zap.String("modifierID", modifierID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to restore stock")
+		}
+	}
+	return nil
+}
+
+// getStockedModifiers reports which of the modifiers keep stock
+func (u *ModifierUsecase) getStockedModifiers(modifierIDs []string) (map[string]bool, error) {
+	stockMap := make(map[string]bool, len(modifierIDs))
+	if len(modifierIDs) == 0 {
+		return stockMap, nil
+	}
+
+	modifiers, err := u.modifierRepo.GetModifiersByIDs(modifierIDs)
+	if err != nil {
+		logger.Log.Error("Failed to fetch modifiers", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch modifiers")
+	}
+
+	for _, modifier := range modifiers {
+		stockMap[modifier.ID] = modifier.EnableStock
+	}
+	return stockMap, nil
+}
+
+// validateTargets checks that the group is offered somewhere and that the targets belong to the business
+func (u *ModifierUsecase) validateTargets(businessID string, categoryIDs, productIDs []string) error {
+	if len(categoryIDs) == 0 && len(productIDs) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Choose at least one category or product to offer the modifiers on")
+	}
+
+	for _, categoryID := range categoryIDs {
+		category, err := u.categoryRepo.GetCategoryByIDAndBusinessID(categoryID, businessID)
+		if err != nil {
+			logger.Log.Error("Failed to get category", zap.Error(err), zap.String("categoryID", categoryID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get category")
+		}
+		if category == nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Category %s not found", categoryID))
+		}
+	}
+
+	if len(productIDs) > 0 {
+		products, err := u.productRepo.GetProductsByIDs(productIDs)
+		if err != nil {
+			logger.Log.Error("Failed to fetch products", zap.Error(err))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+		}
+
+		found := make(map[string]bool, len(products))
+		for _, product := range products {
+			if product.BusinessID == businessID {
+				found[product.ID] = true
+			}
+		}
+
+		for _, productID := range productIDs {
+			if !found[productID] {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s not found", productID))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *ModifierUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, modifierGroupID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if modifierGroupID != nil {
+			group, err := u.modifierRepo.GetModifierGroupByIDAndBusinessID(*modifierGroupID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get modifier group", zap.Error(err), zap.String("modifierGroupID", *modifierGroupID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get modifier group")
+			}
+
+			if group == nil {
+				logger.Log.Warn("Modifier group not found", zap.String("modifierGroupID", *modifierGroupID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildModifiers turns the requested modifiers into models in the order sent.
+// Modifiers sent with an ID must already be in the group.
+func buildModifiers(businessID string, existing []model.Modifier, reqs []contract.ModifierReq) ([]model.Modifier, error) {
+	existingMap := make(map[string]model.Modifier, len(existing))
+	for _, modifier := range existing {
+		existingMap[modifier.ID] = modifier
+	}
+
+	names := make(map[string]bool, len(reqs))
+	modifiers := make([]model.Modifier, len(reqs))
+	for i, req := range reqs {
+		key := strings.ToLower(strings.TrimSpace(req.Name))
+		if names[key] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Modifier %s is listed twice", req.Name))
+		}
+		names[key] = true
+
+		modifier := model.Modifier{BusinessID: businessID, IsActive: true}
+		if req.ID != nil {
+			current, exists := existingMap[*req.ID]
+			if !exists {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Modifier %s not found", *req.ID))
+			}
+			modifier = current
+		}
+
+		modifier.Name = req.Name
+		modifier.Price = req.Price
+		modifier.EnableStock = req.EnableStock
+		modifier.SortOrder = i
+		if req.IsActive != nil {
+			modifier.IsActive = *req.IsActive
+		}
+		if req.StockQty != nil {
+			modifier.StockQty = req.StockQty
+		}
+		if modifier.EnableStock && modifier.StockQty == nil {
+			modifier.StockQty = new(int)
+		}
+		modifiers[i] = modifier
+	}
+
+	return modifiers, nil
+}
+
+// validateSelectionLimits checks that the group's limits can be met with its modifiers
+func validateSelectionLimits(group *model.ModifierGroup, modifierCount int) error {
+	if group.MinSelections > group.MaxSelections {
+		return fiber.NewError(fiber.StatusBadRequest, "Minimum selections cannot be more than maximum selections")
+	}
+	if group.MinSelections > modifierCount {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Minimum selections cannot be more than the %d modifiers of the group", modifierCount))
+	}
+	return nil
+}
+
+// collectModifierIDs returns the IDs of the modifiers chosen on the lines
+func collectModifierIDs(items []*model.TransactionItem) []string {
+	var modifierIDs []string
+	for _, item := range items {
+		for _, modifier := range item.Modifiers {
+			modifierIDs = append(modifierIDs, modifier.ModifierID)
+		}
+	}
+	return modifierIDs
+}
+
+// buildModifierGroupRes builds modifier group response
+func buildModifierGroupRes(group *model.ModifierGroup) *contract.ModifierGroupRes {
+	modifiers := make([]contract.ModifierRes, len(group.Modifiers))
+	for i, modifier := range group.Modifiers {
+		modifiers[i] = contract.ModifierRes{
+			ID:          modifier.ID,
+			Name:        modifier.Name,
+			Price:       modifier.Price,
+			EnableStock: modifier.EnableStock,
+			StockQty:    modifier.StockQty,
+			IsActive:    modifier.IsActive,
+			SortOrder:   modifier.SortOrder,
+		}
+	}
+
+	categoryIDs := make([]string, len(group.Categories))
+	for i, category := range group.Categories {
+		categoryIDs[i] = category.ID
+	}
+
+	productIDs := make([]string, len(group.Products))
+	for i, product := range group.Products {
+		productIDs[i] = product.ID
+	}
+
+	return &contract.ModifierGroupRes{
+		ID:            group.ID,
+		BusinessID:    group.BusinessID,
+		Name:          group.Name,
+		MinSelections: group.MinSelections,
+		MaxSelections: group.MaxSelections,
+		IsActive:      group.IsActive,
+		SortOrder:     group.SortOrder,
+		Modifiers:     modifiers,
+		CategoryIDs:   categoryIDs,
+		ProductIDs:    productIDs,
+		CreatedAt:     group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     group.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// buildTransactionItemModifiersRes builds the modifier lines of a sale or held cart line
+func buildTransactionItemModifiersRes(modifiers []model.TransactionItemModifier) []contract.TransactionItemModifierRes {
+	results := make([]contract.TransactionItemModifierRes, len(modifiers))
+	for i, modifier := range modifiers {
+		results[i] = contract.TransactionItemModifierRes{
+			ModifierID: modifier.ModifierID,
+			GroupName:  modifier.GroupName,
+			Name:       modifier.Name,
+			Price:      modifier.Price,
+		}
+	}
+	return results
+}
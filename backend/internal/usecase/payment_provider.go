@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// PaymentProvider is a payment gateway that collects non-cash payments for a transaction
+type PaymentProvider interface {
+	Name() string
+	// CreateCharge asks the gateway to collect the amount, the result carries the gateway reference
+	CreateCharge(req PaymentChargeReq) (*PaymentChargeResult, error)
+	// GetChargeStatus polls the gateway for the latest state of a charge
+	GetChargeStatus(reference string) (*PaymentChargeResult, error)
+	// VerifyWebhook authenticates a callback and parses it, returns ErrInvalidWebhookSignature when it cannot be trusted
+	VerifyWebhook(header http.Header, body []byte) (*PaymentChargeResult, error)
+}
+
+type PaymentChargeReq struct {
+	ExternalID  string
+	Amount      float64
+	Description string
+	ExpiresAt   time.Time
+}
+
+type PaymentChargeResult struct {
+	Reference   string
+	Status      config.PaymentChargeStatus
+	Method      *config.PaymentMethod
+	CheckoutURL *string
+	QrString    *string
+	PaidAmount  *float64
+	PaidAt      *time.Time
+	ExpiresAt   *time.Time
+}
+
+// isGatewayPaymentMethod reports whether a gateway can settle a charge with the method.
+// Cash, credit, points and gift cards have their own flows at the till.
+func isGatewayPaymentMethod(method config.PaymentMethod) bool {
+	switch method {
+	case config.PAYMENT_METHOD_QRIS, config.PAYMENT_METHOD_CARD, config.PAYMENT_METHOD_BANK_TRANSFER:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewPaymentProvider returns the provider selected by PAYMENT_PROVIDER
+func NewPaymentProvider() PaymentProvider {
+	switch config.PaymentProviderName(config.Env.Payment.Provider) {
+	case config.PAYMENT_PROVIDER_XENDIT:
+		return NewXenditPaymentProvider(config.Env.Payment.XenditBaseURL, config.Env.Payment.XenditSecretKey, config.Env.Payment.CallbackToken)
+	default:
+		return NewFakePaymentProvider(config.Env.Payment.CallbackToken)
+	}
+}
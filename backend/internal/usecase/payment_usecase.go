@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PaymentUsecase struct {
+	paymentChargeRepo *repository.PaymentChargeRepository
+	transactionRepo   *repository.TransactionRepository
+	loyaltyUsecase    *LoyaltyUsecase
+	ingredientUsecase *IngredientUsecase
+	provider          PaymentProvider
+	db                *gorm.DB
+}
+
+func NewPaymentUsecase(
+	paymentChargeRepo *repository.PaymentChargeRepository,
+	transactionRepo *repository.TransactionRepository,
+	loyaltyUsecase *LoyaltyUsecase,
+	ingredientUsecase *IngredientUsecase,
+	provider PaymentProvider,
+	db *gorm.DB,
+) *PaymentUsecase {
+	return &PaymentUsecase{
+		paymentChargeRepo: paymentChargeRepo,
+		transactionRepo:   transactionRepo,
+		loyaltyUsecase:    loyaltyUsecase,
+		ingredientUsecase: ingredientUsecase,
+		provider:          provider,
+		db:                db,
+	}
+}
+
+// CreateCharge starts a gateway payment for a pending transaction.
+// An unexpired charge for the same amount is returned as is, so retries do not open a second invoice.
+func (u *PaymentUsecase) CreateCharge(businessID, transactionID string) (*contract.PaymentChargeRes, error) {
+	transaction, err := u.transactionRepo.GetTransactionByIDAndBusinessID(transactionID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if err := validatePendingTransaction(transaction); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.paymentChargeRepo.GetActiveChargeByTransactionID(transactionID, time.Now())
+	if err != nil {
+		logger.Log.Error("Failed to get payment charge", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create payment charge")
+	}
+	if existing != nil && existing.Provider == u.provider.Name() && existing.Amount == transaction.TotalAmount {
+		return util.ToPointer(buildPaymentChargeRes(*existing)), nil
+	}
+
+	chargeID := uuid.NewString()
+	result, err := u.provider.CreateCharge(PaymentChargeReq{
+		ExternalID:  chargeID,
+		Amount:      transaction.TotalAmount,
+		Description: fmt.Sprintf("Payment for %s", transaction.InvoiceNumber),
+		ExpiresAt:   transaction.ExpiredAt,
+	})
+	if err != nil {
+		logger.Log.Error("Failed to create payment charge", zap.Error(err), zap.String("provider", u.provider.Name()))
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Failed to create payment charge")
+	}
+
+	charge := &model.PaymentCharge{
+		ID:            chargeID,
+		BusinessID:    businessID,
+		TransactionID: transactionID,
+		Provider:      u.provider.Name(),
+		Reference:     result.Reference,
+		Amount:        transaction.TotalAmount,
+		Status:        config.PAYMENT_CHARGE_STATUS_PENDING,
+		CheckoutURL:   result.CheckoutURL,
+		QrString:      result.QrString,
+		ExpiresAt:     result.ExpiresAt,
+	}
+
+	if err := u.paymentChargeRepo.CreateCharge(charge); err != nil {
+		logger.Log.Error("Failed to save payment charge", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create payment charge")
+	}
+
+	return util.ToPointer(buildPaymentChargeRes(*charge)), nil
+}
+
+// CheckCharge polls the gateway for a pending charge and settles it when it has been paid
+func (u *PaymentUsecase) CheckCharge(transactionID, chargeID string) (*contract.PaymentChargeRes, error) {
+	charge, err := u.paymentChargeRepo.GetChargeByIDAndTransactionID(chargeID, transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to get payment charge", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get payment charge")
+	}
+
+	if charge == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Payment charge not found")
+	}
+
+	if charge.Status != config.PAYMENT_CHARGE_STATUS_PENDING || charge.Provider != u.provider.Name() {
+		return util.ToPointer(buildPaymentChargeRes(*charge)), nil
+	}
+
+	result, err := u.provider.GetChargeStatus(charge.Reference)
+	if err != nil {
+		logger.Log.Error("Failed to check payment charge", zap.Error(err), zap.String("chargeID", chargeID))
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Failed to check payment charge")
+	}
+
+	updated, err := u.settleCharge(result, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.ToPointer(buildPaymentChargeRes(*updated)), nil
+}
+
+// HandleWebhook verifies a gateway callback and applies it to the matching charge
+func (u *PaymentUsecase) HandleWebhook(provider string, header http.Header, body []byte) error {
+	if provider != u.provider.Name() {
+		return fiber.NewError(fiber.StatusNotFound, "Unknown payment provider")
+	}
+
+	result, err := u.provider.VerifyWebhook(header, body)
+	if err != nil {
+		if errors.Is(err, ErrInvalidWebhookSignature) {
+			logger.Log.Warn("Rejected payment webhook with invalid signature", zap.String("provider", provider))
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid webhook signature")
+		}
+		logger.Log.Warn("Failed to parse payment webhook", zap.Error(err), zap.String("provider", provider))
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid webhook payload")
+	}
+
+	payload := string(body)
+	_, err = u.settleCharge(result, &payload)
+	return err
+}
+
+// settleCharge applies the gateway state to the charge and, once paid, to its transaction.
+// Charges that are already final are left untouched, so repeated callbacks are harmless.
+func (u *PaymentUsecase) settleCharge(result *PaymentChargeResult, payload *string) (*model.PaymentCharge, error) {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	charge, err := u.paymentChargeRepo.GetChargeForUpdate(tx, u.provider.Name(), result.Reference)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get payment charge", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get payment charge")
+	}
+
+	if charge == nil {
+		tx.Rollback()
+		logger.Log.Warn("Payment charge not found", zap.String("reference", result.Reference))
+		return nil, fiber.NewError(fiber.StatusNotFound, "Payment charge not found")
+	}
+
+	// Already settled or nothing new to apply
+	if charge.Status == config.PAYMENT_CHARGE_STATUS_PAID || result.Status == charge.Status {
+		tx.Rollback()
+		return charge, nil
+	}
+
+	if payload != nil {
+		charge.WebhookPayload = payload
+	}
+
+	if result.Status == config.PAYMENT_CHARGE_STATUS_PAID {
+		if err := u.applyPaidCharge(tx, charge, result); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else {
+		charge.Status = result.Status
+	}
+
+	if err := u.paymentChargeRepo.UpdateCharge(tx, charge); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to update payment charge", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update payment charge")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to settle payment charge")
+	}
+
+	return charge, nil
+}
+
+// applyPaidCharge marks the charge paid and settles its transaction.
+// Money that arrives for a transaction that can no longer take it is flagged for manual reconciliation.
+func (u *PaymentUsecase) applyPaidCharge(tx *gorm.DB, charge *model.PaymentCharge, result *PaymentChargeResult) error {
+	paidAt := time.Now()
+	if result.PaidAt != nil {
+		paidAt = *result.PaidAt
+	}
+
+	paidAmount := charge.Amount
+	if result.PaidAmount != nil {
+		paidAmount = *result.PaidAmount
+	}
+
+	method := config.PAYMENT_METHOD_QRIS
+	if result.Method != nil {
+		method = *result.Method
+	}
+
+	charge.Status = config.PAYMENT_CHARGE_STATUS_PAID
+	charge.PaidAt = &paidAt
+	charge.PaidAmount = &paidAmount
+	charge.Method = &method
+
+	transaction, err := u.transactionRepo.GetTransactionForUpdate(tx, charge.TransactionID, charge.BusinessID)
+	if err != nil {
+		logger.Log.Error("Failed to get transaction", zap.Error(err))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	var note string
+	switch {
+	case transaction == nil:
+		note = "Transaction no longer exists"
+	case transaction.Status != config.TRANSACTION_STATUS_PENDING:
+		note = fmt.Sprintf("Payment received after the transaction was %s", transaction.Status)
+	case !isGatewayPaymentMethod(method):
+		note = fmt.Sprintf("Payment method %s cannot be settled by a gateway", method)
+	case paidAmount < transaction.TotalAmount:
+		note = fmt.Sprintf("Paid amount %.2f is less than the transaction total %.2f", paidAmount, transaction.TotalAmount)
+	case paidAmount > transaction.TotalAmount:
+		// The cart can change after the charge was opened, the difference has to be paid back by hand
+		note = fmt.Sprintf("Paid amount %.2f is more than the transaction total %.2f", paidAmount, transaction.TotalAmount)
+	}
+
+	if note != "" {
+		charge.NeedsReconciliation = true
+		charge.ReconciliationNote = &note
+		logger.Log.Warn("Payment charge needs reconciliation", zap.String("chargeID", charge.ID), zap.String("note", note))
+		return nil
+	}
+
+	// Gateway payments are exact, there is no cash to round. The gateway has taken the money either way,
+	// so a payment the transaction cannot take is flagged rather than failing the callback.
+	payments := []contract.PaymentReq{{Method: string(method), Amount: paidAmount, Reference: &charge.Reference}}
+	if err := applyPayments(transaction, payments, nil, cashRounding{}); err != nil {
+		note = err.Error()
+		charge.NeedsReconciliation = true
+		charge.ReconciliationNote = &note
+		logger.Log.Warn("Payment charge needs reconciliation", zap.String("chargeID", charge.ID), zap.String("note", note))
+		return nil
+	}
+
+	// Keep the gateway's settlement time rather than the time the callback arrived
+	transaction.PaidAt = &paidAt
+	for i := range transaction.Payments {
+		transaction.Payments[i].PaidAt = paidAt
+	}
+
+	if err := u.transactionRepo.SaveTransaction(tx, transaction); err != nil {
+		logger.Log.Error("Failed to update transaction", zap.Error(err))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to finalize payment")
+	}
+
+	if err := createPayments(tx, transaction); err != nil {
+		return err
+	}
+
+	if err := u.ingredientUsecase.DeductStock(tx, transaction.Items); err != nil {
+		return err
+	}
+
+	// Gateway payments have no employee behind them
+	return u.loyaltyUsecase.ApplyPaidTransaction(tx, transaction, nil)
+}
+
+// IsAllowedToAccess checks if user has permission to take gateway payments for the transaction
+func (u *PaymentUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, transactionID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	if permission.Scope() == config.PERMISSION_SCOPE_ORG && transactionID != nil {
+		transaction, err := u.transactionRepo.GetTransactionByIDAndBusinessID(*transactionID, *claims.BusinessID)
+		if err != nil {
+			logger.Log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionID", *transactionID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+		}
+
+		if transaction == nil {
+			logger.Log.Warn("Transaction not found", zap.String("transactionID", *transactionID))
+			return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+		}
+	}
+
+	return nil
+}
+
+func buildPaymentChargeRes(charge model.PaymentCharge) contract.PaymentChargeRes {
+	var method *string
+	if charge.Method != nil {
+		method = util.ToPointer(string(*charge.Method))
+	}
+
+	var paidAtStr *string
+	if charge.PaidAt != nil {
+		paidAtStr = util.ToPointer(charge.PaidAt.Format(time.RFC3339))
+	}
+
+	var expiresAtStr *string
+	if charge.ExpiresAt != nil {
+		expiresAtStr = util.ToPointer(charge.ExpiresAt.Format(time.RFC3339))
+	}
+
+	return contract.PaymentChargeRes{
+		ID:                  charge.ID,
+		TransactionID:       charge.TransactionID,
+		Provider:            charge.Provider,
+		Reference:           charge.Reference,
+		Amount:              charge.Amount,
+		Status:              string(charge.Status),
+		Method:              method,
+		CheckoutURL:         charge.CheckoutURL,
+		QrString:            charge.QrString,
+		PaidAmount:          charge.PaidAmount,
+		PaidAt:              paidAtStr,
+		ExpiresAt:           expiresAtStr,
+		NeedsReconciliation: charge.NeedsReconciliation,
+		ReconciliationNote:  charge.ReconciliationNote,
+		CreatedAt:           charge.CreatedAt.Format(time.RFC3339),
+	}
+}
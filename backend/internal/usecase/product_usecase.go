@@ -9,28 +9,36 @@ import (
 	"app/pkg/logger"
 	"app/pkg/storage"
 	"app/pkg/util"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jinzhu/copier"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type ProductUsecase struct {
-	productRepo  *repository.ProductRepository
-	businessRepo *repository.BusinessRepository
-	storage      *storage.R2Storage
+	productRepo    *repository.ProductRepository
+	businessRepo   *repository.BusinessRepository
+	ingredientRepo *repository.IngredientRepository
+	db             *gorm.DB
+	storage        *storage.R2Storage
 }
 
-func NewProductUsecase(productRepo *repository.ProductRepository, businessRepo *repository.BusinessRepository, storage *storage.R2Storage) *ProductUsecase {
+func NewProductUsecase(productRepo *repository.ProductRepository, businessRepo *repository.BusinessRepository, ingredientRepo *repository.IngredientRepository, db *gorm.DB, storage *storage.R2Storage) *ProductUsecase {
 	return &ProductUsecase{
-		productRepo:  productRepo,
-		businessRepo: businessRepo,
-		storage:      storage,
+		productRepo:    productRepo,
+		businessRepo:   businessRepo,
+		ingredientRepo: ingredientRepo,
+		db:             db,
+		storage:        storage,
 	}
 }
 
-func (u *ProductUsecase) CreateProduct(businessID string, req *contract.CreateProductReq) (*contract.ProductRes, error) {
+func (u *ProductUsecase) CreateProduct(userID, businessID string, req *contract.CreateProductReq) (*contract.ProductRes, error) {
 
 	product := &model.Product{}
 	copier.Copy(product, req)
@@ -38,15 +46,43 @@ func (u *ProductUsecase) CreateProduct(businessID string, req *contract.CreatePr
 	product.BusinessID = businessID
 	product.IsActive = true
 
-	if err := u.productRepo.CreateProduct(product); err != nil {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.productRepo.CreateProduct(tx, product); err != nil {
+		tx.Rollback()
 		logger.Log.Error("Failed to create product", zap.Error(err), zap.String("businessID", businessID))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create product")
 	}
 
+	if product.StockQty != nil {
+		movement := &model.StockMovement{
+			BusinessID: businessID,
+			ProductID:  product.ID,
+			Type:       config.STOCK_MOVEMENT_TYPE_STOCK_TAKE,
+			Reason:     util.ToPointer("Opening stock"),
+			CreatedBy:  &userID,
+		}
+		if err := u.productRepo.SetStock(tx, movement, *product.StockQty); err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to set opening stock", zap.Error(err), zap.String("productID", product.ID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create product")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create product")
+	}
+
 	return u.buildProductRes(product), nil
 }
 
-func (u *ProductUsecase) UpdateProduct(productID string, req *contract.UpdateProductReq) (*contract.ProductRes, error) {
+// UpdateProduct updates a product. A changed stock quantity is recorded as an adjustment in the stock ledger.
+func (u *ProductUsecase) UpdateProduct(userID, productID string, req *contract.UpdateProductReq) (*contract.ProductRes, error) {
 
 	product, err := u.productRepo.GetProductByID(productID)
 	if err != nil {
@@ -58,15 +94,49 @@ func (u *ProductUsecase) UpdateProduct(productID string, req *contract.UpdatePro
 		return nil, fiber.NewError(fiber.StatusNotFound, "Product not found")
 	}
 
+	// The cost of a product with a recipe comes from its ingredients
+	cost := product.Cost
+	stockQty := util.ToValue(product.StockQty)
+	stockCounted := product.StockQty != nil
 	copier.CopyWithOption(product, req, copier.Option{
 		IgnoreEmpty: true,
 	})
+	if product.HasRecipe {
+		product.Cost = cost
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
 
-	if err := u.productRepo.UpdateProduct(product); err != nil {
+	if err := u.productRepo.UpdateProduct(tx, product); err != nil {
+		tx.Rollback()
 		logger.Log.Error("Failed to update product", zap.Error(err), zap.String("productID", productID))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product")
 	}
 
+	if req.StockQty != nil && (!stockCounted || *req.StockQty != stockQty) {
+		movement := &model.StockMovement{
+			BusinessID: product.BusinessID,
+			ProductID:  product.ID,
+			Type:       config.STOCK_MOVEMENT_TYPE_ADJUSTMENT,
+			Reason:     util.ToPointer("Stock changed on the product"),
+			CreatedBy:  &userID,
+		}
+		if err := u.productRepo.SetStock(tx, movement, *req.StockQty); err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to set stock", zap.Error(err), zap.String("productID", productID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product")
+	}
+
 	return u.buildProductRes(product), nil
 }
 
@@ -99,6 +169,343 @@ func (u *ProductUsecase) ListProducts(businessID string, page, pageSize int, sea
 	return productResList, total, nil
 }
 
+// SetProductOptions replaces the option groups of a product and generates a variant for every combination of values.
+// Combinations that already exist keep their price, stock, SKU and barcode. New ones start from the product's price and cost.
+func (u *ProductUsecase) SetProductOptions(productID string, req *contract.SetProductOptionsReq) (*contract.ProductRes, error) {
+	product, err := u.productRepo.GetProductByID(productID)
+	if err != nil {
+		logger.Log.Error("Failed to get product", zap.Error(err), zap.String("productID", productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get product")
+	}
+
+	if product == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Product not found")
+	}
+
+	groups := make([]model.ProductOptionGroup, len(req.Groups))
+	combinations := 1
+	groupNames := make(map[string]bool, len(req.Groups))
+	for i, group := range req.Groups {
+		name := strings.TrimSpace(group.Name)
+		if groupNames[strings.ToLower(name)] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Option %s is listed twice", name))
+		}
+		groupNames[strings.ToLower(name)] = true
+
+		values := make([]string, len(group.Values))
+		seen := make(map[string]bool, len(group.Values))
+		for j, value := range group.Values {
+			value = strings.TrimSpace(value)
+			if value == "" || seen[strings.ToLower(value)] {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Values of option %s must be filled in and different", name))
+			}
+			seen[strings.ToLower(value)] = true
+			values[j] = value
+		}
+
+		combinations *= len(values)
+		groups[i] = model.ProductOptionGroup{
+			ProductID: productID,
+			Name:      name,
+			Values:    values,
+			SortOrder: i,
+		}
+	}
+
+	if len(groups) > 0 && combinations > config.PRODUCT_VARIANT_MAX_COUNT {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Options make %d variants, at most %d are allowed", combinations, config.PRODUCT_VARIANT_MAX_COUNT))
+	}
+
+	// Existing variants are matched on their option values, so renaming a group keeps them
+	existing := make(map[string]model.ProductVariant, len(product.Variants))
+	for _, variant := range product.Variants {
+		existing[variantKey(variant.Options)] = variant
+	}
+
+	var variants []model.ProductVariant
+	if len(groups) > 0 {
+		for i, options := range optionCombinations(groups) {
+			variant, ok := existing[variantKey(options)]
+			if !ok {
+				variant = model.ProductVariant{
+					BusinessID: product.BusinessID,
+					ProductID:  productID,
+					Price:      product.Price,
+					Cost:       product.Cost,
+					IsActive:   true,
+				}
+				if product.EnableStock {
+					variant.StockQty = util.ToPointer(0)
+				}
+			}
+			variant.Options = options
+			variant.Label = model.VariantLabel(options)
+			variant.SortOrder = i
+			variants = append(variants, variant)
+		}
+	}
+
+	if err := u.productRepo.SaveProductOptions(productID, groups, variants); err != nil {
+		logger.Log.Error("Failed to save product options", zap.Error(err), zap.String("productID", productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to save product options")
+	}
+
+	return u.GetProductByID(productID)
+}
+
+// UpdateProductVariant changes the price, cost, stock, SKU or barcode of one variant.
+// A changed stock quantity is recorded as an adjustment in the stock ledger.
+func (u *ProductUsecase) UpdateProductVariant(userID, productID, variantID string, req *contract.UpdateProductVariantReq) (*contract.ProductVariantRes, error) {
+	variant, err := u.productRepo.GetVariantByIDAndProductID(variantID, productID)
+	if err != nil {
+		logger.Log.Error("Failed to get product variant", zap.Error(err), zap.String("variantID", variantID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get product variant")
+	}
+
+	if variant == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Product variant not found")
+	}
+
+	if req.SKU != nil {
+		sku := strings.TrimSpace(*req.SKU)
+		if sku == "" {
+			variant.SKU = nil
+		} else {
+			other, err := u.productRepo.FindVariantBySKU(variant.BusinessID, sku)
+			if err != nil {
+				logger.Log.Error("Failed to check variant SKU", zap.Error(err), zap.String("variantID", variantID))
+				return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product variant")
+			}
+			if other != nil && other.ID != variant.ID {
+				return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("SKU %s is already used by %s", sku, other.Label))
+			}
+			variant.SKU = &sku
+		}
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.Cost != nil && !variant.Product.HasRecipe {
+		variant.Cost = req.Cost
+	}
+	stockQty := util.ToValue(variant.StockQty)
+	stockCounted := variant.StockQty != nil
+	if req.StockQty != nil {
+		variant.StockQty = req.StockQty
+	}
+	if req.BarcodeValue != nil {
+		variant.BarcodeValue = req.BarcodeValue
+	}
+	if req.BarcodeType != nil {
+		variant.BarcodeType = util.ToPointer(config.BarcodeType(*req.BarcodeType))
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.productRepo.UpdateVariant(tx, variant); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to update product variant", zap.Error(err), zap.String("variantID", variantID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product variant")
+	}
+
+	if req.StockQty != nil && (!stockCounted || *req.StockQty != stockQty) {
+		movement := &model.StockMovement{
+			BusinessID: variant.BusinessID,
+			ProductID:  productID,
+			VariantID:  &variant.ID,
+			Type:       config.STOCK_MOVEMENT_TYPE_ADJUSTMENT,
+			Reason:     util.ToPointer("Stock changed on the variant"),
+			CreatedBy:  &userID,
+		}
+		if err := u.productRepo.SetStock(tx, movement, *req.StockQty); err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to set variant stock", zap.Error(err), zap.String("variantID", variantID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product variant")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update product variant")
+	}
+
+	return util.ToPointer(buildProductVariantRes(*variant, 0)), nil
+}
+
+// SetProductRecipe replaces the ingredients used for one unit of a product and works out its cost from them.
+// The ingredients are deducted when a sale of the product is paid.
+func (u *ProductUsecase) SetProductRecipe(productID string, req *contract.SetProductRecipeReq) (*contract.ProductRes, error) {
+	product, err := u.productRepo.GetProductByID(productID)
+	if err != nil {
+		logger.Log.Error("Failed to get product", zap.Error(err), zap.String("productID", productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get product")
+	}
+
+	if product == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Product not found")
+	}
+
+	items := make([]model.ProductRecipeItem, len(req.Items))
+	ingredientIDs := make([]string, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		if seen[item.IngredientID] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Ingredient %s is listed twice", item.IngredientID))
+		}
+		seen[item.IngredientID] = true
+
+		ingredientIDs[i] = item.IngredientID
+		items[i] = model.ProductRecipeItem{
+			ProductID:    productID,
+			IngredientID: item.IngredientID,
+			Quantity:     item.Quantity,
+		}
+	}
+
+	if len(ingredientIDs) > 0 {
+		ingredients, err := u.ingredientRepo.GetIngredientsByIDs(ingredientIDs)
+		if err != nil {
+			logger.Log.Error("Failed to fetch ingredients", zap.Error(err))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch ingredients")
+		}
+
+		found := make(map[string]bool, len(ingredients))
+		for _, ingredient := range ingredients {
+			if ingredient.BusinessID == product.BusinessID {
+				found[ingredient.ID] = true
+			}
+		}
+
+		for _, ingredientID := range ingredientIDs {
+			if !found[ingredientID] {
+				return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Ingredient %s not found", ingredientID))
+			}
+		}
+	}
+
+	if err := u.productRepo.SaveProductRecipe(productID, items); err != nil {
+		logger.Log.Error("Failed to save product recipe", zap.Error(err), zap.String("productID", productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to save product recipe")
+	}
+
+	return u.GetProductByID(productID)
+}
+
+// RecordStockMovement records goods received, transferred, adjusted or counted for a product or one of its variants
+func (u *ProductUsecase) RecordStockMovement(userID, productID string, req *contract.RecordStockMovementReq) (*contract.StockMovementRes, error) {
+	product, err := u.productRepo.GetProductByID(productID)
+	if err != nil {
+		logger.Log.Error("Failed to get product", zap.Error(err), zap.String("productID", productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get product")
+	}
+
+	if product == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Product not found")
+	}
+
+	if !product.EnableStock {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Stock is not kept for this product")
+	}
+
+	// A product with variants is stocked per variant
+	switch {
+	case product.HasVariants && req.VariantID == nil:
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Choose the variant the stock belongs to")
+	case !product.HasVariants && req.VariantID != nil:
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Product has no variants")
+	case req.VariantID != nil:
+		variant, err := u.productRepo.GetVariantByIDAndProductID(*req.VariantID, productID)
+		if err != nil {
+			logger.Log.Error("Failed to get product variant", zap.Error(err), zap.String("variantID", *req.VariantID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get product variant")
+		}
+		if variant == nil {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Product variant not found")
+		}
+	}
+
+	movementType := config.StockMovementType(req.Type)
+	switch movementType {
+	case config.STOCK_MOVEMENT_TYPE_RECEIPT:
+		if req.Quantity <= 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Received quantity must be greater than zero")
+		}
+	case config.STOCK_MOVEMENT_TYPE_STOCK_TAKE:
+		if req.Quantity < 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Counted quantity cannot be negative")
+		}
+	default:
+		if req.Quantity == 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Quantity cannot be zero")
+		}
+	}
+
+	reason := emptyToNil(req.Reason)
+	if movementType == config.STOCK_MOVEMENT_TYPE_ADJUSTMENT && reason == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Reason is required for an adjustment")
+	}
+
+	movement := &model.StockMovement{
+		BusinessID: product.BusinessID,
+		ProductID:  productID,
+		VariantID:  req.VariantID,
+		Type:       movementType,
+		Quantity:   req.Quantity,
+		Reference:  emptyToNil(req.Reference),
+		Reason:     reason,
+		CreatedBy:  &userID,
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if movementType == config.STOCK_MOVEMENT_TYPE_STOCK_TAKE {
+		err = u.productRepo.SetStock(tx, movement, req.Quantity)
+	} else {
+		err = u.productRepo.MoveStock(tx, movement)
+	}
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Not enough stock to take out")
+		}
+		logger.Log.Error("Failed to record stock movement", zap.Error(err), zap.String("productID", productID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record stock movement")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to record stock movement")
+	}
+
+	return util.ToPointer(buildStockMovementRes(*movement)), nil
+}
+
+func (u *ProductUsecase) ListStockMovements(productID string, variantID *string, page, pageSize int) ([]contract.StockMovementRes, int64, error) {
+	movements, total, err := u.productRepo.ListStockMovements(productID, variantID, page, pageSize)
+	if err != nil {
+		logger.Log.Error("Failed to list stock movements", zap.Error(err), zap.String("productID", productID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list stock movements")
+	}
+
+	results := make([]contract.StockMovementRes, len(movements))
+	for i, movement := range movements {
+		results[i] = buildStockMovementRes(movement)
+	}
+
+	return results, total, nil
+}
+
 func (u *ProductUsecase) DeleteProduct(productID string) error {
 	if err := u.productRepo.DeleteProduct(productID); err != nil {
 		logger.Log.Error("Failed to delete product", zap.Error(err), zap.String("productID", productID))
@@ -143,6 +550,31 @@ func (u *ProductUsecase) buildProductRes(product *model.Product) *contract.Produ
 		barcodeType = util.ToPointer(string(*product.BarcodeType))
 	}
 
+	optionGroups := make([]contract.ProductOptionGroupRes, len(product.OptionGroups))
+	for i, group := range product.OptionGroups {
+		optionGroups[i] = contract.ProductOptionGroupRes{
+			ID:     group.ID,
+			Name:   group.Name,
+			Values: group.Values,
+		}
+	}
+
+	variants := make([]contract.ProductVariantRes, len(product.Variants))
+	for i, variant := range product.Variants {
+		variants[i] = buildProductVariantRes(variant, 0)
+	}
+
+	recipe := make([]contract.ProductRecipeItemRes, len(product.RecipeItems))
+	for i, item := range product.RecipeItems {
+		recipe[i] = contract.ProductRecipeItemRes{
+			IngredientID:   item.IngredientID,
+			IngredientName: item.Ingredient.Name,
+			Unit:           item.Ingredient.Unit,
+			Quantity:       item.Quantity,
+			Cost:           roundMoney(item.Quantity * item.Ingredient.Cost),
+		}
+	}
+
 	return &contract.ProductRes{
 		ID:            product.ID,
 		BusinessID:    product.BusinessID,
@@ -159,11 +591,90 @@ func (u *ProductUsecase) buildProductRes(product *model.Product) *contract.Produ
 		BarcodeValue:  product.BarcodeValue,
 		BarcodeType:   barcodeType,
 		IsActive:      product.IsActive,
+		HasVariants:   product.HasVariants,
+		OptionGroups:  optionGroups,
+		Variants:      variants,
+		HasRecipe:     product.HasRecipe,
+		Recipe:        recipe,
 		CreatedAt:     product.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:     product.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
+// buildProductVariantRes builds variant response, quantity sold is only known in sales reports
+func buildProductVariantRes(variant model.ProductVariant, quantitySold int) contract.ProductVariantRes {
+	var barcodeType *string
+	if variant.BarcodeType != nil {
+		barcodeType = util.ToPointer(string(*variant.BarcodeType))
+	}
+
+	return contract.ProductVariantRes{
+		ID:           variant.ID,
+		Options:      variant.Options,
+		Label:        variant.Label,
+		SKU:          variant.SKU,
+		Price:        variant.Price,
+		Cost:         variant.Cost,
+		StockQty:     variant.StockQty,
+		BarcodeValue: variant.BarcodeValue,
+		BarcodeType:  barcodeType,
+		IsActive:     variant.IsActive,
+		QuantitySold: quantitySold,
+	}
+}
+
+// optionCombinations lists every combination of option values, the first group varying slowest
+func optionCombinations(groups []model.ProductOptionGroup) [][]string {
+	combinations := [][]string{{}}
+	for _, group := range groups {
+		next := make([][]string, 0, len(combinations)*len(group.Values))
+		for _, combination := range combinations {
+			for _, value := range group.Values {
+				options := make([]string, len(combination), len(combination)+1)
+				copy(options, combination)
+				next = append(next, append(options, value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// buildStockMovementRes maps a ledger row, with the invoice number and creator name when loaded
+func buildStockMovementRes(movement model.StockMovement) contract.StockMovementRes {
+	var invoiceNumber *string
+	if movement.Transaction != nil {
+		invoiceNumber = &movement.Transaction.InvoiceNumber
+	}
+
+	var creatorName *string
+	if movement.Creator != nil {
+		creatorName = &movement.Creator.Name
+	}
+
+	return contract.StockMovementRes{
+		ID:            movement.ID,
+		ProductID:     movement.ProductID,
+		VariantID:     movement.VariantID,
+		VariantLabel:  movement.VariantLabel,
+		Type:          string(movement.Type),
+		Quantity:      movement.Quantity,
+		BalanceAfter:  movement.BalanceAfter,
+		TransactionID: movement.TransactionID,
+		InvoiceNumber: invoiceNumber,
+		RefundID:      movement.RefundID,
+		Reference:     movement.Reference,
+		Reason:        movement.Reason,
+		CreatedBy:     movement.CreatedBy,
+		CreatorName:   creatorName,
+		CreatedAt:     movement.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func variantKey(options []string) string {
+	return strings.ToLower(strings.Join(options, "\x00"))
+}
+
 func (u *ProductUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, productID *string) error {
 	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
 
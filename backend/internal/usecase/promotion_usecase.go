@@ -0,0 +1,431 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PromotionUsecase struct {
+	promotionRepo *repository.PromotionRepository
+	categoryRepo  *repository.CategoryRepository
+	productRepo   *repository.ProductRepository
+	db            *gorm.DB
+}
+
+func NewPromotionUsecase(
+	promotionRepo *repository.PromotionRepository,
+	categoryRepo *repository.CategoryRepository,
+	productRepo *repository.ProductRepository,
+	db *gorm.DB,
+) *PromotionUsecase {
+	return &PromotionUsecase{
+		promotionRepo: promotionRepo,
+		categoryRepo:  categoryRepo,
+		productRepo:   productRepo,
+		db:            db,
+	}
+}
+
+func (u *PromotionUsecase) CreatePromotion(businessID string, req *contract.CreatePromotionReq) (*contract.PromotionRes, error) {
+	maxSortOrder, err := u.promotionRepo.GetMaxSortOrder(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get max sort_order", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create promotion")
+	}
+
+	promotion := &model.Promotion{
+		BusinessID:     businessID,
+		Name:           req.Name,
+		Type:           config.PromotionType(req.Type),
+		Value:          req.Value,
+		BuyQuantity:    req.BuyQuantity,
+		GetQuantity:    req.GetQuantity,
+		MinSpend:       req.MinSpend,
+		DailyStartTime: emptyToNil(req.DailyStartTime),
+		DailyEndTime:   emptyToNil(req.DailyEndTime),
+		Stacking:       config.PROMOTION_STACKING_STACKABLE,
+		AppliesToAll:   true,
+		IsActive:       true,
+		SortOrder:      maxSortOrder + 1,
+	}
+	if req.Stacking != nil {
+		promotion.Stacking = config.PromotionStacking(*req.Stacking)
+	}
+	if req.AppliesToAll != nil {
+		promotion.AppliesToAll = *req.AppliesToAll
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	if promotion.StartsAt, err = parseScheduleTime(req.StartsAt); err != nil {
+		return nil, err
+	}
+	if promotion.EndsAt, err = parseScheduleTime(req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	if err := u.validateTargets(businessID, promotion.AppliesToAll, req.CategoryIDs, req.ProductIDs); err != nil {
+		return nil, err
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.promotionRepo.CreatePromotion(tx, promotion); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create promotion", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create promotion")
+	}
+
+	if err := u.promotionRepo.ReplacePromotionTargets(tx, promotion.ID, req.CategoryIDs, req.ProductIDs); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to set promotion targets", zap.Error(err), zap.String("promotionID", promotion.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create promotion")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create promotion")
+	}
+
+	return u.GetPromotionByID(promotion.ID)
+}
+
+func (u *PromotionUsecase) UpdatePromotion(promotionID string, req *contract.UpdatePromotionReq) (*contract.PromotionRes, error) {
+	promotion, err := u.promotionRepo.GetPromotionByID(promotionID)
+	if err != nil {
+		logger.Log.Error("Failed to get promotion", zap.Error(err), zap.String("promotionID", promotionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get promotion")
+	}
+
+	if promotion == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Promotion not found")
+	}
+
+	if req.Name != nil {
+		promotion.Name = *req.Name
+	}
+	if req.Type != nil {
+		promotion.Type = config.PromotionType(*req.Type)
+	}
+	if req.Value != nil {
+		promotion.Value = *req.Value
+	}
+	if req.BuyQuantity != nil {
+		promotion.BuyQuantity = req.BuyQuantity
+	}
+	if req.GetQuantity != nil {
+		promotion.GetQuantity = req.GetQuantity
+	}
+	if req.MinSpend != nil {
+		promotion.MinSpend = *req.MinSpend
+	}
+	if req.StartsAt != nil {
+		if promotion.StartsAt, err = parseScheduleTime(req.StartsAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndsAt != nil {
+		if promotion.EndsAt, err = parseScheduleTime(req.EndsAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.DailyStartTime != nil {
+		promotion.DailyStartTime = emptyToNil(req.DailyStartTime)
+	}
+	if req.DailyEndTime != nil {
+		promotion.DailyEndTime = emptyToNil(req.DailyEndTime)
+	}
+	if req.Stacking != nil {
+		promotion.Stacking = config.PromotionStacking(*req.Stacking)
+	}
+	if req.AppliesToAll != nil {
+		promotion.AppliesToAll = *req.AppliesToAll
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		promotion.SortOrder = *req.SortOrder
+	}
+
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	// Targets are kept unless the request replaces them
+	categoryIDs := make([]string, len(promotion.Categories))
+	for i, category := range promotion.Categories {
+		categoryIDs[i] = category.ID
+	}
+	if req.CategoryIDs != nil {
+		categoryIDs = *req.CategoryIDs
+	}
+
+	productIDs := make([]string, len(promotion.Products))
+	for i, product := range promotion.Products {
+		productIDs[i] = product.ID
+	}
+	if req.ProductIDs != nil {
+		productIDs = *req.ProductIDs
+	}
+
+	if err := u.validateTargets(promotion.BusinessID, promotion.AppliesToAll, categoryIDs, productIDs); err != nil {
+		return nil, err
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.promotionRepo.UpdatePromotion(tx, promotion); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to update promotion", zap.Error(err), zap.String("promotionID", promotionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update promotion")
+	}
+
+	if err := u.promotionRepo.ReplacePromotionTargets(tx, promotion.ID, categoryIDs, productIDs); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to set promotion targets", zap.Error(err), zap.String("promotionID", promotionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update promotion")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update promotion")
+	}
+
+	return u.GetPromotionByID(promotion.ID)
+}
+
+func (u *PromotionUsecase) GetPromotionByID(promotionID string) (*contract.PromotionRes, error) {
+	promotion, err := u.promotionRepo.GetPromotionByID(promotionID)
+	if err != nil {
+		logger.Log.Error("Failed to get promotion", zap.Error(err), zap.String("promotionID", promotionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get promotion")
+	}
+
+	if promotion == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Promotion not found")
+	}
+
+	return buildPromotionRes(promotion), nil
+}
+
+func (u *PromotionUsecase) ListPromotions(businessID string) ([]contract.PromotionRes, error) {
+	promotions, err := u.promotionRepo.ListPromotions(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to list promotions", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to list promotions")
+	}
+
+	results := make([]contract.PromotionRes, len(promotions))
+	for i := range promotions {
+		results[i] = *buildPromotionRes(&promotions[i])
+	}
+
+	return results, nil
+}
+
+func (u *PromotionUsecase) DeletePromotion(promotionID string) error {
+	if err := u.promotionRepo.DeletePromotion(promotionID); err != nil {
+		logger.Log.Error("Failed to delete promotion", zap.Error(err), zap.String("promotionID", promotionID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete promotion")
+	}
+
+	return nil
+}
+
+// validatePromotion checks the fields each promotion type needs and drops the ones it ignores
+func validatePromotion(promotion *model.Promotion) error {
+	switch promotion.Type {
+	case config.PROMOTION_TYPE_PERCENTAGE:
+		if promotion.Value > 100 {
+			return fiber.NewError(fiber.StatusBadRequest, "Percentage cannot exceed 100%")
+		}
+		promotion.BuyQuantity = nil
+		promotion.GetQuantity = nil
+	case config.PROMOTION_TYPE_AMOUNT:
+		promotion.BuyQuantity = nil
+		promotion.GetQuantity = nil
+	case config.PROMOTION_TYPE_BUNDLE_PRICE:
+		if promotion.BuyQuantity == nil || *promotion.BuyQuantity < 2 {
+			return fiber.NewError(fiber.StatusBadRequest, "A bundle needs a buy quantity of at least 2")
+		}
+		promotion.GetQuantity = nil
+	case config.PROMOTION_TYPE_BUY_X_GET_Y:
+		if promotion.BuyQuantity == nil || promotion.GetQuantity == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Buy X get Y needs a buy and a get quantity")
+		}
+		if promotion.Value > 100 {
+			return fiber.NewError(fiber.StatusBadRequest, "Percentage off the free items cannot exceed 100%")
+		}
+	}
+
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return fiber.NewError(fiber.StatusBadRequest, "End time must be after start time")
+	}
+
+	if (promotion.DailyStartTime == nil) != (promotion.DailyEndTime == nil) {
+		return fiber.NewError(fiber.StatusBadRequest, "Daily start and end times must be set together")
+	}
+	if promotion.DailyStartTime != nil && *promotion.DailyStartTime == *promotion.DailyEndTime {
+		return fiber.NewError(fiber.StatusBadRequest, "Daily start and end times must differ")
+	}
+
+	return nil
+}
+
+// parseScheduleTime parses a schedule date, an empty value clears it
+func parseScheduleTime(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid time %s", *value))
+	}
+	return &t, nil
+}
+
+// emptyToNil turns an empty string into nil
+func emptyToNil(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
+
+// validateTargets checks that a limited promotion has targets and that they belong to the business
+func (u *PromotionUsecase) validateTargets(businessID string, appliesToAll bool, categoryIDs, productIDs []string) error {
+	if !appliesToAll && len(categoryIDs) == 0 && len(productIDs) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Choose at least one category or product, or apply the promotion to all products")
+	}
+
+	for _, categoryID := range categoryIDs {
+		category, err := u.categoryRepo.GetCategoryByIDAndBusinessID(categoryID, businessID)
+		if err != nil {
+			logger.Log.Error("Failed to get category", zap.Error(err), zap.String("categoryID", categoryID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get category")
+		}
+		if category == nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Category %s not found", categoryID))
+		}
+	}
+
+	if len(productIDs) > 0 {
+		products, err := u.productRepo.GetProductsByIDs(productIDs)
+		if err != nil {
+			logger.Log.Error("Failed to fetch products", zap.Error(err))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+		}
+
+		found := make(map[string]bool, len(products))
+		for _, product := range products {
+			if product.BusinessID == businessID {
+				found[product.ID] = true
+			}
+		}
+
+		for _, productID := range productIDs {
+			if !found[productID] {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s not found", productID))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *PromotionUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, promotionID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if promotionID != nil {
+			promotion, err := u.promotionRepo.GetPromotionByIDAndBusinessID(*promotionID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get promotion", zap.Error(err), zap.String("promotionID", *promotionID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get promotion")
+			}
+
+			if promotion == nil {
+				logger.Log.Warn("Promotion not found", zap.String("promotionID", *promotionID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildPromotionRes builds promotion response
+func buildPromotionRes(promotion *model.Promotion) *contract.PromotionRes {
+	categoryIDs := make([]string, len(promotion.Categories))
+	for i, category := range promotion.Categories {
+		categoryIDs[i] = category.ID
+	}
+
+	productIDs := make([]string, len(promotion.Products))
+	for i, product := range promotion.Products {
+		productIDs[i] = product.ID
+	}
+
+	var startsAt, endsAt *string
+	if promotion.StartsAt != nil {
+		str := promotion.StartsAt.Format(time.RFC3339)
+		startsAt = &str
+	}
+	if promotion.EndsAt != nil {
+		str := promotion.EndsAt.Format(time.RFC3339)
+		endsAt = &str
+	}
+
+	return &contract.PromotionRes{
+		ID:             promotion.ID,
+		BusinessID:     promotion.BusinessID,
+		Name:           promotion.Name,
+		Type:           string(promotion.Type),
+		Value:          promotion.Value,
+		BuyQuantity:    promotion.BuyQuantity,
+		GetQuantity:    promotion.GetQuantity,
+		MinSpend:       promotion.MinSpend,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		DailyStartTime: promotion.DailyStartTime,
+		DailyEndTime:   promotion.DailyEndTime,
+		Stacking:       string(promotion.Stacking),
+		AppliesToAll:   promotion.AppliesToAll,
+		IsActive:       promotion.IsActive,
+		SortOrder:      promotion.SortOrder,
+		CategoryIDs:    categoryIDs,
+		ProductIDs:     productIDs,
+		CreatedAt:      promotion.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      promotion.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,521 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// receiptLayout is the shape of a printable receipt, a narrow till slip or an A4 invoice
+type receiptLayout string
+
+const (
+	receiptLayoutReceipt receiptLayout = "receipt"
+	receiptLayoutInvoice receiptLayout = "invoice"
+)
+
+// parseReceiptLayout defaults to the narrow receipt
+func parseReceiptLayout(layout string) receiptLayout {
+	if receiptLayout(layout) == receiptLayoutInvoice {
+		return receiptLayoutInvoice
+	}
+	return receiptLayoutReceipt
+}
+
+// Narrow receipts match an 80mm roll, their height follows the content
+const (
+	narrowReceiptWidth  = 80.0
+	narrowReceiptMargin = 4.0
+	narrowLogoWidth     = 30.0
+	narrowLineHeight    = 4.5
+	invoiceMargin       = 15.0
+	invoiceLogoHeight   = 20.0
+	invoiceLineHeight   = 6.0
+)
+
+var receiptHTMLTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<title>{{if .Invoice}}Invoice{{else}}Receipt{{end}} {{.Receipt.InvoiceNumber}} - {{.Receipt.BusinessName}}</title>
+	<style>
+		body { margin: 0; padding: 24px 12px; background: #f5f5f5; color: #222; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; font-size: 14px; }
+		.paper { margin: 0 auto; padding: 24px; background: #fff; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+		.receipt { max-width: 340px; }
+		.invoice { max-width: 800px; padding: 40px; }
+		.center { text-align: center; }
+		.right { text-align: right; }
+		.muted { color: #666; }
+		.pre { white-space: pre-line; }
+		.logo { max-width: 50%; max-height: 96px; }
+		.name { margin: 8px 0 4px; font-size: 20px; font-weight: 600; }
+		.status { margin: 12px 0; padding: 6px; border: 2px solid #c0392b; color: #c0392b; font-weight: 700; text-align: center; letter-spacing: 2px; }
+		table { width: 100%; border-collapse: collapse; }
+		td, th { padding: 4px 0; vertical-align: top; }
+		.invoice th { border-bottom: 2px solid #222; text-align: left; }
+		.invoice .items td { border-bottom: 1px solid #eee; }
+		.invoice .header { display: flex; justify-content: space-between; gap: 24px; }
+		.invoice .totals { width: 50%; margin-left: auto; margin-top: 16px; }
+		hr { border: 0; border-top: 1px dashed #999; margin: 12px 0; }
+		.total td { font-size: 16px; font-weight: 700; }
+		.actions { margin: 16px auto 0; text-align: center; }
+		.actions a { margin: 0 8px; color: #2563eb; }
+	</style>
+</head>
+<body>
+{{- $r := .Receipt}}
+{{- if .Invoice}}
+	<div class="paper invoice">
+		<div class="header">
+			<div>
+				{{if .Logo}}<img class="logo" src="{{.Logo}}" alt="{{$r.BusinessName}}">{{end}}
+				<div class="name">{{$r.BusinessName}}</div>
+				{{if $r.Address}}<div class="muted pre">{{$r.Address}}</div>{{end}}
+			</div>
+			<div class="right">
+				<div class="name">INVOICE</div>
+				<div>{{$r.InvoiceNumber}}</div>
+				<div class="muted">{{$r.Date}}</div>
+				{{if $r.Cashier}}<div class="muted">Cashier: {{$r.Cashier}}</div>{{end}}
+			</div>
+		</div>
+		{{if $r.Header}}<p class="pre">{{$r.Header}}</p>{{end}}
+		{{if $r.Status}}<div class="status">{{$r.Status}}</div>{{end}}
+		<table class="items" style="margin-top: 24px;">
+			<tr><th>Item</th><th class="right">Qty</th><th class="right">Price</th><th class="right">Discount</th><th class="right">Amount</th></tr>
+			{{- range $r.Items}}
+			<tr>
+				<td>{{.Name}}{{range .Modifiers}}<div class="muted">{{.Label}}{{if .Amount}} {{.Amount}}{{end}}</div>{{end}}{{if .Discount}}<div class="muted">{{.Discount.Label}}</div>{{end}}</td>
+				<td class="right">{{.Quantity}}</td>
+				<td class="right">{{.Price}}</td>
+				<td class="right">{{if .Discount}}{{.Discount.Amount}}{{end}}</td>
+				<td class="right">{{.Amount}}</td>
+			</tr>
+			{{- end}}
+		</table>
+		<table class="totals">
+			{{- range $r.Totals}}
+			<tr><td>{{.Label}}</td><td class="right">{{.Amount}}</td></tr>
+			{{- end}}
+			<tr class="total"><td>{{$r.Total.Label}}</td><td class="right">{{$r.Total.Amount}}</td></tr>
+			{{- range $r.AfterTotal}}
+			<tr class="muted"><td>{{.Label}}</td><td class="right">{{.Amount}}</td></tr>
+			{{- end}}
+		</table>
+		{{if $r.Footer}}<p class="center pre" style="margin-top: 32px;">{{$r.Footer}}</p>{{end}}
+	</div>
+{{- else}}
+	<div class="paper receipt">
+		<div class="center">
+			{{if .Logo}}<img class="logo" src="{{.Logo}}" alt="{{$r.BusinessName}}">{{end}}
+			<div class="name">{{$r.BusinessName}}</div>
+			{{if $r.Address}}<div class="muted pre">{{$r.Address}}</div>{{end}}
+			{{if $r.Header}}<div class="pre">{{$r.Header}}</div>{{end}}
+		</div>
+		<hr>
+		<table>
+			<tr><td>No</td><td class="right">{{$r.InvoiceNumber}}</td></tr>
+			<tr><td>Date</td><td class="right">{{$r.Date}}</td></tr>
+			{{if $r.Cashier}}<tr><td>Cashier</td><td class="right">{{$r.Cashier}}</td></tr>{{end}}
+		</table>
+		<hr>
+		<table>
+			{{- range $r.Items}}
+			<tr><td colspan="2">{{.Name}}</td></tr>
+			{{- range .Modifiers}}
+			<tr class="muted"><td>&nbsp;&nbsp;{{.Label}}</td><td class="right">{{.Amount}}</td></tr>
+			{{- end}}
+			<tr class="muted"><td>&nbsp;&nbsp;{{.Quantity}} x {{.Price}}</td><td class="right">{{.Amount}}</td></tr>
+			{{if .Discount}}<tr class="muted"><td>&nbsp;&nbsp;{{.Discount.Label}}</td><td class="right">{{.Discount.Amount}}</td></tr>{{end}}
+			{{- end}}
+		</table>
+		<hr>
+		<table>
+			{{- range $r.Totals}}
+			<tr><td>{{.Label}}</td><td class="right">{{.Amount}}</td></tr>
+			{{- end}}
+			<tr class="total"><td>{{$r.Total.Label}}</td><td class="right">{{$r.Total.Amount}}</td></tr>
+			{{- range $r.AfterTotal}}
+			<tr><td>{{.Label}}</td><td class="right">{{.Amount}}</td></tr>
+			{{- end}}
+		</table>
+		{{if $r.Status}}<div class="status">{{$r.Status}}</div>{{end}}
+		{{if $r.Footer}}<p class="center pre">{{$r.Footer}}</p>{{end}}
+	</div>
+{{- end}}
+	<div class="actions">
+		{{if .Invoice}}<a href="?layout=receipt">View receipt</a>{{else}}<a href="?layout=invoice">View invoice</a>{{end}}
+		<a href="{{.PDFURL}}?layout={{.Layout}}">Download PDF</a>
+	</div>
+</body>
+</html>
+`))
+
+// Email clients drop style sheets and data URLs, so every style is inline and the logo is a link
+var receiptEmailTemplate = template.Must(template.New("receiptEmail").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;">
+{{- $r := .Receipt}}
+	<table width="100%" cellpadding="0" cellspacing="0" style="background-color: #f5f5f5; padding: 40px 20px;">
+		<tr>
+			<td align="center">
+				<table width="480" cellpadding="0" cellspacing="0" style="background-color: #ffffff; border-radius: 8px; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
+					<tr>
+						<td style="padding: 32px 40px 16px; text-align: center;">
+							{{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{$r.BusinessName}}" style="max-width: 160px; max-height: 80px; margin-bottom: 12px;">{{end}}
+							<h2 style="margin: 0 0 4px 0; color: #333333; font-size: 22px; font-weight: 600;">{{$r.BusinessName}}</h2>
+							{{if $r.Address}}<p style="margin: 0; color: #666666; font-size: 14px; line-height: 1.5; white-space: pre-line;">{{$r.Address}}</p>{{end}}
+							{{if $r.Header}}<p style="margin: 12px 0 0 0; color: #666666; font-size: 14px; line-height: 1.5; white-space: pre-line;">{{$r.Header}}</p>{{end}}
+						</td>
+					</tr>
+					<tr>
+						<td style="padding: 0 40px;">
+							<table width="100%" cellpadding="0" cellspacing="0" style="font-size: 14px; color: #333333; border-top: 1px dashed #cccccc; border-bottom: 1px dashed #cccccc;">
+								<tr><td style="padding: 8px 0 2px;">No</td><td align="right" style="padding: 8px 0 2px;">{{$r.InvoiceNumber}}</td></tr>
+								<tr><td style="padding: 2px 0;">Date</td><td align="right" style="padding: 2px 0;">{{$r.Date}}</td></tr>
+								{{if $r.Cashier}}<tr><td style="padding: 2px 0 8px;">Cashier</td><td align="right" style="padding: 2px 0 8px;">{{$r.Cashier}}</td></tr>{{end}}
+							</table>
+							<table width="100%" cellpadding="0" cellspacing="0" style="font-size: 14px; color: #333333; margin-top: 8px;">
+								{{- range $r.Items}}
+								<tr><td colspan="2" style="padding: 6px 0 0;">{{.Name}}</td></tr>
+								{{- range .Modifiers}}
+								<tr><td style="padding: 0 0 0 12px; color: #666666;">{{.Label}}</td><td align="right" style="color: #666666;">{{.Amount}}</td></tr>
+								{{- end}}
+								<tr><td style="padding: 0 0 0 12px; color: #666666;">{{.Quantity}} x {{.Price}}</td><td align="right">{{.Amount}}</td></tr>
+								{{if .Discount}}<tr><td style="padding: 0 0 0 12px; color: #666666;">{{.Discount.Label}}</td><td align="right" style="color: #666666;">{{.Discount.Amount}}</td></tr>{{end}}
+								{{- end}}
+							</table>
+							<table width="100%" cellpadding="0" cellspacing="0" style="font-size: 14px; color: #333333; margin-top: 12px; border-top: 1px dashed #cccccc;">
+								{{- range $r.Totals}}
+								<tr><td style="padding: 4px 0 0;">{{.Label}}</td><td align="right" style="padding: 4px 0 0;">{{.Amount}}</td></tr>
+								{{- end}}
+								<tr><td style="padding: 8px 0; font-size: 18px; font-weight: 700;">{{$r.Total.Label}}</td><td align="right" style="padding: 8px 0; font-size: 18px; font-weight: 700;">{{$r.Total.Amount}}</td></tr>
+								{{- range $r.AfterTotal}}
+								<tr><td style="color: #666666;">{{.Label}}</td><td align="right" style="color: #666666;">{{.Amount}}</td></tr>
+								{{- end}}
+							</table>
+						</td>
+					</tr>
+					<tr>
+						<td align="center" style="padding: 24px 40px;">
+							<a href="{{.ReceiptURL}}" style="display: inline-block; padding: 12px 28px; background-color: #2563eb; color: #ffffff; text-decoration: none; border-radius: 6px; font-size: 15px; font-weight: 600;">View receipt</a>
+							<p style="margin: 12px 0 0 0; font-size: 13px;"><a href="{{.InvoiceURL}}" style="color: #2563eb;">Download invoice PDF</a></p>
+						</td>
+					</tr>
+					{{if $r.Footer}}
+					<tr>
+						<td style="padding: 0 40px 32px; text-align: center; color: #666666; font-size: 14px; line-height: 1.5; white-space: pre-line;">{{$r.Footer}}</td>
+					</tr>
+					{{end}}
+				</table>
+			</td>
+		</tr>
+	</table>
+</body>
+</html>
+`))
+
+// renderReceiptHTML renders a self-contained page, the logo is inlined so the page needs no other request
+func renderReceiptHTML(r receipt, logo *receiptLogo, layout receiptLayout, pdfURL string) ([]byte, error) {
+	data := struct {
+		Receipt receipt
+		Invoice bool
+		Layout  receiptLayout
+		Logo    template.URL
+		PDFURL  string
+	}{
+		Receipt: r,
+		Invoice: layout == receiptLayoutInvoice,
+		Layout:  layout,
+		PDFURL:  pdfURL,
+	}
+	if logo != nil {
+		data.Logo = template.URL(fmt.Sprintf("data:image/%s;base64,%s", logo.Format, base64.StdEncoding.EncodeToString(logo.Data)))
+	}
+
+	var buf bytes.Buffer
+	if err := receiptHTMLTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderReceiptEmail renders the receipt as an HTML email body linking to the public receipt
+func renderReceiptEmail(r receipt, logoURL, receiptURL, invoiceURL string) (string, error) {
+	data := struct {
+		Receipt    receipt
+		LogoURL    string
+		ReceiptURL string
+		InvoiceURL string
+	}{r, logoURL, receiptURL, invoiceURL}
+
+	var buf bytes.Buffer
+	if err := receiptEmailTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderReceiptPDF draws the receipt with the built-in PDF fonts, no external renderer is involved
+func renderReceiptPDF(r receipt, logo *receiptLogo, layout receiptLayout) ([]byte, error) {
+	var pdf *fpdf.Fpdf
+	if layout == receiptLayoutInvoice {
+		pdf = fpdf.New("P", "mm", "A4", "")
+		pdf.SetMargins(invoiceMargin, invoiceMargin, invoiceMargin)
+		pdf.SetAutoPageBreak(true, invoiceMargin)
+		pdf.AddPage()
+		drawInvoicePDF(pdf, r, logo)
+	} else {
+		// Draw once on a long page to measure the content, then again on a page cut to fit
+		measure := newNarrowReceiptPDF(1000)
+		drawNarrowReceiptPDF(measure, r, logo)
+		if err := measure.Error(); err != nil {
+			return nil, err
+		}
+
+		pdf = newNarrowReceiptPDF(measure.GetY() + narrowReceiptMargin)
+		drawNarrowReceiptPDF(pdf, r, logo)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newNarrowReceiptPDF(height float64) *fpdf.Fpdf {
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: narrowReceiptWidth, Ht: height},
+	})
+	pdf.SetMargins(narrowReceiptMargin, narrowReceiptMargin, narrowReceiptMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	return pdf
+}
+
+func drawNarrowReceiptPDF(pdf *fpdf.Fpdf, r receipt, logo *receiptLogo) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width := narrowReceiptWidth - 2*narrowReceiptMargin
+
+	if drawPDFLogo(pdf, logo, (narrowReceiptWidth-narrowLogoWidth)/2, pdf.GetY(), narrowLogoWidth, 0) {
+		pdf.Ln(2)
+	}
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.MultiCell(width, 6, tr(r.BusinessName), "", "C", false)
+	pdf.SetFont("Helvetica", "", 8)
+	if r.Address != "" {
+		pdf.MultiCell(width, narrowLineHeight, tr(r.Address), "", "C", false)
+	}
+	if r.Header != "" {
+		pdf.MultiCell(width, narrowLineHeight, tr(r.Header), "", "C", false)
+	}
+
+	drawPDFSeparator(pdf, width)
+	drawPDFRow(pdf, width, narrowLineHeight, tr("No"), tr(r.InvoiceNumber))
+	drawPDFRow(pdf, width, narrowLineHeight, tr("Date"), tr(r.Date))
+	if r.Cashier != "" {
+		drawPDFRow(pdf, width, narrowLineHeight, tr("Cashier"), tr(r.Cashier))
+	}
+	drawPDFSeparator(pdf, width)
+
+	for _, item := range r.Items {
+		pdf.MultiCell(width, narrowLineHeight, tr(item.Name), "", "L", false)
+		for _, modifier := range item.Modifiers {
+			drawPDFRow(pdf, width, narrowLineHeight, tr("  "+modifier.Label), tr(modifier.Amount))
+		}
+		drawPDFRow(pdf, width, narrowLineHeight, tr(fmt.Sprintf("  %d x %s", item.Quantity, item.Price)), tr(item.Amount))
+		if item.Discount != nil {
+			drawPDFRow(pdf, width, narrowLineHeight, tr("  "+item.Discount.Label), tr(item.Discount.Amount))
+		}
+	}
+	drawPDFSeparator(pdf, width)
+
+	for _, line := range r.Totals {
+		drawPDFRow(pdf, width, narrowLineHeight, tr(line.Label), tr(line.Amount))
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	drawPDFRow(pdf, width, 6, tr(r.Total.Label), tr(r.Total.Amount))
+	pdf.SetFont("Helvetica", "", 8)
+	for _, line := range r.AfterTotal {
+		drawPDFRow(pdf, width, narrowLineHeight, tr(line.Label), tr(line.Amount))
+	}
+
+	if r.Status != "" {
+		drawPDFSeparator(pdf, width)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.MultiCell(width, 6, tr(r.Status), "", "C", false)
+		pdf.SetFont("Helvetica", "", 8)
+	}
+
+	if r.Footer != "" {
+		pdf.Ln(3)
+		pdf.MultiCell(width, narrowLineHeight, tr(r.Footer), "", "C", false)
+	}
+}
+
+func drawInvoicePDF(pdf *fpdf.Fpdf, r receipt, logo *receiptLogo) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*invoiceMargin
+	top := pdf.GetY()
+
+	// Business on the left, invoice details on the right
+	if drawPDFLogo(pdf, logo, invoiceMargin, top, 0, invoiceLogoHeight) {
+		pdf.SetY(top + invoiceLogoHeight + 2)
+	}
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.MultiCell(width/2, 7, tr(r.BusinessName), "", "L", false)
+	pdf.SetFont("Helvetica", "", 9)
+	if r.Address != "" {
+		pdf.MultiCell(width/2, 5, tr(r.Address), "", "L", false)
+	}
+	leftBottom := pdf.GetY()
+
+	pdf.SetXY(invoiceMargin+width/2, top)
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(width/2, 10, "INVOICE", "", 2, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(width/2, 5, tr(r.InvoiceNumber), "", 2, "R", false, 0, "")
+	pdf.CellFormat(width/2, 5, tr(r.Date), "", 2, "R", false, 0, "")
+	if r.Cashier != "" {
+		pdf.CellFormat(width/2, 5, tr("Cashier: "+r.Cashier), "", 2, "R", false, 0, "")
+	}
+	pdf.SetXY(invoiceMargin, max(leftBottom, pdf.GetY())+4)
+
+	if r.Header != "" {
+		pdf.MultiCell(width, 5, tr(r.Header), "", "L", false)
+		pdf.Ln(2)
+	}
+	if r.Status != "" {
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(192, 57, 43)
+		pdf.CellFormat(width, 8, tr(r.Status), "1", 1, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(2)
+	}
+
+	// Items table
+	columns := []float64{width - 105, 15, 30, 30, 30}
+	pdf.SetFont("Helvetica", "B", 10)
+	for i, title := range []string{"Item", "Qty", "Price", "Discount", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(columns[i], invoiceLineHeight+1, title, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range r.Items {
+		name := item.Name
+		for _, modifier := range item.Modifiers {
+			name += "\n" + strings.TrimSpace(modifier.Label+" "+modifier.Amount)
+		}
+		discount := ""
+		if item.Discount != nil {
+			name += "\n" + item.Discount.Label
+			discount = item.Discount.Amount
+		}
+		lines := pdf.SplitText(tr(name), columns[0])
+		height := float64(len(lines)) * invoiceLineHeight
+		if pdf.GetY()+height > pageHeight-invoiceMargin {
+			pdf.AddPage()
+		}
+
+		y := pdf.GetY()
+		pdf.MultiCell(columns[0], invoiceLineHeight, strings.Join(lines, "\n"), "", "L", false)
+		pdf.SetXY(invoiceMargin+columns[0], y)
+		pdf.CellFormat(columns[1], invoiceLineHeight, fmt.Sprint(item.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[2], invoiceLineHeight, tr(item.Price), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[3], invoiceLineHeight, tr(discount), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[4], invoiceLineHeight, tr(item.Amount), "", 0, "R", false, 0, "")
+		pdf.SetXY(invoiceMargin, y+height)
+		pdf.Line(invoiceMargin, y+height, invoiceMargin+width, y+height)
+	}
+	pdf.Ln(4)
+
+	// Totals on the right half
+	totalsX := invoiceMargin + width/2
+	totalsWidth := width / 2
+	for _, line := range r.Totals {
+		pdf.SetX(totalsX)
+		drawPDFRow(pdf, totalsWidth, invoiceLineHeight, tr(line.Label), tr(line.Amount))
+	}
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetX(totalsX)
+	drawPDFRow(pdf, totalsWidth, invoiceLineHeight+2, tr(r.Total.Label), tr(r.Total.Amount))
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range r.AfterTotal {
+		pdf.SetX(totalsX)
+		drawPDFRow(pdf, totalsWidth, invoiceLineHeight, tr(line.Label), tr(line.Amount))
+	}
+
+	if r.Footer != "" {
+		pdf.Ln(10)
+		pdf.MultiCell(width, 5, tr(r.Footer), "", "C", false)
+	}
+}
+
+// drawPDFLogo places the logo scaled to the given width or height and moves below it.
+// Logos the PDF library can't embed, such as interlaced PNGs, are left out.
+func drawPDFLogo(pdf *fpdf.Fpdf, logo *receiptLogo, x, y, width, height float64) bool {
+	if logo == nil {
+		return false
+	}
+
+	options := fpdf.ImageOptions{ImageType: strings.ToUpper(logo.Format)}
+	info := pdf.RegisterImageOptionsReader("logo", options, bytes.NewReader(logo.Data))
+	if pdf.Err() {
+		pdf.ClearError()
+		return false
+	}
+
+	if width == 0 {
+		width = height * info.Width() / info.Height()
+	} else {
+		height = width * info.Height() / info.Width()
+	}
+	pdf.ImageOptions("logo", x, y, width, height, false, options, 0, "")
+	pdf.SetY(y + height)
+	return true
+}
+
+// drawPDFRow prints a label with the amount aligned right, wrapping a long label above the amount
+func drawPDFRow(pdf *fpdf.Fpdf, width, height float64, label, amount string) {
+	x := pdf.GetX()
+	amountWidth := pdf.GetStringWidth(amount) + 2
+	lines := pdf.SplitText(label, width-amountWidth)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	for _, line := range lines[:len(lines)-1] {
+		pdf.SetX(x)
+		pdf.CellFormat(width, height, line, "", 1, "L", false, 0, "")
+	}
+	pdf.SetX(x)
+	pdf.CellFormat(width-amountWidth, height, lines[len(lines)-1], "", 0, "L", false, 0, "")
+	pdf.CellFormat(amountWidth, height, amount, "", 1, "R", false, 0, "")
+}
+
+func drawPDFSeparator(pdf *fpdf.Fpdf, width float64) {
+	pdf.Ln(1)
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	pdf.Line(pdf.GetX(), pdf.GetY(), pdf.GetX()+width, pdf.GetY())
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.Ln(1.5)
+}
@@ -0,0 +1,680 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/escpos"
+	"app/pkg/logger"
+	"app/pkg/storage"
+	"app/pkg/util"
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Logos are printed at most half the paper width so they don't dominate the receipt
+const receiptLogoWidthRatio = 2
+
+// Email receipts go out through the SMTP account of EmailUsecase
+const receiptEmailProvider = "smtp"
+
+type ReceiptUsecase struct {
+	transactionRepo     *repository.TransactionRepository
+	businessRepo        *repository.BusinessRepository
+	receiptDeliveryRepo *repository.ReceiptDeliveryRepository
+	emailUsecase        *EmailUsecase
+	messagingProvider   MessagingProvider
+	storage             *storage.R2Storage
+}
+
+func NewReceiptUsecase(
+	transactionRepo *repository.TransactionRepository,
+	businessRepo *repository.BusinessRepository,
+	receiptDeliveryRepo *repository.ReceiptDeliveryRepository,
+	emailUsecase *EmailUsecase,
+	messagingProvider MessagingProvider,
+	storage *storage.R2Storage,
+) *ReceiptUsecase {
+	return &ReceiptUsecase{
+		transactionRepo:     transactionRepo,
+		businessRepo:        businessRepo,
+		receiptDeliveryRepo: receiptDeliveryRepo,
+		emailUsecase:        emailUsecase,
+		messagingProvider:   messagingProvider,
+		storage:             storage,
+	}
+}
+
+// receipt is a transaction laid out as the lines printed on a receipt, shared by every receipt format
+type receipt struct {
+	BusinessName  string
+	Address       string
+	Header        string
+	Footer        string
+	InvoiceNumber string
+	Date          string
+	Cashier       string
+	Status        string // Empty for paid transactions
+	Items         []receiptItem
+	Totals        []receiptLine
+	Total         receiptLine
+	AfterTotal    []receiptLine // Inclusive taxes, rounding, payments and change
+}
+
+type receiptItem struct {
+	Name      string
+	Modifiers []receiptLine // Printed under the name, their price is already in the unit price
+	Quantity  int
+	Price     string
+	Amount    string
+	Discount  *receiptLine
+}
+
+// receiptLogo is the decoded business logo with its original bytes for formats that embed the file
+type receiptLogo struct {
+	Image  image.Image
+	Data   []byte
+	Format string // png or jpeg
+}
+
+type receiptLine struct {
+	Label  string
+	Amount string
+}
+
+// RenderEscpos renders a transaction as raw ESC/POS bytes a print bridge can send straight to a thermal printer
+func (u *ReceiptUsecase) RenderEscpos(businessID, transactionID string, query *contract.EscposReceiptQuery) ([]byte, error) {
+	width := escpos.PaperWidth58
+	if query.PaperWidth != 0 {
+		width = escpos.PaperWidth(query.PaperWidth)
+	}
+	codePage := escpos.CodePagePC437
+	if query.CodePage != "" {
+		codePage = escpos.CodePage(query.CodePage)
+	}
+
+	transaction, business, err := u.getTransactionAndBusiness(businessID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	printer, err := escpos.New(width, codePage)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	printer.Align(escpos.AlignCenter)
+	if logo := u.loadLogo(business); logo != nil {
+		printer.Image(logo.Image, width.Dots()/receiptLogoWidthRatio)
+	}
+
+	r := buildReceipt(transaction, business)
+	writeEscposReceipt(printer, r)
+
+	return printer.Bytes(), nil
+}
+
+// RenderPublicHTML renders the receipt behind a public receipt link as an HTML page
+func (u *ReceiptUsecase) RenderPublicHTML(token string, query *contract.PublicReceiptQuery) ([]byte, error) {
+	transaction, business, err := u.getTransactionByReceiptToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	r := buildReceipt(transaction, business)
+	page, err := renderReceiptHTML(r, u.loadLogo(business), parseReceiptLayout(query.Layout), receiptPDFURL(token))
+	if err != nil {
+		logger.Log.Error("Failed to render receipt HTML", zap.Error(err), zap.String("transactionID", transaction.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to render receipt")
+	}
+
+	return page, nil
+}
+
+// RenderPublicPDF renders the receipt behind a public receipt link as a PDF and returns it with a file name
+func (u *ReceiptUsecase) RenderPublicPDF(token string, query *contract.PublicReceiptQuery) ([]byte, string, error) {
+	transaction, business, err := u.getTransactionByReceiptToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	layout := parseReceiptLayout(query.Layout)
+	r := buildReceipt(transaction, business)
+	document, err := renderReceiptPDF(r, u.loadLogo(business), layout)
+	if err != nil {
+		logger.Log.Error("Failed to render receipt PDF", zap.Error(err), zap.String("transactionID", transaction.ID))
+		return nil, "", fiber.NewError(fiber.StatusInternalServerError, "Failed to render receipt")
+	}
+
+	fileName := fmt.Sprintf("%s-%s.pdf", layout, transaction.InvoiceNumber)
+	return document, fileName, nil
+}
+
+// SendReceipt sends the receipt of a paid transaction to a customer by email or WhatsApp.
+// Every send is recorded, a failed send is returned with its error so it can be resent.
+func (u *ReceiptUsecase) SendReceipt(userID, businessID, transactionID string, req *contract.SendReceiptReq) (*contract.ReceiptDeliveryRes, error) {
+	channel := config.ReceiptChannel(req.Channel)
+	recipient, err := normalizeReceiptRecipient(channel, req.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.deliverReceipt(userID, businessID, transactionID, channel, recipient)
+}
+
+// ResendReceipt sends a receipt again to the channel and recipient of an earlier send
+func (u *ReceiptUsecase) ResendReceipt(userID, businessID, transactionID, deliveryID string) (*contract.ReceiptDeliveryRes, error) {
+	delivery, err := u.receiptDeliveryRepo.GetReceiptDeliveryByIDAndTransactionID(deliveryID, transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to get receipt delivery", zap.Error(err), zap.String("deliveryID", deliveryID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get receipt delivery")
+	}
+
+	if delivery == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Receipt delivery not found")
+	}
+
+	return u.deliverReceipt(userID, businessID, transactionID, delivery.Channel, delivery.Recipient)
+}
+
+// ListReceiptDeliveries lists the receipts sent for a transaction, newest first
+func (u *ReceiptUsecase) ListReceiptDeliveries(transactionID string) ([]contract.ReceiptDeliveryRes, error) {
+	deliveries, err := u.receiptDeliveryRepo.ListReceiptDeliveriesByTransactionID(transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to list receipt deliveries", zap.Error(err), zap.String("transactionID", transactionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to list receipt deliveries")
+	}
+
+	results := make([]contract.ReceiptDeliveryRes, len(deliveries))
+	for i, delivery := range deliveries {
+		results[i] = buildReceiptDeliveryRes(delivery)
+	}
+
+	return results, nil
+}
+
+// IsAllowedToAccess checks the role permissions and that the transaction belongs to the business
+func (u *ReceiptUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, transactionID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	if permission.Scope() == config.PERMISSION_SCOPE_ORG && transactionID != nil {
+		transaction, err := u.transactionRepo.GetTransactionByIDAndBusinessID(*transactionID, *claims.BusinessID)
+		if err != nil {
+			logger.Log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionID", *transactionID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+		}
+
+		if transaction == nil {
+			logger.Log.Warn("Transaction not found", zap.String("transactionID", *transactionID))
+			return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+		}
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Helper Functions
+// ============================================================================
+
+func (u *ReceiptUsecase) getTransactionAndBusiness(businessID, transactionID string) (*model.Transaction, *model.Business, error) {
+	transaction, err := u.transactionRepo.GetTransactionByID(transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionID", transactionID))
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if transaction == nil || transaction.BusinessID != businessID {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+	}
+
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get business", zap.Error(err))
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	return transaction, business, nil
+}
+
+// deliverReceipt records a send, hands the receipt to the channel and stores the outcome
+func (u *ReceiptUsecase) deliverReceipt(userID, businessID, transactionID string, channel config.ReceiptChannel, recipient string) (*contract.ReceiptDeliveryRes, error) {
+	transaction, business, err := u.getTransactionAndBusiness(businessID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Status != config.TRANSACTION_STATUS_PAID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only paid transactions have a receipt to send")
+	}
+
+	receiptURL, _, err := generateReceiptURL(transaction.ID)
+	if err != nil {
+		logger.Log.Error("Failed to generate receipt link", zap.Error(err), zap.String("transactionID", transactionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to generate receipt link")
+	}
+
+	delivery := &model.ReceiptDelivery{
+		BusinessID:    businessID,
+		TransactionID: transaction.ID,
+		Channel:       channel,
+		Recipient:     recipient,
+		Status:        config.RECEIPT_DELIVERY_STATUS_PENDING,
+		Provider:      receiptEmailProvider,
+		SentBy:        userID,
+	}
+	if channel == config.RECEIPT_CHANNEL_WHATSAPP {
+		delivery.Provider = u.messagingProvider.Name()
+	}
+
+	if err := u.receiptDeliveryRepo.CreateReceiptDelivery(delivery); err != nil {
+		logger.Log.Error("Failed to create receipt delivery", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to send receipt")
+	}
+
+	r := buildReceipt(transaction, business)
+	var reference *string
+	switch channel {
+	case config.RECEIPT_CHANNEL_EMAIL:
+		err = u.sendReceiptEmail(r, business, recipient, receiptURL)
+	case config.RECEIPT_CHANNEL_WHATSAPP:
+		var messageID string
+		messageID, err = u.messagingProvider.SendWhatsApp(buildReceiptWhatsAppMessage(r, recipient, receiptURL))
+		if err == nil {
+			reference = &messageID
+		}
+	}
+
+	now := time.Now()
+	if err != nil {
+		logger.Log.Error("Failed to send receipt", zap.Error(err), zap.String("deliveryID", delivery.ID), zap.String("channel", string(channel)))
+		delivery.Status = config.RECEIPT_DELIVERY_STATUS_FAILED
+		delivery.Error = util.ToPointer(err.Error())
+	} else {
+		delivery.Status = config.RECEIPT_DELIVERY_STATUS_SENT
+		delivery.Reference = reference
+		delivery.SentAt = &now
+	}
+	delivery.UpdatedAt = now
+
+	if err := u.receiptDeliveryRepo.UpdateReceiptDelivery(delivery); err != nil {
+		logger.Log.Error("Failed to update receipt delivery", zap.Error(err), zap.String("deliveryID", delivery.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update receipt delivery")
+	}
+
+	saved, err := u.receiptDeliveryRepo.GetReceiptDeliveryByIDAndTransactionID(delivery.ID, transaction.ID)
+	if err != nil || saved == nil {
+		logger.Log.Error("Failed to get receipt delivery", zap.Error(err), zap.String("deliveryID", delivery.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get receipt delivery")
+	}
+
+	return util.ToPointer(buildReceiptDeliveryRes(*saved)), nil
+}
+
+func (u *ReceiptUsecase) sendReceiptEmail(r receipt, business *model.Business, recipient, receiptURL string) error {
+	var logoURL string
+	if business.Logo != nil && u.storage != nil {
+		logoURL = u.storage.PublicURL(*business.Logo)
+		if logoURL == "" {
+			logoURL, _ = u.storage.PresignGet(*business.Logo, 0)
+		}
+	}
+
+	body, err := renderReceiptEmail(r, logoURL, receiptURL, receiptURL+"/pdf?layout="+string(receiptLayoutInvoice))
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Receipt %s - %s", r.InvoiceNumber, r.BusinessName)
+	return u.emailUsecase.SendHTMLEmail(recipient, subject, body)
+}
+
+// getTransactionByReceiptToken resolves a public receipt link, the signature stands in for a login
+func (u *ReceiptUsecase) getTransactionByReceiptToken(token string) (*model.Transaction, *model.Business, error) {
+	claims, err := util.VerifyToken(token, config.Env.JWT.Secret)
+	if err != nil {
+		logger.Log.Warn("Invalid or expired receipt token", zap.Error(err))
+		return nil, nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired receipt link")
+	}
+
+	if config.TokenType(claims.Type) != config.TokenTypeReceipt {
+		logger.Log.Warn("Invalid token type for receipt", zap.String("tokenType", claims.Type))
+		return nil, nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired receipt link")
+	}
+
+	transaction, err := u.transactionRepo.GetTransactionByID(claims.ID)
+	if err != nil {
+		logger.Log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionID", claims.ID))
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if transaction == nil {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+	}
+
+	business, err := u.businessRepo.GetBusinessByID(transaction.BusinessID)
+	if err != nil {
+		logger.Log.Error("Failed to get business", zap.Error(err))
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	return transaction, business, nil
+}
+
+// generateReceiptURL signs an expiring link to the public receipt page of a transaction
+func generateReceiptURL(transactionID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(config.RECEIPT_LINK_TTL)
+	token, err := util.GenerateToken(transactionID, string(config.TokenTypeReceipt), config.Env.JWT.Secret, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return fmt.Sprintf("%s/receipts/%s", config.Env.App.BaseURL, token), expiresAt, nil
+}
+
+func receiptPDFURL(token string) string {
+	return fmt.Sprintf("%s/receipts/%s/pdf", config.Env.App.BaseURL, token)
+}
+
+// loadLogo fetches and decodes the business logo, a receipt without the logo beats no receipt
+func (u *ReceiptUsecase) loadLogo(business *model.Business) *receiptLogo {
+	if business.Logo == nil || u.storage == nil {
+		return nil
+	}
+
+	data, err := u.storage.Download(*business.Logo)
+	if err != nil {
+		logger.Log.Warn("Failed to download business logo", zap.Error(err), zap.String("businessID", business.ID))
+		return nil
+	}
+
+	logo, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		logger.Log.Warn("Failed to decode business logo", zap.Error(err), zap.String("businessID", business.ID))
+		return nil
+	}
+
+	return &receiptLogo{Image: logo, Data: data, Format: format}
+}
+
+// buildReceipt lays out the items, totals and payments of a transaction
+func buildReceipt(transaction *model.Transaction, business *model.Business) receipt {
+	r := receipt{
+		BusinessName:  business.Name,
+		Address:       util.ToValue(business.Address),
+		Header:        util.ToValue(business.ReceiptHeader),
+		Footer:        util.ToValue(business.ReceiptFooter),
+		InvoiceNumber: transaction.InvoiceNumber,
+		Date:          transaction.CreatedAt.Format("02/01/2006 15:04"),
+		Cashier:       transaction.Creator.Name,
+	}
+
+	switch transaction.Status {
+	case config.TRANSACTION_STATUS_PENDING:
+		r.Status = "UNPAID"
+	case config.TRANSACTION_STATUS_EXPIRED:
+		r.Status = "EXPIRED"
+	case config.TRANSACTION_STATUS_CANCELLED:
+		r.Status = "CANCELLED"
+	}
+
+	for _, item := range transaction.Items {
+		line := receiptItem{
+			Name:     item.DisplayName(),
+			Quantity: item.Quantity,
+			Price:    formatMoney(item.Price),
+			Amount:   formatMoney(item.GrossAmount),
+		}
+		for _, modifier := range item.Modifiers {
+			modifierLine := receiptLine{Label: "+ " + modifier.Name}
+			if modifier.Price > 0 {
+				modifierLine.Amount = formatMoney(modifier.Price)
+			}
+			line.Modifiers = append(line.Modifiers, modifierLine)
+		}
+		if item.DiscountAmount > 0 {
+			// Promotions are named along with the cashier's reason
+			reasons := make([]string, 0, len(item.Promotions)+1)
+			for _, promotion := range item.Promotions {
+				reasons = append(reasons, promotion.Name)
+			}
+			if item.DiscountReason != nil && *item.DiscountReason != "" {
+				reasons = append(reasons, *item.DiscountReason)
+			}
+			label := "Discount"
+			if len(reasons) > 0 {
+				label = fmt.Sprintf("Discount (%s)", strings.Join(reasons, ", "))
+			}
+			line.Discount = &receiptLine{Label: label, Amount: "-" + formatMoney(item.DiscountAmount)}
+		}
+		r.Items = append(r.Items, line)
+	}
+
+	r.Totals = append(r.Totals, receiptLine{Label: "Subtotal", Amount: formatMoney(transaction.GrossAmount)})
+	if transaction.OrderDiscountAmount > 0 {
+		label := "Order discount"
+		if transaction.DiscountReason != nil && *transaction.DiscountReason != "" {
+			label = fmt.Sprintf("Order discount (%s)", *transaction.DiscountReason)
+		}
+		r.Totals = append(r.Totals, receiptLine{Label: label, Amount: "-" + formatMoney(transaction.OrderDiscountAmount)})
+	}
+	if transaction.VoucherAmount > 0 {
+		r.Totals = append(r.Totals, receiptLine{Label: fmt.Sprintf("Voucher (%s)", util.ToValue(transaction.VoucherCode)), Amount: "-" + formatMoney(transaction.VoucherAmount)})
+	}
+
+	var inclusiveTaxes []receiptLine
+	for _, tax := range transaction.Taxes {
+		line := receiptLine{
+			Label:  fmt.Sprintf("%s %s%%", tax.Name, formatRate(tax.Rate)),
+			Amount: formatMoney(tax.Amount),
+		}
+		if tax.IsInclusive {
+			line.Label = "Incl. " + line.Label
+			inclusiveTaxes = append(inclusiveTaxes, line)
+			continue
+		}
+		r.Totals = append(r.Totals, line)
+	}
+
+	r.Total = receiptLine{Label: "TOTAL", Amount: formatMoney(transaction.TotalAmount)}
+
+	r.AfterTotal = append(r.AfterTotal, inclusiveTaxes...)
+	if transaction.RoundingAmount != 0 {
+		r.AfterTotal = append(r.AfterTotal, receiptLine{Label: "Rounding", Amount: formatSignedMoney(transaction.RoundingAmount)})
+	}
+	for _, payment := range transaction.Payments {
+		// Later repayments of a credit sale are not part of the sale itself
+		if payment.IsCreditRepayment {
+			continue
+		}
+		r.AfterTotal = append(r.AfterTotal, receiptLine{Label: paymentMethodLabel(payment.Method), Amount: formatMoney(payment.Amount)})
+	}
+	if transaction.Status == config.TRANSACTION_STATUS_PAID {
+		r.AfterTotal = append(r.AfterTotal, receiptLine{Label: "Change", Amount: formatMoney(transaction.ChangeAmount)})
+	}
+	if transaction.OutstandingAmount > 0 {
+		r.AfterTotal = append(r.AfterTotal, receiptLine{Label: "Balance due", Amount: formatMoney(transaction.OutstandingAmount)})
+	}
+	if transaction.PointsEarned > 0 {
+		r.AfterTotal = append(r.AfterTotal, receiptLine{Label: "Points earned", Amount: fmt.Sprintf("+%d", transaction.PointsEarned)})
+	}
+
+	return r
+}
+
+// writeEscposReceipt prints the receipt after the logo, the printer is center aligned
+func writeEscposReceipt(printer *escpos.Printer, r receipt) {
+	printer.Bold(true).Large(true).Text(r.BusinessName).Large(false).Bold(false)
+	if r.Address != "" {
+		printer.Text(r.Address)
+	}
+	if r.Header != "" {
+		printer.Text(r.Header)
+	}
+
+	printer.Align(escpos.AlignLeft).Separator()
+	printer.Row("No", r.InvoiceNumber)
+	printer.Row("Date", r.Date)
+	if r.Cashier != "" {
+		printer.Row("Cashier", r.Cashier)
+	}
+	printer.Separator()
+
+	for _, item := range r.Items {
+		printer.Text(item.Name)
+		for _, modifier := range item.Modifiers {
+			printer.Row("  "+modifier.Label, modifier.Amount)
+		}
+		printer.Row(fmt.Sprintf("  %d x %s", item.Quantity, item.Price), item.Amount)
+		if item.Discount != nil {
+			printer.Row("  "+item.Discount.Label, item.Discount.Amount)
+		}
+	}
+	printer.Separator()
+
+	for _, line := range r.Totals {
+		printer.Row(line.Label, line.Amount)
+	}
+	printer.Bold(true).Row(r.Total.Label, r.Total.Amount).Bold(false)
+	for _, line := range r.AfterTotal {
+		printer.Row(line.Label, line.Amount)
+	}
+
+	if r.Status != "" {
+		printer.Separator().Align(escpos.AlignCenter).Bold(true).Text(r.Status).Bold(false)
+	}
+
+	if r.Footer != "" {
+		printer.Align(escpos.AlignCenter).Feed(1).Text(r.Footer)
+	}
+
+	printer.Feed(3).Cut()
+}
+
+// buildReceiptWhatsAppMessage sends the receipt PDF with a short summary and the link as its caption
+func buildReceiptWhatsAppMessage(r receipt, recipient, receiptURL string) WhatsAppMessage {
+	body := fmt.Sprintf("Thank you for shopping at %s.\nReceipt %s, total %s.\n\nView your receipt: %s", r.BusinessName, r.InvoiceNumber, r.Total.Amount, receiptURL)
+	return WhatsAppMessage{
+		To:           recipient,
+		Body:         body,
+		DocumentURL:  util.ToPointer(receiptURL + "/pdf"),
+		DocumentName: util.ToPointer(fmt.Sprintf("%s-%s.pdf", receiptLayoutReceipt, r.InvoiceNumber)),
+	}
+}
+
+// normalizeReceiptRecipient checks the recipient fits the channel, WhatsApp numbers are stored in international format
+func normalizeReceiptRecipient(channel config.ReceiptChannel, recipient string) (string, error) {
+	recipient = strings.TrimSpace(recipient)
+
+	switch channel {
+	case config.RECEIPT_CHANNEL_EMAIL:
+		address, err := mail.ParseAddress(recipient)
+		if err != nil || address.Address != recipient {
+			return "", fiber.NewError(fiber.StatusBadRequest, "Recipient must be a valid email address")
+		}
+		return strings.ToLower(recipient), nil
+	case config.RECEIPT_CHANNEL_WHATSAPP:
+		number, ok := util.NormalizePhoneNumber(recipient)
+		if !ok {
+			return "", fiber.NewError(fiber.StatusBadRequest, "Recipient must be a valid WhatsApp number")
+		}
+		return number, nil
+	}
+
+	return "", fiber.NewError(fiber.StatusBadRequest, "Unsupported receipt channel")
+}
+
+func buildReceiptDeliveryRes(delivery model.ReceiptDelivery) contract.ReceiptDeliveryRes {
+	var sentAt *string
+	if delivery.SentAt != nil {
+		sentAt = util.ToPointer(delivery.SentAt.Format(time.RFC3339))
+	}
+
+	return contract.ReceiptDeliveryRes{
+		ID:            delivery.ID,
+		TransactionID: delivery.TransactionID,
+		Channel:       string(delivery.Channel),
+		Recipient:     delivery.Recipient,
+		Status:        string(delivery.Status),
+		Provider:      delivery.Provider,
+		Reference:     delivery.Reference,
+		Error:         delivery.Error,
+		SentBy:        delivery.SentBy,
+		SenderName:    delivery.Sender.Name,
+		SentAt:        sentAt,
+		CreatedAt:     delivery.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// formatMoney formats an amount the Indonesian way, e.g. Rp 12.500 or Rp 12.500,50
+func formatMoney(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	sign := ""
+	if amount < 0 && cents > 0 {
+		sign = "-"
+	}
+	if cents%100 != 0 {
+		return fmt.Sprintf("%sRp %s,%02d", sign, grouped.String(), cents%100)
+	}
+	return fmt.Sprintf("%sRp %s", sign, grouped.String())
+}
+
+// formatSignedMoney always shows the sign, for adjustments that can go either way
+func formatSignedMoney(amount float64) string {
+	if amount > 0 {
+		return "+" + formatMoney(amount)
+	}
+	return formatMoney(amount)
+}
+
+// formatRate drops trailing zeros of a percentage, e.g. 11 or 2.5
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
+
+func paymentMethodLabel(method config.PaymentMethod) string {
+	switch method {
+	case config.PAYMENT_METHOD_CASH:
+		return "Cash"
+	case config.PAYMENT_METHOD_QRIS:
+		return "QRIS"
+	case config.PAYMENT_METHOD_BANK_TRANSFER:
+		return "Bank transfer"
+	case config.PAYMENT_METHOD_CARD:
+		return "Card"
+	case config.PAYMENT_METHOD_CREDIT:
+		return "Credit"
+	case config.PAYMENT_METHOD_POINTS:
+		return "Points"
+	case config.PAYMENT_METHOD_GIFT_CARD:
+		return "Gift card"
+	}
+	return string(method)
+}
@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RefundUsecase struct {
+	refundRepo        *repository.RefundRepository
+	transactionRepo   *repository.TransactionRepository
+	productRepo       *repository.ProductRepository
+	cashSessionRepo   *repository.CashSessionRepository
+	loyaltyUsecase    *LoyaltyUsecase
+	voucherUsecase    *VoucherUsecase
+	giftCardUsecase   *GiftCardUsecase
+	modifierUsecase   *ModifierUsecase
+	ingredientUsecase *IngredientUsecase
+	db                *gorm.DB
+}
+
+func NewRefundUsecase(
+	refundRepo *repository.RefundRepository,
+	transactionRepo *repository.TransactionRepository,
+	productRepo *repository.ProductRepository,
+	cashSessionRepo *repository.CashSessionRepository,
+	loyaltyUsecase *LoyaltyUsecase,
+	voucherUsecase *VoucherUsecase,
+	giftCardUsecase *GiftCardUsecase,
+	modifierUsecase *ModifierUsecase,
+	ingredientUsecase *IngredientUsecase,
+	db *gorm.DB,
+) *RefundUsecase {
+	return &RefundUsecase{
+		refundRepo:        refundRepo,
+		transactionRepo:   transactionRepo,
+		productRepo:       productRepo,
+		cashSessionRepo:   cashSessionRepo,
+		loyaltyUsecase:    loyaltyUsecase,
+		voucherUsecase:    voucherUsecase,
+		giftCardUsecase:   giftCardUsecase,
+		modifierUsecase:   modifierUsecase,
+		ingredientUsecase: ingredientUsecase,
+		db:                db,
+	}
+}
+
+// CreateRefund records a full or partial return against a paid transaction
+func (u *RefundUsecase) CreateRefund(userID, businessID, transactionID string, req *contract.CreateRefundReq) (*contract.RefundRes, error) {
+	// Cash refunds come out of the drawer the employee has open
+	cashSession, err := u.cashSessionRepo.GetOpenSessionByUserID(userID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get open cash session", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	// Lock the sale so concurrent refunds can't exceed what was sold
+	transaction, err := u.transactionRepo.GetTransactionForUpdate(tx, transactionID, businessID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if transaction == nil {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+	}
+
+	if transaction.Status != config.TRANSACTION_STATUS_PAID {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Can only refund paid transactions")
+	}
+
+	refundedLines, err := u.refundRepo.GetRefundedLines(tx, transactionID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get refunded quantities", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create refund")
+	}
+
+	refundItems, amount, err := u.buildRefundItems(transaction.Items, req.Items, refundedLines)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// A credit refund lowers what the customer owes instead of paying money back
+	method := config.PaymentMethod(req.Method)
+	var creditAmount float64
+	if method == config.PAYMENT_METHOD_CREDIT {
+		if amount > transaction.OutstandingAmount {
+			tx.Rollback()
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Refund exceeds the outstanding credit. Outstanding: %.2f, Refund: %.2f", transaction.OutstandingAmount, amount))
+		}
+		creditAmount = amount
+	} else if transaction.CreditAmount > 0 {
+		// On a credit sale the debt is cleared first, only money actually collected is paid out
+		earlierRefunds, err := u.refundRepo.GetRefundedAmount(tx, transactionID)
+		if err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to get refunded amount", zap.Error(err))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create refund")
+		}
+
+		creditAmount = math.Min(amount, transaction.OutstandingAmount)
+		collected := roundMoney(transaction.TotalAmount - transaction.OutstandingAmount - earlierRefunds)
+		if roundMoney(amount-creditAmount) > collected {
+			tx.Rollback()
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Refund exceeds what the customer has paid. Paid: %.2f, Refund: %.2f", math.Max(collected, 0), roundMoney(amount-creditAmount)))
+		}
+	}
+
+	if creditAmount > 0 {
+		outstanding := roundMoney(transaction.OutstandingAmount - creditAmount)
+		if err := tx.Model(&model.Transaction{}).Where("id = ?", transaction.ID).Update("outstanding_amount", outstanding).Error; err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to update outstanding amount", zap.Error(err), zap.String("transactionID", transaction.ID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create refund")
+		}
+	}
+
+	refund := &model.Refund{
+		BusinessID:    businessID,
+		TransactionID: transactionID,
+		CreatedBy:     userID,
+		Method:        method,
+		Amount:        amount,
+		CreditAmount:  creditAmount,
+		Reason:        req.Reason,
+		Restock:       req.Restock,
+		Items:         refundItems,
+	}
+	if cashSession != nil {
+		refund.CashSessionID = &cashSession.ID
+	}
+
+	if err := u.refundRepo.CreateRefund(tx, refund); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create refund", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create refund")
+	}
+
+	if req.Restock {
+		if err := u.restockItems(tx, refund, transaction.Items); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Points earned on what was returned are taken back
+	refundedAmount, err := u.refundRepo.GetRefundedAmount(tx, transactionID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get refunded amount", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create refund")
+	}
+
+	if err := u.loyaltyUsecase.ReverseRefund(tx, transaction, refund, refundedAmount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// A gift card refund goes back on the cards the sale was paid with
+	if err := u.giftCardUsecase.RestoreRefund(tx, transaction, refund); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// A fully refunded sale gives its voucher use back
+	if err := u.voucherUsecase.ReleaseRefunded(tx, transaction, refundedAmount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit refund", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create refund")
+	}
+
+	// Reload with creator for response
+	created, err := u.refundRepo.GetRefundByID(refund.ID)
+	if err != nil || created == nil {
+		logger.Log.Error("Failed to reload refund", zap.Error(err))
+		return util.ToPointer(buildRefundRes(*refund)), nil
+	}
+
+	return util.ToPointer(buildRefundRes(*created)), nil
+}
+
+// ListRefunds lists the refund history of a transaction
+func (u *RefundUsecase) ListRefunds(transactionID string) ([]contract.RefundRes, error) {
+	refunds, err := u.refundRepo.ListRefundsByTransactionID(transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to list refunds", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to list refunds")
+	}
+
+	results := make([]contract.RefundRes, len(refunds))
+	for i, refund := range refunds {
+		results[i] = buildRefundRes(refund)
+	}
+
+	return results, nil
+}
+
+// IsAllowedToAccess checks if user has permission to access refunds of a transaction
+func (u *RefundUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, transactionID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	if permission.Scope() == config.PERMISSION_SCOPE_ORG && transactionID != nil {
+		transaction, err := u.transactionRepo.GetTransactionByIDAndBusinessID(*transactionID, *claims.BusinessID)
+		if err != nil {
+			logger.Log.Error("Failed to get transaction", zap.Error(err), zap.String("transactionID", *transactionID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+		}
+
+		if transaction == nil {
+			logger.Log.Warn("Transaction not found", zap.String("transactionID", *transactionID))
+			return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+		}
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Helper Functions
+// ============================================================================
+
+// buildRefundItems validates requested quantities against what is still refundable and calculates the amount
+func (u *RefundUsecase) buildRefundItems(soldItems []model.TransactionItem, reqItems []contract.RefundItemReq, refundedLines map[string]repository.RefundedLine) ([]model.RefundItem, float64, error) {
+	soldMap := make(map[string]model.TransactionItem, len(soldItems))
+	for _, item := range soldItems {
+		soldMap[item.ID] = item
+	}
+
+	// Merge duplicate lines so the same item can't slip past the limit twice
+	requested := make(map[string]int)
+	order := make([]string, 0, len(reqItems))
+	for _, item := range reqItems {
+		if _, exists := requested[item.TransactionItemID]; !exists {
+			order = append(order, item.TransactionItemID)
+		}
+		requested[item.TransactionItemID] += item.Quantity
+	}
+
+	var amount float64
+	refundItems := make([]model.RefundItem, 0, len(order))
+
+	for _, itemID := range order {
+		sold, exists := soldMap[itemID]
+		if !exists {
+			return nil, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Item %s is not part of this transaction", itemID))
+		}
+
+		quantity := requested[itemID]
+		refunded := refundedLines[itemID]
+		refundable := sold.Quantity - refunded.Quantity
+		if quantity > refundable {
+			return nil, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Cannot refund %d of %s. Refundable: %d", quantity, sold.DisplayName(), refundable))
+		}
+
+		// Refund what the customer actually paid, after discounts and with exclusive taxes
+		paid := sold.Subtotal + sold.TaxAmount
+		unitPrice := roundMoney(paid / float64(sold.Quantity))
+
+		// Rounded shares of earlier refunds may add up past the line, the last one gets what is left
+		remaining := math.Max(roundMoney(paid-refunded.Amount), 0)
+		subtotal := remaining
+		if quantity < refundable {
+			subtotal = math.Min(roundMoney(paid*float64(quantity)/float64(sold.Quantity)), remaining)
+		}
+		amount += subtotal
+
+		refundItems = append(refundItems, model.RefundItem{
+			TransactionItemID: sold.ID,
+			ProductID:         sold.ProductID,
+			ProductName:       sold.ProductName,
+			VariantID:         sold.VariantID,
+			VariantLabel:      sold.VariantLabel,
+			Price:             unitPrice,
+			Quantity:          quantity,
+			Subtotal:          subtotal,
+		})
+	}
+
+	return refundItems, amount, nil
+}
+
+// restockItems puts refunded quantities back into stock, along with the modifiers and ingredients sold on them
+// Every restocked line is recorded as a refund movement.
+func (u *RefundUsecase) restockItems(tx *gorm.DB, refund *model.Refund, soldItems []model.TransactionItem) error {
+	items := refund.Items
+	soldMap := make(map[string]model.TransactionItem, len(soldItems))
+	for _, item := range soldItems {
+		soldMap[item.ID] = item
+	}
+
+	quantities := make(map[string]int, len(items))
+	for _, item := range items {
+		quantities[item.TransactionItemID] += item.Quantity
+	}
+
+	if err := u.ingredientUsecase.RestoreStock(tx, soldItems, quantities); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := u.modifierUsecase.RestoreStock(tx, soldMap[item.TransactionItemID].Modifiers, item.Quantity); err != nil {
+			return err
+		}
+
+		if item.ProductID == nil {
+			continue
+		}
+
+		product, err := u.productRepo.GetProductByID(*item.ProductID)
+		if err != nil || product == nil || !product.EnableStock {
+			continue
+		}
+
+		err = u.productRepo.MoveStock(tx, &model.StockMovement{
+			BusinessID:    refund.BusinessID,
+			ProductID:     *item.ProductID,
+			VariantID:     item.VariantID,
+			Type:          config.STOCK_MOVEMENT_TYPE_REFUND,
+			Quantity:      item.Quantity,
+			TransactionID: &refund.TransactionID,
+			RefundID:      &refund.ID,
+			Reason:        &refund.Reason,
+			CreatedBy:     &refund.CreatedBy,
+		})
+		if err != nil {
+			logger.Log.Error("Failed to restock refunded item", zap.Error(err), zap.String("productID", *item.ProductID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to restock items")
+		}
+	}
+	return nil
+}
+
+// buildRefundRes builds refund response
+func buildRefundRes(refund model.Refund) contract.RefundRes {
+	items := make([]contract.RefundItemRes, len(refund.Items))
+	for i, item := range refund.Items {
+		items[i] = contract.RefundItemRes{
+			ID:                item.ID,
+			TransactionItemID: item.TransactionItemID,
+			ProductID:         item.ProductID,
+			ProductName:       item.ProductName,
+			VariantID:         item.VariantID,
+			VariantLabel:      item.VariantLabel,
+			Price:             item.Price,
+			Quantity:          item.Quantity,
+			Subtotal:          item.Subtotal,
+		}
+	}
+
+	return contract.RefundRes{
+		ID:            refund.ID,
+		TransactionID: refund.TransactionID,
+		CreatedBy:     refund.CreatedBy,
+		CreatorName:   refund.Creator.Name,
+		Method:        string(refund.Method),
+		Amount:        refund.Amount,
+		CreditAmount:  refund.CreditAmount,
+		Reason:        refund.Reason,
+		Restock:       refund.Restock,
+		CreatedAt:     refund.CreatedAt.Format(time.RFC3339),
+		Items:         items,
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"app/internal/contract"
+	"app/internal/model"
+	"app/internal/repository"
+	"testing"
+)
+
+func TestBuildRefundItemsRepeatedPartialRefunds(t *testing.T) {
+	tests := []struct {
+		name     string
+		subtotal float64
+		tax      float64
+		quantity int
+		steps    []int
+	}{
+		{name: "shares rounded up", subtotal: 100, quantity: 6, steps: []int{1, 1, 1, 1, 1, 1}},
+		{name: "shares rounded down", subtotal: 100, quantity: 3, steps: []int{1, 1, 1}},
+		{name: "uneven steps with tax", subtotal: 90.1, tax: 9.91, quantity: 7, steps: []int{2, 1, 3, 1}},
+		{name: "single refund of everything", subtotal: 45.5, quantity: 4, steps: []int{4}},
+	}
+
+	u := &RefundUsecase{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sold := model.TransactionItem{ID: "item-1", Quantity: tt.quantity, Subtotal: tt.subtotal, TaxAmount: tt.tax}
+			paid := roundMoney(tt.subtotal + tt.tax)
+			refunded := repository.RefundedLine{TransactionItemID: sold.ID}
+
+			for i, quantity := range tt.steps {
+				lines := map[string]repository.RefundedLine{sold.ID: refunded}
+				items, amount, err := u.buildRefundItems([]model.TransactionItem{sold}, []contract.RefundItemReq{{TransactionItemID: sold.ID, Quantity: quantity}}, lines)
+				if err != nil {
+					t.Fatalf("refund %d: unexpected error: %v", i+1, err)
+				}
+				if len(items) != 1 || items[0].Subtotal != amount {
+					t.Fatalf("refund %d: got items %+v for amount %v", i+1, items, amount)
+				}
+				if amount < 0 {
+					t.Fatalf("refund %d: negative amount %v", i+1, amount)
+				}
+
+				refunded.Quantity += quantity
+				refunded.Amount = roundMoney(refunded.Amount + amount)
+				if refunded.Amount > paid {
+					t.Fatalf("refund %d: refunded %v of a line paid %v", i+1, refunded.Amount, paid)
+				}
+			}
+
+			if refunded.Amount != paid {
+				t.Errorf("refunded %v in total, want the paid %v", refunded.Amount, paid)
+			}
+
+			_, _, err := u.buildRefundItems([]model.TransactionItem{sold}, []contract.RefundItemReq{{TransactionItemID: sold.ID, Quantity: 1}}, map[string]repository.RefundedLine{sold.ID: refunded})
+			if err == nil {
+				t.Errorf("refunding past the sold quantity should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TaxRuleUsecase struct {
+	taxRuleRepo  *repository.TaxRuleRepository
+	categoryRepo *repository.CategoryRepository
+	productRepo  *repository.ProductRepository
+	db           *gorm.DB
+}
+
+func NewTaxRuleUsecase(
+	taxRuleRepo *repository.TaxRuleRepository,
+	categoryRepo *repository.CategoryRepository,
+	productRepo *repository.ProductRepository,
+	db *gorm.DB,
+) *TaxRuleUsecase {
+	return &TaxRuleUsecase{
+		taxRuleRepo:  taxRuleRepo,
+		categoryRepo: categoryRepo,
+		productRepo:  productRepo,
+		db:           db,
+	}
+}
+
+func (u *TaxRuleUsecase) CreateTaxRule(businessID string, req *contract.CreateTaxRuleReq) (*contract.TaxRuleRes, error) {
+	maxSortOrder, err := u.taxRuleRepo.GetMaxSortOrder(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get max sort_order", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create tax rule")
+	}
+
+	rule := &model.TaxRule{
+		BusinessID:   businessID,
+		Name:         req.Name,
+		Kind:         config.TaxKind(req.Kind),
+		Rate:         req.Rate,
+		IsInclusive:  req.IsInclusive,
+		AppliesToAll: true,
+		IsActive:     true,
+		SortOrder:    maxSortOrder + 1,
+	}
+	if req.AppliesToAll != nil {
+		rule.AppliesToAll = *req.AppliesToAll
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := u.validateTargets(businessID, rule.AppliesToAll, req.CategoryIDs, req.ProductIDs); err != nil {
+		return nil, err
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.taxRuleRepo.CreateTaxRule(tx, rule); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to create tax rule", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create tax rule")
+	}
+
+	if err := u.taxRuleRepo.ReplaceTaxRuleTargets(tx, rule.ID, req.CategoryIDs, req.ProductIDs); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to set tax rule targets", zap.Error(err), zap.String("taxRuleID", rule.ID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create tax rule")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create tax rule")
+	}
+
+	return u.GetTaxRuleByID(rule.ID)
+}
+
+func (u *TaxRuleUsecase) UpdateTaxRule(taxRuleID string, req *contract.UpdateTaxRuleReq) (*contract.TaxRuleRes, error) {
+	rule, err := u.taxRuleRepo.GetTaxRuleByID(taxRuleID)
+	if err != nil {
+		logger.Log.Error("Failed to get tax rule", zap.Error(err), zap.String("taxRuleID", taxRuleID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get tax rule")
+	}
+
+	if rule == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Tax rule not found")
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Kind != nil {
+		rule.Kind = config.TaxKind(*req.Kind)
+	}
+	if req.Rate != nil {
+		rule.Rate = *req.Rate
+	}
+	if req.IsInclusive != nil {
+		rule.IsInclusive = *req.IsInclusive
+	}
+	if req.AppliesToAll != nil {
+		rule.AppliesToAll = *req.AppliesToAll
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		rule.SortOrder = *req.SortOrder
+	}
+
+	// Targets are kept unless the request replaces them
+	categoryIDs := make([]string, len(rule.Categories))
+	for i, category := range rule.Categories {
+		categoryIDs[i] = category.ID
+	}
+	if req.CategoryIDs != nil {
+		categoryIDs = *req.CategoryIDs
+	}
+
+	productIDs := make([]string, len(rule.Products))
+	for i, product := range rule.Products {
+		productIDs[i] = product.ID
+	}
+	if req.ProductIDs != nil {
+		productIDs = *req.ProductIDs
+	}
+
+	if err := u.validateTargets(rule.BusinessID, rule.AppliesToAll, categoryIDs, productIDs); err != nil {
+		return nil, err
+	}
+
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	if err := u.taxRuleRepo.UpdateTaxRule(tx, rule); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to update tax rule", zap.Error(err), zap.String("taxRuleID", taxRuleID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update tax rule")
+	}
+
+	if err := u.taxRuleRepo.ReplaceTaxRuleTargets(tx, rule.ID, categoryIDs, productIDs); err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to set tax rule targets", zap.Error(err), zap.String("taxRuleID", taxRuleID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update tax rule")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update tax rule")
+	}
+
+	return u.GetTaxRuleByID(rule.ID)
+}
+
+func (u *TaxRuleUsecase) GetTaxRuleByID(taxRuleID string) (*contract.TaxRuleRes, error) {
+	rule, err := u.taxRuleRepo.GetTaxRuleByID(taxRuleID)
+	if err != nil {
+		logger.Log.Error("Failed to get tax rule", zap.Error(err), zap.String("taxRuleID", taxRuleID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get tax rule")
+	}
+
+	if rule == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Tax rule not found")
+	}
+
+	return buildTaxRuleRes(rule), nil
+}
+
+func (u *TaxRuleUsecase) ListTaxRules(businessID string) ([]contract.TaxRuleRes, error) {
+	rules, err := u.taxRuleRepo.ListTaxRules(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to list tax rules", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to list tax rules")
+	}
+
+	results := make([]contract.TaxRuleRes, len(rules))
+	for i := range rules {
+		results[i] = *buildTaxRuleRes(&rules[i])
+	}
+
+	return results, nil
+}
+
+func (u *TaxRuleUsecase) DeleteTaxRule(taxRuleID string) error {
+	if err := u.taxRuleRepo.DeleteTaxRule(taxRuleID); err != nil {
+		logger.Log.Error("Failed to delete tax rule", zap.Error(err), zap.String("taxRuleID", taxRuleID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete tax rule")
+	}
+
+	return nil
+}
+
+// validateTargets checks that a limited rule has targets and that they belong to the business
+func (u *TaxRuleUsecase) validateTargets(businessID string, appliesToAll bool, categoryIDs, productIDs []string) error {
+	if !appliesToAll && len(categoryIDs) == 0 && len(productIDs) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Choose at least one category or product, or apply the rule to all products")
+	}
+
+	for _, categoryID := range categoryIDs {
+		category, err := u.categoryRepo.GetCategoryByIDAndBusinessID(categoryID, businessID)
+		if err != nil {
+			logger.Log.Error("Failed to get category", zap.Error(err), zap.String("categoryID", categoryID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get category")
+		}
+		if category == nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Category %s not found", categoryID))
+		}
+	}
+
+	if len(productIDs) > 0 {
+		products, err := u.productRepo.GetProductsByIDs(productIDs)
+		if err != nil {
+			logger.Log.Error("Failed to fetch products", zap.Error(err))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+		}
+
+		found := make(map[string]bool, len(products))
+		for _, product := range products {
+			if product.BusinessID == businessID {
+				found[product.ID] = true
+			}
+		}
+
+		for _, productID := range productIDs {
+			if !found[productID] {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s not found", productID))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *TaxRuleUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, taxRuleID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if taxRuleID != nil {
+			rule, err := u.taxRuleRepo.GetTaxRuleByIDAndBusinessID(*taxRuleID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get tax rule", zap.Error(err), zap.String("taxRuleID", *taxRuleID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get tax rule")
+			}
+
+			if rule == nil {
+				logger.Log.Warn("Tax rule not found", zap.String("taxRuleID", *taxRuleID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildTaxRuleRes builds tax rule response
+func buildTaxRuleRes(rule *model.TaxRule) *contract.TaxRuleRes {
+	categoryIDs := make([]string, len(rule.Categories))
+	for i, category := range rule.Categories {
+		categoryIDs[i] = category.ID
+	}
+
+	productIDs := make([]string, len(rule.Products))
+	for i, product := range rule.Products {
+		productIDs[i] = product.ID
+	}
+
+	return &contract.TaxRuleRes{
+		ID:           rule.ID,
+		BusinessID:   rule.BusinessID,
+		Name:         rule.Name,
+		Kind:         string(rule.Kind),
+		Rate:         rule.Rate,
+		IsInclusive:  rule.IsInclusive,
+		AppliesToAll: rule.AppliesToAll,
+		IsActive:     rule.IsActive,
+		SortOrder:    rule.SortOrder,
+		CategoryIDs:  categoryIDs,
+		ProductIDs:   productIDs,
+		CreatedAt:    rule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    rule.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -7,15 +7,22 @@ import (
 	"app/internal/model"
 	"app/internal/repository"
 	"app/pkg/logger"
+	"app/pkg/qris"
 	"app/pkg/storage"
 	"app/pkg/util"
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"math/rand"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/skip2/go-qrcode"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionUsecase struct {
@@ -23,6 +30,17 @@ type TransactionUsecase struct {
 	transactionItemRepo *repository.TransactionItemRepository
 	productRepo         *repository.ProductRepository
 	businessRepo        *repository.BusinessRepository
+	userRepo            *repository.UserRepository
+	invoiceSequenceRepo *repository.InvoiceSequenceRepository
+	taxRuleRepo         *repository.TaxRuleRepository
+	promotionRepo       *repository.PromotionRepository
+	cashSessionRepo     *repository.CashSessionRepository
+	customerRepo        *repository.CustomerRepository
+	loyaltyUsecase      *LoyaltyUsecase
+	voucherUsecase      *VoucherUsecase
+	giftCardUsecase     *GiftCardUsecase
+	modifierUsecase     *ModifierUsecase
+	ingredientUsecase   *IngredientUsecase
 	db                  *gorm.DB
 }
 
@@ -31,6 +49,17 @@ func NewTransactionUsecase(
 	transactionItemRepo *repository.TransactionItemRepository,
 	productRepo *repository.ProductRepository,
 	businessRepo *repository.BusinessRepository,
+	userRepo *repository.UserRepository,
+	invoiceSequenceRepo *repository.InvoiceSequenceRepository,
+	taxRuleRepo *repository.TaxRuleRepository,
+	promotionRepo *repository.PromotionRepository,
+	cashSessionRepo *repository.CashSessionRepository,
+	customerRepo *repository.CustomerRepository,
+	loyaltyUsecase *LoyaltyUsecase,
+	voucherUsecase *VoucherUsecase,
+	giftCardUsecase *GiftCardUsecase,
+	modifierUsecase *ModifierUsecase,
+	ingredientUsecase *IngredientUsecase,
 	db *gorm.DB,
 	storage *storage.R2Storage,
 ) *TransactionUsecase {
@@ -39,44 +68,76 @@ func NewTransactionUsecase(
 		transactionItemRepo: transactionItemRepo,
 		productRepo:         productRepo,
 		businessRepo:        businessRepo,
+		userRepo:            userRepo,
+		invoiceSequenceRepo: invoiceSequenceRepo,
+		taxRuleRepo:         taxRuleRepo,
+		promotionRepo:       promotionRepo,
+		cashSessionRepo:     cashSessionRepo,
+		customerRepo:        customerRepo,
+		loyaltyUsecase:      loyaltyUsecase,
+		voucherUsecase:      voucherUsecase,
+		giftCardUsecase:     giftCardUsecase,
+		modifierUsecase:     modifierUsecase,
+		ingredientUsecase:   ingredientUsecase,
 		db:                  db,
 	}
 }
 
 // CreateTransaction handles the checkout process
 func (u *TransactionUsecase) CreateTransaction(userID, businessID string, req *contract.CreateTransactionReq) (*contract.TransactionRes, error) {
+	return u.createTransaction(userID, businessID, req, nil)
+}
+
+// createTransaction creates the transaction, claim runs first inside the same database transaction
+// so whatever the sale is made from is only taken when the sale is saved
+func (u *TransactionUsecase) createTransaction(userID, businessID string, req *contract.CreateTransactionReq, claim func(tx *gorm.DB) error) (*contract.TransactionRes, error) {
 
 	// Validate products and build items
-	productMap, err := u.fetchAndValidateProducts(req.Items, businessID)
+	productMap, variantMap, err := u.fetchAndValidateProducts(req.Items, businessID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create transaction items and calculate total
-	totalAmount, transactionItems := u.buildTransactionItems(req.Items, productMap, "")
+	lineModifiers, err := u.modifierUsecase.ResolveModifiers(businessID, req.Items, productMap)
+	if err != nil {
+		return nil, err
+	}
 
-	// For cash payment
-	status := config.TRANSACTION_STATUS_PENDING
-	var receivedAmount, changeAmount float64
-	var paidAt *time.Time
+	promotions, err := u.getRunningPromotions(businessID, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-	if req.IsCashPaid {
-		if req.ReceivedAmount == nil {
-			return nil, fiber.NewError(fiber.StatusBadRequest, "Received amount is required")
-		}
+	voucher, err := u.voucherUsecase.GetUsableVoucher(businessID, req.VoucherCode, req.CustomerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-		receivedAmount = *req.ReceivedAmount
-		changeAmount = receivedAmount - totalAmount
+	// Create transaction items and calculate total
+	amounts, transactionItems, err := u.buildTransactionItems(req.Items, productMap, variantMap, lineModifiers, promotions, req.Discount, voucher, "")
+	if err != nil {
+		return nil, err
+	}
 
-		if changeAmount < 0 {
-			return nil, fiber.NewError(fiber.StatusBadRequest, "Received amount is less than total amount")
-		}
+	if err := u.applyTaxes(businessID, transactionItems, productMap, &amounts); err != nil {
+		return nil, err
+	}
 
-		// status
+	discountApprovedBy, err := u.authorizeDiscount(userID, businessID, amounts.MaxDiscountPercent, req.ApproverID, req.ApproverPin)
+	if err != nil {
+		return nil, err
+	}
 
-		status = config.TRANSACTION_STATUS_PAID
-		paidAt = util.ToPointer(time.Now())
+	customer, err := u.getCustomer(businessID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
 
+	// The sale counts in the drawer the cashier has open
+	cashSession, err := u.cashSessionRepo.GetOpenSessionByUserID(userID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get open cash session", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get cash session")
 	}
 
 	// Start transaction
@@ -86,28 +147,74 @@ func (u *TransactionUsecase) CreateTransaction(userID, businessID string, req *c
 	}
 	defer handlePanic(tx)
 
+	if claim != nil {
+		if err := claim(tx); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	invoiceNumber, err := u.nextInvoiceNumber(tx, businessID, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Create transaction
 	transaction := &model.Transaction{
-		BusinessID:     businessID,
-		CreatedBy:      userID,
-		TotalAmount:    totalAmount,
-		InvoiceNumber:  generateInvoiceNumber(),
-		ReceivedAmount: receivedAmount,
-		ChangeAmount:   changeAmount,
-		Status:         status,
-		PaidAt:         paidAt,
-		ExpiredAt:      time.Now().Add(config.TRANSACTION_EXPIRY_TIME),
+		BusinessID:    businessID,
+		CreatedBy:     userID,
+		InvoiceNumber: invoiceNumber,
+		Status:        config.TRANSACTION_STATUS_PENDING,
+		ExpiredAt:     now.Add(config.TRANSACTION_EXPIRY_TIME),
+	}
+	if cashSession != nil {
+		transaction.CashSessionID = &cashSession.ID
+	}
+	if customer != nil {
+		transaction.CustomerID = &customer.ID
+	}
+	applyTransactionAmounts(transaction, amounts, req.Discount, voucher, discountApprovedBy)
+
+	// Paid at checkout
+	if req.IsCashPaid || len(req.Payments) > 0 {
+		rounding, err := u.getCashRounding(businessID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := applyPayments(transaction, req.Payments, req.ReceivedAmount, rounding); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := u.applyCredit(tx, businessID, transaction); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
-	if err := tx.Create(transaction).Error; err != nil {
+	if err := tx.Omit(clause.Associations).Create(transaction).Error; err != nil {
 		tx.Rollback()
 		logger.Log.Error("Failed to create transaction", zap.Error(err))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create transaction")
 	}
 
-	// Set transaction ID for items and create them
+	if transaction.VoucherID != nil {
+		if err := u.voucherUsecase.Redeem(tx, *transaction.VoucherID, transaction); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Set transaction ID for items and their promotions and create them
 	for _, item := range transactionItems {
 		item.TransactionID = transaction.ID
+		for i := range item.Promotions {
+			item.Promotions[i].TransactionID = transaction.ID
+		}
 	}
 
 	if err := tx.Create(&transactionItems).Error; err != nil {
@@ -116,8 +223,40 @@ func (u *TransactionUsecase) CreateTransaction(userID, businessID string, req *c
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create transaction items")
 	}
 
+	if err := createTaxes(tx, transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := createPayments(tx, transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if transaction.Status == config.TRANSACTION_STATUS_PAID {
+		if err := u.loyaltyUsecase.ApplyPaidTransaction(tx, transaction, &userID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := u.giftCardUsecase.ApplyPaidTransaction(tx, transaction, &userID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := u.ingredientUsecase.DeductStock(tx, convertToTransactionItems(transactionItems)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// Update stock
-	if err := u.updateStock(tx, req.Items, productMap, false); err != nil {
+	if err := u.updateStock(tx, transaction, userID, req.Items, productMap, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.modifierUsecase.DeductStock(tx, transactionItems); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -130,6 +269,7 @@ func (u *TransactionUsecase) CreateTransaction(userID, businessID string, req *c
 
 	// Load items for response
 	transaction.Items = convertToTransactionItems(transactionItems)
+	transaction.Customer = customer
 	return util.ToPointer(buildTransactionRes(util.ToValue(transaction))), nil
 }
 
@@ -141,13 +281,53 @@ func (u *TransactionUsecase) UpdateTransaction(userID, businessID, transactionID
 		return nil, err
 	}
 
-	oldItems := transaction.Items
-
 	// Validate new products
-	productMap, err := u.fetchAndValidateProducts(req.Items, businessID)
+	productMap, variantMap, err := u.fetchAndValidateProducts(req.Items, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	lineModifiers, err := u.modifierUsecase.ResolveModifiers(businessID, req.Items, productMap)
+	if err != nil {
+		return nil, err
+	}
+
+	promotions, err := u.getRunningPromotions(businessID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// The customer is kept unless the request picks another one
+	if req.CustomerID != nil {
+		transaction.CustomerID = req.CustomerID
+	}
+
+	voucher, err := u.voucherUsecase.GetUsableVoucher(businessID, req.VoucherCode, transaction.CustomerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Build new items and recalculate totals
+	amounts, transactionItems, err := u.buildTransactionItems(req.Items, productMap, variantMap, lineModifiers, promotions, req.Discount, voucher, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.applyTaxes(businessID, transactionItems, productMap, &amounts); err != nil {
+		return nil, err
+	}
+
+	discountApprovedBy, err := u.authorizeDiscount(userID, businessID, amounts.MaxDiscountPercent, req.ApproverID, req.ApproverPin)
+	if err != nil {
+		return nil, err
+	}
+	applyTransactionAmounts(transaction, amounts, req.Discount, voucher, discountApprovedBy)
+
+	customer, err := u.getCustomer(businessID, transaction.CustomerID)
 	if err != nil {
 		return nil, err
 	}
+	transaction.Customer = customer
 
 	tx := u.db.Begin()
 	if tx.Error != nil {
@@ -155,14 +335,32 @@ func (u *TransactionUsecase) UpdateTransaction(userID, businessID, transactionID
 	}
 	defer handlePanic(tx)
 
-	// Restore stock from old items
-	if err := u.restoreStock(tx, oldItems); err != nil {
+	// Lock the row and check again, it may have been paid, cancelled or expired since it was read
+	locked, err := u.transactionRepo.GetTransactionForUpdate(tx, transactionID, businessID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if err := validatePendingTransaction(locked); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Restore stock from the items as they are now, not as they were first read
+	if err := u.restoreStock(tx, transaction, locked.Items, &userID, "Cart changed"); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	// Update stock with new items
-	if err := u.updateStock(tx, req.Items, productMap, false); err != nil {
+	if err := u.updateStock(tx, transaction, userID, req.Items, productMap, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.modifierUsecase.DeductStock(tx, transactionItems); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -174,48 +372,85 @@ func (u *TransactionUsecase) UpdateTransaction(userID, businessID, transactionID
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update transaction")
 	}
 
-	// Create new items and update total
-	totalAmount, transactionItems := u.buildTransactionItems(req.Items, productMap, transactionID)
-	transaction.TotalAmount = totalAmount
+	// Tax lines are worked out again from the new items
+	if err := tx.Where("transaction_id = ?", transactionID).Delete(&model.TransactionTax{}).Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to delete old taxes", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update transaction")
+	}
 
-	// For cash payment
-	if req.IsCashPaid {
-		status := config.TRANSACTION_STATUS_PENDING
-		var receivedAmount, changeAmount float64
-		if req.ReceivedAmount == nil {
-			return nil, fiber.NewError(fiber.StatusBadRequest, "Received amount is required")
+	// Paid on update
+	if req.IsCashPaid || len(req.Payments) > 0 {
+		rounding, err := u.getCashRounding(businessID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 
-		receivedAmount = *req.ReceivedAmount
-		changeAmount = receivedAmount - totalAmount
-
-		if changeAmount < 0 {
-			return nil, fiber.NewError(fiber.StatusBadRequest, "Received amount is less than total amount")
+		if err := applyPayments(transaction, req.Payments, req.ReceivedAmount, rounding); err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 
-		// status
-		if req.IsCashPaid {
-			status = config.TRANSACTION_STATUS_PAID
+		if err := u.applyCredit(tx, businessID, transaction); err != nil {
+			tx.Rollback()
+			return nil, err
 		}
-
-		transaction.Status = status
-		transaction.ReceivedAmount = receivedAmount
-		transaction.ChangeAmount = changeAmount
-		transaction.PaidAt = util.ToPointer(time.Now())
 	}
 
-	if err := tx.Save(transaction).Error; err != nil {
+	// Old items are already deleted, so associations must not be upserted back
+	if err := tx.Omit(clause.Associations).Save(transaction).Error; err != nil {
 		tx.Rollback()
 		logger.Log.Error("Failed to update transaction", zap.Error(err))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update transaction")
 	}
 
+	// The voucher is redeemed again, so a changed cart or customer is checked against its limits
+	if err := u.voucherUsecase.Release(tx, transactionID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if transaction.VoucherID != nil {
+		if err := u.voucherUsecase.Redeem(tx, *transaction.VoucherID, transaction); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if err := tx.Create(&transactionItems).Error; err != nil {
 		tx.Rollback()
 		logger.Log.Error("Failed to create new items", zap.Error(err))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create transaction items")
 	}
 
+	if err := createTaxes(tx, transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := createPayments(tx, transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if transaction.Status == config.TRANSACTION_STATUS_PAID {
+		if err := u.loyaltyUsecase.ApplyPaidTransaction(tx, transaction, &userID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := u.giftCardUsecase.ApplyPaidTransaction(tx, transaction, &userID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := u.ingredientUsecase.DeductStock(tx, convertToTransactionItems(transactionItems)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		logger.Log.Error("Failed to commit transaction", zap.Error(err))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update transaction")
@@ -225,33 +460,194 @@ func (u *TransactionUsecase) UpdateTransaction(userID, businessID, transactionID
 	return util.ToPointer(buildTransactionRes(util.ToValue(transaction))), nil
 }
 
-// PayTransaction finalizes a transaction with cash payment
+// PayTransaction finalizes a transaction with one or more payments
 func (u *TransactionUsecase) PayTransaction(userID, businessID, transactionID string, req *contract.PayTransactionReq) (*contract.TransactionRes, error) {
-	transaction, err := u.getAndValidatePendingTransaction(transactionID, businessID)
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	// Lock the row so the same sale cannot be paid twice
+	transaction, err := u.transactionRepo.GetTransactionForUpdate(tx, transactionID, businessID)
 	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if err := validatePendingTransaction(transaction); err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	// Validate amount received
-	if req.ReceivedAmount < transaction.TotalAmount {
-		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient payment. Required: %.2f, Received: %.2f", transaction.TotalAmount, req.ReceivedAmount))
+	rounding, err := u.getCashRounding(businessID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
-	// Update transaction
-	now := time.Now()
-	transaction.ReceivedAmount = req.ReceivedAmount
-	transaction.ChangeAmount = req.ReceivedAmount - transaction.TotalAmount
-	transaction.Status = config.TRANSACTION_STATUS_PAID
-	transaction.PaidAt = &now
+	if err := applyPayments(transaction, req.Payments, req.ReceivedAmount, rounding); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.applyCredit(tx, businessID, transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-	if err := u.transactionRepo.UpdateTransaction(transaction); err != nil {
+	if err := tx.Omit(clause.Associations).Save(transaction).Error; err != nil {
+		tx.Rollback()
 		logger.Log.Error("Failed to update transaction", zap.Error(err))
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to finalize payment")
 	}
 
+	if err := createPayments(tx, transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.loyaltyUsecase.ApplyPaidTransaction(tx, transaction, &userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.giftCardUsecase.ApplyPaidTransaction(tx, transaction, &userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.ingredientUsecase.DeductStock(tx, transaction.Items); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to finalize payment")
+	}
+
 	return util.ToPointer(buildTransactionRes(util.ToValue(transaction))), nil
 }
 
+// CancelTransaction voids a pending or paid transaction and restores its stock
+func (u *TransactionUsecase) CancelTransaction(userID, businessID, transactionID string, req *contract.CancelTransactionReq) (*contract.TransactionRes, error) {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer handlePanic(tx)
+
+	transaction, err := u.transactionRepo.GetTransactionForUpdate(tx, transactionID, businessID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to get transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
+	}
+
+	if transaction == nil {
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+	}
+
+	var approvedBy *string
+	switch transaction.Status {
+	case config.TRANSACTION_STATUS_PENDING:
+	case config.TRANSACTION_STATUS_PAID:
+		// Returned items were already handled by their refunds, voiding would restock them twice
+		if len(transaction.Refunds) > 0 {
+			tx.Rollback()
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Transaction has refunds. Refund the remaining items instead")
+		}
+
+		// Money the customer already repaid on a credit sale would be kept without being given back
+		repayments, err := u.transactionRepo.CountCreditRepayments(tx, transaction.ID)
+		if err != nil {
+			tx.Rollback()
+			logger.Log.Error("Failed to count credit repayments", zap.Error(err), zap.String("transactionID", transaction.ID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to cancel transaction")
+		}
+		if repayments > 0 {
+			tx.Rollback()
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Transaction has credit repayments. Refund the items instead")
+		}
+
+		// Voiding a completed sale needs a manager or owner to sign off
+		approverID := userID
+		if req.ApproverID != nil {
+			approverID = *req.ApproverID
+		}
+
+		approver, err := u.verifyApproverPin(businessID, approverID, util.ToValue(req.ApproverPin))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		approvedBy = &approver.ID
+	default:
+		tx.Rollback()
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Only pending or paid transactions can be cancelled")
+	}
+
+	// Restore stock from items
+	if err := u.restoreStock(tx, transaction, transaction.Items, &userID, "Cancelled: "+req.Reason); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Ingredients are only deducted once a sale is paid
+	if transaction.Status == config.TRANSACTION_STATUS_PAID {
+		if err := u.ingredientUsecase.RestoreStock(tx, transaction.Items, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Points earned on the sale are taken back and points it was paid with are given back
+	if err := u.loyaltyUsecase.ReverseCancel(tx, transaction, userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Gift card balances spent on the sale go back on the cards and the voucher can be used again
+	if err := u.giftCardUsecase.RestoreCancel(tx, transaction, userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.voucherUsecase.Release(tx, transactionID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Only the cancel columns are written, the loaded items and refunds must not be saved again.
+	// A voided credit sale is no longer owed.
+	err = tx.Model(&model.Transaction{}).
+		Where("id = ?", transaction.ID).
+		Updates(map[string]any{
+			"status":             config.TRANSACTION_STATUS_CANCELLED,
+			"outstanding_amount": 0,
+			"cancelled_at":       time.Now(),
+			"cancelled_by":       userID,
+			"cancel_approved_by": approvedBy,
+			"cancel_reason":      req.Reason,
+			"updated_at":         time.Now(),
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error("Failed to cancel transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to cancel transaction")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to cancel transaction")
+	}
+
+	return u.GetTransaction(transactionID)
+}
+
 // GetTransaction gets a transaction by ID
 func (u *TransactionUsecase) GetTransaction(transactionID string) (*contract.TransactionRes, error) {
 	transaction, err := u.transactionRepo.GetTransactionByID(transactionID)
@@ -264,7 +660,18 @@ func (u *TransactionUsecase) GetTransaction(transactionID string) (*contract.Tra
 		return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
 	}
 
-	return util.ToPointer(buildTransactionRes(util.ToValue(transaction))), nil
+	res := buildTransactionRes(util.ToValue(transaction))
+
+	// Shareable link to the receipt customers can open without logging in
+	receiptURL, expiresAt, err := generateReceiptURL(transaction.ID)
+	if err != nil {
+		logger.Log.Error("Failed to generate receipt link", zap.Error(err), zap.String("transactionID", transactionID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to generate receipt link")
+	}
+	res.ReceiptURL = &receiptURL
+	res.ReceiptURLExpiresAt = util.ToPointer(expiresAt.Format(time.RFC3339))
+
+	return &res, nil
 }
 
 // ListTransactions lists transactions with pagination
@@ -283,9 +690,84 @@ func (u *TransactionUsecase) ListTransactions(businessID string, page, pageSize
 	return results, total, nil
 }
 
-// IsAllowedToAccess checks if user has permission to access transactions
-func (u *TransactionUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, transactionID *string) error {
-	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+// GetTransactionQris generates a dynamic QRIS with the total amount of a pending transaction
+func (u *TransactionUsecase) GetTransactionQris(businessID, transactionID string) (*contract.QrisRes, error) {
+	transaction, err := u.getAndValidatePendingTransaction(transactionID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get business", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	payload, err := qris.GenerateDynamic(buildQrisMerchant(*business), transaction.TotalAmount, transaction.InvoiceNumber)
+	if err != nil {
+		return nil, qrisError(err)
+	}
+
+	res, err := buildQrisRes(payload)
+	if err != nil {
+		return nil, err
+	}
+	res.Amount = &transaction.TotalAmount
+	res.ExpiredAt = util.ToPointer(transaction.ExpiredAt.Format(time.RFC3339))
+
+	return res, nil
+}
+
+// ExpireStaleTransactions expires one batch of pending transactions past their expiry and returns their stock.
+// It returns the number of transactions expired in this batch.
+func (u *TransactionUsecase) ExpireStaleTransactions(batchSize int) (int, error) {
+	tx := u.db.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	defer handlePanic(tx)
+
+	transactions, err := u.transactionRepo.LockExpiredPendingTransactions(tx, time.Now(), batchSize)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if len(transactions) == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	ids := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+
+		if err := u.restoreStock(tx, &transaction, transaction.Items, nil, "Expired"); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if err := u.voucherUsecase.Release(tx, transaction.ID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := u.transactionRepo.UpdateTransactionsStatus(tx, ids, config.TRANSACTION_STATUS_EXPIRED); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return len(transactions), nil
+}
+
+// IsAllowedToAccess checks if user has permission to access transactions
+func (u *TransactionUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, transactionID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
 
 	if !allowed || permission == nil {
 		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
@@ -321,19 +803,23 @@ func handlePanic(tx *gorm.DB) {
 	}
 }
 
-// fetchAndValidateProducts fetches products and validates them
-func (u *TransactionUsecase) fetchAndValidateProducts(items []contract.TransactionItemReq, businessID string) (map[string]*model.Product, error) {
-	// Get product IDs
+// fetchAndValidateProducts fetches the products and variants of the cart and validates them
+func (u *TransactionUsecase) fetchAndValidateProducts(items []contract.TransactionItemReq, businessID string) (map[string]*model.Product, map[string]*model.ProductVariant, error) {
+	// Get product and variant IDs
 	productIDs := make([]string, len(items))
+	var variantIDs []string
 	for i, item := range items {
 		productIDs[i] = item.ProductID
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
 	}
 
 	// Fetch products
 	products, err := u.productRepo.GetProductsByIDs(productIDs)
 	if err != nil {
 		logger.Log.Error("Failed to fetch products", zap.Error(err))
-		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
 	}
 
 	// Create product map
@@ -342,66 +828,600 @@ func (u *TransactionUsecase) fetchAndValidateProducts(items []contract.Transacti
 		productMap[p.ID] = p
 	}
 
+	variantMap := make(map[string]*model.ProductVariant)
+	if len(variantIDs) > 0 {
+		variants, err := u.productRepo.GetVariantsByIDs(variantIDs)
+		if err != nil {
+			logger.Log.Error("Failed to fetch product variants", zap.Error(err))
+			return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch products")
+		}
+		for _, v := range variants {
+			variantMap[v.ID] = v
+		}
+	}
+
 	// Validate products
 	for _, item := range items {
 		product, exists := productMap[item.ProductID]
 		if !exists {
-			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s not found", item.ProductID))
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s not found", item.ProductID))
 		}
 		if !product.IsActive {
-			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s is not active", product.Name))
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s is not active", product.Name))
 		}
 		if product.BusinessID != businessID {
-			return nil, fiber.NewError(fiber.StatusForbidden, "You don't have permission to sell this product")
+			return nil, nil, fiber.NewError(fiber.StatusForbidden, "You don't have permission to sell this product")
+		}
+
+		// Products with variants are sold, priced and stocked per variant
+		if product.HasVariants != (item.VariantID != nil) {
+			if product.HasVariants {
+				return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Choose a variant of %s", product.Name))
+			}
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Product %s has no variants", product.Name))
+		}
+
+		if item.VariantID != nil {
+			variant, exists := variantMap[*item.VariantID]
+			if !exists || variant.ProductID != product.ID {
+				return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Variant %s of %s not found", *item.VariantID, product.Name))
+			}
+			if !variant.IsActive {
+				return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Variant %s of %s is not active", variant.Label, product.Name))
+			}
+			if product.EnableStock && variant.StockQty != nil && *variant.StockQty < item.Quantity {
+				return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient stock for product %s %s. Available: %d, Requested: %d", product.Name, variant.Label, *variant.StockQty, item.Quantity))
+			}
+			continue
 		}
 
 		// Check stock if enabled
 		if product.EnableStock && product.StockQty != nil && util.ToValue(product.StockQty) < item.Quantity {
-			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient stock for product %s. Available: %d, Requested: %d", product.Name, *product.StockQty, item.Quantity))
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient stock for product %s. Available: %d, Requested: %d", product.Name, *product.StockQty, item.Quantity))
 		}
 	}
 
-	return productMap, nil
+	return productMap, variantMap, nil
+}
+
+// transactionAmounts are the order totals worked out from the cart
+type transactionAmounts struct {
+	Gross         float64
+	OrderDiscount float64
+	Discount      float64
+	Net           float64
+	// Part of the discount given by promotions
+	Promotion float64
+	// Part of the discount given by the voucher
+	Voucher float64
+	// Exclusive taxes and service charges added to the net amount, and taxes already inside it
+	Tax          float64
+	InclusiveTax float64
+	Taxes        []model.TransactionTax
+	// Largest manual discount given on a single line, in percent of its amount after promotions
+	MaxDiscountPercent float64
 }
 
-// buildTransactionItems creates transaction items and calculates total
-func (u *TransactionUsecase) buildTransactionItems(items []contract.TransactionItemReq, productMap map[string]*model.Product, transactionID string) (float64, []*model.TransactionItem) {
-	var totalAmount float64
+// buildTransactionItems creates transaction items with their modifiers, promotions and discounts and calculates the totals.
+// Modifier prices are added to the unit price. Line discounts are taken off the amount after promotions, then the order discount and the voucher
+// are spread over the lines in proportion to their amount after line discounts.
+func (u *TransactionUsecase) buildTransactionItems(items []contract.TransactionItemReq, productMap map[string]*model.Product, variantMap map[string]*model.ProductVariant, lineModifiers [][]model.TransactionItemModifier, promotions []model.Promotion, orderDiscount *contract.DiscountReq, voucher *model.Voucher, transactionID string) (transactionAmounts, []*model.TransactionItem, error) {
+	var amounts transactionAmounts
+	var afterLineDiscounts float64
 	transactionItems := make([]*model.TransactionItem, len(items))
 
 	for i, item := range items {
 		product := productMap[item.ProductID]
-		subtotal := product.Price * float64(item.Quantity)
-		totalAmount += subtotal
+		price := product.Price
+
+		// A variant has its own price, its label is kept next to the product name
+		var variantLabel *string
+		if item.VariantID != nil {
+			variant := variantMap[*item.VariantID]
+			price = variant.Price
+			variantLabel = &variant.Label
+		}
+		for _, modifier := range lineModifiers[i] {
+			price += modifier.Price
+		}
+		price = roundMoney(price)
+		gross := roundMoney(price * float64(item.Quantity))
+
+		// The recipe is copied so a later change does not alter what a cancel or refund puts back
+		ingredients := make([]model.TransactionItemIngredient, len(product.RecipeItems))
+		for j, recipeItem := range product.RecipeItems {
+			ingredients[j] = model.TransactionItemIngredient{
+				IngredientID: recipeItem.IngredientID,
+				Name:         recipeItem.Ingredient.Name,
+				Unit:         recipeItem.Ingredient.Unit,
+				Quantity:     recipeItem.Quantity,
+			}
+		}
 
 		transactionItems[i] = &model.TransactionItem{
 			TransactionID: transactionID,
 			ProductID:     &item.ProductID,
 			ProductName:   product.Name,
-			Price:         product.Price,
+			VariantID:     item.VariantID,
+			VariantLabel:  variantLabel,
+			Modifiers:     lineModifiers[i],
+			Ingredients:   ingredients,
+			Price:         price,
 			Quantity:      item.Quantity,
-			Subtotal:      subtotal,
+			GrossAmount:   gross,
+		}
+		amounts.Gross += gross
+	}
+
+	calculatePromotions(promotions, transactionItems, productMap)
+
+	for i, item := range items {
+		transactionItem := transactionItems[i]
+		base := roundMoney(transactionItem.GrossAmount - transactionItem.PromotionAmount)
+
+		lineDiscount, err := calculateDiscount(item.Discount, base)
+		if err != nil {
+			return amounts, nil, err
+		}
+
+		transactionItem.DiscountAmount = roundMoney(transactionItem.PromotionAmount + lineDiscount)
+		if item.Discount != nil {
+			transactionItem.DiscountType = util.ToPointer(config.DiscountType(item.Discount.Type))
+			transactionItem.DiscountValue = &item.Discount.Value
+			transactionItem.DiscountReason = &item.Discount.Reason
+		}
+
+		amounts.Promotion += transactionItem.PromotionAmount
+		afterLineDiscounts += base - lineDiscount
+	}
+
+	orderDiscountAmount, err := calculateDiscount(orderDiscount, afterLineDiscounts)
+	if err != nil {
+		return amounts, nil, err
+	}
+
+	// The voucher comes off what is left after the order discount
+	var voucherAmount float64
+	if voucher != nil {
+		afterOrderDiscount := roundMoney(afterLineDiscounts - orderDiscountAmount)
+		if afterOrderDiscount < voucher.MinSpend {
+			return amounts, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Voucher needs a minimum spend of %.2f", voucher.MinSpend))
+		}
+		voucherAmount = roundMoney(voucher.DiscountOn(afterOrderDiscount))
+	}
+
+	// The last line with an amount takes the rounding remainder
+	remaining := orderDiscountAmount
+	remainingVoucher := voucherAmount
+	last := -1
+	for i, item := range transactionItems {
+		if item.GrossAmount-item.DiscountAmount > 0 {
+			last = i
+		}
+	}
+	for i, item := range transactionItems {
+		base := item.GrossAmount - item.DiscountAmount
+		share, voucherShare := 0.0, 0.0
+		if i == last {
+			share = roundMoney(remaining)
+			voucherShare = roundMoney(remainingVoucher)
+		} else if afterLineDiscounts > 0 {
+			share = roundMoney(orderDiscountAmount * base / afterLineDiscounts)
+			voucherShare = roundMoney(voucherAmount * base / afterLineDiscounts)
+		}
+		remaining -= share
+		remainingVoucher -= voucherShare
+
+		item.OrderDiscountAmount = share
+		item.VoucherAmount = voucherShare
+		item.DiscountAmount = roundMoney(item.DiscountAmount + share + voucherShare)
+		item.Subtotal = roundMoney(item.GrossAmount - item.DiscountAmount)
+
+		amounts.Discount += item.DiscountAmount
+		amounts.Net += item.Subtotal
+
+		// Promotions and vouchers are set by the owner, so only what the cashier took off counts against their limit
+		if afterPromotions := item.GrossAmount - item.PromotionAmount; afterPromotions > 0 {
+			manualDiscount := item.DiscountAmount - item.PromotionAmount - item.VoucherAmount
+			amounts.MaxDiscountPercent = max(amounts.MaxDiscountPercent, roundMoney(manualDiscount/afterPromotions*100))
+		}
+	}
+
+	amounts.Gross = roundMoney(amounts.Gross)
+	amounts.OrderDiscount = orderDiscountAmount
+	amounts.Discount = roundMoney(amounts.Discount)
+	amounts.Promotion = roundMoney(amounts.Promotion)
+	amounts.Voucher = voucherAmount
+	amounts.Net = roundMoney(amounts.Net)
+
+	return amounts, transactionItems, nil
+}
+
+// calculateDiscount returns the discount amount on base, nil means no discount
+func calculateDiscount(discount *contract.DiscountReq, base float64) (float64, error) {
+	if discount == nil {
+		return 0, nil
+	}
+
+	switch config.DiscountType(discount.Type) {
+	case config.DISCOUNT_TYPE_PERCENTAGE:
+		if discount.Value > 100 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "Percentage discount cannot exceed 100%")
+		}
+		return roundMoney(base * discount.Value / 100), nil
+	default:
+		if discount.Value > base {
+			return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Discount of %.2f exceeds the amount of %.2f", discount.Value, base))
+		}
+		return roundMoney(discount.Value), nil
+	}
+}
+
+// getRunningPromotions returns the promotions of the business that run at the time, in calculation order
+func (u *TransactionUsecase) getRunningPromotions(businessID string, now time.Time) ([]model.Promotion, error) {
+	promotions, err := u.promotionRepo.ListActivePromotions(businessID, now)
+	if err != nil {
+		logger.Log.Error("Failed to get promotions", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to calculate promotions")
+	}
+
+	running := make([]model.Promotion, 0, len(promotions))
+	for _, promotion := range promotions {
+		if promotion.IsRunningAt(now) {
+			running = append(running, promotion)
 		}
 	}
 
-	return totalAmount, transactionItems
+	return running, nil
+}
+
+// promotionUnit is one unit of a line taking part in a quantity based promotion
+type promotionUnit struct {
+	line  int
+	price float64
 }
 
-// updateStock updates product stock quantities
-func (u *TransactionUsecase) updateStock(tx *gorm.DB, items []contract.TransactionItemReq, productMap map[string]*model.Product, increase bool) error {
+// calculatePromotions applies the promotions in order on the gross amount of the lines.
+// Each promotion works on what earlier ones left of a line and needs the cart gross to reach its minimum spend.
+// Exclusive promotions skip lines another promotion already took and keep later promotions off the lines they take.
+// Buy X get Y and bundles group the eligible units from the most expensive down, so the cheapest unit
+// of each group is the free one; amount promotions are taken once off the eligible lines.
+func calculatePromotions(promotions []model.Promotion, items []*model.TransactionItem, productMap map[string]*model.Product) {
+	var cartGross float64
+	for _, item := range items {
+		cartGross += item.GrossAmount
+	}
+
+	exclusive := make([]bool, len(items))
+	for p := range promotions {
+		promotion := &promotions[p]
+		if cartGross < promotion.MinSpend {
+			continue
+		}
+
+		eligible := make([]int, 0, len(items))
+		for i, item := range items {
+			product := productMap[util.ToValue(item.ProductID)]
+			if product == nil || exclusive[i] || item.GrossAmount-item.PromotionAmount <= 0 || !promotion.AppliesTo(*product) {
+				continue
+			}
+			if promotion.Stacking == config.PROMOTION_STACKING_EXCLUSIVE && len(item.Promotions) > 0 {
+				continue
+			}
+			eligible = append(eligible, i)
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		discounts := make(map[int]float64, len(eligible))
+		quantities := make(map[int]int, len(eligible))
+
+		switch promotion.Type {
+		case config.PROMOTION_TYPE_PERCENTAGE:
+			for _, i := range eligible {
+				discounts[i] = (items[i].GrossAmount - items[i].PromotionAmount) * promotion.Value / 100
+				quantities[i] = items[i].Quantity
+			}
+
+		case config.PROMOTION_TYPE_AMOUNT:
+			var base float64
+			for _, i := range eligible {
+				base += items[i].GrossAmount - items[i].PromotionAmount
+			}
+
+			// The last line takes the rounding remainder
+			amount := roundMoney(min(promotion.Value, base))
+			remaining := amount
+			for n, i := range eligible {
+				share := roundMoney(remaining)
+				if n < len(eligible)-1 {
+					share = roundMoney(amount * (items[i].GrossAmount - items[i].PromotionAmount) / base)
+				}
+				remaining -= share
+				discounts[i] = share
+				quantities[i] = items[i].Quantity
+			}
+
+		case config.PROMOTION_TYPE_BUY_X_GET_Y, config.PROMOTION_TYPE_BUNDLE_PRICE:
+			units := make([]promotionUnit, 0)
+			for _, i := range eligible {
+				price := (items[i].GrossAmount - items[i].PromotionAmount) / float64(items[i].Quantity)
+				for range items[i].Quantity {
+					units = append(units, promotionUnit{line: i, price: price})
+				}
+			}
+			sort.SliceStable(units, func(a, b int) bool { return units[a].price > units[b].price })
+
+			buy := util.ToValue(promotion.BuyQuantity)
+			groupSize := buy
+			if promotion.Type == config.PROMOTION_TYPE_BUY_X_GET_Y {
+				groupSize += util.ToValue(promotion.GetQuantity)
+			}
+			if groupSize <= 0 {
+				continue
+			}
+
+			for start := 0; start+groupSize <= len(units); start += groupSize {
+				group := units[start : start+groupSize]
+
+				if promotion.Type == config.PROMOTION_TYPE_BUY_X_GET_Y {
+					for _, unit := range group[buy:] {
+						discounts[unit.line] += unit.price * promotion.Value / 100
+						quantities[unit.line]++
+					}
+					continue
+				}
+
+				// The bundle saving is shared by the units in proportion to their price
+				var total float64
+				for _, unit := range group {
+					total += unit.price
+				}
+				saving := total - promotion.Value
+				if saving <= 0 {
+					continue
+				}
+				for _, unit := range group {
+					discounts[unit.line] += saving * unit.price / total
+					quantities[unit.line]++
+				}
+			}
+		}
+
+		for _, i := range eligible {
+			item := items[i]
+			amount := min(roundMoney(discounts[i]), roundMoney(item.GrossAmount-item.PromotionAmount))
+			if amount <= 0 {
+				continue
+			}
+
+			item.PromotionAmount = roundMoney(item.PromotionAmount + amount)
+			item.Promotions = append(item.Promotions, model.TransactionItemPromotion{
+				TransactionID: item.TransactionID,
+				PromotionID:   &promotion.ID,
+				Name:          promotion.Name,
+				Type:          promotion.Type,
+				Quantity:      quantities[i],
+				Amount:        amount,
+			})
+			if promotion.Stacking == config.PROMOTION_STACKING_EXCLUSIVE {
+				exclusive[i] = true
+			}
+		}
+	}
+}
+
+// applyTaxes works out the tax lines of the cart with the active rules of the business
+func (u *TransactionUsecase) applyTaxes(businessID string, items []*model.TransactionItem, productMap map[string]*model.Product, amounts *transactionAmounts) error {
+	rules, err := u.taxRuleRepo.ListActiveTaxRules(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get tax rules", zap.Error(err), zap.String("businessID", businessID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to calculate taxes")
+	}
+
+	calculateTaxes(rules, items, productMap, amounts)
+	return nil
+}
+
+// calculateTaxes applies the rules line by line on the subtotal after discounts.
+// Inclusive rules are taken out of the subtotal, which already contains them.
+// Exclusive service charges are charged on the price without inclusive taxes,
+// exclusive taxes on that price plus the service charge, as PB1 is charged on restaurant bills.
+func calculateTaxes(rules []model.TaxRule, items []*model.TransactionItem, productMap map[string]*model.Product, amounts *transactionAmounts) {
+	taxes := make([]model.TransactionTax, len(rules))
+	for i, rule := range rules {
+		taxes[i] = model.TransactionTax{
+			TaxRuleID:   &rules[i].ID,
+			Name:        rule.Name,
+			Kind:        rule.Kind,
+			Rate:        rule.Rate,
+			IsInclusive: rule.IsInclusive,
+		}
+	}
+
+	for _, item := range items {
+		product := productMap[util.ToValue(item.ProductID)]
+		if product == nil {
+			continue
+		}
+
+		applicable := make([]int, 0, len(rules))
+		var inclusiveRate float64
+		for i, rule := range rules {
+			if !rule.AppliesTo(*product) {
+				continue
+			}
+			applicable = append(applicable, i)
+			if rule.IsInclusive {
+				inclusiveRate += rule.Rate
+			}
+		}
+
+		base := roundMoney(item.Subtotal * 100 / (100 + inclusiveRate))
+		var serviceCharge float64
+		for _, i := range applicable {
+			rule := rules[i]
+			if !rule.IsInclusive && rule.Kind == config.TAX_KIND_TAX {
+				continue
+			}
+
+			amount := roundMoney(base * rule.Rate / 100)
+			taxes[i].TaxableAmount += base
+			taxes[i].Amount += amount
+			if rule.IsInclusive {
+				amounts.InclusiveTax += amount
+				continue
+			}
+			serviceCharge += amount
+			item.TaxAmount += amount
+		}
+
+		for _, i := range applicable {
+			rule := rules[i]
+			if rule.IsInclusive || rule.Kind != config.TAX_KIND_TAX {
+				continue
+			}
+
+			taxable := base + serviceCharge
+			amount := roundMoney(taxable * rule.Rate / 100)
+			taxes[i].TaxableAmount += taxable
+			taxes[i].Amount += amount
+			item.TaxAmount += amount
+		}
+
+		item.TaxAmount = roundMoney(item.TaxAmount)
+		amounts.Tax += item.TaxAmount
+	}
+
+	amounts.Tax = roundMoney(amounts.Tax)
+	amounts.InclusiveTax = roundMoney(amounts.InclusiveTax)
+	amounts.Taxes = make([]model.TransactionTax, 0, len(taxes))
+	for _, tax := range taxes {
+		if tax.TaxableAmount <= 0 {
+			continue
+		}
+		tax.TaxableAmount = roundMoney(tax.TaxableAmount)
+		tax.Amount = roundMoney(tax.Amount)
+		amounts.Taxes = append(amounts.Taxes, tax)
+	}
+}
+
+// applyTransactionAmounts copies the cart totals, order discount and voucher onto the transaction
+func applyTransactionAmounts(transaction *model.Transaction, amounts transactionAmounts, discount *contract.DiscountReq, voucher *model.Voucher, approvedBy *string) {
+	transaction.GrossAmount = amounts.Gross
+	transaction.OrderDiscountAmount = amounts.OrderDiscount
+	transaction.DiscountAmount = amounts.Discount
+	transaction.NetAmount = amounts.Net
+	transaction.PromotionAmount = amounts.Promotion
+	transaction.TaxAmount = amounts.Tax
+	transaction.InclusiveTaxAmount = amounts.InclusiveTax
+	transaction.Taxes = amounts.Taxes
+	transaction.TotalAmount = roundMoney(amounts.Net + amounts.Tax)
+	transaction.DiscountApprovedBy = approvedBy
+
+	transaction.DiscountType = nil
+	transaction.DiscountValue = nil
+	transaction.DiscountReason = nil
+	if discount != nil {
+		transaction.DiscountType = util.ToPointer(config.DiscountType(discount.Type))
+		transaction.DiscountValue = &discount.Value
+		transaction.DiscountReason = &discount.Reason
+	}
+
+	transaction.VoucherID = nil
+	transaction.VoucherCode = nil
+	transaction.VoucherAmount = amounts.Voucher
+	if voucher != nil {
+		transaction.VoucherID = &voucher.ID
+		transaction.VoucherCode = &voucher.Code
+	}
+}
+
+// authorizeDiscount checks the largest line discount against the limit of the user's role.
+// Going over it needs the PIN of a manager or owner whose own limit covers the discount.
+func (u *TransactionUsecase) authorizeDiscount(userID, businessID string, discountPercent float64, approverID, approverPin *string) (*string, error) {
+	if discountPercent <= 0 {
+		return nil, nil
+	}
+
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get business", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil || user == nil {
+		logger.Log.Error("Failed to get user", zap.Error(err), zap.String("userID", userID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get user")
+	}
+
+	limit := maxDiscountPercent(*business, user.Role)
+	if discountPercent <= limit {
+		return nil, nil
+	}
+
+	if approverPin == nil {
+		return nil, fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("Discount of %.2f%% exceeds your limit of %.2f%%. A manager or owner PIN is required", discountPercent, limit))
+	}
+
+	id := userID
+	if approverID != nil {
+		id = *approverID
+	}
+
+	approver, err := u.verifyApproverPin(businessID, id, *approverPin)
+	if err != nil {
+		return nil, err
+	}
+
+	if approverLimit := maxDiscountPercent(*business, approver.Role); discountPercent > approverLimit {
+		return nil, fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("Discount of %.2f%% exceeds the approver's limit of %.2f%%", discountPercent, approverLimit))
+	}
+
+	return &approver.ID, nil
+}
+
+// maxDiscountPercent returns the largest discount a role may give on its own
+func maxDiscountPercent(business model.Business, role config.UserRole) float64 {
+	switch role {
+	case config.USER_ROLE_CASHIER:
+		return business.CashierMaxDiscountPercent
+	case config.USER_ROLE_MANAGER:
+		return business.ManagerMaxDiscountPercent
+	default:
+		return 100
+	}
+}
+
+// roundMoney rounds to whole cents
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// updateStock updates product stock quantities, each line is recorded as a sale movement of the transaction
+func (u *TransactionUsecase) updateStock(tx *gorm.DB, transaction *model.Transaction, userID string, items []contract.TransactionItemReq, productMap map[string]*model.Product, increase bool) error {
 	for _, item := range items {
 		product := productMap[item.ProductID]
 		if !product.EnableStock {
 			continue
 		}
 
-		var err error
+		quantity := -item.Quantity
 		if increase {
-			err = u.productRepo.IncreaseStock(tx, item.ProductID, item.Quantity)
-		} else {
-			err = u.productRepo.DecreaseStock(tx, item.ProductID, item.Quantity)
+			quantity = item.Quantity
 		}
 
+		err := u.productRepo.MoveStock(tx, &model.StockMovement{
+			BusinessID:    transaction.BusinessID,
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			Type:          config.STOCK_MOVEMENT_TYPE_SALE,
+			Quantity:      quantity,
+			TransactionID: &transaction.ID,
+			CreatedBy:     &userID,
+		})
 		if err != nil {
 			logger.Log.Error("Failed to update stock", zap.Error(err), zap.String("productID", item.ProductID))
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to update stock")
@@ -410,9 +1430,14 @@ func (u *TransactionUsecase) updateStock(tx *gorm.DB, items []contract.Transacti
 	return nil
 }
 
-// restoreStock restores stock from transaction items
-func (u *TransactionUsecase) restoreStock(tx *gorm.DB, items []model.TransactionItem) error {
+// restoreStock restores stock from transaction items. The sale movements are reversed with the reason given,
+// userID is nil when the system does it.
+func (u *TransactionUsecase) restoreStock(tx *gorm.DB, transaction *model.Transaction, items []model.TransactionItem, userID *string, reason string) error {
 	for _, item := range items {
+		if err := u.modifierUsecase.RestoreStock(tx, item.Modifiers, item.Quantity); err != nil {
+			return err
+		}
+
 		if item.ProductID == nil {
 			continue
 		}
@@ -423,7 +1448,17 @@ func (u *TransactionUsecase) restoreStock(tx *gorm.DB, items []model.Transaction
 			continue
 		}
 
-		if err := u.productRepo.IncreaseStock(tx, *item.ProductID, item.Quantity); err != nil {
+		err = u.productRepo.MoveStock(tx, &model.StockMovement{
+			BusinessID:    transaction.BusinessID,
+			ProductID:     *item.ProductID,
+			VariantID:     item.VariantID,
+			Type:          config.STOCK_MOVEMENT_TYPE_SALE,
+			Quantity:      item.Quantity,
+			TransactionID: &transaction.ID,
+			Reason:        &reason,
+			CreatedBy:     userID,
+		})
+		if err != nil {
 			logger.Log.Error("Failed to restore stock", zap.Error(err))
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to restore stock")
 		}
@@ -431,6 +1466,74 @@ func (u *TransactionUsecase) restoreStock(tx *gorm.DB, items []model.Transaction
 	return nil
 }
 
+// verifyApproverPin checks that the approver is an active manager or owner of the business with a matching PIN.
+// Too many wrong PINs in a row lock the approver's PIN for a while.
+func (u *TransactionUsecase) verifyApproverPin(businessID, approverID, pin string) (*model.User, error) {
+	if pin == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Manager or owner PIN is required")
+	}
+
+	approver, err := u.userRepo.GetUserByIDAndBusinessID(approverID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get approver", zap.Error(err), zap.String("approverID", approverID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to verify approver")
+	}
+
+	if approver == nil || !approver.IsActive {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Invalid approver or PIN")
+	}
+
+	if approver.Role != config.USER_ROLE_MANAGER && approver.Role != config.USER_ROLE_OWNER {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Approver must be a manager or owner")
+	}
+
+	now := time.Now()
+	if approver.PinLockedUntil != nil && approver.PinLockedUntil.After(now) {
+		logger.Log.Warn("Approver PIN is locked", zap.String("approverID", approverID), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusTooManyRequests, fmt.Sprintf("Too many wrong PINs, try again after %s", approver.PinLockedUntil.Format("15:04")))
+	}
+
+	if approver.PinHash == nil || !util.ComparePasswordHash(pin, *approver.PinHash) {
+		lockedUntil, err := u.userRepo.RecordFailedPin(approverID, config.APPROVER_PIN_MAX_ATTEMPTS, now.Add(config.APPROVER_PIN_LOCK_DURATION))
+		if err != nil {
+			logger.Log.Error("Failed to record wrong PIN", zap.Error(err), zap.String("approverID", approverID))
+		}
+
+		logger.Log.Warn("Wrong approver PIN", zap.String("approverID", approverID), zap.String("businessID", businessID), zap.Bool("locked", lockedUntil != nil))
+		if lockedUntil != nil {
+			return nil, fiber.NewError(fiber.StatusTooManyRequests, fmt.Sprintf("Too many wrong PINs, try again after %s", lockedUntil.Format("15:04")))
+		}
+		return nil, fiber.NewError(fiber.StatusForbidden, "Invalid approver or PIN")
+	}
+
+	if approver.PinFailedAttempts > 0 {
+		if err := u.userRepo.ResetFailedPins(approverID); err != nil {
+			logger.Log.Error("Failed to reset wrong PIN count", zap.Error(err), zap.String("approverID", approverID))
+		}
+	}
+
+	return approver, nil
+}
+
+// getCustomer returns the customer picked for a transaction, nil when none is picked
+func (u *TransactionUsecase) getCustomer(businessID string, customerID *string) (*model.Customer, error) {
+	if customerID == nil {
+		return nil, nil
+	}
+
+	customer, err := u.customerRepo.GetCustomerByIDAndBusinessID(*customerID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *customerID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+
+	if customer == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Customer not found")
+	}
+
+	return customer, nil
+}
+
 // getAndValidatePendingTransaction retrieves and validates a pending transaction
 func (u *TransactionUsecase) getAndValidatePendingTransaction(transactionID, businessID string) (*model.Transaction, error) {
 	transaction, err := u.transactionRepo.GetTransactionByIDAndBusinessID(transactionID, businessID)
@@ -439,19 +1542,213 @@ func (u *TransactionUsecase) getAndValidatePendingTransaction(transactionID, bus
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get transaction")
 	}
 
+	if err := validatePendingTransaction(transaction); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// validatePendingTransaction checks that the transaction exists, is pending and has not expired
+func validatePendingTransaction(transaction *model.Transaction) error {
 	if transaction == nil {
-		return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+		return fiber.NewError(fiber.StatusNotFound, "Transaction not found")
 	}
 
 	if transaction.Status != config.TRANSACTION_STATUS_PENDING {
-		return nil, fiber.NewError(fiber.StatusBadRequest, "Can only modify pending transactions")
+		return fiber.NewError(fiber.StatusBadRequest, "Can only modify pending transactions")
 	}
 
 	if time.Now().After(transaction.ExpiredAt) {
-		return nil, fiber.NewError(fiber.StatusBadRequest, "Transaction has expired")
+		return fiber.NewError(fiber.StatusBadRequest, "Transaction has expired")
 	}
 
-	return transaction, nil
+	return nil
+}
+
+// applyPayments validates the payments against the total and marks the transaction as paid.
+// A lone receivedAmount is treated as a single cash payment for older clients.
+// Only the cash portion can be overpaid or rounded, change is given back from it.
+func applyPayments(transaction *model.Transaction, payments []contract.PaymentReq, receivedAmount *float64, rounding cashRounding) error {
+	if len(payments) == 0 {
+		if receivedAmount == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Received amount is required")
+		}
+		payments = []contract.PaymentReq{{Method: string(config.PAYMENT_METHOD_CASH), Amount: *receivedAmount}}
+	}
+
+	now := time.Now()
+	var cashAmount, nonCashAmount float64
+	transactionPayments := make([]model.TransactionPayment, 0, len(payments))
+	for _, payment := range payments {
+		if payment.Amount <= 0 {
+			continue
+		}
+
+		method := config.PaymentMethod(payment.Method)
+		if method == config.PAYMENT_METHOD_CASH {
+			cashAmount += payment.Amount
+		} else {
+			nonCashAmount += payment.Amount
+		}
+
+		transactionPayments = append(transactionPayments, model.TransactionPayment{
+			TransactionID: transaction.ID,
+			Method:        method,
+			Amount:        payment.Amount,
+			Reference:     payment.Reference,
+			PaidAt:        now,
+		})
+	}
+
+	if nonCashAmount > transaction.TotalAmount {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Non-cash payments exceed total amount. Total: %.2f, Non-cash: %.2f", transaction.TotalAmount, nonCashAmount))
+	}
+
+	// Non-cash payments are exact, only what is left for cash is rounded
+	var roundingAmount float64
+	if cashAmount > 0 {
+		cashDue := max(transaction.TotalAmount-nonCashAmount, 0)
+		roundingAmount = roundMoney(rounding.apply(cashDue) - cashDue)
+	}
+	amountDue := roundMoney(transaction.TotalAmount + roundingAmount)
+
+	receivedTotal := cashAmount + nonCashAmount
+	if receivedTotal < amountDue {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient payment. Required: %.2f, Received: %.2f", amountDue, receivedTotal))
+	}
+
+	transaction.Payments = transactionPayments
+	transaction.RoundingAmount = roundingAmount
+	transaction.ReceivedAmount = receivedTotal
+	transaction.ChangeAmount = roundMoney(receivedTotal - amountDue)
+	transaction.Status = config.TRANSACTION_STATUS_PAID
+	transaction.PaidAt = &now
+
+	return nil
+}
+
+// applyCredit puts the credit part of the payments on the customer's tab, as long as it stays within their credit limit
+func (u *TransactionUsecase) applyCredit(tx *gorm.DB, businessID string, transaction *model.Transaction) error {
+	var creditAmount float64
+	for _, payment := range transaction.Payments {
+		if payment.Method == config.PAYMENT_METHOD_CREDIT {
+			creditAmount += payment.Amount
+		}
+	}
+	transaction.CreditAmount = roundMoney(creditAmount)
+	transaction.OutstandingAmount = transaction.CreditAmount
+
+	if creditAmount == 0 {
+		return nil
+	}
+
+	if transaction.CustomerID == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Choose a customer to sell on credit")
+	}
+
+	// Lock the customer so two credit sales at once can't both slip under the limit
+	customer, err := u.customerRepo.GetCustomerForUpdate(tx, *transaction.CustomerID, businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get customer", zap.Error(err), zap.String("customerID", *transaction.CustomerID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get customer")
+	}
+	if customer == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Customer not found")
+	}
+
+	outstanding, err := u.customerRepo.GetOutstandingBalance(tx, customer.ID)
+	if err != nil {
+		logger.Log.Error("Failed to get outstanding balance", zap.Error(err), zap.String("customerID", customer.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get outstanding balance")
+	}
+
+	if roundMoney(outstanding+transaction.CreditAmount) > customer.CreditLimit {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Credit limit exceeded. Limit: %.2f, Outstanding: %.2f, Requested: %.2f", customer.CreditLimit, outstanding, transaction.CreditAmount))
+	}
+
+	return nil
+}
+
+// cashRounding is the business policy for rounding the cash part of a bill, the zero value does not round
+type cashRounding struct {
+	Mode config.CashRoundingMode
+	Unit float64
+}
+
+// newCashRounding reads the rounding policy of the business
+func newCashRounding(business model.Business) cashRounding {
+	return cashRounding{
+		Mode: business.CashRoundingMode,
+		Unit: float64(business.CashRoundingUnit),
+	}
+}
+
+// apply rounds the amount to a multiple of the unit
+func (r cashRounding) apply(amount float64) float64 {
+	if r.Unit <= 0 {
+		return amount
+	}
+
+	// Round to cents first so 1500.0000001 is not rounded up to the next unit
+	units := roundMoney(amount) / r.Unit
+	switch r.Mode {
+	case config.CASH_ROUNDING_MODE_NEAREST:
+		return math.Round(units) * r.Unit
+	case config.CASH_ROUNDING_MODE_UP:
+		return math.Ceil(units) * r.Unit
+	case config.CASH_ROUNDING_MODE_DOWN:
+		return math.Floor(units) * r.Unit
+	default:
+		return amount
+	}
+}
+
+// getCashRounding loads the cash rounding policy of the business
+func (u *TransactionUsecase) getCashRounding(businessID string) (cashRounding, error) {
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil || business == nil {
+		logger.Log.Error("Failed to get business", zap.Error(err), zap.String("businessID", businessID))
+		return cashRounding{}, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	return newCashRounding(*business), nil
+}
+
+// createPayments stores the payments set by applyPayments
+func createPayments(tx *gorm.DB, transaction *model.Transaction) error {
+	if len(transaction.Payments) == 0 {
+		return nil
+	}
+
+	for i := range transaction.Payments {
+		transaction.Payments[i].TransactionID = transaction.ID
+	}
+
+	if err := tx.Create(&transaction.Payments).Error; err != nil {
+		logger.Log.Error("Failed to create transaction payments", zap.Error(err))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record payments")
+	}
+
+	return nil
+}
+
+// createTaxes stores the tax lines set by applyTransactionAmounts
+func createTaxes(tx *gorm.DB, transaction *model.Transaction) error {
+	if len(transaction.Taxes) == 0 {
+		return nil
+	}
+
+	for i := range transaction.Taxes {
+		transaction.Taxes[i].TransactionID = transaction.ID
+	}
+
+	if err := tx.Create(&transaction.Taxes).Error; err != nil {
+		logger.Log.Error("Failed to create transaction taxes", zap.Error(err))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record taxes")
+	}
+
+	return nil
 }
 
 // convertToTransactionItems converts pointer slice to value slice
@@ -465,15 +1762,79 @@ func convertToTransactionItems(items []*model.TransactionItem) []model.Transacti
 
 // buildTransactionRes builds transaction response
 func buildTransactionRes(transaction model.Transaction) contract.TransactionRes {
+	// Refund history, only present when refunds were preloaded
+	var refundedAmount float64
+	refundedQuantities := make(map[string]int)
+	refunds := make([]contract.RefundRes, len(transaction.Refunds))
+	for i, refund := range transaction.Refunds {
+		refundedAmount += refund.Amount
+		for _, item := range refund.Items {
+			refundedQuantities[item.TransactionItemID] += item.Quantity
+		}
+		refunds[i] = buildRefundRes(refund)
+	}
+
+	payments := make([]contract.TransactionPaymentRes, len(transaction.Payments))
+	for i, payment := range transaction.Payments {
+		payments[i] = contract.TransactionPaymentRes{
+			ID:                payment.ID,
+			Method:            string(payment.Method),
+			Amount:            payment.Amount,
+			Reference:         payment.Reference,
+			PaidAt:            payment.PaidAt.Format(time.RFC3339),
+			IsCreditRepayment: payment.IsCreditRepayment,
+		}
+	}
+
+	taxes := make([]contract.TransactionTaxRes, len(transaction.Taxes))
+	for i, tax := range transaction.Taxes {
+		taxes[i] = contract.TransactionTaxRes{
+			ID:            tax.ID,
+			TaxRuleID:     tax.TaxRuleID,
+			Name:          tax.Name,
+			Kind:          string(tax.Kind),
+			Rate:          tax.Rate,
+			IsInclusive:   tax.IsInclusive,
+			TaxableAmount: tax.TaxableAmount,
+			Amount:        tax.Amount,
+		}
+	}
+
 	items := make([]contract.TransactionItemRes, len(transaction.Items))
 	for i, item := range transaction.Items {
+		promotions := make([]contract.TransactionItemPromotionRes, len(item.Promotions))
+		for j, promotion := range item.Promotions {
+			promotions[j] = contract.TransactionItemPromotionRes{
+				ID:          promotion.ID,
+				PromotionID: promotion.PromotionID,
+				Name:        promotion.Name,
+				Type:        string(promotion.Type),
+				Quantity:    promotion.Quantity,
+				Amount:      promotion.Amount,
+			}
+		}
+
 		items[i] = contract.TransactionItemRes{
-			ID:          item.ID,
-			ProductID:   item.ProductID,
-			ProductName: item.ProductName,
-			Price:       item.Price,
-			Quantity:    item.Quantity,
-			Subtotal:    item.Subtotal,
+			ID:                  item.ID,
+			ProductID:           item.ProductID,
+			ProductName:         item.ProductName,
+			VariantID:           item.VariantID,
+			VariantLabel:        item.VariantLabel,
+			Modifiers:           buildTransactionItemModifiersRes(item.Modifiers),
+			Price:               item.Price,
+			Quantity:            item.Quantity,
+			GrossAmount:         item.GrossAmount,
+			DiscountType:        (*string)(item.DiscountType),
+			DiscountValue:       item.DiscountValue,
+			DiscountReason:      item.DiscountReason,
+			DiscountAmount:      item.DiscountAmount,
+			OrderDiscountAmount: item.OrderDiscountAmount,
+			PromotionAmount:     item.PromotionAmount,
+			VoucherAmount:       item.VoucherAmount,
+			Promotions:          promotions,
+			Subtotal:            item.Subtotal,
+			TaxAmount:           item.TaxAmount,
+			RefundedQuantity:    refundedQuantities[item.ID],
 		}
 	}
 
@@ -483,34 +1844,179 @@ func buildTransactionRes(transaction model.Transaction) contract.TransactionRes
 		paidAtStr = &str
 	}
 
+	var cancelledAtStr *string
+	if transaction.CancelledAt != nil {
+		str := transaction.CancelledAt.Format(time.RFC3339)
+		cancelledAtStr = &str
+	}
+
+	var customerName *string
+	if transaction.Customer != nil {
+		customerName = &transaction.Customer.Name
+	}
+
 	return contract.TransactionRes{
-		ID:             transaction.ID,
-		BusinessID:     transaction.BusinessID,
-		InvoiceNumber:  transaction.InvoiceNumber,
-		CreatedBy:      transaction.CreatedBy,
-		CreatorName:    transaction.Creator.Name,
-		TotalAmount:    transaction.TotalAmount,
-		ReceivedAmount: transaction.ReceivedAmount,
-		ChangeAmount:   transaction.ChangeAmount,
-		Status:         string(transaction.Status),
-		PaidAt:         paidAtStr,
-		ExpiredAt:      transaction.ExpiredAt.Format(time.RFC3339),
-		CreatedAt:      transaction.CreatedAt.Format(time.RFC3339),
-		Items:          items,
-	}
-}
-
-func generateInvoiceNumber() string {
-	// Format: DDMMYY + 3 random uppercase letters, example 130126JTY
-	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		ID:                  transaction.ID,
+		BusinessID:          transaction.BusinessID,
+		InvoiceNumber:       transaction.InvoiceNumber,
+		CreatedBy:           transaction.CreatedBy,
+		CreatorName:         transaction.Creator.Name,
+		GrossAmount:         transaction.GrossAmount,
+		DiscountType:        (*string)(transaction.DiscountType),
+		DiscountValue:       transaction.DiscountValue,
+		DiscountReason:      transaction.DiscountReason,
+		OrderDiscountAmount: transaction.OrderDiscountAmount,
+		DiscountAmount:      transaction.DiscountAmount,
+		NetAmount:           transaction.NetAmount,
+		DiscountApprovedBy:  transaction.DiscountApprovedBy,
+		PromotionAmount:     transaction.PromotionAmount,
+		VoucherCode:         transaction.VoucherCode,
+		VoucherAmount:       transaction.VoucherAmount,
+		TaxAmount:           transaction.TaxAmount,
+		InclusiveTaxAmount:  transaction.InclusiveTaxAmount,
+		Taxes:               taxes,
+		TotalAmount:         transaction.TotalAmount,
+		RoundingAmount:      transaction.RoundingAmount,
+		CreditAmount:        transaction.CreditAmount,
+		OutstandingAmount:   transaction.OutstandingAmount,
+		PointsEarned:        transaction.PointsEarned,
+		PointsRedeemed:      transaction.PointsRedeemed,
+		ReceivedAmount:      transaction.ReceivedAmount,
+		ChangeAmount:        transaction.ChangeAmount,
+		Status:              string(transaction.Status),
+		PaidAt:              paidAtStr,
+		ExpiredAt:           transaction.ExpiredAt.Format(time.RFC3339),
+		CancelledAt:         cancelledAtStr,
+		CancelledBy:         transaction.CancelledBy,
+		CancelApprovedBy:    transaction.CancelApprovedBy,
+		CancelReason:        transaction.CancelReason,
+		CashSessionID:       transaction.CashSessionID,
+		CustomerID:          transaction.CustomerID,
+		CustomerName:        customerName,
+		CreatedAt:           transaction.CreatedAt.Format(time.RFC3339),
+		Items:               items,
+		Payments:            payments,
+		RefundedAmount:      refundedAmount,
+		Refunds:             refunds,
+	}
+}
 
-	now := time.Now()
-	datePart := now.Format("020106")
+// buildQrisMerchant maps the business QRIS settings to the payload generator input
+func buildQrisMerchant(business model.Business) qris.Merchant {
+	return qris.Merchant{
+		Name:           business.Name,
+		City:           util.ToValue(business.QrisMerchantCity),
+		PostalCode:     util.ToValue(business.QrisPostalCode),
+		MCC:            util.ToValue(business.QrisMcc),
+		NMID:           util.ToValue(business.QrisNmid),
+		Criteria:       util.ToValue(business.QrisMerchantCriteria),
+		AcquirerDomain: util.ToValue(business.QrisAcquirerDomain),
+		MerchantPAN:    util.ToValue(business.QrisMerchantPan),
+		MerchantID:     util.ToValue(business.QrisMerchantID),
+	}
+}
+
+// qrisError converts payload generator errors to http errors
+func qrisError(err error) error {
+	switch {
+	case errors.Is(err, qris.ErrIncompleteMerchant):
+		return fiber.NewError(fiber.StatusBadRequest, "QRIS is not set up for this business. Fill in the NMID, MCC and merchant city first")
+	case errors.Is(err, qris.ErrInvalidAmount):
+		return fiber.NewError(fiber.StatusBadRequest, "QRIS amount must be greater than zero")
+	case errors.Is(err, qris.ErrValueTooLong):
+		return fiber.NewError(fiber.StatusBadRequest, "QRIS merchant data is too long, shorten the acquirer domain, merchant PAN or merchant ID")
+	default:
+		logger.Log.Error("Failed to generate QRIS", zap.Error(err))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to generate QRIS")
+	}
+}
 
-	b := make([]byte, 3)
-	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+// buildQrisRes renders the payload as a PNG QR code
+func buildQrisRes(payload string) (*contract.QrisRes, error) {
+	png, err := qrcode.Encode(payload, qrcode.Medium, config.QRIS_IMAGE_SIZE)
+	if err != nil {
+		logger.Log.Error("Failed to render QRIS image", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to generate QRIS")
 	}
 
-	return datePart + string(b)
+	return &contract.QrisRes{
+		Payload: payload,
+		Image:   "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// nextInvoiceNumber reserves the next number of the business inside the checkout transaction
+func (u *TransactionUsecase) nextInvoiceNumber(tx *gorm.DB, businessID string, now time.Time) (string, error) {
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get business", zap.Error(err))
+		return "", fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+
+	counter, err := u.invoiceSequenceRepo.NextValue(tx, businessID, invoicePeriodKey(business.InvoiceResetPeriod, now), invoiceNumberPrefix(*business, now))
+	if err != nil {
+		logger.Log.Error("Failed to get next invoice number", zap.Error(err))
+		return "", fiber.NewError(fiber.StatusInternalServerError, "Failed to generate invoice number")
+	}
+
+	return formatInvoiceNumber(*business, counter, now), nil
+}
+
+// invoiceDateLayouts maps the supported invoice date formats to Go layouts
+var invoiceDateLayouts = map[string]string{
+	"YYYYMMDD": "20060102",
+	"YYMMDD":   "060102",
+	"YYYYMM":   "200601",
+	"YYMM":     "0601",
+	"YYYY":     "2006",
+}
+
+// formatInvoiceNumber joins prefix, date and zero padded counter, example INV-261017-0001
+func formatInvoiceNumber(business model.Business, counter int64, now time.Time) string {
+	return invoiceNumberPrefix(business, now) + fmt.Sprintf("%0*d", business.InvoiceCounterPadding, counter)
+}
+
+// invoiceNumberPrefix renders everything before the counter, example INV-261017-
+func invoiceNumberPrefix(business model.Business, now time.Time) string {
+	parts := make([]string, 0, 2)
+	if business.InvoicePrefix != "" {
+		parts = append(parts, business.InvoicePrefix)
+	}
+	if layout, ok := invoiceDateLayouts[business.InvoiceDateFormat]; ok {
+		parts = append(parts, now.Format(layout))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return strings.Join(parts, business.InvoiceSeparator) + business.InvoiceSeparator
+}
+
+// invoicePeriodKey names the sequence counter used for the reset period
+func invoicePeriodKey(period config.InvoiceResetPeriod, now time.Time) string {
+	switch period {
+	case config.INVOICE_RESET_PERIOD_DAILY:
+		return now.Format("20060102")
+	case config.INVOICE_RESET_PERIOD_MONTHLY:
+		return now.Format("200601")
+	case config.INVOICE_RESET_PERIOD_YEARLY:
+		return now.Format("2006")
+	default:
+		return "all"
+	}
+}
+
+// invoiceDateCoversPeriod reports whether the date part is precise enough for the reset period,
+// otherwise the restarted counter would repeat numbers that were already issued
+func invoiceDateCoversPeriod(dateFormat string, period config.InvoiceResetPeriod) bool {
+	switch period {
+	case config.INVOICE_RESET_PERIOD_DAILY:
+		return strings.HasSuffix(dateFormat, "DD")
+	case config.INVOICE_RESET_PERIOD_MONTHLY:
+		return strings.Contains(dateFormat, "MM")
+	case config.INVOICE_RESET_PERIOD_YEARLY:
+		return strings.Contains(dateFormat, "YY")
+	default:
+		return true
+	}
 }
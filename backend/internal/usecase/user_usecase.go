@@ -7,25 +7,32 @@ import (
 	"app/internal/model"
 	"app/internal/repository"
 	"app/pkg/logger"
+	"app/pkg/qris"
 	"app/pkg/storage"
 	"app/pkg/util"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type UserUsecase struct {
-	userRepo     *repository.UserRepository
-	businessRepo *repository.BusinessRepository
-	storage      *storage.R2Storage
+	userRepo            *repository.UserRepository
+	businessRepo        *repository.BusinessRepository
+	invoiceSequenceRepo *repository.InvoiceSequenceRepository
+	storage             *storage.R2Storage
+	db                  *gorm.DB
 }
 
-func NewUserUsecase(userRepo *repository.UserRepository, businessRepo *repository.BusinessRepository, storage *storage.R2Storage) *UserUsecase {
+func NewUserUsecase(userRepo *repository.UserRepository, businessRepo *repository.BusinessRepository, invoiceSequenceRepo *repository.InvoiceSequenceRepository, storage *storage.R2Storage, db *gorm.DB) *UserUsecase {
 	return &UserUsecase{
-		userRepo:     userRepo,
-		businessRepo: businessRepo,
-		storage:      storage,
+		userRepo:            userRepo,
+		businessRepo:        businessRepo,
+		invoiceSequenceRepo: invoiceSequenceRepo,
+		storage:             storage,
+		db:                  db,
 	}
 }
 
@@ -81,6 +88,17 @@ func (u *UserUsecase) EditCurrentUser(
 	if req.Image != nil {
 		user.Image = req.Image
 	}
+	// Owners set a PIN so they can approve voids on the register
+	if req.Pin != nil {
+		hashedPin, err := util.HashPassword(*req.Pin)
+		if err != nil {
+			logger.Log.Error("Failed to hash PIN", zap.Error(err))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update user")
+		}
+		user.PinHash = &hashedPin
+		user.PinFailedAttempts = 0
+		user.PinLockedUntil = nil
+	}
 
 	if err := u.userRepo.UpdateUser(user); err != nil {
 		logger.Log.Error("Update user failed", zap.Error(err), zap.String("userID", userID))
@@ -169,20 +187,145 @@ func (u *UserUsecase) EditBusiness(userID string, businessID string, req *contra
 	if req.Logo != nil {
 		business.Logo = req.Logo
 	}
+	if req.QrisNmid != nil {
+		business.QrisNmid = req.QrisNmid
+	}
+	if req.QrisMerchantCriteria != nil {
+		business.QrisMerchantCriteria = req.QrisMerchantCriteria
+	}
+	if req.QrisMcc != nil {
+		business.QrisMcc = req.QrisMcc
+	}
+	if req.QrisMerchantCity != nil {
+		business.QrisMerchantCity = req.QrisMerchantCity
+	}
+	if req.QrisPostalCode != nil {
+		business.QrisPostalCode = req.QrisPostalCode
+	}
+	if req.QrisAcquirerDomain != nil {
+		business.QrisAcquirerDomain = req.QrisAcquirerDomain
+	}
+	if req.QrisMerchantPan != nil {
+		business.QrisMerchantPan = req.QrisMerchantPan
+	}
+	if req.QrisMerchantID != nil {
+		business.QrisMerchantID = req.QrisMerchantID
+	}
+	if req.InvoicePrefix != nil {
+		business.InvoicePrefix = *req.InvoicePrefix
+	}
+	if req.InvoiceSeparator != nil {
+		business.InvoiceSeparator = *req.InvoiceSeparator
+	}
+	if req.InvoiceDateFormat != nil {
+		business.InvoiceDateFormat = *req.InvoiceDateFormat
+	}
+	if req.InvoiceCounterPadding != nil {
+		business.InvoiceCounterPadding = *req.InvoiceCounterPadding
+	}
+	resetPeriodChanged := false
+	if req.InvoiceResetPeriod != nil {
+		resetPeriodChanged = business.InvoiceResetPeriod != config.InvoiceResetPeriod(*req.InvoiceResetPeriod)
+		business.InvoiceResetPeriod = config.InvoiceResetPeriod(*req.InvoiceResetPeriod)
+	}
+	if req.CashierMaxDiscountPercent != nil {
+		business.CashierMaxDiscountPercent = *req.CashierMaxDiscountPercent
+	}
+	if req.ManagerMaxDiscountPercent != nil {
+		business.ManagerMaxDiscountPercent = *req.ManagerMaxDiscountPercent
+	}
+	if req.CashRoundingMode != nil {
+		business.CashRoundingMode = config.CashRoundingMode(*req.CashRoundingMode)
+	}
+	if req.CashRoundingUnit != nil {
+		business.CashRoundingUnit = *req.CashRoundingUnit
+	}
+	if req.ReceiptHeader != nil {
+		business.ReceiptHeader = req.ReceiptHeader
+	}
+	if req.ReceiptFooter != nil {
+		business.ReceiptFooter = req.ReceiptFooter
+	}
+	if req.CreditReminderTemplate != nil {
+		business.CreditReminderTemplate = normalizeOptionalString(req.CreditReminderTemplate)
+	}
+	if req.LoyaltyEnabled != nil {
+		business.LoyaltyEnabled = *req.LoyaltyEnabled
+	}
+	if req.LoyaltySpendPerPoint != nil {
+		business.LoyaltySpendPerPoint = *req.LoyaltySpendPerPoint
+	}
+	if req.LoyaltyPointValue != nil {
+		business.LoyaltyPointValue = *req.LoyaltyPointValue
+	}
+	if req.LoyaltyMinRedeemPoints != nil {
+		business.LoyaltyMinRedeemPoints = *req.LoyaltyMinRedeemPoints
+	}
+	if req.LoyaltyPointsExpiryDays != nil {
+		business.LoyaltyPointsExpiryDays = req.LoyaltyPointsExpiryDays
+		if *req.LoyaltyPointsExpiryDays == 0 {
+			business.LoyaltyPointsExpiryDays = nil
+		}
+	}
+
+	// Numbers restart every period, the date part has to tell the periods apart
+	if !invoiceDateCoversPeriod(business.InvoiceDateFormat, business.InvoiceResetPeriod) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invoice date format must include the %s reset period", business.InvoiceResetPeriod))
+	}
 
 	if business == nil {
 		if err := u.businessRepo.CreateBusiness(business); err != nil {
 			return nil, err
 		}
 	} else {
-		if err := u.businessRepo.UpdateBusiness(business); err != nil {
+		tx := u.db.Begin()
+		if tx.Error != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction")
+		}
+		defer handlePanic(tx)
+
+		// Counters of the old period may be behind numbers issued since, the next sale seeds a fresh one
+		if resetPeriodChanged {
+			if err := u.invoiceSequenceRepo.DeleteByBusinessID(tx, business.ID); err != nil {
+				tx.Rollback()
+				logger.Log.Error("Failed to reset invoice sequences", zap.Error(err), zap.String("businessID", business.ID))
+				return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update business")
+			}
+		}
+
+		if err := u.businessRepo.UpdateBusiness(tx, business); err != nil {
+			tx.Rollback()
 			return nil, err
 		}
+
+		if err := tx.Commit().Error; err != nil {
+			logger.Log.Error("Failed to commit business update", zap.Error(err), zap.String("businessID", business.ID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update business")
+		}
 	}
 
 	return util.ToPointer(BuildBusinessRes(*business, u.storage)), nil
 }
 
+// === BUSINESS QRIS ===
+func (u *UserUsecase) GetBusinessQris(businessID string) (*contract.QrisRes, error) {
+	business, err := u.businessRepo.GetBusinessByID(businessID)
+	if err != nil {
+		logger.Log.Error("Failed to get business", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get business")
+	}
+	if business == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Business not found")
+	}
+
+	payload, err := qris.GenerateStatic(buildQrisMerchant(*business))
+	if err != nil {
+		return nil, qrisError(err)
+	}
+
+	return buildQrisRes(payload)
+}
+
 func (u *UserUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, targetUserID *string) error {
 	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
 
@@ -248,6 +391,46 @@ func BuildBusinessRes(business model.Business, storage *storage.R2Storage) contr
 		EmployeeSize: business.EmployeeSize,
 		Category:     business.Category,
 		Logo:         logo,
+		Qris: &contract.QrisMerchantRes{
+			Nmid:             business.QrisNmid,
+			MerchantCriteria: business.QrisMerchantCriteria,
+			Mcc:              business.QrisMcc,
+			MerchantCity:     business.QrisMerchantCity,
+			PostalCode:       business.QrisPostalCode,
+			AcquirerDomain:   business.QrisAcquirerDomain,
+			MerchantPan:      business.QrisMerchantPan,
+			MerchantID:       business.QrisMerchantID,
+		},
+		Invoice: &contract.InvoiceFormatRes{
+			Prefix:         business.InvoicePrefix,
+			Separator:      business.InvoiceSeparator,
+			DateFormat:     business.InvoiceDateFormat,
+			CounterPadding: business.InvoiceCounterPadding,
+			ResetPeriod:    string(business.InvoiceResetPeriod),
+			Example:        formatInvoiceNumber(business, 1, time.Now()),
+		},
+		Discount: &contract.DiscountLimitRes{
+			CashierMaxPercent: business.CashierMaxDiscountPercent,
+			ManagerMaxPercent: business.ManagerMaxDiscountPercent,
+		},
+		CashRounding: &contract.CashRoundingRes{
+			Mode: string(business.CashRoundingMode),
+			Unit: business.CashRoundingUnit,
+		},
+		Receipt: &contract.ReceiptRes{
+			Header: business.ReceiptHeader,
+			Footer: business.ReceiptFooter,
+		},
+		Credit: &contract.CreditSettingsRes{
+			ReminderTemplate: business.CreditReminderTemplate,
+		},
+		Loyalty: &contract.LoyaltyRulesRes{
+			Enabled:          business.LoyaltyEnabled,
+			SpendPerPoint:    business.LoyaltySpendPerPoint,
+			PointValue:       business.LoyaltyPointValue,
+			MinRedeemPoints:  business.LoyaltyMinRedeemPoints,
+			PointsExpiryDays: business.LoyaltyPointsExpiryDays,
+		},
 	}
 }
 
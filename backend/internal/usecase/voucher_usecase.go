@@ -0,0 +1,548 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"app/internal/contract"
+	"app/internal/middleware"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/logger"
+	"app/pkg/util"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type VoucherUsecase struct {
+	voucherRepo *repository.VoucherRepository
+	db          *gorm.DB
+}
+
+func NewVoucherUsecase(voucherRepo *repository.VoucherRepository, db *gorm.DB) *VoucherUsecase {
+	return &VoucherUsecase{
+		voucherRepo: voucherRepo,
+		db:          db,
+	}
+}
+
+// CreateVoucher creates one voucher with a chosen or generated code
+func (u *VoucherUsecase) CreateVoucher(userID, businessID string, req *contract.CreateVoucherReq) (*contract.VoucherRes, error) {
+	var code string
+	if req.Code != nil {
+		code = util.NormalizeCode(*req.Code)
+		existing, err := u.voucherRepo.FindExistingCodes(businessID, []string{code})
+		if err != nil {
+			logger.Log.Error("Failed to check voucher code", zap.Error(err), zap.String("businessID", businessID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create voucher")
+		}
+		if len(existing) > 0 {
+			return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Voucher code %s already exists", code))
+		}
+	} else {
+		codes, err := generateUniqueCodes("", 1, func(codes []string) ([]string, error) {
+			return u.voucherRepo.FindExistingCodes(businessID, codes)
+		})
+		if err != nil {
+			logger.Log.Error("Failed to generate voucher code", zap.Error(err), zap.String("businessID", businessID))
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create voucher")
+		}
+		code = codes[0]
+	}
+
+	voucher := model.Voucher{
+		BusinessID:       businessID,
+		Code:             code,
+		Type:             config.DiscountType(req.Type),
+		Value:            req.Value,
+		MaxDiscount:      req.MaxDiscount,
+		MinSpend:         req.MinSpend,
+		UsageLimit:       req.UsageLimit,
+		PerCustomerLimit: req.PerCustomerLimit,
+		IsActive:         true,
+		CreatedBy:        &userID,
+	}
+	if req.IsActive != nil {
+		voucher.IsActive = *req.IsActive
+	}
+
+	var err error
+	if voucher.StartsAt, err = parseScheduleTime(req.StartsAt); err != nil {
+		return nil, err
+	}
+	if voucher.EndsAt, err = parseScheduleTime(req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	if err := validateVoucher(&voucher); err != nil {
+		return nil, err
+	}
+
+	vouchers := []model.Voucher{voucher}
+	if err := u.voucherRepo.CreateVouchers(u.db, vouchers); err != nil {
+		logger.Log.Error("Failed to create voucher", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create voucher")
+	}
+
+	return util.ToPointer(buildVoucherRes(vouchers[0])), nil
+}
+
+// GenerateVouchers creates a batch of vouchers with random codes that can be exported together
+func (u *VoucherUsecase) GenerateVouchers(userID, businessID string, req *contract.GenerateVouchersReq) (*contract.VoucherBatchRes, error) {
+	template := model.Voucher{
+		BusinessID:       businessID,
+		Type:             config.DiscountType(req.Type),
+		Value:            req.Value,
+		MaxDiscount:      req.MaxDiscount,
+		MinSpend:         req.MinSpend,
+		UsageLimit:       req.UsageLimit,
+		PerCustomerLimit: req.PerCustomerLimit,
+		IsActive:         true,
+		CreatedBy:        &userID,
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	var err error
+	if template.StartsAt, err = parseScheduleTime(req.StartsAt); err != nil {
+		return nil, err
+	}
+	if template.EndsAt, err = parseScheduleTime(req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	if err := validateVoucher(&template); err != nil {
+		return nil, err
+	}
+
+	codes, err := generateUniqueCodes(util.NormalizeCode(req.Prefix), req.Count, func(codes []string) ([]string, error) {
+		return u.voucherRepo.FindExistingCodes(businessID, codes)
+	})
+	if err != nil {
+		logger.Log.Error("Failed to generate voucher codes", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to generate vouchers")
+	}
+
+	batchID := uuid.NewString()
+	vouchers := make([]model.Voucher, len(codes))
+	for i, code := range codes {
+		vouchers[i] = template
+		vouchers[i].Code = code
+		vouchers[i].BatchID = &batchID
+	}
+
+	if err := u.voucherRepo.CreateVouchers(u.db, vouchers); err != nil {
+		logger.Log.Error("Failed to create vouchers", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to generate vouchers")
+	}
+
+	res := &contract.VoucherBatchRes{
+		BatchID:  batchID,
+		Vouchers: make([]contract.VoucherRes, len(vouchers)),
+	}
+	for i, voucher := range vouchers {
+		res.Vouchers[i] = buildVoucherRes(voucher)
+	}
+
+	return res, nil
+}
+
+func (u *VoucherUsecase) UpdateVoucher(voucherID string, req *contract.UpdateVoucherReq) (*contract.VoucherRes, error) {
+	voucher, err := u.voucherRepo.GetVoucherByID(voucherID)
+	if err != nil {
+		logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("voucherID", voucherID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get voucher")
+	}
+
+	if voucher == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Voucher not found")
+	}
+
+	if req.MinSpend != nil {
+		voucher.MinSpend = *req.MinSpend
+	}
+	if req.UsageLimit != nil {
+		if *req.UsageLimit < voucher.UsedCount {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Usage limit can't be below the %d uses so far", voucher.UsedCount))
+		}
+		voucher.UsageLimit = req.UsageLimit
+	}
+	if req.PerCustomerLimit != nil {
+		voucher.PerCustomerLimit = req.PerCustomerLimit
+	}
+	if req.StartsAt != nil {
+		if voucher.StartsAt, err = parseScheduleTime(req.StartsAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndsAt != nil {
+		if voucher.EndsAt, err = parseScheduleTime(req.EndsAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		voucher.IsActive = *req.IsActive
+	}
+
+	if err := validateVoucher(voucher); err != nil {
+		return nil, err
+	}
+
+	if err := u.voucherRepo.UpdateVoucher(voucher); err != nil {
+		logger.Log.Error("Failed to update voucher", zap.Error(err), zap.String("voucherID", voucherID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update voucher")
+	}
+
+	return u.GetVoucherByID(voucherID)
+}
+
+func (u *VoucherUsecase) GetVoucherByID(voucherID string) (*contract.VoucherRes, error) {
+	voucher, err := u.voucherRepo.GetVoucherByID(voucherID)
+	if err != nil {
+		logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("voucherID", voucherID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get voucher")
+	}
+
+	if voucher == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Voucher not found")
+	}
+
+	return util.ToPointer(buildVoucherRes(*voucher)), nil
+}
+
+// GetVoucherByCode looks up a code as typed at the till
+func (u *VoucherUsecase) GetVoucherByCode(businessID, code string) (*contract.VoucherRes, error) {
+	voucher, err := u.voucherRepo.GetVoucherByCode(businessID, util.NormalizeCode(code))
+	if err != nil {
+		logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get voucher")
+	}
+
+	if voucher == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Voucher not found")
+	}
+
+	return util.ToPointer(buildVoucherRes(*voucher)), nil
+}
+
+func (u *VoucherUsecase) ListVouchers(businessID string, page, pageSize int, search, batchID string) ([]contract.VoucherRes, int64, error) {
+	vouchers, total, err := u.voucherRepo.ListVouchers(businessID, page, pageSize, util.NormalizeCode(search), batchID)
+	if err != nil {
+		logger.Log.Error("Failed to list vouchers", zap.Error(err), zap.String("businessID", businessID))
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to list vouchers")
+	}
+
+	results := make([]contract.VoucherRes, len(vouchers))
+	for i, voucher := range vouchers {
+		results[i] = buildVoucherRes(voucher)
+	}
+
+	return results, total, nil
+}
+
+// ExportVouchers writes the vouchers of a business, or of one batch, as CSV
+func (u *VoucherUsecase) ExportVouchers(businessID string, query *contract.ExportCodesQuery) ([]byte, string, error) {
+	vouchers, err := u.voucherRepo.ListVouchersForExport(businessID, query.BatchID)
+	if err != nil {
+		logger.Log.Error("Failed to list vouchers", zap.Error(err), zap.String("businessID", businessID))
+		return nil, "", fiber.NewError(fiber.StatusInternalServerError, "Failed to export vouchers")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"code", "type", "value", "max_discount", "min_spend", "usage_limit", "per_customer_limit", "used_count", "starts_at", "ends_at", "is_active"})
+	for _, voucher := range vouchers {
+		writer.Write([]string{
+			voucher.Code,
+			string(voucher.Type),
+			formatCSVAmount(voucher.Value),
+			formatCSVOptionalAmount(voucher.MaxDiscount),
+			formatCSVAmount(voucher.MinSpend),
+			formatCSVOptionalInt(voucher.UsageLimit),
+			formatCSVOptionalInt(voucher.PerCustomerLimit),
+			strconv.Itoa(voucher.UsedCount),
+			formatCSVOptionalTime(voucher.StartsAt),
+			formatCSVOptionalTime(voucher.EndsAt),
+			strconv.FormatBool(voucher.IsActive),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logger.Log.Error("Failed to write vouchers CSV", zap.Error(err))
+		return nil, "", fiber.NewError(fiber.StatusInternalServerError, "Failed to export vouchers")
+	}
+
+	fileName := "vouchers.csv"
+	if query.BatchID != "" {
+		fileName = fmt.Sprintf("vouchers-%s.csv", query.BatchID)
+	}
+
+	return buf.Bytes(), fileName, nil
+}
+
+// GetUsableVoucher looks up a voucher entered at checkout and checks it can be used now.
+// Usage limits are checked under lock when it is redeemed. No code means no voucher.
+func (u *VoucherUsecase) GetUsableVoucher(businessID string, code *string, customerID *string, now time.Time) (*model.Voucher, error) {
+	if code == nil || util.NormalizeCode(*code) == "" {
+		return nil, nil
+	}
+
+	voucher, err := u.voucherRepo.GetVoucherByCode(businessID, util.NormalizeCode(*code))
+	if err != nil {
+		logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("businessID", businessID))
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get voucher")
+	}
+
+	if voucher == nil || !voucher.IsValidAt(now) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Voucher is not valid")
+	}
+
+	if voucher.PerCustomerLimit != nil && customerID == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Choose a customer to use this voucher")
+	}
+
+	return voucher, nil
+}
+
+// Redeem counts a use of the voucher against the transaction. The voucher row is locked,
+// so two terminals redeeming the last use at once can't both get it.
+func (u *VoucherUsecase) Redeem(tx *gorm.DB, voucherID string, transaction *model.Transaction) error {
+	voucher, err := u.voucherRepo.GetVoucherForUpdate(tx, voucherID)
+	if err != nil {
+		logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("voucherID", voucherID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to redeem voucher")
+	}
+
+	if voucher == nil || !voucher.IsValidAt(time.Now()) {
+		return fiber.NewError(fiber.StatusBadRequest, "Voucher is not valid")
+	}
+
+	if voucher.UsageLimit != nil && voucher.UsedCount >= *voucher.UsageLimit {
+		return fiber.NewError(fiber.StatusBadRequest, "Voucher has been fully used")
+	}
+
+	if voucher.PerCustomerLimit != nil {
+		if transaction.CustomerID == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Choose a customer to use this voucher")
+		}
+
+		used, err := u.voucherRepo.CountCustomerRedemptions(tx, voucher.ID, *transaction.CustomerID)
+		if err != nil {
+			logger.Log.Error("Failed to count voucher redemptions", zap.Error(err), zap.String("voucherID", voucher.ID))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to redeem voucher")
+		}
+		if used >= int64(*voucher.PerCustomerLimit) {
+			return fiber.NewError(fiber.StatusBadRequest, "Customer has already used this voucher")
+		}
+	}
+
+	if err := u.voucherRepo.AddUsedCount(tx, voucher.ID, 1); err != nil {
+		logger.Log.Error("Failed to update voucher usage", zap.Error(err), zap.String("voucherID", voucher.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to redeem voucher")
+	}
+
+	if err := u.voucherRepo.CreateRedemption(tx, &model.VoucherRedemption{
+		BusinessID:    transaction.BusinessID,
+		VoucherID:     voucher.ID,
+		TransactionID: transaction.ID,
+		CustomerID:    transaction.CustomerID,
+		Amount:        transaction.VoucherAmount,
+	}); err != nil {
+		logger.Log.Error("Failed to create voucher redemption", zap.Error(err), zap.String("voucherID", voucher.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to redeem voucher")
+	}
+
+	return nil
+}
+
+// Release gives back the use of a voucher by a transaction that is cancelled, expired or edited
+func (u *VoucherUsecase) Release(tx *gorm.DB, transactionID string) error {
+	redemption, err := u.voucherRepo.GetActiveRedemption(tx, transactionID)
+	if err != nil {
+		logger.Log.Error("Failed to get voucher redemption", zap.Error(err), zap.String("transactionID", transactionID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to release voucher")
+	}
+
+	if redemption == nil {
+		return nil
+	}
+
+	// Lock the voucher like a redemption does, so the used count is never moved concurrently
+	if _, err := u.voucherRepo.GetVoucherForUpdate(tx, redemption.VoucherID); err != nil {
+		logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("voucherID", redemption.VoucherID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to release voucher")
+	}
+
+	if err := u.voucherRepo.AddUsedCount(tx, redemption.VoucherID, -1); err != nil {
+		logger.Log.Error("Failed to update voucher usage", zap.Error(err), zap.String("voucherID", redemption.VoucherID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to release voucher")
+	}
+
+	if err := u.voucherRepo.ReverseRedemption(tx, redemption.ID, time.Now()); err != nil {
+		logger.Log.Error("Failed to reverse voucher redemption", zap.Error(err), zap.String("redemptionID", redemption.ID))
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to release voucher")
+	}
+
+	return nil
+}
+
+// ReleaseRefunded gives back the use of a voucher once everything bought with it has been refunded
+func (u *VoucherUsecase) ReleaseRefunded(tx *gorm.DB, transaction *model.Transaction, refundedAmount float64) error {
+	if transaction.VoucherID == nil || roundMoney(refundedAmount) < transaction.TotalAmount {
+		return nil
+	}
+
+	return u.Release(tx, transaction.ID)
+}
+
+func (u *VoucherUsecase) IsAllowedToAccess(claims middleware.Claims, allowedPermissions []config.Permission, voucherID *string) error {
+	allowed, permission := config.DoesRoleAllowedToAccess(claims.Role, allowedPermissions)
+
+	if !allowed || permission == nil {
+		return fiber.NewError(fiber.StatusForbidden, "You don't have permission to perform this action")
+	}
+
+	scope := permission.Scope()
+
+	if scope == config.PERMISSION_SCOPE_ORG {
+		if voucherID != nil {
+			voucher, err := u.voucherRepo.GetVoucherByIDAndBusinessID(*voucherID, *claims.BusinessID)
+			if err != nil {
+				logger.Log.Error("Failed to get voucher", zap.Error(err), zap.String("voucherID", *voucherID))
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to get voucher")
+			}
+
+			if voucher == nil {
+				logger.Log.Warn("Voucher not found", zap.String("voucherID", *voucherID))
+				return fiber.NewError(fiber.StatusNotFound, "You don't have permission to perform this action")
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateVoucher checks the discount and dates of a voucher
+func validateVoucher(voucher *model.Voucher) error {
+	if voucher.Type == config.DISCOUNT_TYPE_PERCENTAGE {
+		if voucher.Value > 100 {
+			return fiber.NewError(fiber.StatusBadRequest, "Percentage cannot exceed 100%")
+		}
+	} else {
+		voucher.MaxDiscount = nil
+	}
+
+	if voucher.StartsAt != nil && voucher.EndsAt != nil && !voucher.EndsAt.After(*voucher.StartsAt) {
+		return fiber.NewError(fiber.StatusBadRequest, "End time must be after start time")
+	}
+
+	return nil
+}
+
+// generateUniqueCodes generates count random codes after the prefix that are not taken yet
+func generateUniqueCodes(prefix string, count int, findExisting func(codes []string) ([]string, error)) ([]string, error) {
+	codes := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+
+	// Collisions are rare with 32^8 codes, a few rounds are enough to replace them
+	for attempt := 0; attempt < 5 && len(codes) < count; attempt++ {
+		candidates := make([]string, 0, count-len(codes))
+		for len(candidates) < count-len(codes) {
+			random, err := util.GenerateCode(config.CODE_RANDOM_LENGTH)
+			if err != nil {
+				return nil, err
+			}
+			code := prefix + random
+			if seen[code] {
+				continue
+			}
+			seen[code] = true
+			candidates = append(candidates, code)
+		}
+
+		existing, err := findExisting(candidates)
+		if err != nil {
+			return nil, err
+		}
+		taken := make(map[string]bool, len(existing))
+		for _, code := range existing {
+			taken[code] = true
+		}
+
+		for _, code := range candidates {
+			if !taken[code] {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	if len(codes) < count {
+		return nil, fmt.Errorf("generated only %d of %d unique codes", len(codes), count)
+	}
+	return codes, nil
+}
+
+// formatCSVAmount formats money for CSV exports without thousand separators
+func formatCSVAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func formatCSVOptionalAmount(amount *float64) string {
+	if amount == nil {
+		return ""
+	}
+	return formatCSVAmount(*amount)
+}
+
+func formatCSVOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatCSVOptionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+// buildVoucherRes builds voucher response
+func buildVoucherRes(voucher model.Voucher) contract.VoucherRes {
+	var startsAt, endsAt *string
+	if voucher.StartsAt != nil {
+		str := voucher.StartsAt.Format(time.RFC3339)
+		startsAt = &str
+	}
+	if voucher.EndsAt != nil {
+		str := voucher.EndsAt.Format(time.RFC3339)
+		endsAt = &str
+	}
+
+	return contract.VoucherRes{
+		ID:               voucher.ID,
+		BusinessID:       voucher.BusinessID,
+		Code:             voucher.Code,
+		BatchID:          voucher.BatchID,
+		Type:             string(voucher.Type),
+		Value:            voucher.Value,
+		MaxDiscount:      voucher.MaxDiscount,
+		MinSpend:         voucher.MinSpend,
+		UsageLimit:       voucher.UsageLimit,
+		PerCustomerLimit: voucher.PerCustomerLimit,
+		UsedCount:        voucher.UsedCount,
+		StartsAt:         startsAt,
+		EndsAt:           endsAt,
+		IsActive:         voucher.IsActive,
+		CreatedAt:        voucher.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        voucher.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WhatsAppCloudMessagingProvider sends messages through the WhatsApp Business Cloud API
+type WhatsAppCloudMessagingProvider struct {
+	baseURL       string
+	phoneNumberID string
+	accessToken   string
+	client        *http.Client
+}
+
+func NewWhatsAppCloudMessagingProvider(baseURL, phoneNumberID, accessToken string) *WhatsAppCloudMessagingProvider {
+	return &WhatsAppCloudMessagingProvider{
+		baseURL:       baseURL,
+		phoneNumberID: phoneNumberID,
+		accessToken:   accessToken,
+		client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type whatsAppCloudMessageReq struct {
+	MessagingProduct string                 `json:"messaging_product"`
+	RecipientType    string                 `json:"recipient_type"`
+	To               string                 `json:"to"`
+	Type             string                 `json:"type"`
+	Text             *whatsAppCloudText     `json:"text,omitempty"`
+	Document         *whatsAppCloudDocument `json:"document,omitempty"`
+}
+
+type whatsAppCloudText struct {
+	Body       string `json:"body"`
+	PreviewURL bool   `json:"preview_url"`
+}
+
+type whatsAppCloudDocument struct {
+	Link     string `json:"link"`
+	Filename string `json:"filename,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+type whatsAppCloudMessageRes struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+func (p *WhatsAppCloudMessagingProvider) Name() string {
+	return string(config.MESSAGING_PROVIDER_WHATSAPP_CLOUD)
+}
+
+func (p *WhatsAppCloudMessagingProvider) SendWhatsApp(msg WhatsAppMessage) (string, error) {
+	req := whatsAppCloudMessageReq{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               msg.To,
+	}
+	if msg.DocumentURL != nil {
+		req.Type = "document"
+		req.Document = &whatsAppCloudDocument{Link: *msg.DocumentURL, Caption: msg.Body}
+		if msg.DocumentName != nil {
+			req.Document.Filename = *msg.DocumentName
+		}
+	} else {
+		req.Type = "text"
+		req.Text = &whatsAppCloudText{Body: msg.Body, PreviewURL: true}
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	var res whatsAppCloudMessageRes
+	if err := p.do(http.MethodPost, "/"+url.PathEscape(p.phoneNumberID)+"/messages", body, &res); err != nil {
+		return "", err
+	}
+
+	if len(res.Messages) == 0 {
+		return "", errors.New("whatsapp cloud returned no message id")
+	}
+
+	return res.Messages[0].ID, nil
+}
+
+func (p *WhatsAppCloudMessagingProvider) do(method, path string, body []byte, out any) error {
+	req, err := http.NewRequest(method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("whatsapp cloud %s %s returned %d: %s", method, path, res.StatusCode, string(resBody))
+	}
+
+	return json.Unmarshal(resBody, out)
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"app/internal/config"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const xenditCallbackTokenHeader = "X-Callback-Token"
+
+// XenditPaymentProvider collects payments through Xendit invoices
+type XenditPaymentProvider struct {
+	baseURL       string
+	secretKey     string
+	callbackToken string
+	client        *http.Client
+}
+
+func NewXenditPaymentProvider(baseURL, secretKey, callbackToken string) *XenditPaymentProvider {
+	return &XenditPaymentProvider{
+		baseURL:       baseURL,
+		secretKey:     secretKey,
+		callbackToken: callbackToken,
+		client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type xenditInvoiceReq struct {
+	ExternalID      string  `json:"external_id"`
+	Amount          float64 `json:"amount"`
+	Description     string  `json:"description"`
+	InvoiceDuration int     `json:"invoice_duration"`
+	Currency        string  `json:"currency"`
+}
+
+// xenditInvoice is shared by the invoice API response and the invoice callback
+type xenditInvoice struct {
+	ID            string   `json:"id"`
+	ExternalID    string   `json:"external_id"`
+	Status        string   `json:"status"`
+	Amount        float64  `json:"amount"`
+	PaidAmount    *float64 `json:"paid_amount"`
+	PaidAt        *string  `json:"paid_at"`
+	ExpiryDate    *string  `json:"expiry_date"`
+	InvoiceURL    *string  `json:"invoice_url"`
+	PaymentMethod *string  `json:"payment_method"`
+}
+
+func (p *XenditPaymentProvider) Name() string {
+	return string(config.PAYMENT_PROVIDER_XENDIT)
+}
+
+func (p *XenditPaymentProvider) CreateCharge(req PaymentChargeReq) (*PaymentChargeResult, error) {
+	duration := int(time.Until(req.ExpiresAt).Seconds())
+	if duration < 1 {
+		duration = 1
+	}
+
+	body, err := json.Marshal(xenditInvoiceReq{
+		ExternalID:      req.ExternalID,
+		Amount:          req.Amount,
+		Description:     req.Description,
+		InvoiceDuration: duration,
+		Currency:        "IDR",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice xenditInvoice
+	if err := p.do(http.MethodPost, "/v2/invoices", body, &invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice.toResult(), nil
+}
+
+func (p *XenditPaymentProvider) GetChargeStatus(reference string) (*PaymentChargeResult, error) {
+	var invoice xenditInvoice
+	if err := p.do(http.MethodGet, "/v2/invoices/"+url.PathEscape(reference), nil, &invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice.toResult(), nil
+}
+
+// VerifyWebhook checks the callback verification token Xendit sends with every callback
+func (p *XenditPaymentProvider) VerifyWebhook(header http.Header, body []byte) (*PaymentChargeResult, error) {
+	token := header.Get(xenditCallbackTokenHeader)
+	if p.callbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.callbackToken)) != 1 {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var invoice xenditInvoice
+	if err := json.Unmarshal(body, &invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice.toResult(), nil
+}
+
+func (p *XenditPaymentProvider) do(method, path string, body []byte, out any) error {
+	req, err := http.NewRequest(method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.secretKey, "")
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("xendit %s %s returned %d: %s", method, path, res.StatusCode, string(resBody))
+	}
+
+	return json.Unmarshal(resBody, out)
+}
+
+func (i xenditInvoice) toResult() *PaymentChargeResult {
+	result := &PaymentChargeResult{
+		Reference:   i.ID,
+		CheckoutURL: i.InvoiceURL,
+		PaidAmount:  i.PaidAmount,
+		PaidAt:      parseXenditTime(i.PaidAt),
+		ExpiresAt:   parseXenditTime(i.ExpiryDate),
+	}
+
+	switch i.Status {
+	case "PAID", "SETTLED":
+		result.Status = config.PAYMENT_CHARGE_STATUS_PAID
+	case "EXPIRED":
+		result.Status = config.PAYMENT_CHARGE_STATUS_EXPIRED
+	default:
+		result.Status = config.PAYMENT_CHARGE_STATUS_PENDING
+	}
+
+	if i.PaymentMethod != nil {
+		var method config.PaymentMethod
+		switch *i.PaymentMethod {
+		case "QR_CODE":
+			method = config.PAYMENT_METHOD_QRIS
+		case "CREDIT_CARD", "DEBIT_CARD":
+			method = config.PAYMENT_METHOD_CARD
+		default:
+			method = config.PAYMENT_METHOD_BANK_TRANSFER
+		}
+		result.Method = &method
+	}
+
+	return result
+}
+
+func parseXenditTime(value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
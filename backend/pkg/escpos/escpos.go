@@ -0,0 +1,291 @@
+package escpos
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Control bytes of the ESC/POS command set
+const (
+	esc = 0x1b
+	gs  = 0x1d
+	lf  = 0x0a
+)
+
+// PaperWidth is the roll width of a thermal printer in millimetres
+type PaperWidth int
+
+const (
+	PaperWidth58 PaperWidth = 58
+	PaperWidth80 PaperWidth = 80
+)
+
+// Columns is the number of font A characters that fit on one line
+func (w PaperWidth) Columns() int {
+	if w == PaperWidth80 {
+		return 48
+	}
+	return 32
+}
+
+// Dots is the printable width in dots at 203 dpi
+func (w PaperWidth) Dots() int {
+	if w == PaperWidth80 {
+		return 576
+	}
+	return 384
+}
+
+// CodePage is a character table of the printer, selected with ESC t
+type CodePage string
+
+const (
+	CodePagePC437   CodePage = "pc437"
+	CodePagePC850   CodePage = "pc850"
+	CodePagePC858   CodePage = "pc858"
+	CodePageWPC1252 CodePage = "wpc1252"
+)
+
+var codePages = map[CodePage]struct {
+	table   byte
+	charmap *charmap.Charmap
+}{
+	CodePagePC437:   {0, charmap.CodePage437},
+	CodePagePC850:   {2, charmap.CodePage850},
+	CodePagePC858:   {19, charmap.CodePage858},
+	CodePageWPC1252: {16, charmap.Windows1252},
+}
+
+type Align byte
+
+const (
+	AlignLeft   Align = 0
+	AlignCenter Align = 1
+	AlignRight  Align = 2
+)
+
+var ErrUnknownCodePage = errors.New("escpos code page is not supported")
+
+// Printer collects ESC/POS commands for one print job
+type Printer struct {
+	buf      bytes.Buffer
+	width    PaperWidth
+	codePage CodePage
+	wide     bool
+}
+
+// New starts a print job, resetting the printer and selecting the code page
+func New(width PaperWidth, codePage CodePage) (*Printer, error) {
+	page, ok := codePages[codePage]
+	if !ok {
+		return nil, ErrUnknownCodePage
+	}
+
+	p := &Printer{width: width, codePage: codePage}
+	p.buf.Write([]byte{esc, '@'})
+	p.buf.Write([]byte{esc, 't', page.table})
+	return p, nil
+}
+
+// Columns is the number of characters that fit on one line in the current size
+func (p *Printer) Columns() int {
+	if p.wide {
+		return p.width.Columns() / 2
+	}
+	return p.width.Columns()
+}
+
+func (p *Printer) Align(align Align) *Printer {
+	p.buf.Write([]byte{esc, 'a', byte(align)})
+	return p
+}
+
+func (p *Printer) Bold(on bool) *Printer {
+	p.buf.Write([]byte{esc, 'E', boolByte(on)})
+	return p
+}
+
+// Large doubles the width and height of the following text
+func (p *Printer) Large(on bool) *Printer {
+	size := byte(0x00)
+	if on {
+		size = 0x11
+	}
+	p.wide = on
+	p.buf.Write([]byte{gs, '!', size})
+	return p
+}
+
+// Text prints the text wrapped to the line width, ending with a line feed
+func (p *Printer) Text(text string) *Printer {
+	for _, line := range Wrap(text, p.Columns()) {
+		p.write(line)
+		p.buf.WriteByte(lf)
+	}
+	return p
+}
+
+// Row prints the left text with the right text aligned to the end of the line.
+// A left text too long to share the line is wrapped above it.
+func (p *Printer) Row(left, right string) *Printer {
+	columns := p.Columns()
+	rightWidth := len([]rune(right))
+	lines := Wrap(left, columns)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	last := lines[len(lines)-1]
+	if len([]rune(last))+1+rightWidth > columns {
+		lines = append(lines, "")
+		last = ""
+	}
+	for _, line := range lines[:len(lines)-1] {
+		p.write(line)
+		p.buf.WriteByte(lf)
+	}
+
+	padding := columns - len([]rune(last)) - rightWidth
+	if padding < 1 {
+		padding = 1
+	}
+	p.write(last + strings.Repeat(" ", padding) + right)
+	p.buf.WriteByte(lf)
+	return p
+}
+
+// Separator prints a dashed line across the paper
+func (p *Printer) Separator() *Printer {
+	p.write(strings.Repeat("-", p.Columns()))
+	p.buf.WriteByte(lf)
+	return p
+}
+
+// Feed prints the given number of empty lines
+func (p *Printer) Feed(lines int) *Printer {
+	if lines > 0 {
+		p.buf.Write([]byte{esc, 'd', byte(lines)})
+	}
+	return p
+}
+
+// Cut feeds the paper past the cutter and makes a partial cut
+func (p *Printer) Cut() *Printer {
+	p.buf.Write([]byte{gs, 'V', 66, 0})
+	return p
+}
+
+// Image prints a raster image scaled down to fit maxDots, or the paper width when zero
+func (p *Printer) Image(img image.Image, maxDots int) *Printer {
+	if maxDots <= 0 || maxDots > p.width.Dots() {
+		maxDots = p.width.Dots()
+	}
+
+	bitmap, widthBytes, height := rasterize(img, maxDots)
+	if height == 0 {
+		return p
+	}
+
+	// GS v 0, normal density raster bit image
+	p.buf.Write([]byte{gs, 'v', '0', 0,
+		byte(widthBytes), byte(widthBytes >> 8),
+		byte(height), byte(height >> 8),
+	})
+	p.buf.Write(bitmap)
+	return p
+}
+
+// Bytes returns the commands of the print job
+func (p *Printer) Bytes() []byte {
+	return p.buf.Bytes()
+}
+
+// write encodes the text in the selected code page, characters without a glyph become '?'
+func (p *Printer) write(text string) {
+	encoder := codePages[p.codePage].charmap
+	for _, r := range text {
+		b, ok := encoder.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		p.buf.WriteByte(b)
+	}
+}
+
+// Wrap breaks the text into lines of at most width characters, on spaces when possible
+func Wrap(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := []rune{}
+		for _, word := range strings.Fields(paragraph) {
+			runes := []rune(word)
+			for len(runes) > width {
+				if len(line) > 0 {
+					lines = append(lines, string(line))
+					line = line[:0]
+				}
+				lines = append(lines, string(runes[:width]))
+				runes = runes[width:]
+			}
+			if len(line) > 0 && len(line)+1+len(runes) > width {
+				lines = append(lines, string(line))
+				line = line[:0]
+			}
+			if len(line) > 0 {
+				line = append(line, ' ')
+			}
+			line = append(line, runes...)
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// rasterize scales the image to at most maxDots wide and thresholds it to one bit per dot, MSB first
+func rasterize(img image.Image, maxDots int) ([]byte, int, int) {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if srcWidth == 0 || srcHeight == 0 {
+		return nil, 0, 0
+	}
+
+	width, height := srcWidth, srcHeight
+	if width > maxDots {
+		width = maxDots
+		height = srcHeight * maxDots / srcWidth
+		if height == 0 {
+			height = 1
+		}
+	}
+
+	widthBytes := (width + 7) / 8
+	bitmap := make([]byte, widthBytes*height)
+	for y := 0; y < height; y++ {
+		srcY := bounds.Min.Y + y*srcHeight/height
+		for x := 0; x < width; x++ {
+			srcX := bounds.Min.X + x*srcWidth/width
+			r, g, b, a := img.At(srcX, srcY).RGBA()
+			// Transparent pixels are paper, the rest is dark below half luminance
+			if a < 0x8000 {
+				continue
+			}
+			luminance := (299*r + 587*g + 114*b) / 1000
+			if luminance < 0x8000 {
+				bitmap[y*widthBytes+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+
+	return bitmap, widthBytes, height
+}
+
+func boolByte(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
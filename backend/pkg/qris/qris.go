@@ -0,0 +1,217 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// EMVCo merchant presented mode tags used by QRIS
+const (
+	tagPayloadFormat        = "00"
+	tagPointOfInitiation    = "01"
+	tagMerchantAccount      = "26"
+	tagQrisMerchantAccount  = "51"
+	tagMerchantCategoryCode = "52"
+	tagCurrency             = "53"
+	tagAmount               = "54"
+	tagCountryCode          = "58"
+	tagMerchantName         = "59"
+	tagMerchantCity         = "60"
+	tagPostalCode           = "61"
+	tagAdditionalData       = "62"
+	tagCRC                  = "63"
+
+	// Sub tags of merchant account information
+	subTagGlobalUniqueID = "00"
+	subTagMerchantPAN    = "01"
+	subTagMerchantID     = "02"
+	subTagCriteria       = "03"
+
+	// Sub tags of additional data
+	subTagBillNumber    = "01"
+	subTagTerminalLabel = "07"
+
+	payloadFormatVersion = "01"
+	initiationStatic     = "11"
+	initiationDynamic    = "12"
+	qrisGlobalUniqueID   = "ID.CO.QRIS.WWW"
+	currencyIDR          = "360"
+	countryID            = "ID"
+
+	maxMerchantNameLength = 25
+	maxMerchantCityLength = 15
+	maxBillNumberLength   = 25
+	maxValueLength        = 99
+)
+
+var (
+	ErrIncompleteMerchant = errors.New("qris merchant data is incomplete")
+	ErrInvalidAmount      = errors.New("qris amount must be greater than zero")
+	ErrValueTooLong       = errors.New("qris value is longer than 99 bytes")
+)
+
+// Merchant holds the data printed on the merchant's QRIS sticker by the acquirer
+type Merchant struct {
+	Name           string
+	City           string
+	PostalCode     string
+	MCC            string // Merchant category code, 4 digits
+	NMID           string // National merchant ID issued by the QRIS switch
+	Criteria       string // UMI, UKE, UME, UBE or URE
+	AcquirerDomain string // Reverse domain of the acquirer, example ID.CO.BANKNAME.WWW
+	MerchantPAN    string
+	MerchantID     string
+}
+
+// GenerateStatic builds a static QRIS payload where the customer enters the amount
+func GenerateStatic(m Merchant) (string, error) {
+	return generate(m, initiationStatic, "", "")
+}
+
+// GenerateDynamic builds a single use QRIS payload with the amount and bill number embedded
+func GenerateDynamic(m Merchant, amount float64, billNumber string) (string, error) {
+	if amount <= 0 {
+		return "", ErrInvalidAmount
+	}
+	return generate(m, initiationDynamic, formatAmount(amount), billNumber)
+}
+
+func generate(m Merchant, initiation, amount, billNumber string) (string, error) {
+	if m.Name == "" || m.City == "" || m.MCC == "" || m.NMID == "" {
+		return "", ErrIncompleteMerchant
+	}
+
+	var b builder
+	b.add(tagPayloadFormat, payloadFormatVersion)
+	b.add(tagPointOfInitiation, initiation)
+
+	// Acquirer account is optional, the QRIS account below is enough for the switch to route
+	if m.AcquirerDomain != "" && m.MerchantPAN != "" {
+		account, err := merchantAccount(m.AcquirerDomain, m.MerchantPAN, m.MerchantID, m.Criteria)
+		if err != nil {
+			return "", err
+		}
+		b.add(tagMerchantAccount, account)
+	}
+	account, err := merchantAccount(qrisGlobalUniqueID, "", m.NMID, m.Criteria)
+	if err != nil {
+		return "", err
+	}
+	b.add(tagQrisMerchantAccount, account)
+
+	b.add(tagMerchantCategoryCode, m.MCC)
+	b.add(tagCurrency, currencyIDR)
+	if amount != "" {
+		b.add(tagAmount, amount)
+	}
+	b.add(tagCountryCode, countryID)
+	b.add(tagMerchantName, truncate(m.Name, maxMerchantNameLength))
+	b.add(tagMerchantCity, truncate(m.City, maxMerchantCityLength))
+	if m.PostalCode != "" {
+		b.add(tagPostalCode, m.PostalCode)
+	}
+	if billNumber != "" {
+		var additional builder
+		additional.add(subTagBillNumber, truncate(billNumber, maxBillNumberLength))
+		additional.add(subTagTerminalLabel, "POS")
+		if additional.err != nil {
+			return "", additional.err
+		}
+		b.add(tagAdditionalData, additional.String())
+	}
+	if b.err != nil {
+		return "", b.err
+	}
+
+	// The checksum covers everything up to and including the CRC tag and its length
+	payload := b.String() + tagCRC + "04"
+
+	return payload + CRC16(payload), nil
+}
+
+func merchantAccount(globalUniqueID, pan, merchantID, criteria string) (string, error) {
+	var b builder
+	b.add(subTagGlobalUniqueID, globalUniqueID)
+	if pan != "" {
+		b.add(subTagMerchantPAN, pan)
+	}
+	if merchantID != "" {
+		b.add(subTagMerchantID, merchantID)
+	}
+	if criteria != "" {
+		b.add(subTagCriteria, criteria)
+	}
+	return b.String(), b.err
+}
+
+// builder joins data objects and keeps the first encoding error
+type builder struct {
+	strings.Builder
+	err error
+}
+
+func (b *builder) add(id, value string) {
+	if b.err != nil {
+		return
+	}
+
+	encoded, err := tlv(id, value)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.WriteString(encoded)
+}
+
+// tlv encodes a data object as ID, two digit length and value
+func tlv(id, value string) (string, error) {
+	if len(value) > maxValueLength {
+		return "", fmt.Errorf("%w: tag %s has %d bytes", ErrValueTooLong, id, len(value))
+	}
+	return fmt.Sprintf("%s%02d%s", id, len(value), value), nil
+}
+
+// formatAmount drops the decimals for whole rupiah amounts, which is what most issuers expect
+func formatAmount(amount float64) string {
+	if amount == math.Trunc(amount) {
+		return fmt.Sprintf("%.0f", amount)
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// truncate cuts s to at most max bytes without splitting a multi-byte character
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= max {
+		return s
+	}
+
+	end := 0
+	for end < len(s) {
+		_, size := utf8.DecodeRuneInString(s[end:])
+		if end+size > max {
+			break
+		}
+		end += size
+	}
+	return strings.TrimSpace(s[:end])
+}
+
+// CRC16 computes the CRC-16/CCITT-FALSE checksum required by EMVCo as 4 uppercase hex digits
+func CRC16(payload string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(payload); i++ {
+		crc ^= uint16(payload[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"time"
 
@@ -100,3 +101,16 @@ func (r *R2Storage) PublicURL(key string) string {
 	}
 	return fmt.Sprintf("%s/%s", r.publicURL, key)
 }
+
+func (r *R2Storage) Download(key string) ([]byte, error) {
+	out, err := r.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: &r.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	crand "crypto/rand"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -65,3 +66,39 @@ func GenerateBusinessCode() string {
 	code := r.Intn(1000000) // 0 to 999999
 	return fmt.Sprintf("%06d", code)
 }
+
+// GenerateCode generates a random uppercase code for vouchers and gift cards,
+// leaving out characters that are easy to misread (0, O, 1 and I)
+func GenerateCode(length int) (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	code := make([]byte, length)
+	if _, err := crand.Read(code); err != nil {
+		return "", err
+	}
+	// The alphabet has 32 characters, so every byte maps to one without bias
+	for i := range code {
+		code[i] = alphabet[int(code[i])%len(alphabet)]
+	}
+	return string(code), nil
+}
+
+// NormalizeCode trims and uppercases a voucher or gift card code as typed by a cashier
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NormalizePhoneNumber strips formatting from a phone number and returns it in international
+// format without the plus sign, a leading 0 is taken as an Indonesian number (0812... becomes 62812...)
+func NormalizePhoneNumber(phone string) (string, bool) {
+	number := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+	number = strings.TrimPrefix(number, "+")
+	if strings.HasPrefix(number, "0") {
+		number = "62" + number[1:]
+	}
+
+	if len(number) < 8 || len(number) > 15 || strings.Trim(number, "0123456789") != "" {
+		return "", false
+	}
+	return number, true
+}